@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"math/rand"
 	"net/http"
@@ -14,6 +17,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -29,148 +33,20 @@ import (
 	"go.mau.fi/whatsmeow/types/events"
 	waLog "go.mau.fi/whatsmeow/util/log"
 	"google.golang.org/protobuf/proto"
-)
-
-// Message represents a chat message for our client
-type Message struct {
-	Time      time.Time
-	Sender    string
-	Content   string
-	IsFromMe  bool
-	MediaType string
-	Filename  string
-}
-
-// Database handler for storing message history
-type MessageStore struct {
-	db *sql.DB
-}
-
-// Initialize message store
-func NewMessageStore() (*MessageStore, error) {
-	// Create directory for database if it doesn't exist
-	if err := os.MkdirAll("store", 0755); err != nil {
-		return nil, fmt.Errorf("failed to create store directory: %v", err)
-	}
-
-	// Open SQLite database for messages
-	db, err := sql.Open("sqlite3", "file:store/messages.db?_foreign_keys=on")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open message database: %v", err)
-	}
-
-	// Create tables if they don't exist
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS chats (
-			jid TEXT PRIMARY KEY,
-			name TEXT,
-			last_message_time TIMESTAMP
-		);
-		
-		CREATE TABLE IF NOT EXISTS messages (
-			id TEXT,
-			chat_jid TEXT,
-			sender TEXT,
-			content TEXT,
-			timestamp TIMESTAMP,
-			is_from_me BOOLEAN,
-			media_type TEXT,
-			filename TEXT,
-			url TEXT,
-			media_key BLOB,
-			file_sha256 BLOB,
-			file_enc_sha256 BLOB,
-			file_length INTEGER,
-			PRIMARY KEY (id, chat_jid),
-			FOREIGN KEY (chat_jid) REFERENCES chats(jid)
-		);
-	`)
-	if err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to create tables: %v", err)
-	}
-
-	return &MessageStore{db: db}, nil
-}
-
-// Close the database connection
-func (store *MessageStore) Close() error {
-	return store.db.Close()
-}
 
-// Store a chat in the database
-func (store *MessageStore) StoreChat(jid, name string, lastMessageTime time.Time) error {
-	_, err := store.db.Exec(
-		"INSERT OR REPLACE INTO chats (jid, name, last_message_time) VALUES (?, ?, ?)",
-		jid, name, lastMessageTime,
-	)
-	return err
-}
-
-// Store a message in the database
-func (store *MessageStore) StoreMessage(id, chatJID, sender, content string, timestamp time.Time, isFromMe bool,
-	mediaType, filename, url string, mediaKey, fileSHA256, fileEncSHA256 []byte, fileLength uint64) error {
-	// Only store if there's actual content or media
-	if content == "" && mediaType == "" {
-		return nil
-	}
-
-	_, err := store.db.Exec(
-		`INSERT OR REPLACE INTO messages 
-		(id, chat_jid, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		id, chatJID, sender, content, timestamp, isFromMe, mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength,
-	)
-	return err
-}
-
-// Get messages from a chat
-func (store *MessageStore) GetMessages(chatJID string, limit int) ([]Message, error) {
-	rows, err := store.db.Query(
-		"SELECT sender, content, timestamp, is_from_me, media_type, filename FROM messages WHERE chat_jid = ? ORDER BY timestamp DESC LIMIT ?",
-		chatJID, limit,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var messages []Message
-	for rows.Next() {
-		var msg Message
-		var timestamp time.Time
-		err := rows.Scan(&msg.Sender, &msg.Content, &timestamp, &msg.IsFromMe, &msg.MediaType, &msg.Filename)
-		if err != nil {
-			return nil, err
-		}
-		msg.Time = timestamp
-		messages = append(messages, msg)
-	}
-
-	return messages, nil
-}
-
-// Get all chats
-func (store *MessageStore) GetChats() (map[string]time.Time, error) {
-	rows, err := store.db.Query("SELECT jid, last_message_time FROM chats ORDER BY last_message_time DESC")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	chats := make(map[string]time.Time)
-	for rows.Next() {
-		var jid string
-		var lastMessageTime time.Time
-		err := rows.Scan(&jid, &lastMessageTime)
-		if err != nil {
-			return nil, err
-		}
-		chats[jid] = lastMessageTime
-	}
-
-	return chats, nil
-}
+	"whatsapp-client/pkg/api"
+	"whatsapp-client/pkg/config"
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/janitor"
+	"whatsapp-client/pkg/jobs"
+	applog "whatsapp-client/pkg/logger"
+	"whatsapp-client/pkg/metrics"
+	"whatsapp-client/pkg/openapi"
+	"whatsapp-client/pkg/scheduler"
+	"whatsapp-client/pkg/tlsutil"
+	"whatsapp-client/pkg/validation"
+	"whatsapp-client/pkg/webhook"
+)
 
 // Extract text content from a message
 func extractTextContent(msg *waProto.Message) string {
@@ -183,16 +59,60 @@ func extractTextContent(msg *waProto.Message) string {
 		return text
 	} else if extendedText := msg.GetExtendedTextMessage(); extendedText != nil {
 		return extendedText.GetText()
+	} else if poll := pollCreation(msg); poll != nil {
+		// A poll is shown as its question; options and votes are stored apart
+		return poll.GetName()
+	} else if contact := msg.GetContactMessage(); contact != nil {
+		// A shared contact is shown as its name
+		return contact.GetDisplayName()
 	}
 
 	// For now, we're ignoring non-text messages
 	return ""
 }
 
+// Return the poll a message creates, whichever poll message version it uses
+func pollCreation(msg *waProto.Message) *waProto.PollCreationMessage {
+	if poll := msg.GetPollCreationMessage(); poll != nil {
+		return poll
+	}
+	if poll := msg.GetPollCreationMessageV2(); poll != nil {
+		return poll
+	}
+	return msg.GetPollCreationMessageV3()
+}
+
+// Extract the ID and text of the message being replied to, if any
+func extractQuotedContext(msg *waProto.Message) (quotedID string, quotedContent string) {
+	if msg == nil {
+		return "", ""
+	}
+
+	var contextInfo *waProto.ContextInfo
+	switch {
+	case msg.GetExtendedTextMessage() != nil:
+		contextInfo = msg.GetExtendedTextMessage().GetContextInfo()
+	case msg.GetImageMessage() != nil:
+		contextInfo = msg.GetImageMessage().GetContextInfo()
+	case msg.GetVideoMessage() != nil:
+		contextInfo = msg.GetVideoMessage().GetContextInfo()
+	case msg.GetAudioMessage() != nil:
+		contextInfo = msg.GetAudioMessage().GetContextInfo()
+	case msg.GetDocumentMessage() != nil:
+		contextInfo = msg.GetDocumentMessage().GetContextInfo()
+	}
+
+	if contextInfo.GetStanzaID() == "" {
+		return "", ""
+	}
+	return contextInfo.GetStanzaID(), extractTextContent(contextInfo.GetQuotedMessage())
+}
+
 // SendMessageResponse represents the response for the send message API
 type SendMessageResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	Reason  string `json:"reason,omitempty"`
 }
 
 // SendMessageRequest represents the request body for the send message API
@@ -204,6 +124,13 @@ type SendMessageRequest struct {
 
 // Function to send a WhatsApp message
 func sendWhatsAppMessage(client *whatsmeow.Client, recipient string, message string, mediaPath string) (bool, string) {
+	return sendWhatsAppMedia(client, recipient, message, mediaPath, true)
+}
+
+// sendWhatsAppMedia sends a WhatsApp message; voiceNote selects whether an
+// Ogg audio attachment is sent as a push-to-talk voice note or as a plain
+// audio file
+func sendWhatsAppMedia(client *whatsmeow.Client, recipient string, message string, mediaPath string, voiceNote bool) (bool, string) {
 	if !client.IsConnected() {
 		return false, "Not connected to WhatsApp"
 	}
@@ -212,6 +139,12 @@ func sendWhatsAppMessage(client *whatsmeow.Client, recipient string, message str
 	var recipientJID types.JID
 	var err error
 
+	// Canonicalize phone numbers so that every spelling of a number
+	// reaches the same account
+	if normalized, err := validation.NormalizeRecipient(recipient); err == nil {
+		recipient = normalized
+	}
+
 	// Check if recipient is a JID
 	isJID := strings.Contains(recipient, "@")
 
@@ -330,7 +263,7 @@ func sendWhatsAppMessage(client *whatsmeow.Client, recipient string, message str
 				FileSHA256:    resp.FileSHA256,
 				FileLength:    &resp.FileLength,
 				Seconds:       proto.Uint32(seconds),
-				PTT:           proto.Bool(true),
+				PTT:           proto.Bool(voiceNote),
 				Waveform:      waveform,
 			}
 		case whatsmeow.MediaVideo:
@@ -395,6 +328,11 @@ func extractMediaInfo(msg *waProto.Message) (mediaType string, filename string,
 			aud.GetURL(), aud.GetMediaKey(), aud.GetFileSHA256(), aud.GetFileEncSHA256(), aud.GetFileLength()
 	}
 
+	// Check for a shared contact card; it has nothing to download
+	if msg.GetContactMessage() != nil {
+		return "vcard", "", "", nil, nil, nil, 0
+	}
+
 	// Check for document message
 	if doc := msg.GetDocumentMessage(); doc != nil {
 		filename := doc.GetFileName()
@@ -408,52 +346,215 @@ func extractMediaInfo(msg *waProto.Message) (mediaType string, filename string,
 	return "", "", "", nil, nil, nil, 0
 }
 
+// applyLocation copies the coordinates, name and address of a shared or live
+// location onto dst
+func applyLocation(dst *database.Message, msg *waProto.Message) {
+	if loc := msg.GetLocationMessage(); loc != nil {
+		lat, lon := loc.GetDegreesLatitude(), loc.GetDegreesLongitude()
+		dst.LocationLat, dst.LocationLon = &lat, &lon
+		dst.LocationName = loc.GetName()
+		dst.LocationAddress = loc.GetAddress()
+		dst.LocationIsLive = loc.GetIsLive()
+	} else if live := msg.GetLiveLocationMessage(); live != nil {
+		lat, lon := live.GetDegreesLatitude(), live.GetDegreesLongitude()
+		dst.LocationLat, dst.LocationLon = &lat, &lon
+		dst.LocationIsLive = true
+	}
+}
+
+// applyMediaMetadata copies the MIME type, dimensions, duration and preview
+// thumbnail of a media attachment onto dst
+func applyMediaMetadata(dst *database.Message, msg *waProto.Message) {
+	if img := msg.GetImageMessage(); img != nil {
+		dst.MediaMimeType = img.GetMimetype()
+		dst.MediaWidth = int(img.GetWidth())
+		dst.MediaHeight = int(img.GetHeight())
+		dst.Thumbnail = img.GetJPEGThumbnail()
+	} else if vid := msg.GetVideoMessage(); vid != nil {
+		dst.MediaMimeType = vid.GetMimetype()
+		dst.MediaWidth = int(vid.GetWidth())
+		dst.MediaHeight = int(vid.GetHeight())
+		dst.MediaDurationSeconds = int(vid.GetSeconds())
+		dst.Thumbnail = vid.GetJPEGThumbnail()
+	} else if aud := msg.GetAudioMessage(); aud != nil {
+		dst.MediaMimeType = aud.GetMimetype()
+		dst.MediaDurationSeconds = int(aud.GetSeconds())
+	} else if doc := msg.GetDocumentMessage(); doc != nil {
+		dst.MediaMimeType = doc.GetMimetype()
+		dst.Thumbnail = doc.GetJPEGThumbnail()
+	} else if contact := msg.GetContactMessage(); contact != nil {
+		dst.VCard = contact.GetVcard()
+	}
+}
+
 // Handle regular incoming messages with media support
-func handleMessage(client *whatsmeow.Client, messageStore *MessageStore, msg *events.Message, logger waLog.Logger) {
+func handleMessage(client *whatsmeow.Client, store *database.Store, hub *api.EventHub, dispatcher *webhook.WebhookDispatcher, msg *events.Message, logger waLog.Logger) {
+	// Status updates are not chat messages and are kept apart
+	if msg.Info.Chat == types.StatusBroadcastJID {
+		handleStatus(store, msg, logger)
+		return
+	}
+
 	// Save message to database
 	chatJID := msg.Info.Chat.String()
 	sender := msg.Info.Sender.User
 
 	// Get appropriate chat name (pass nil for conversation since we don't have one for regular messages)
-	name := GetChatName(client, messageStore, msg.Info.Chat, chatJID, nil, sender, logger)
+	name := GetChatName(client, store, msg.Info.Chat, chatJID, nil, sender, logger)
 
 	// Update chat in database with the message timestamp (keeps last message time updated)
-	err := messageStore.StoreChat(chatJID, name, msg.Info.Timestamp)
+	err := store.StoreChat(&database.Chat{
+		JID:             chatJID,
+		Name:            name,
+		LastMessageTime: msg.Info.Timestamp,
+	})
 	if err != nil {
 		logger.Warnf("Failed to store chat: %v", err)
 	}
 
+	// A revoke retracts an earlier message instead of carrying content
+	if protocolMsg := msg.Message.GetProtocolMessage(); protocolMsg != nil && protocolMsg.GetType() == waProto.ProtocolMessage_REVOKE {
+		revokedID := protocolMsg.GetKey().GetID()
+		err := store.SoftDeleteMessage(revokedID, chatJID, msg.Info.Timestamp)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("Failed to mark message %s as deleted: %v", revokedID, err)
+		}
+		return
+	}
+
+	// An edit replaces the content of an earlier message; the old content is kept
+	if protocolMsg := msg.Message.GetProtocolMessage(); protocolMsg != nil && protocolMsg.GetType() == waProto.ProtocolMessage_MESSAGE_EDIT {
+		editedID := protocolMsg.GetKey().GetID()
+		editedAt := msg.Info.Timestamp
+		if ts := protocolMsg.GetTimestampMS(); ts > 0 {
+			editedAt = time.UnixMilli(ts)
+		}
+		// Only text edits are tracked; caption edits carry no text content
+		content := extractTextContent(protocolMsg.GetEditedMessage())
+		if content == "" {
+			return
+		}
+		err := store.RecordMessageEdit(editedID, chatJID, content, editedAt)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("Failed to record edit of message %s: %v", editedID, err)
+		}
+		return
+	}
+
+	// A reaction annotates an earlier message; an empty emoji removes it
+	if reaction := msg.Message.GetReactionMessage(); reaction != nil {
+		reactedID := reaction.GetKey().GetID()
+		if reaction.GetText() == "" {
+			err = store.DeleteReaction(reactedID, chatJID, sender)
+		} else {
+			err = store.StoreReaction(&database.Reaction{
+				MessageID: reactedID,
+				ChatJID:   chatJID,
+				Sender:    sender,
+				Emoji:     reaction.GetText(),
+				Timestamp: msg.Info.Timestamp,
+			})
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("Failed to store reaction to message %s: %v", reactedID, err)
+		}
+		return
+	}
+
+	// Pinning changes an earlier message's state rather than adding one
+	if pin := msg.Message.GetPinInChatMessage(); pin != nil {
+		pinnedID := pin.GetKey().GetID()
+		var err error
+		switch pin.GetType() {
+		case waProto.PinInChatMessage_PIN_FOR_ALL:
+			err = store.PinMessage(pinnedID, chatJID, sender, msg.Info.Timestamp)
+		case waProto.PinInChatMessage_UNPIN_FOR_ALL:
+			err = store.UnpinMessage(pinnedID, chatJID)
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("Failed to update pin of message %s: %v", pinnedID, err)
+		}
+		return
+	}
+
+	// A poll vote changes an earlier poll's results rather than adding a message
+	if msg.Message.GetPollUpdateMessage() != nil {
+		handlePollVote(client, store, msg, logger)
+		return
+	}
+
+	// A poll is stored as a message carrying its question plus its options
+	if poll := pollCreation(msg.Message); poll != nil {
+		options := make([]database.PollOption, len(poll.GetOptions()))
+		for i, option := range poll.GetOptions() {
+			options[i] = database.NewPollOption(option.GetOptionName())
+		}
+		err := store.StorePoll(&database.PollMessage{
+			MessageID:     msg.Info.ID,
+			ChatJID:       chatJID,
+			Question:      poll.GetName(),
+			Options:       options,
+			AllowMultiple: poll.GetSelectableOptionsCount() != 1,
+		})
+		if err != nil {
+			logger.Warnf("Failed to store poll %s: %v", msg.Info.ID, err)
+		}
+	}
+
 	// Extract text content
 	content := extractTextContent(msg.Message)
 
 	// Extract media info
 	mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength := extractMediaInfo(msg.Message)
 
-	// Skip if there's no content and no media
-	if content == "" && mediaType == "" {
+	// Extract the message being replied to
+	quotedID, quotedContent := extractQuotedContext(msg.Message)
+
+	// Store message in database
+	message := &database.Message{
+		ID:            msg.Info.ID,
+		ChatJID:       chatJID,
+		Sender:        sender,
+		Content:       content,
+		Timestamp:     msg.Info.Timestamp,
+		IsFromMe:      msg.Info.IsFromMe,
+		MediaType:     mediaType,
+		Filename:      filename,
+		URL:           url,
+		MediaKey:      mediaKey,
+		FileSHA256:    fileSHA256,
+		FileEncSHA256: fileEncSHA256,
+		FileLength:    fileLength,
+
+		QuotedMessageID:      quotedID,
+		QuotedMessageContent: quotedContent,
+	}
+	applyMediaMetadata(message, msg.Message)
+	applyLocation(message, msg.Message)
+
+	// Skip if there's no content, media or location
+	if content == "" && mediaType == "" && !message.IsLocation() {
 		return
 	}
 
-	// Store message in database
-	err = messageStore.StoreMessage(
-		msg.Info.ID,
-		chatJID,
-		sender,
-		content,
-		msg.Info.Timestamp,
-		msg.Info.IsFromMe,
-		mediaType,
-		filename,
-		url,
-		mediaKey,
-		fileSHA256,
-		fileEncSHA256,
-		fileLength,
-	)
+	err = store.StoreMessage(message)
 
 	if err != nil {
 		logger.Warnf("Failed to store message: %v", err)
 	} else {
+		// Push the message to WebSocket subscribers
+		hub.PublishMessage(message)
+
+		// Notify webhooks of messages received from others. Deliveries
+		// retry with backoff, so they must not hold up the event handler.
+		if !message.IsFromMe {
+			go func() {
+				if err := dispatcher.Dispatch(webhook.WebhookEvent{Type: webhook.EventNewMessage, Data: message}); err != nil {
+					logger.Warnf("Failed to deliver message %s to webhooks: %v", message.ID, err)
+				}
+			}()
+		}
+
 		// Log message reception
 		timestamp := msg.Info.Timestamp.Format("2006-01-02 15:04:05")
 		direction := "←"
@@ -464,12 +565,319 @@ func handleMessage(client *whatsmeow.Client, messageStore *MessageStore, msg *ev
 		// Log based on message type
 		if mediaType != "" {
 			fmt.Printf("[%s] %s %s: [%s: %s] %s\n", timestamp, direction, sender, mediaType, filename, content)
+		} else if message.IsLocation() {
+			fmt.Printf("[%s] %s %s: [location: %.5f, %.5f] %s\n", timestamp, direction, sender,
+				*message.LocationLat, *message.LocationLon, message.LocationName)
 		} else if content != "" {
 			fmt.Printf("[%s] %s %s: %s\n", timestamp, direction, sender, content)
 		}
 	}
 }
 
+// Store a status update posted by a contact or by us
+func handleStatus(store *database.Store, msg *events.Message, logger waLog.Logger) {
+	content := extractTextContent(msg.Message)
+	mediaType, _, url, mediaKey, _, _, _ := extractMediaInfo(msg.Message)
+	if content == "" && mediaType == "" {
+		return
+	}
+
+	err := store.StoreStatus(&database.StatusMessage{
+		ID:        msg.Info.ID,
+		AuthorJID: msg.Info.Sender.ToNonAD().String(),
+		Content:   content,
+		MediaType: mediaType,
+		URL:       url,
+		MediaKey:  mediaKey,
+		Timestamp: msg.Info.Timestamp,
+	})
+	if err != nil {
+		logger.Warnf("Failed to store status %s: %v", msg.Info.ID, err)
+	}
+}
+
+// Copy the contacts known to the WhatsApp session into the message store
+func syncContacts(client *whatsmeow.Client, store *database.Store, logger waLog.Logger) {
+	contacts, err := client.Store.Contacts.GetAllContacts()
+	if err != nil {
+		logger.Warnf("Failed to load contacts: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for jid, info := range contacts {
+		contact := &database.Contact{
+			JID:          jid.String(),
+			DisplayName:  info.FullName,
+			PushName:     info.PushName,
+			BusinessName: info.BusinessName,
+			LastSync:     now,
+		}
+		if contact.DisplayName == "" {
+			contact.DisplayName = info.FirstName
+		}
+		if jid.Server == types.DefaultUserServer {
+			contact.Phone = jid.User
+		}
+
+		if err := store.StoreContact(contact); err != nil {
+			logger.Warnf("Failed to store contact %s: %v", jid, err)
+		}
+	}
+
+	logger.Infof("Synced %d contacts", len(contacts))
+}
+
+// Record group membership changes
+func handleGroupInfo(store *database.Store, info *events.GroupInfo, logger waLog.Logger) {
+	groupJID := info.JID.String()
+	upsert := func(participants []types.JID, isAdmin bool) {
+		for _, jid := range participants {
+			err := store.UpsertParticipant(&database.GroupParticipant{
+				GroupJID:       groupJID,
+				ParticipantJID: jid.String(),
+				IsAdmin:        isAdmin,
+				JoinedAt:       info.Timestamp,
+			})
+			if err != nil {
+				logger.Warnf("Failed to store participant %s of %s: %v", jid, groupJID, err)
+			}
+		}
+	}
+
+	upsert(info.Join, false)
+	upsert(info.Promote, true)
+	upsert(info.Demote, false)
+
+	for _, jid := range info.Leave {
+		err := store.RemoveParticipant(groupJID, jid.String(), info.Timestamp)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("Failed to remove participant %s from %s: %v", jid, groupJID, err)
+		}
+	}
+
+	if info.Name == nil && info.Topic == nil {
+		return
+	}
+	stored, err := store.GetGroupInfo(groupJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		stored = &database.GroupInfo{JID: groupJID}
+	} else if err != nil {
+		logger.Warnf("Failed to get group info of %s: %v", groupJID, err)
+		return
+	}
+	if info.Name != nil {
+		stored.Subject = info.Name.Name
+	}
+	if info.Topic != nil {
+		stored.Description = info.Topic.Topic
+		if info.Topic.TopicDeleted {
+			stored.Description = ""
+		}
+	}
+	stored.LastSyncedAt = time.Now()
+	if err := store.StoreGroupInfo(stored); err != nil {
+		logger.Warnf("Failed to store group info of %s: %v", groupJID, err)
+	}
+}
+
+// Record the metadata of a group fetched from WhatsApp
+func storeGroupInfo(store *database.Store, info *types.GroupInfo, logger waLog.Logger) {
+	stored := &database.GroupInfo{
+		JID:          info.JID.String(),
+		Subject:      info.Name,
+		Description:  info.Topic,
+		LastSyncedAt: time.Now(),
+	}
+	if !info.OwnerJID.IsEmpty() {
+		stored.OwnerJID = info.OwnerJID.String()
+	}
+	if !info.GroupCreated.IsZero() {
+		stored.CreatedAt = &info.GroupCreated
+	}
+	if err := store.StoreGroupInfo(stored); err != nil {
+		logger.Warnf("Failed to store group info of %s: %v", stored.JID, err)
+	}
+}
+
+// Record a call event in the call log. Offered calls are logged as missed
+// until they are accepted or declined; the duration is set when an accepted
+// call ends.
+func handleCall(store *database.Store, evt interface{}, logger waLog.Logger) {
+	var err error
+	switch v := evt.(type) {
+	case *events.CallOffer:
+		callType := database.CallTypeVoice
+		if v.Data != nil && v.Data.GetChildByTag("video").Tag != "" {
+			callType = database.CallTypeVideo
+		}
+		// A one-to-one call takes place in the chat with its caller
+		caller := v.CallCreator.ToNonAD().String()
+		err = store.StoreCallLog(&database.CallLog{
+			ID:         v.CallID,
+			ChatJID:    caller,
+			CallerJID:  caller,
+			CallType:   callType,
+			CallStatus: database.CallStatusMissed,
+			StartedAt:  v.Timestamp,
+		})
+
+	case *events.CallOfferNotice:
+		callType := database.CallTypeVoice
+		if v.Media == "video" {
+			callType = database.CallTypeVideo
+		}
+		err = store.StoreCallLog(&database.CallLog{
+			ID:         v.CallID,
+			ChatJID:    v.From.ToNonAD().String(),
+			CallerJID:  v.CallCreator.ToNonAD().String(),
+			CallType:   callType,
+			CallStatus: database.CallStatusMissed,
+			StartedAt:  v.Timestamp,
+		})
+
+	case *events.CallAccept:
+		err = updateCallLog(store, v.CallID, func(call *database.CallLog) {
+			call.CallStatus = database.CallStatusAnswered
+			call.StartedAt = v.Timestamp
+		})
+
+	case *events.CallReject:
+		err = updateCallLog(store, v.CallID, func(call *database.CallLog) {
+			if call.CallStatus == database.CallStatusMissed {
+				call.CallStatus = database.CallStatusDeclined
+			}
+		})
+
+	case *events.CallTerminate:
+		err = updateCallLog(store, v.CallID, func(call *database.CallLog) {
+			if call.CallStatus == database.CallStatusAnswered {
+				call.DurationSeconds = int(v.Timestamp.Sub(call.StartedAt).Seconds())
+			}
+		})
+	}
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Warnf("Failed to record call event: %v", err)
+	}
+}
+
+// Apply a change to a stored call log entry
+func updateCallLog(store *database.Store, callID string, update func(call *database.CallLog)) error {
+	call, err := store.GetCallLogByID(callID)
+	if err != nil {
+		return err
+	}
+	update(call)
+	return store.StoreCallLog(call)
+}
+
+// Update message delivery status from a receipt
+func handleReceipt(store *database.Store, receipt *events.Receipt, logger waLog.Logger) {
+	var status database.MessageStatus
+	switch receipt.Type {
+	case types.ReceiptTypeDelivered:
+		status = database.StatusDelivered
+	case types.ReceiptTypeRead, types.ReceiptTypeReadSelf:
+		status = database.StatusRead
+	default:
+		return
+	}
+
+	chatJID := receipt.Chat.String()
+	for _, id := range receipt.MessageIDs {
+		err := store.UpdateMessageStatus(id, chatJID, status)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("Failed to update status of message %s: %v", id, err)
+		}
+	}
+}
+
+// Drop the cached profile picture of a user or group whose picture changed
+func handlePicture(store *database.Store, picture *events.Picture, logger waLog.Logger) {
+	if err := store.InvalidateProfilePicture(picture.JID.String()); err != nil {
+		logger.Warnf("Failed to invalidate profile picture of %s: %v", picture.JID, err)
+	}
+}
+
+// Record a vote in a stored poll. Votes are encrypted and name the selected
+// options by their SHA-256 hash, so they are matched against the poll's options.
+func handlePollVote(client *whatsmeow.Client, store *database.Store, msg *events.Message, logger waLog.Logger) {
+	pollID := msg.Message.GetPollUpdateMessage().GetPollCreationMessageKey().GetID()
+	chatJID := msg.Info.Chat.String()
+
+	poll, err := store.GetPoll(pollID, chatJID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("Failed to get poll %s: %v", pollID, err)
+		}
+		return
+	}
+
+	vote, err := client.DecryptPollVote(msg)
+	if err != nil {
+		logger.Warnf("Failed to decrypt vote in poll %s: %v", pollID, err)
+		return
+	}
+
+	hashes := whatsmeow.HashPollOptions(poll.OptionTexts())
+	var selected []string
+	for _, hash := range vote.GetSelectedOptions() {
+		for i, optionHash := range hashes {
+			if bytes.Equal(hash, optionHash) {
+				selected = append(selected, poll.Options[i].Text)
+				break
+			}
+		}
+	}
+
+	err = store.StorePollVote(&database.PollVote{
+		MessageID:       pollID,
+		ChatJID:         chatJID,
+		VoterJID:        msg.Info.Sender.ToNonAD().String(),
+		SelectedOptions: selected,
+		VotedAt:         msg.Info.Timestamp,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Warnf("Failed to store vote in poll %s: %v", pollID, err)
+	}
+}
+
+// Mirror chat archival done on another device
+func handleArchive(store *database.Store, archive *events.Archive, logger waLog.Logger) {
+	chatJID := archive.JID.String()
+
+	var err error
+	if archive.Action.GetArchived() {
+		err = store.ArchiveChat(chatJID, archive.Timestamp)
+	} else {
+		err = store.UnarchiveChat(chatJID)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Warnf("Failed to update archive state of chat %s: %v", chatJID, err)
+	}
+}
+
+// Mirror chat muting done on another device. A chat muted indefinitely has
+// no end timestamp and is stored as muted for a hundred years.
+func handleMute(store *database.Store, mute *events.Mute, logger waLog.Logger) {
+	chatJID := mute.JID.String()
+
+	var err error
+	if mute.Action.GetMuted() {
+		until := mute.Timestamp.AddDate(100, 0, 0)
+		if end := mute.Action.GetMuteEndTimestamp(); end > 0 {
+			until = time.UnixMilli(end)
+		}
+		err = store.MuteChat(chatJID, until)
+	} else {
+		err = store.UnmuteChat(chatJID)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Warnf("Failed to update mute state of chat %s: %v", chatJID, err)
+	}
+}
+
 // DownloadMediaRequest represents the request body for the download media API
 type DownloadMediaRequest struct {
 	MessageID string `json:"message_id"`
@@ -484,29 +892,6 @@ type DownloadMediaResponse struct {
 	Path     string `json:"path,omitempty"`
 }
 
-// Store additional media info in the database
-func (store *MessageStore) StoreMediaInfo(id, chatJID, url string, mediaKey, fileSHA256, fileEncSHA256 []byte, fileLength uint64) error {
-	_, err := store.db.Exec(
-		"UPDATE messages SET url = ?, media_key = ?, file_sha256 = ?, file_enc_sha256 = ?, file_length = ? WHERE id = ? AND chat_jid = ?",
-		url, mediaKey, fileSHA256, fileEncSHA256, fileLength, id, chatJID,
-	)
-	return err
-}
-
-// Get media info from the database
-func (store *MessageStore) GetMediaInfo(id, chatJID string) (string, string, string, []byte, []byte, []byte, uint64, error) {
-	var mediaType, filename, url string
-	var mediaKey, fileSHA256, fileEncSHA256 []byte
-	var fileLength uint64
-
-	err := store.db.QueryRow(
-		"SELECT media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length FROM messages WHERE id = ? AND chat_jid = ?",
-		id, chatJID,
-	).Scan(&mediaType, &filename, &url, &mediaKey, &fileSHA256, &fileEncSHA256, &fileLength)
-
-	return mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength, err
-}
-
 // MediaDownloader implements the whatsmeow.DownloadableMessage interface
 type MediaDownloader struct {
 	URL           string
@@ -554,32 +939,20 @@ func (d *MediaDownloader) GetMediaType() whatsmeow.MediaType {
 }
 
 // Function to download media from a message
-func downloadMedia(client *whatsmeow.Client, messageStore *MessageStore, messageID, chatJID string) (bool, string, string, string, error) {
-	// Query the database for the message
-	var mediaType, filename, url string
-	var mediaKey, fileSHA256, fileEncSHA256 []byte
-	var fileLength uint64
-	var err error
-
+func downloadMedia(client *whatsmeow.Client, store *database.Store, messageID, chatJID string) (bool, string, string, string, error) {
 	// First, check if we already have this file
 	chatDir := fmt.Sprintf("store/%s", strings.ReplaceAll(chatJID, ":", "_"))
 	localPath := ""
 
 	// Get media info from the database
-	mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength, err = messageStore.GetMediaInfo(messageID, chatJID)
-
+	msg, err := store.GetMessage(messageID, chatJID)
 	if err != nil {
-		// Try to get basic info if extended info isn't available
-		err = messageStore.db.QueryRow(
-			"SELECT media_type, filename FROM messages WHERE id = ? AND chat_jid = ?",
-			messageID, chatJID,
-		).Scan(&mediaType, &filename)
-
-		if err != nil {
-			return false, "", "", "", fmt.Errorf("failed to find message: %v", err)
-		}
+		return false, "", "", "", fmt.Errorf("failed to find message: %v", err)
 	}
 
+	mediaType, filename, url := msg.MediaType, msg.Filename, msg.URL
+	mediaKey, fileSHA256, fileEncSHA256, fileLength := msg.MediaKey, msg.FileSHA256, msg.FileEncSHA256, msg.FileLength
+
 	// Check if this is a media message
 	if mediaType == "" {
 		return false, "", "", "", fmt.Errorf("not a media message")
@@ -675,10 +1048,11 @@ func extractDirectPathFromURL(url string) string {
 	return "/" + pathPart
 }
 
-// Start a REST API server to expose the WhatsApp client functionality
-func startRESTServer(client *whatsmeow.Client, messageStore *MessageStore, port int) {
+// Start a REST API server to expose the WhatsApp client functionality. The
+// returned function applies the settings of a reloaded configuration.
+func startRESTServer(client *whatsmeow.Client, store *database.Store, cfg *config.Config, cleaner *janitor.Janitor, queue *jobs.Queue, hub *api.EventHub, appMetrics *metrics.Metrics, port int) func(*config.Config) {
 	// Handler for sending messages
-	http.HandleFunc("/api/send", func(w http.ResponseWriter, r *http.Request) {
+	sendHandler := func(w http.ResponseWriter, r *http.Request) {
 		// Only allow POST requests
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -703,6 +1077,49 @@ func startRESTServer(client *whatsmeow.Client, messageStore *MessageStore, port
 			return
 		}
 
+		if req.MediaPath != "" {
+			if err := validation.ValidateFilePath(req.MediaPath, cfg.AllowedUploadDirs); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid media path: %v", err), http.StatusBadRequest)
+				return
+			}
+			if executable, err := validation.IsExecutable(req.MediaPath); err != nil || executable {
+				http.Error(w, "Invalid media path: file is an executable or cannot be read", http.StatusBadRequest)
+				return
+			}
+			if err := validation.ValidateFileSize(req.MediaPath, cfg.MaxUploadFileSizeBytes); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid media path: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := validation.ValidateMIMEContent(req.MediaPath); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid media path: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Refuse to message blocked contacts
+		recipientJID := req.Recipient
+		if normalized, err := validation.NormalizeRecipient(recipientJID); err == nil {
+			recipientJID = normalized
+		}
+		if !strings.Contains(recipientJID, "@") {
+			recipientJID = types.NewJID(recipientJID, types.DefaultUserServer).String()
+		}
+		blocked, err := store.IsBlocked(recipientJID)
+		if err != nil {
+			http.Error(w, "Failed to check whether the recipient is blocked", http.StatusInternalServerError)
+			return
+		}
+		if blocked {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(SendMessageResponse{
+				Success: false,
+				Message: "Recipient is blocked",
+				Reason:  "blocked",
+			})
+			return
+		}
+
 		fmt.Println("Received request to send message", req.Message, req.MediaPath)
 
 		// Send the message
@@ -721,10 +1138,12 @@ func startRESTServer(client *whatsmeow.Client, messageStore *MessageStore, port
 			Success: success,
 			Message: message,
 		})
-	})
+	}
+	http.HandleFunc("/api/send", sendHandler)
+	http.HandleFunc("/api/v1/send", sendHandler)
 
 	// Handler for downloading media
-	http.HandleFunc("/api/download", func(w http.ResponseWriter, r *http.Request) {
+	downloadHandler := func(w http.ResponseWriter, r *http.Request) {
 		// Only allow POST requests
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -745,7 +1164,7 @@ func startRESTServer(client *whatsmeow.Client, messageStore *MessageStore, port
 		}
 
 		// Download the media
-		success, mediaType, filename, path, err := downloadMedia(client, messageStore, req.MessageID, req.ChatJID)
+		success, mediaType, filename, path, err := downloadMedia(client, store, req.MessageID, req.ChatJID)
 
 		// Set response headers
 		w.Header().Set("Content-Type", "application/json")
@@ -772,18 +1191,123 @@ func startRESTServer(client *whatsmeow.Client, messageStore *MessageStore, port
 			Filename: filename,
 			Path:     path,
 		})
+	}
+	http.HandleFunc("/api/download", downloadHandler)
+	http.HandleFunc("/api/v1/download", downloadHandler)
+
+	// Push newly received messages to WebSocket and Server-Sent Events subscribers
+	http.Handle("/ws/messages", api.WebSocketHandler(hub, cfg.CORSAllowedOrigins))
+	http.Handle("/events", api.SSEHandler(hub))
+
+	send := func(recipient, message, mediaPath string, opts api.SendOptions) error {
+		if success, result := sendWhatsAppMedia(client, recipient, message, mediaPath, opts.VoiceNote); !success {
+			return errors.New(result)
+		}
+		return nil
+	}
+
+	// Serve the remaining API routes from the api package, under /api/v1
+	// or routed by the Accept header
+	routes := api.NewRouter(api.NewHandler(store, client, cfg, cleaner, send, queue).Routes())
+	http.Handle("/api/", http.StripPrefix("/api", api.VersionMiddleware(cfg.StrictVersioning)(routes)))
+
+	// Metrics are served outside /api but, unlike the health check, behind
+	// the API key
+	if appMetrics != nil {
+		http.Handle("GET /metrics", appMetrics.Handler())
+	}
+
+	// The OpenAPI description of the API is generated from its request and
+	// response types
+	if cfg.ServeOpenAPI {
+		spec, err := openapi.GenerateSpec()
+		if err != nil {
+			fmt.Printf("Failed to generate OpenAPI spec: %v\n", err)
+		} else {
+			http.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(spec)
+			})
+		}
+	}
+
+	// Tag every request with an ID, log it, apply CORS, compress the
+	// response, recover from handler panics, rate limit every client, cap
+	// body sizes, then require the API key on every route but the health
+	// check
+	if cfg.APIKey == "" {
+		fmt.Println("Warning: WHATSAPP_API_KEY is not set, the REST API is unauthenticated")
+	}
+	authenticated := api.AuthMiddleware(cfg.APIKey)(api.RouteRecorder(http.DefaultServeMux))
+
+	healthPath := cfg.HealthCheckPath
+	if !strings.HasPrefix(healthPath, "/") {
+		healthPath = "/" + healthPath
+	}
+	root := http.NewServeMux()
+	root.Handle("GET "+healthPath, api.HealthHandler(store, client))
+	root.Handle("/", authenticated)
+
+	// The middleware is rebuilt when a reloaded configuration changes the
+	// rate limits, CORS origins or log level
+	buildHandler := func(cfg *config.Config) http.Handler {
+		handler := api.RouteRecorder(root)
+		handler = api.BodyLimitMiddleware(cfg.MaxRequestBodyBytes)(handler)
+		handler = api.RateLimitMiddleware(cfg.RateLimit)(handler)
+		handler = api.RecoveryMiddleware(slog.Default())(handler)
+		handler = api.CompressionMiddleware(cfg.CompressionMinBytes)(handler)
+		handler = api.CORSMiddleware(cfg.CORSAllowedOrigins)(handler)
+		handler = api.LoggingMiddleware(slog.Default(), appMetrics)(handler)
+		return api.RequestIDMiddleware()(handler)
+	}
+	var current atomic.Pointer[http.Handler]
+	initial := buildHandler(cfg)
+	current.Store(&initial)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(*current.Load()).ServeHTTP(w, r)
 	})
 
 	// Start the server
 	serverAddr := fmt.Sprintf(":%d", port)
-	fmt.Printf("Starting REST API server on %s...\n", serverAddr)
+	if cfg.TLSEnabled() {
+		fmt.Printf("Starting REST API server on %s with TLS...\n", serverAddr)
+	} else {
+		fmt.Printf("Starting REST API server on %s...\n", serverAddr)
+	}
+
+	server := api.NewServer(cfg, handler)
+	server.Addr = serverAddr
 
 	// Run server in a goroutine so it doesn't block
 	go func() {
-		if err := http.ListenAndServe(serverAddr, nil); err != nil {
+		var err error
+		if cfg.TLSEnabled() {
+			// The certificate is reloaded on SIGHUP, e.g. after renewal
+			var certs *tlsutil.TLSConfigManager
+			certs, err = tlsutil.NewTLSConfigManager(cfg.TLSCertFile, cfg.TLSKeyFile)
+			if err == nil {
+				server.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+				err = server.ListenAndServeTLS("", "")
+			}
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil {
 			fmt.Printf("REST API server error: %v\n", err)
 		}
 	}()
+
+	return func(cfg *config.Config) {
+		requestLogger, err := applog.NewLogger(cfg.LogLevel, cfg.LogFormat)
+		if err != nil {
+			fmt.Printf("Failed to apply reloaded log level: %v\n", err)
+		} else {
+			slog.SetDefault(requestLogger)
+		}
+		reloaded := buildHandler(cfg)
+		current.Store(&reloaded)
+		fmt.Println("Applied reloaded configuration")
+	}
 }
 
 func main() {
@@ -827,26 +1351,86 @@ func main() {
 	}
 
 	// Initialize message store
-	messageStore, err := NewMessageStore()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Errorf("Invalid configuration: %v", err)
+		return
+	}
+	if err := config.ValidateTLSConfig(cfg); err != nil {
+		logger.Errorf("Invalid TLS configuration: %v", err)
+		return
+	}
+	if err := cfg.InitMediaDir(); err != nil {
+		logger.Errorf("Failed to initialize media directory: %v", err)
+		return
+	}
+	validation.SetDefaultCountryCode(cfg.DefaultCountryCode)
+	store, err := database.NewStore(cfg.DatabasePath, cfg.StoreDir, database.WithWALCheckpointInterval(cfg.WALCheckpointInterval))
 	if err != nil {
 		logger.Errorf("Failed to initialize message store: %v", err)
 		return
 	}
-	defer messageStore.Close()
+	defer store.Close()
+
+	// Prometheus metrics; nil when disabled, which records nothing
+	var appMetrics *metrics.Metrics
+	if cfg.MetricsEnabled {
+		appMetrics = metrics.New(store.DBStats)
+		store.SetMetrics(appMetrics)
+	}
+
+	// Structured logger for the REST API
+	requestLogger, err := applog.NewLogger(cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		logger.Errorf("Failed to initialize request logger: %v", err)
+		return
+	}
+	slog.SetDefault(requestLogger)
+
+	// Fans newly stored messages out to WebSocket subscribers
+	hub := api.NewEventHub()
+
+	// Delivers received messages to registered webhooks
+	dispatcher := webhook.NewWebhookDispatcher(store, logger)
 
 	// Setup event handling for messages and history sync
 	client.AddEventHandler(func(evt interface{}) {
 		switch v := evt.(type) {
 		case *events.Message:
 			// Process regular messages
-			handleMessage(client, messageStore, v, logger)
+			handleMessage(client, store, hub, dispatcher, v, logger)
 
 		case *events.HistorySync:
 			// Process history sync events
-			handleHistorySync(client, messageStore, v, logger)
+			handleHistorySync(client, store, v, logger)
+
+		case *events.Receipt:
+			// Track delivery and read receipts
+			handleReceipt(store, v, logger)
+
+		case *events.GroupInfo:
+			// Keep the group roster in sync
+			handleGroupInfo(store, v, logger)
+
+		case *events.Archive:
+			// Follow chats archived or unarchived elsewhere
+			handleArchive(store, v, logger)
+
+		case *events.Mute:
+			// Follow chats muted or unmuted elsewhere
+			handleMute(store, v, logger)
+
+		case *events.Picture:
+			// Refetch changed profile pictures on next request
+			handlePicture(store, v, logger)
+
+		case *events.CallOffer, *events.CallOfferNotice, *events.CallAccept, *events.CallReject, *events.CallTerminate:
+			// Record calls in the call log
+			handleCall(store, v, logger)
 
 		case *events.Connected:
 			logger.Infof("Connected to WhatsApp")
+			go syncContacts(client, store, logger)
 
 		case *events.LoggedOut:
 			logger.Warnf("Device logged out, please scan QR code to log in again")
@@ -905,8 +1489,40 @@ func main() {
 
 	fmt.Println("\n✓ Connected to WhatsApp! Type 'help' for commands.")
 
+	// Remove stale data in the background
+	cleaner := janitor.NewJanitor(store, janitor.JanitorConfig{
+		StatusTTL:               cfg.StatusTTL,
+		SoftDeleteRetentionDays: cfg.SoftDeleteRetentionDays,
+		VacuumInterval:          cfg.VacuumInterval,
+		Logger:                  logger,
+	})
+
+	// Send messages accepted by POST /messages/async in the background
+	queue := jobs.NewQueue(store, func(recipient, message, mediaPath string) error {
+		if success, result := sendWhatsAppMessage(client, recipient, message, mediaPath); !success {
+			return errors.New(result)
+		}
+		return nil
+	}, cfg.AsyncWorkers, logger)
+
 	// Start REST API server
-	startRESTServer(client, messageStore, 8080)
+	applyConfig := startRESTServer(client, store, cfg, cleaner, queue, hub, appMetrics, 8080)
+
+	// Dispatch scheduled and queued messages and clean up in the background
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go scheduler.NewScheduler(store, func(msg *database.ScheduledMessage) error {
+		success, result := sendWhatsAppMessage(client, msg.Recipient, msg.Content, msg.MediaPath)
+		if !success {
+			return errors.New(result)
+		}
+		return nil
+	}, logger).Start(backgroundCtx)
+	go cleaner.Start(backgroundCtx)
+	go queue.Start(backgroundCtx)
+
+	// Apply log level, CORS and rate limit changes on SIGHUP
+	go config.NewConfigWatcher(cfg).Watch(backgroundCtx, applyConfig)
 
 	// Create a channel to keep the main goroutine alive
 	exitChan := make(chan os.Signal, 1)
@@ -918,19 +1534,19 @@ func main() {
 	<-exitChan
 
 	fmt.Println("Disconnecting...")
-	// Disconnect client
+	// Close WebSocket subscriptions and disconnect client
+	hub.Close()
 	client.Disconnect()
 }
 
 // GetChatName determines the appropriate name for a chat based on JID and other info
-func GetChatName(client *whatsmeow.Client, messageStore *MessageStore, jid types.JID, chatJID string, conversation interface{}, sender string, logger waLog.Logger) string {
+func GetChatName(client *whatsmeow.Client, store *database.Store, jid types.JID, chatJID string, conversation interface{}, sender string, logger waLog.Logger) string {
 	// First, check if chat already exists in database with a name
-	var existingName string
-	err := messageStore.db.QueryRow("SELECT name FROM chats WHERE jid = ?", chatJID).Scan(&existingName)
-	if err == nil && existingName != "" {
+	existing, err := store.GetChat(chatJID)
+	if err == nil && existing.Name != "" {
 		// Chat exists with a name, use that
-		logger.Infof("Using existing chat name for %s: %s", chatJID, existingName)
-		return existingName
+		logger.Infof("Using existing chat name for %s: %s", chatJID, existing.Name)
+		return existing.Name
 	}
 
 	// Need to determine chat name
@@ -974,6 +1590,9 @@ func GetChatName(client *whatsmeow.Client, messageStore *MessageStore, jid types
 		// If we didn't get a name, try group info
 		if name == "" {
 			groupInfo, err := client.GetGroupInfo(jid)
+			if err == nil {
+				storeGroupInfo(store, groupInfo, logger)
+			}
 			if err == nil && groupInfo.Name != "" {
 				name = groupInfo.Name
 			} else {
@@ -1006,7 +1625,7 @@ func GetChatName(client *whatsmeow.Client, messageStore *MessageStore, jid types
 }
 
 // Handle history sync events
-func handleHistorySync(client *whatsmeow.Client, messageStore *MessageStore, historySync *events.HistorySync, logger waLog.Logger) {
+func handleHistorySync(client *whatsmeow.Client, store *database.Store, historySync *events.HistorySync, logger waLog.Logger) {
 	fmt.Printf("Received history sync event with %d conversations\n", len(historySync.Data.Conversations))
 
 	syncedCount := 0
@@ -1026,7 +1645,7 @@ func handleHistorySync(client *whatsmeow.Client, messageStore *MessageStore, his
 		}
 
 		// Get appropriate chat name by passing the history sync conversation directly
-		name := GetChatName(client, messageStore, jid, chatJID, conversation, "", logger)
+		name := GetChatName(client, store, jid, chatJID, conversation, "", logger)
 
 		// Process messages
 		messages := conversation.Messages
@@ -1045,9 +1664,14 @@ func handleHistorySync(client *whatsmeow.Client, messageStore *MessageStore, his
 				continue
 			}
 
-			messageStore.StoreChat(chatJID, name, timestamp)
+			store.StoreChat(&database.Chat{
+				JID:             chatJID,
+				Name:            name,
+				LastMessageTime: timestamp,
+			})
 
-			// Store messages
+			// Collect messages and store them in bulk
+			batch := make([]*database.Message, 0, len(messages))
 			for _, msg := range messages {
 				if msg == nil || msg.Message == nil {
 					continue
@@ -1072,11 +1696,16 @@ func handleHistorySync(client *whatsmeow.Client, messageStore *MessageStore, his
 					mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength = extractMediaInfo(msg.Message.Message)
 				}
 
+				// Extract the message being replied to
+				quotedID, quotedContent := extractQuotedContext(msg.Message.Message)
+
 				// Log the message content for debugging
 				logger.Infof("Message content: %v, Media Type: %v", content, mediaType)
 
-				// Skip messages with no content and no media
-				if content == "" && mediaType == "" {
+				// Skip messages with no content, media or location
+				isLocation := msg.Message.Message.GetLocationMessage() != nil ||
+					msg.Message.Message.GetLiveLocationMessage() != nil
+				if content == "" && mediaType == "" && !isLocation {
 					continue
 				}
 
@@ -1098,7 +1727,7 @@ func handleHistorySync(client *whatsmeow.Client, messageStore *MessageStore, his
 					sender = jid.User
 				}
 
-				// Store message
+				// Get message ID
 				msgID := ""
 				if msg.Message.Key != nil && msg.Message.Key.ID != nil {
 					msgID = *msg.Message.Key.ID
@@ -1112,34 +1741,34 @@ func handleHistorySync(client *whatsmeow.Client, messageStore *MessageStore, his
 					continue
 				}
 
-				err = messageStore.StoreMessage(
-					msgID,
-					chatJID,
-					sender,
-					content,
-					timestamp,
-					isFromMe,
-					mediaType,
-					filename,
-					url,
-					mediaKey,
-					fileSHA256,
-					fileEncSHA256,
-					fileLength,
-				)
-				if err != nil {
-					logger.Warnf("Failed to store history message: %v", err)
-				} else {
-					syncedCount++
-					// Log successful message storage
-					if mediaType != "" {
-						logger.Infof("Stored message: [%s] %s -> %s: [%s: %s] %s",
-							timestamp.Format("2006-01-02 15:04:05"), sender, chatJID, mediaType, filename, content)
-					} else {
-						logger.Infof("Stored message: [%s] %s -> %s: %s",
-							timestamp.Format("2006-01-02 15:04:05"), sender, chatJID, content)
-					}
+				message := &database.Message{
+					ID:            msgID,
+					ChatJID:       chatJID,
+					Sender:        sender,
+					Content:       content,
+					Timestamp:     timestamp,
+					IsFromMe:      isFromMe,
+					MediaType:     mediaType,
+					Filename:      filename,
+					URL:           url,
+					MediaKey:      mediaKey,
+					FileSHA256:    fileSHA256,
+					FileEncSHA256: fileEncSHA256,
+					FileLength:    fileLength,
+
+					QuotedMessageID:      quotedID,
+					QuotedMessageContent: quotedContent,
 				}
+				applyMediaMetadata(message, msg.Message.Message)
+				applyLocation(message, msg.Message.Message)
+				batch = append(batch, message)
+			}
+
+			if err := store.BulkStoreMessages(batch); err != nil {
+				logger.Warnf("Failed to store history messages for %s: %v", chatJID, err)
+			} else {
+				syncedCount += len(batch)
+				logger.Infof("Stored %d history messages for %s", len(batch), chatJID)
 			}
 		}
 	}
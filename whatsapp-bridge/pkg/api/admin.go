@@ -0,0 +1,88 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"whatsapp-client/pkg/database"
+)
+
+// BackupRequest represents the request body for backing up the database
+type BackupRequest struct {
+	DestPath string `json:"dest_path"`
+}
+
+// requireAdminKey rejects requests whose X-Admin-Key header does not match
+// the configured admin key. Admin endpoints are disabled when no key is set.
+func (h *Handler) requireAdminKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg == nil || h.cfg.AdminKey == "" {
+			writeErrorResponse(w, r, http.StatusForbidden, "admin endpoints are disabled")
+			return
+		}
+		key := r.Header.Get("X-Admin-Key")
+		if subtle.ConstantTimeCompare([]byte(key), []byte(h.cfg.AdminKey)) != 1 {
+			writeErrorResponse(w, r, http.StatusUnauthorized, "invalid admin key")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// handleBackup handles POST /admin/backup
+func (h *Handler) handleBackup(w http.ResponseWriter, r *http.Request) {
+	var req BackupRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if req.DestPath == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "dest_path is required")
+		return
+	}
+
+	err := h.store.Backup(req.DestPath)
+	switch {
+	case errors.Is(err, database.ErrInvalidBackupDestination):
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrMaintenanceInProgress):
+		writeErrorResponse(w, r, http.StatusConflict, err.Error())
+	case err != nil:
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to back up database")
+	default:
+		writeSuccessResponse(w, "Backup created", map[string]string{"path": req.DestPath})
+	}
+}
+
+// handleVacuum handles POST /admin/vacuum
+func (h *Handler) handleVacuum(w http.ResponseWriter, r *http.Request) {
+	err := h.store.Vacuum()
+	if errors.Is(err, database.ErrMaintenanceInProgress) {
+		writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to vacuum database")
+		return
+	}
+
+	writeSuccessResponse(w, "Database vacuumed", nil)
+}
+
+// handleGetDBStats handles GET /admin/db_stats
+func (h *Handler) handleGetDBStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.store.DatabaseStats()
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get database stats")
+		return
+	}
+
+	writeSuccessResponse(w, "", stats)
+}
+
+// handleGetJanitorStats handles GET /admin/janitor_stats
+func (h *Handler) handleGetJanitorStats(w http.ResponseWriter, r *http.Request) {
+	writeSuccessResponse(w, "", h.janitor.Stats())
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"whatsapp-client/pkg/jobs"
+)
+
+// AsyncSendResponse identifies the job a queued send is tracked by
+type AsyncSendResponse struct {
+	JobID string `json:"job_id"`
+}
+
+// handleSendAsync handles POST /messages/async. The message is validated
+// and queued; its progress is polled through GET /jobs/{id}.
+func (h *Handler) handleSendAsync(w http.ResponseWriter, r *http.Request) {
+	var req SendMessageRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if err := validateSendMessageRequest(req, h.cfg.AllowedUploadDirs, h.cfg.MaxUploadFileSizeBytes); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if h.queue == nil {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, "background sending is not available")
+		return
+	}
+	job, err := h.queue.Enqueue(jobs.Request{Recipient: req.Recipient, Message: req.Message, MediaPath: req.MediaPath})
+	if errors.Is(err, jobs.ErrQueueFull) {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to queue message")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusAccepted, Response{
+		Success: true,
+		Message: "Message queued",
+		Data:    AsyncSendResponse{JobID: job.ID},
+	})
+}
+
+// handleGetJob handles GET /jobs/{id}
+func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.store.GetJob(r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "job not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get job")
+		return
+	}
+
+	writeSuccessResponse(w, "", job)
+}
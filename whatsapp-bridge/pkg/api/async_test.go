@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsapp-client/pkg/config"
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/jobs"
+)
+
+func TestSendAsyncRoute(t *testing.T) {
+	store := newTestStore(t)
+	sent := make(chan string, 1)
+	queue := jobs.NewQueue(store, func(recipient, message, mediaPath string) error {
+		sent <- message
+		return nil
+	}, 1, waLog.Noop)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Start(ctx)
+
+	h := &Handler{store: store, queue: queue, cfg: &config.Config{}}
+	rec := httptest.NewRecorder()
+	body := `{"recipient":"15551234567","message":"Report attached"}`
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/async", strings.NewReader(body)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data AsyncSendResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Data.JobID == "" {
+		t.Fatal("Expected a job ID")
+	}
+
+	select {
+	case msg := <-sent:
+		if msg != "Report attached" {
+			t.Errorf("Expected the queued message to be sent, got %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the queued message to be sent")
+	}
+
+	var job *database.Job
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		rec = httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/jobs/"+resp.Data.JobID, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var jobResp struct {
+			Data *database.Job `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&jobResp); err != nil {
+			t.Fatalf("Failed to decode job: %v", err)
+		}
+		if job = jobResp.Data; job.Status.IsFinal() {
+			break
+		}
+	}
+	if job.Status != database.JobStatusCompleted || job.CompletedAt == nil {
+		t.Errorf("Expected a completed job, got %+v", job)
+	}
+
+	rec = httptest.NewRecorder()
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/jobs/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown job, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/async", strings.NewReader(`{"recipient":"nobody","message":"Hi"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid recipient, got %d", rec.Code)
+	}
+}
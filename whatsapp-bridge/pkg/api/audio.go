@@ -0,0 +1,166 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// SendAudioRequest represents the request body for sending audio, given
+// either inline as base64 or as a path on the bridge host
+type SendAudioRequest struct {
+	Recipient   string `json:"recipient"`
+	AudioPath   string `json:"audio_path,omitempty"`
+	AudioBase64 string `json:"audio_base64,omitempty"`
+	AsVoiceNote bool   `json:"as_voice_note,omitempty"`
+}
+
+// Audio MIME types accepted by POST /messages/audio
+const (
+	audioOgg  = "audio/ogg"
+	audioMPEG = "audio/mpeg"
+	audioWAV  = "audio/wav"
+)
+
+// ValidateAudioRequest checks that exactly one audio source is given and
+// that it holds Ogg, MP3 or WAV audio. It returns the detected MIME type
+// and the decoded audio when the request carries it inline. An audio_path
+// must lie within allowedDirs and may be at most maxBytes long.
+func ValidateAudioRequest(req SendAudioRequest, allowedDirs []string, maxBytes int64) ([]byte, string, error) {
+	if err := validation.ValidateRecipient(req.Recipient); err != nil {
+		return nil, "", fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	var data, header []byte
+	switch {
+	case req.AudioBase64 != "" && req.AudioPath != "":
+		return nil, "", fmt.Errorf("only one of audio_base64 and audio_path may be set")
+
+	case req.AudioBase64 != "":
+		var err error
+		data, err = base64.StdEncoding.DecodeString(req.AudioBase64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid audio_base64: %w", err)
+		}
+		header = data
+
+	case req.AudioPath != "":
+		if err := validateMediaPath(req.AudioPath, allowedDirs, maxBytes); err != nil {
+			return nil, "", fmt.Errorf("invalid audio_path: %w", err)
+		}
+		f, err := os.Open(req.AudioPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid audio_path: %w", err)
+		}
+		defer f.Close()
+		header = make([]byte, 12)
+		n, err := io.ReadFull(f, header)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return nil, "", fmt.Errorf("invalid audio_path: %w", err)
+		}
+		header = header[:n]
+
+	default:
+		return nil, "", fmt.Errorf("one of audio_base64 or audio_path is required")
+	}
+
+	mimeType := sniffAudio(header)
+	if mimeType == "" {
+		return nil, "", fmt.Errorf("audio must be one of %s, %s or %s", audioOgg, audioMPEG, audioWAV)
+	}
+	return data, mimeType, nil
+}
+
+// sniffAudio returns the MIME type of the audio starting with header, or ""
+// if it is not a supported format
+func sniffAudio(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return audioOgg
+	case len(header) >= 12 && bytes.HasPrefix(header, []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return audioWAV
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return audioMPEG
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// MPEG audio frame sync
+		return audioMPEG
+	}
+	return ""
+}
+
+// handleSendAudio handles POST /messages/audio
+func (h *Handler) handleSendAudio(w http.ResponseWriter, r *http.Request) {
+	var req SendAudioRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	data, mimeType, err := ValidateAudioRequest(req, h.cfg.AllowedUploadDirs, h.cfg.MaxUploadFileSizeBytes)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	path := req.AudioPath
+	if data != nil {
+		ext := ".ogg"
+		if mimeType != audioOgg {
+			ext = ".audio"
+		}
+		path, err = writeTempFile("whatsapp-audio-*"+ext, data)
+		if err != nil {
+			writeErrorResponse(w, r, http.StatusInternalServerError, "failed to store audio")
+			return
+		}
+		defer os.Remove(path)
+	}
+
+	// The sender only recognizes Ogg Opus by its extension, so anything
+	// else is converted first
+	if mimeType != audioOgg || strings.ToLower(filepath.Ext(path)) != ".ogg" {
+		path, err = convertToOggOpus(r.Context(), h.cfg.FFmpegPath, path)
+		if err != nil {
+			writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to convert audio: %v", err))
+			return
+		}
+		defer os.Remove(path)
+	}
+
+	if err := h.send(req.Recipient, "", path, SendOptions{VoiceNote: req.AsVoiceNote}); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to send audio: %v", err))
+		return
+	}
+
+	writeSuccessResponse(w, fmt.Sprintf("Audio sent to %s", req.Recipient), nil)
+}
+
+// convertToOggOpus encodes the audio file at path as mono Ogg Opus with
+// ffmpeg and returns the path of the temporary file it wrote
+func convertToOggOpus(ctx context.Context, ffmpeg, path string) (string, error) {
+	out, err := os.CreateTemp("", "whatsapp-audio-*.ogg")
+	if err != nil {
+		return "", err
+	}
+	out.Close()
+
+	cmd := exec.CommandContext(ctx, ffmpeg, "-y", "-loglevel", "error", "-i", path,
+		"-vn", "-c:a", "libopus", "-ac", "1", "-ar", "48000", "-f", "ogg", out.Name())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(out.Name())
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return out.Name(), nil
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"whatsapp-client/pkg/config"
+)
+
+// testWAV returns a short mono 16-bit PCM WAV file of silence
+func testWAV() []byte {
+	const sampleRate, samples = 8000, 800
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+samples*2))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, []any{
+		uint32(16), uint16(1), uint16(1), uint32(sampleRate), uint32(sampleRate * 2), uint16(2), uint16(16),
+	})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(samples*2))
+	buf.Write(make([]byte, samples*2))
+	return buf.Bytes()
+}
+
+func TestValidateAudioRequest(t *testing.T) {
+	dir := t.TempDir()
+	wavPath := filepath.Join(dir, "clip.wav")
+	if err := os.WriteFile(wavPath, testWAV(), 0600); err != nil {
+		t.Fatalf("Failed to write audio: %v", err)
+	}
+	textPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(textPath, []byte("not audio"), 0600); err != nil {
+		t.Fatalf("Failed to write text: %v", err)
+	}
+
+	outsidePath := filepath.Join(t.TempDir(), "clip.wav")
+	if err := os.WriteFile(outsidePath, testWAV(), 0600); err != nil {
+		t.Fatalf("Failed to write audio: %v", err)
+	}
+
+	encode := func(b []byte) string { return base64.StdEncoding.EncodeToString(b) }
+	const recipient = "1234567890"
+
+	tests := []struct {
+		name     string
+		req      SendAudioRequest
+		wantMIME string
+		wantErr  bool
+	}{
+		{"inline ogg", SendAudioRequest{Recipient: recipient, AudioBase64: encode([]byte("OggS\x00\x02"))}, "audio/ogg", false},
+		{"inline mp3", SendAudioRequest{Recipient: recipient, AudioBase64: encode([]byte("ID3\x04\x00"))}, "audio/mpeg", false},
+		{"inline mp3 frame", SendAudioRequest{Recipient: recipient, AudioBase64: encode([]byte{0xFF, 0xFB, 0x90, 0x00})}, "audio/mpeg", false},
+		{"wav path", SendAudioRequest{Recipient: recipient, AudioPath: wavPath, AsVoiceNote: true}, "audio/wav", false},
+		{"no source", SendAudioRequest{Recipient: recipient}, "", true},
+		{"both sources", SendAudioRequest{Recipient: recipient, AudioPath: wavPath, AudioBase64: encode(testWAV())}, "", true},
+		{"invalid base64", SendAudioRequest{Recipient: recipient, AudioBase64: "not base64!"}, "", true},
+		{"inline not audio", SendAudioRequest{Recipient: recipient, AudioBase64: encode([]byte("hello world"))}, "", true},
+		{"path not audio", SendAudioRequest{Recipient: recipient, AudioPath: textPath}, "", true},
+		{"missing path", SendAudioRequest{Recipient: recipient, AudioPath: filepath.Join(dir, "missing.wav")}, "", true},
+		{"invalid recipient", SendAudioRequest{Recipient: "nobody", AudioPath: wavPath}, "", true},
+		{"path outside allowed dirs", SendAudioRequest{Recipient: recipient, AudioPath: outsidePath}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mimeType, err := ValidateAudioRequest(tt.req, []string{dir}, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateAudioRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if mimeType != tt.wantMIME {
+				t.Errorf("Expected MIME type %q, got %q", tt.wantMIME, mimeType)
+			}
+		})
+	}
+}
+
+func TestHandleSendAudioConvertsWAV(t *testing.T) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+
+	var sentPath string
+	var sentData []byte
+	var sentOpts SendOptions
+	h := &Handler{
+		cfg: &config.Config{FFmpegPath: ffmpeg},
+		send: func(recipient, message, mediaPath string, opts SendOptions) error {
+			sentPath, sentOpts = mediaPath, opts
+			sentData, _ = os.ReadFile(mediaPath)
+			return nil
+		},
+	}
+
+	body := `{"recipient":"1234567890","audio_base64":"` + base64.StdEncoding.EncodeToString(testWAV()) + `","as_voice_note":true}`
+	rec := httptest.NewRecorder()
+	h.handleSendAudio(rec, httptest.NewRequest(http.MethodPost, "/messages/audio", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if filepath.Ext(sentPath) != ".ogg" {
+		t.Errorf("Expected an .ogg file to be sent, got %q", sentPath)
+	}
+	if !bytes.HasPrefix(sentData, []byte("OggS")) {
+		t.Errorf("Expected sent audio to start with OggS, got %q", sentData[:min(len(sentData), 4)])
+	}
+	if !sentOpts.VoiceNote {
+		t.Error("Expected audio to be sent as a voice note")
+	}
+	if _, err := os.Stat(sentPath); !os.IsNotExist(err) {
+		t.Errorf("Expected converted file to be removed, stat error: %v", err)
+	}
+}
+
+func TestHandleSendAudioConversionFailure(t *testing.T) {
+	called := false
+	h := &Handler{
+		cfg: &config.Config{FFmpegPath: filepath.Join(t.TempDir(), "no-ffmpeg")},
+		send: func(recipient, message, mediaPath string, opts SendOptions) error {
+			called = true
+			return nil
+		},
+	}
+
+	body := `{"recipient":"1234567890","audio_base64":"` + base64.StdEncoding.EncodeToString(testWAV()) + `"}`
+	rec := httptest.NewRecorder()
+	h.handleSendAudio(rec, httptest.NewRequest(http.MethodPost, "/messages/audio", strings.NewReader(body)))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if called {
+		t.Error("Expected nothing to be sent when conversion fails")
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := AuthMiddleware("secret")(next)
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		wantStatus    int
+	}{
+		{"valid key", http.MethodGet, "Bearer secret", http.StatusNoContent},
+		{"lowercase scheme", http.MethodGet, "bearer secret", http.StatusNoContent},
+		{"missing header", http.MethodGet, "", http.StatusUnauthorized},
+		{"wrong scheme", http.MethodGet, "Basic secret", http.StatusUnauthorized},
+		{"wrong key", http.MethodPost, "Bearer wrong", http.StatusUnauthorized},
+		{"preflight bypass", http.MethodOptions, "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/chats", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus != http.StatusUnauthorized {
+				return
+			}
+
+			var resp Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode error body: %v", err)
+			}
+			if resp.Success || resp.Error == "" {
+				t.Errorf("Expected an error response, got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	rec := httptest.NewRecorder()
+	AuthMiddleware("")(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/chats", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected requests to pass without a configured key, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// BodyLimitMiddleware caps request bodies at maxBytes. Requests that declare
+// a larger Content-Length are rejected with 413 up front; bodies that turn
+// out larger while being read fail in parseJSONBody. A non-positive maxBytes
+// disables the limit.
+func BodyLimitMiddleware(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxBytes <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				writeErrorResponse(w, r, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("request body exceeds %d bytes", maxBytes))
+				return
+			}
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
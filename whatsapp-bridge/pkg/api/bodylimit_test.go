@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageRequest
+		if err := parseJSONBody(r, &req); err != nil {
+			writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := BodyLimitMiddleware(64)(next)
+
+	small := `{"recipient":"123","message":"hi"}`
+	large := `{"recipient":"123","message":"` + strings.Repeat("x", 100) + `"}`
+
+	tests := []struct {
+		name       string
+		body       io.Reader
+		wantStatus int
+	}{
+		{"within limit", strings.NewReader(small), http.StatusNoContent},
+		{"declared too large", strings.NewReader(large), http.StatusRequestEntityTooLarge},
+		// No Content-Length, so the limit is only hit while decoding
+		{"streamed too large", io.MultiReader(strings.NewReader(large)), http.StatusRequestEntityTooLarge},
+		{"malformed", strings.NewReader(`{`), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/labels", tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusNoContent {
+				return
+			}
+			var resp Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode error body: %v", err)
+			}
+			if resp.Error == "" {
+				t.Error("Expected an error message")
+			}
+		})
+	}
+}
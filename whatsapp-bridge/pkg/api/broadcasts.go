@@ -0,0 +1,46 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// handleListBroadcasts handles GET /broadcasts
+func (h *Handler) handleListBroadcasts(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.store.GetBroadcastLists()
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get broadcast lists")
+		return
+	}
+
+	writeSuccessResponse(w, "", lists)
+}
+
+// handleGetBroadcastRecipients handles GET /broadcasts/{jid}/recipients
+func (h *Handler) handleGetBroadcastRecipients(w http.ResponseWriter, r *http.Request) {
+	jid := r.PathValue("jid")
+	if !strings.HasSuffix(jid, "@broadcast") {
+		writeErrorResponse(w, r, http.StatusBadRequest, "invalid broadcast list JID")
+		return
+	}
+
+	_, err := h.store.GetBroadcastList(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "broadcast list not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get broadcast list")
+		return
+	}
+
+	recipients, err := h.store.GetBroadcastRecipients(jid)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get broadcast recipients")
+		return
+	}
+
+	writeSuccessResponse(w, "", recipients)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"whatsapp-client/pkg/config"
+	"whatsapp-client/pkg/validation"
+)
+
+// BulkSendRequest represents the request body for sending one text message
+// to several recipients
+type BulkSendRequest struct {
+	Recipients []string `json:"recipients"`
+	Message    string   `json:"message"`
+}
+
+// RecipientResult is the outcome of a bulk send for one recipient
+type RecipientResult struct {
+	Recipient string `json:"recipient"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkSendResponse lists the outcome for every recipient, in request order
+type BulkSendResponse struct {
+	Results []RecipientResult `json:"results"`
+}
+
+// ValidateBulkSendRequest checks the message and every recipient, allowing
+// at most maxRecipients of them
+func ValidateBulkSendRequest(req BulkSendRequest, maxRecipients int) error {
+	if len(req.Recipients) > maxRecipients {
+		return fmt.Errorf("at most %d recipients are allowed", maxRecipients)
+	}
+	if err := validation.ValidateBulkRecipients(req.Recipients); err != nil {
+		return fmt.Errorf("invalid recipients: %w", err)
+	}
+	if err := validation.ValidateMessageContent(req.Message); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	return nil
+}
+
+// bulkSendHandler rate limits handleBulkSend separately from the global
+// limit, since one bulk request fans out to many messages
+func (h *Handler) bulkSendHandler() http.Handler {
+	limit := config.RateLimitConfig{GlobalBurst: 1}
+	if h.cfg != nil {
+		limit.GlobalRPS = h.cfg.BulkSendPerMinute / 60
+		limit.TrustedProxies = h.cfg.RateLimit.TrustedProxies
+	}
+	return RateLimitMiddleware(limit)(http.HandlerFunc(h.handleBulkSend))
+}
+
+// handleBulkSend handles POST /messages/bulk. Recipients are sent to one at
+// a time; the response is 207 when only some sends succeeded and 500 when
+// none did.
+func (h *Handler) handleBulkSend(w http.ResponseWriter, r *http.Request) {
+	var req BulkSendRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if err := ValidateBulkSendRequest(req, h.cfg.BulkSendMaxRecipients); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results := make([]RecipientResult, len(req.Recipients))
+	sent := 0
+	for i, recipient := range req.Recipients {
+		results[i].Recipient = recipient
+		if err := h.send(recipient, req.Message, "", SendOptions{}); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Success = true
+		sent++
+	}
+
+	message := fmt.Sprintf("Sent to %d of %d recipients", sent, len(results))
+	if sent == len(results) {
+		writeSuccessResponse(w, message, BulkSendResponse{Results: results})
+		return
+	}
+
+	status := http.StatusMultiStatus
+	if sent == 0 {
+		status = http.StatusInternalServerError
+	}
+	writeJSONResponse(w, status, Response{
+		Success:   false,
+		Message:   message,
+		Data:      BulkSendResponse{Results: results},
+		RequestID: RequestIDFromContext(r.Context()),
+	})
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"whatsapp-client/pkg/config"
+)
+
+func TestBulkSendRoute(t *testing.T) {
+	var sentTo []string
+	h := &Handler{
+		cfg: &config.Config{BulkSendMaxRecipients: 3},
+		send: func(recipient, message, mediaPath string, opts SendOptions) error {
+			if recipient == "15550000000" {
+				return errors.New("not on WhatsApp")
+			}
+			sentTo = append(sentTo, recipient)
+			return nil
+		},
+	}
+	routes := NewRouter(h.Routes())
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/bulk", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{"recipients":["15551111111","15550000000","123456789-123456@g.us"],"message":"Office closed today"}`)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status 207, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data BulkSendResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := []RecipientResult{
+		{Recipient: "15551111111", Success: true},
+		{Recipient: "15550000000", Error: "not on WhatsApp"},
+		{Recipient: "123456789-123456@g.us", Success: true},
+	}
+	if len(resp.Data.Results) != len(want) {
+		t.Fatalf("Expected results %+v, got %+v", want, resp.Data.Results)
+	}
+	for i := range want {
+		if resp.Data.Results[i] != want[i] {
+			t.Errorf("Expected results %+v, got %+v", want, resp.Data.Results)
+			break
+		}
+	}
+	if len(sentTo) != 2 {
+		t.Errorf("Expected two messages sent, got %v", sentTo)
+	}
+}
+
+func TestBulkSendValidation(t *testing.T) {
+	h := &Handler{
+		cfg: &config.Config{BulkSendMaxRecipients: 2},
+		send: func(recipient, message, mediaPath string, opts SendOptions) error {
+			t.Errorf("Unexpected send to %s", recipient)
+			return nil
+		},
+	}
+	routes := NewRouter(h.Routes())
+
+	for _, body := range []string{
+		`{"recipients":["15551111111","15552222222","15553333333"],"message":"Hi"}`,
+		`{"recipients":[],"message":"Hi"}`,
+		`{"recipients":["15551111111","nobody"],"message":"Hi"}`,
+		`{"recipients":["15551111111"],"message":""}`,
+	} {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/bulk", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestBulkSendRateLimit(t *testing.T) {
+	h := &Handler{
+		cfg:  &config.Config{BulkSendMaxRecipients: 50, BulkSendPerMinute: 1},
+		send: func(recipient, message, mediaPath string, opts SendOptions) error { return nil },
+	}
+	routes := NewRouter(h.Routes())
+	post := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/bulk", strings.NewReader(`{"recipients":["15551111111"],"message":"Hi"}`)))
+		return rec
+	}
+
+	if rec := post(); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := post()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+}
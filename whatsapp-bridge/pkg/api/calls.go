@@ -0,0 +1,66 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// handleListCalls handles GET /calls?chat_jid=...
+func (h *Handler) handleListCalls(w http.ResponseWriter, r *http.Request) {
+	chatJID := r.URL.Query().Get("chat_jid")
+	if chatJID != "" {
+		if err := validation.ValidateJID(chatJID); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "invalid chat_jid: "+err.Error())
+			return
+		}
+	}
+
+	limit, offset, _, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	calls, err := h.store.GetCallLogs(chatJID, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get calls")
+		return
+	}
+
+	writeSuccessResponse(w, "", calls)
+}
+
+// handleListMissedCalls handles GET /calls/missed?limit=...
+func (h *Handler) handleListMissedCalls(w http.ResponseWriter, r *http.Request) {
+	limit, _, _, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	calls, err := h.store.GetMissedCalls(limit)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get missed calls")
+		return
+	}
+
+	writeSuccessResponse(w, "", calls)
+}
+
+// handleGetCall handles GET /calls/{id}
+func (h *Handler) handleGetCall(w http.ResponseWriter, r *http.Request) {
+	call, err := h.store.GetCallLogByID(r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "call not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get call")
+		return
+	}
+
+	writeSuccessResponse(w, "", call)
+}
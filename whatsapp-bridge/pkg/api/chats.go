@@ -0,0 +1,429 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"whatsapp-client/pkg/database"
+)
+
+// MuteChatRequest represents the request body for muting a chat
+type MuteChatRequest struct {
+	Until time.Time `json:"until"`
+}
+
+// ReadPositionRequest represents the request body for setting a chat's read position
+type ReadPositionRequest struct {
+	MessageID string `json:"message_id"`
+}
+
+// ChatStatsResponse represents the message statistics of a chat
+type ChatStatsResponse struct {
+	ChatJID string `json:"chat_jid"`
+	*database.ChatStatistics
+	// ByHour counts messages per hour of day, index 0 being 00:00-00:59
+	ByHour   [24]int             `json:"by_hour"`
+	Activity []database.DayCount `json:"activity"`
+}
+
+// handleListChats handles GET /chats?cursor=...&label_id=...&include_archived=...&include_last_message=...
+func (h *Handler) handleListChats(w http.ResponseWriter, r *http.Request) {
+	limit, offset, cursor, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := database.ChatOptions{
+		ResolveContactNames: true,
+		LabelID:             r.URL.Query().Get("label_id"),
+		Offset:              offset,
+	}
+	if v := r.URL.Query().Get("include_archived"); v != "" {
+		opts.IncludeArchived, err = strconv.ParseBool(v)
+		if err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "invalid include_archived parameter")
+			return
+		}
+	}
+	var includeLastMessage bool
+	if v := r.URL.Query().Get("include_last_message"); v != "" {
+		includeLastMessage, err = strconv.ParseBool(v)
+		if err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "invalid include_last_message parameter")
+			return
+		}
+	}
+
+	page, err := h.store.GetChatsPage(limit, cursor, opts)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get chats")
+		return
+	}
+	total, err := h.store.CountChats(opts)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to count chats")
+		return
+	}
+
+	warnOffsetDeprecated(w, r)
+	if !includeLastMessage {
+		writeSuccessResponse(w, "", newPagedResponse(page, total))
+		return
+	}
+
+	items, err := h.store.WithLastMessages(page.Items)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get last messages")
+		return
+	}
+
+	writeSuccessResponse(w, "", newPagedResponse(&database.PageResult[*database.ChatWithLastMessage]{
+		Items:      items,
+		NextCursor: page.NextCursor,
+	}, total))
+}
+
+// handleMarkChatRead handles POST /chats/{jid}/read
+func (h *Handler) handleMarkChatRead(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.store.MarkChatRead(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to mark chat as read")
+		return
+	}
+
+	writeSuccessResponse(w, "Chat marked as read", nil)
+}
+
+// handleSetReadPosition handles PUT /chats/{jid}/read_position
+func (h *Handler) handleSetReadPosition(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req ReadPositionRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if req.MessageID == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "message_id is required")
+		return
+	}
+
+	err = h.store.SetReadPosition(jid, req.MessageID, time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat or message not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to set read position")
+		return
+	}
+
+	writeSuccessResponse(w, "Read position updated", nil)
+}
+
+// handleListArchivedChats handles GET /chats/archived
+func (h *Handler) handleListArchivedChats(w http.ResponseWriter, r *http.Request) {
+	limit, offset, _, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	chats, err := h.store.GetArchivedChats(limit, offset)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get archived chats")
+		return
+	}
+
+	writeSuccessResponse(w, "", chats)
+}
+
+// handleArchiveChat handles POST /chats/{jid}/archive
+func (h *Handler) handleArchiveChat(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.store.ArchiveChat(jid, time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to archive chat")
+		return
+	}
+
+	writeSuccessResponse(w, "Chat archived", nil)
+}
+
+// handleUnarchiveChat handles DELETE /chats/{jid}/archive
+func (h *Handler) handleUnarchiveChat(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.store.UnarchiveChat(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to unarchive chat")
+		return
+	}
+
+	writeSuccessResponse(w, "Chat unarchived", nil)
+}
+
+// handleListMutedChats handles GET /chats/muted
+func (h *Handler) handleListMutedChats(w http.ResponseWriter, r *http.Request) {
+	chats, err := h.store.GetMutedChats()
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get muted chats")
+		return
+	}
+
+	writeSuccessResponse(w, "", chats)
+}
+
+// handleListUnreadChats handles GET /chats/unread?min_unread=...
+func (h *Handler) handleListUnreadChats(w http.ResponseWriter, r *http.Request) {
+	minUnread, err := ParseIntParam(r, "min_unread", 1, 1, math.MaxInt)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	chats, err := h.store.GetUnreadChats()
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get unread chats")
+		return
+	}
+
+	unread := make([]*database.Chat, 0, len(chats))
+	for _, chat := range chats {
+		if chat.UnreadCount >= minUnread {
+			unread = append(unread, chat)
+		}
+	}
+
+	items, err := h.store.WithLastMessages(unread)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get last messages")
+		return
+	}
+
+	writeSuccessResponse(w, "", items)
+}
+
+// handleMuteChat handles POST /chats/{jid}/mute
+func (h *Handler) handleMuteChat(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req MuteChatRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if !req.Until.After(time.Now()) {
+		writeErrorResponse(w, r, http.StatusBadRequest, "until must be in the future")
+		return
+	}
+
+	err = h.store.MuteChat(jid, req.Until)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to mute chat")
+		return
+	}
+
+	writeSuccessResponse(w, "Chat muted", nil)
+}
+
+// handleUnmuteChat handles DELETE /chats/{jid}/mute
+func (h *Handler) handleUnmuteChat(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.store.UnmuteChat(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to unmute chat")
+		return
+	}
+
+	writeSuccessResponse(w, "Chat unmuted", nil)
+}
+
+// handleDeleteChat handles DELETE /chats/{jid}
+func (h *Handler) handleDeleteChat(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.store.DeleteChat(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to delete chat")
+		return
+	}
+
+	writeSuccessResponse(w, "Chat deleted", nil)
+}
+
+// handleClearChat handles DELETE /chats/{jid}/messages
+func (h *Handler) handleClearChat(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, err = h.store.GetChat(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get chat")
+		return
+	}
+
+	if err := h.store.DeleteChatMessages(jid); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to delete chat messages")
+		return
+	}
+
+	writeSuccessResponse(w, "Chat cleared", nil)
+}
+
+// handleGetChatStats handles GET /chats/{jid}/stats?days=...
+func (h *Handler) handleGetChatStats(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	days, err := ParseIntParam(r, "days", 30, 1, 365)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, err = h.store.GetChat(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get chat")
+		return
+	}
+
+	stats, err := h.store.GetChatStatistics(jid)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get chat statistics")
+		return
+	}
+	hourly, err := h.store.GetMessageStats(jid)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get message stats")
+		return
+	}
+	activity, err := h.store.GetChatActivityByDay(jid, days)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get chat activity")
+		return
+	}
+
+	writeSuccessResponse(w, "", ChatStatsResponse{
+		ChatJID:        jid,
+		ChatStatistics: stats,
+		ByHour:         hourly.ByHour,
+		Activity:       activity,
+	})
+}
+
+// handleGetChatMedia handles GET /chats/{jid}/media?media_type=...&limit=...&offset=...
+func (h *Handler) handleGetChatMedia(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mediaType := r.URL.Query().Get("media_type")
+	if mediaType != "" && !database.ValidMediaType(mediaType) {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("unsupported media_type: %s", mediaType))
+		return
+	}
+
+	limit, offset, _, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := h.store.GetMediaMessages(jid, mediaType, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get media messages")
+		return
+	}
+
+	media := make([]*database.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.IsMedia() {
+			media = append(media, msg)
+		}
+	}
+
+	writeSuccessResponse(w, "", MessagesResponse{
+		Messages: media,
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
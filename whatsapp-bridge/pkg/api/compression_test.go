@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	messages := make([]map[string]string, 200)
+	for i := range messages {
+		messages[i] = map[string]string{"id": "msg", "content": strings.Repeat("hello ", 10)}
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/small" {
+			writeSuccessResponse(w, "ok", nil)
+			return
+		}
+		writeSuccessResponse(w, "Messages retrieved", messages)
+	})
+	handler := CompressionMiddleware(DefaultCompressionMinBytes)(next)
+
+	request := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	plain := request("/messages", "")
+	if plain.Header().Get("Content-Encoding") != "" {
+		t.Fatal("Expected no compression without Accept-Encoding")
+	}
+
+	compressed := request("/messages", "deflate, gzip;q=0.8")
+	if compressed.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", compressed.Code)
+	}
+	if got := compressed.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Expected gzip Content-Encoding, got %q", got)
+	}
+	if got := compressed.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Expected Vary: Accept-Encoding, got %q", got)
+	}
+	if compressed.Body.Len() >= plain.Body.Len() {
+		t.Errorf("Expected compressed body (%d bytes) to be smaller than %d bytes", compressed.Body.Len(), plain.Body.Len())
+	}
+
+	gz, err := gzip.NewReader(compressed.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	decompressed, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to decompress body: %v", err)
+	}
+	if !bytes.Equal(decompressed, plain.Body.Bytes()) {
+		t.Error("Expected decompressed body to match the uncompressed response")
+	}
+
+	small := request("/small", "gzip")
+	if small.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected responses under the threshold to be sent uncompressed")
+	}
+	if !strings.Contains(small.Body.String(), `"success":true`) {
+		t.Errorf("Expected the small response body to be intact, got %q", small.Body.String())
+	}
+
+	if rejected := request("/messages", "gzip;q=0"); rejected.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected no compression when gzip is refused with q=0")
+	}
+}
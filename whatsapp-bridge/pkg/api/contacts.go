@@ -0,0 +1,280 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/validation"
+	"whatsapp-client/pkg/vcard"
+)
+
+// SendContactRequest represents the request body for sharing a contact card
+type SendContactRequest struct {
+	Recipient  string `json:"recipient"`
+	ContactJID string `json:"contact_jid"`
+}
+
+// ValidateSendContactRequest validates a send contact request
+func ValidateSendContactRequest(req SendContactRequest) error {
+	if err := validation.ValidateRecipient(req.Recipient); err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	if err := validation.ValidateContactJID(req.ContactJID); err != nil {
+		return fmt.Errorf("invalid contact_jid: %w", err)
+	}
+	return nil
+}
+
+// countryCodePattern matches an ISO 3166-1 alpha-2 country code
+var countryCodePattern = regexp.MustCompile(`^[A-Za-z]{2}$`)
+
+// handleListContacts handles GET /contacts. The country parameter, an
+// ISO 3166-1 alpha-2 code, lists only the contacts whose number belongs to
+// that country.
+func (h *Handler) handleListContacts(w http.ResponseWriter, r *http.Request) {
+	var contacts []*database.Contact
+	var err error
+	if country := r.URL.Query().Get("country"); country != "" {
+		if !countryCodePattern.MatchString(country) {
+			writeErrorResponse(w, r, http.StatusBadRequest, "country must be a two-letter ISO 3166-1 code")
+			return
+		}
+		contacts, err = h.store.GetContactsByCountry(country)
+	} else {
+		contacts, err = h.store.GetContacts()
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get contacts")
+		return
+	}
+
+	writeSuccessResponse(w, "", contacts)
+}
+
+// handleSearchContacts handles GET /contacts/search?q=...&limit=...
+func (h *Handler) handleSearchContacts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if err := validation.ValidateSearchQuery(query); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid q: %v", err))
+		return
+	}
+	limit, err := ParseIntParam(r, "limit", 20, 1, 100)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contacts, err := h.store.SearchContacts(query, limit)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to search contacts")
+		return
+	}
+
+	writeSuccessResponse(w, "", contacts)
+}
+
+// ContactLookupResponse is the body of a contact lookup that found nothing
+type ContactLookupResponse struct {
+	Found bool `json:"found"`
+}
+
+// ValidateContactLookupParam validates the {jid} of a contact lookup
+func ValidateContactLookupParam(jid string) error {
+	if err := validation.ValidateJID(jid); err != nil {
+		return fmt.Errorf("invalid jid: %w", err)
+	}
+	return nil
+}
+
+// handleGetContact handles GET /contacts/{jid}
+func (h *Handler) handleGetContact(w http.ResponseWriter, r *http.Request) {
+	jid := r.PathValue("jid")
+	if err := ValidateContactLookupParam(jid); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contact, err := h.store.GetContact(jid)
+	writeContactLookup(w, r, contact, err)
+}
+
+// handleGetContactByPhone handles GET /contacts/by_phone?phone=...
+func (h *Handler) handleGetContactByPhone(w http.ResponseWriter, r *http.Request) {
+	// Contacts store the number as it appears in their JID, without a plus
+	phone := strings.TrimPrefix(r.URL.Query().Get("phone"), "+")
+	if err := validation.ValidatePhoneNumber(phone); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid phone: %v", err))
+		return
+	}
+
+	contact, err := h.store.GetContactByPhone(phone)
+	writeContactLookup(w, r, contact, err)
+}
+
+// writeContactLookup writes the result of a contact lookup. A contact that
+// is not stored is reported as 404 with found set to false.
+func writeContactLookup(w http.ResponseWriter, r *http.Request, contact *database.Contact, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		requestID := RequestIDFromContext(r.Context())
+		log.Printf("[%s] %s %s: %d contact not found", requestID, r.Method, r.URL.Path, http.StatusNotFound)
+		writeJSONResponse(w, http.StatusNotFound, Response{
+			Success:   false,
+			Error:     "contact not found",
+			Data:      ContactLookupResponse{Found: false},
+			RequestID: requestID,
+		})
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get contact")
+		return
+	}
+
+	writeSuccessResponse(w, "", contact)
+}
+
+// handleListBlockedContacts handles GET /contacts/blocked
+func (h *Handler) handleListBlockedContacts(w http.ResponseWriter, r *http.Request) {
+	contacts, err := h.store.GetBlockedContacts()
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get blocked contacts")
+		return
+	}
+
+	writeSuccessResponse(w, "", contacts)
+}
+
+// handleBlockContact handles POST /contacts/{jid}/block
+func (h *Handler) handleBlockContact(w http.ResponseWriter, r *http.Request) {
+	jid := r.PathValue("jid")
+	if err := validation.ValidateContactJID(jid); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid jid: %v", err))
+		return
+	}
+
+	if err := h.store.BlockContact(jid); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to block contact")
+		return
+	}
+
+	writeSuccessResponse(w, "Contact blocked", nil)
+}
+
+// handleUnblockContact handles DELETE /contacts/{jid}/block
+func (h *Handler) handleUnblockContact(w http.ResponseWriter, r *http.Request) {
+	jid := r.PathValue("jid")
+	if err := validation.ValidateContactJID(jid); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid jid: %v", err))
+		return
+	}
+
+	err := h.store.UnblockContact(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "contact is not blocked")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to unblock contact")
+		return
+	}
+
+	writeSuccessResponse(w, "Contact unblocked", nil)
+}
+
+// handleGetContactMentions handles GET /contacts/{jid}/mentions
+func (h *Handler) handleGetContactMentions(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, offset, _, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := h.store.GetMentionsForJID(jid, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get mentions")
+		return
+	}
+
+	writeSuccessResponse(w, "", messages)
+}
+
+// handleSendContact handles POST /messages/contact
+func (h *Handler) handleSendContact(w http.ResponseWriter, r *http.Request) {
+	var req SendContactRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if err := ValidateSendContactRequest(req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contact, err := h.store.GetContact(req.ContactJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "contact not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get contact")
+		return
+	}
+
+	to, err := parseRecipientJID(req.Recipient)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid recipient: %v", err))
+		return
+	}
+
+	if h.wa == nil {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, "not connected to WhatsApp")
+		return
+	}
+	card := contactCard(contact)
+	resp, err := h.wa.SendMessage(r.Context(), to, &waE2E.Message{
+		ContactMessage: &waE2E.ContactMessage{
+			DisplayName: proto.String(card.Name),
+			Vcard:       proto.String(vcard.FormatVCard(card)),
+		},
+	})
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to send contact: %v", err))
+		return
+	}
+
+	writeSuccessResponse(w, fmt.Sprintf("Contact sent to %s", req.Recipient), map[string]string{"message_id": resp.ID})
+}
+
+// contactCard picks the best known name and number of a stored contact,
+// falling back to the number in its JID
+func contactCard(c *database.Contact) *vcard.Contact {
+	phone := c.Phone
+	if phone == "" {
+		phone, _, _ = strings.Cut(c.JID, "@")
+	}
+
+	name := phone
+	for _, n := range []string{c.DisplayName, c.PushName, c.BusinessName} {
+		if n != "" {
+			name = n
+			break
+		}
+	}
+	return &vcard.Contact{Name: name, Phone: phone}
+}
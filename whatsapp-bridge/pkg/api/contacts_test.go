@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"whatsapp-client/pkg/database"
+)
+
+// newTestStore opens a store in a temporary directory for the test
+func newTestStore(t *testing.T) *database.Store {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := database.NewStore(filepath.Join(dir, "messages.db"), dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSendContactRoute(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.StoreContact(&database.Contact{JID: "4915112345678@s.whatsapp.net", PushName: "José Müller"}); err != nil {
+		t.Fatalf("Failed to store contact: %v", err)
+	}
+
+	sender := &mockSender{}
+	h := &Handler{store: store, wa: sender}
+	send := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/contact", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := send(`{"recipient":"1234567890","contact_jid":"4915112345678@s.whatsapp.net"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	card := sender.msg.GetContactMessage()
+	if card.GetDisplayName() != "José Müller" {
+		t.Errorf("Expected display name José Müller, got %q", card.GetDisplayName())
+	}
+	if !strings.Contains(card.GetVcard(), "TEL;waid=4915112345678:+4915112345678") {
+		t.Errorf("Expected the contact's number in the vCard, got %q", card.GetVcard())
+	}
+
+	if rec := send(`{"recipient":"1234567890","contact_jid":"4400000000@s.whatsapp.net"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown contact, got %d", rec.Code)
+	}
+	if rec := send(`{"recipient":"1234567890","contact_jid":"123456789-123456@g.us"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a group JID, got %d", rec.Code)
+	}
+}
+
+func TestGetContactRoutes(t *testing.T) {
+	store := newTestStore(t)
+	contact := &database.Contact{JID: "4915112345678@s.whatsapp.net", Phone: "4915112345678", PushName: "José Müller"}
+	if err := store.StoreContact(contact); err != nil {
+		t.Fatalf("Failed to store contact: %v", err)
+	}
+
+	h := &Handler{store: store}
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		found  bool
+	}{
+		{"by jid", "/contacts/4915112345678@s.whatsapp.net", http.StatusOK, true},
+		{"unknown jid", "/contacts/4400000000@s.whatsapp.net", http.StatusNotFound, false},
+		{"invalid jid", "/contacts/not-a-jid", http.StatusBadRequest, false},
+		{"by phone", "/contacts/by_phone?phone=4915112345678", http.StatusOK, true},
+		{"by phone with plus", "/contacts/by_phone?phone=%2B4915112345678", http.StatusOK, true},
+		{"unknown phone", "/contacts/by_phone?phone=4400000000", http.StatusNotFound, false},
+		{"invalid phone", "/contacts/by_phone?phone=abc", http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1"+tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+
+			switch tt.status {
+			case http.StatusOK:
+				var resp struct {
+					Data database.Contact `json:"data"`
+				}
+				if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.Data.JID != contact.JID || resp.Data.PushName != contact.PushName {
+					t.Errorf("Expected %+v, got %+v", contact, resp.Data)
+				}
+			case http.StatusNotFound:
+				if !strings.Contains(rec.Body.String(), `"found":false`) {
+					t.Errorf("Expected found:false in the body, got %s", rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestListContactsByCountry(t *testing.T) {
+	store := newTestStore(t)
+	for _, c := range []*database.Contact{
+		{JID: "4915112345678@s.whatsapp.net", PushName: "José Müller"},
+		{JID: "12025550123@s.whatsapp.net", PushName: "Bob"},
+	} {
+		if err := store.StoreContact(c); err != nil {
+			t.Fatalf("Failed to store contact: %v", err)
+		}
+	}
+
+	h := &Handler{store: store}
+	list := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/contacts"+query, nil))
+		return rec
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 2},
+		{"?country=DE", 1},
+		{"?country=us", 1},
+		{"?country=FR", 0},
+	}
+	for _, tt := range tests {
+		rec := list(tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Data []*database.Contact `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(resp.Data) != tt.want {
+			t.Errorf("%q: expected %d contacts, got %d", tt.query, tt.want, len(resp.Data))
+		}
+	}
+
+	if rec := list("?country=USA"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a three-letter code, got %d", rec.Code)
+	}
+}
+
+func TestBlockContactRoutes(t *testing.T) {
+	store := newTestStore(t)
+	const jid = "4915112345678@s.whatsapp.net"
+	h := &Handler{store: store}
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(method, "/v1"+path, nil))
+		return rec
+	}
+
+	if rec := do(http.MethodPost, "/contacts/"+jid+"/block"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 blocking, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := do(http.MethodGet, "/contacts/blocked")
+	var resp struct {
+		Data []*database.Contact `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].JID != jid {
+		t.Fatalf("Expected %s to be listed as blocked, got %+v", jid, resp.Data)
+	}
+
+	if rec := do(http.MethodDelete, "/contacts/"+jid+"/block"); rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 unblocking, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/contacts/"+jid+"/block"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 unblocking twice, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/contacts/123456789-123456@g.us/block"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 blocking a group, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+)
+
+// CORSMiddleware allows browser requests from the listed origins. A matching
+// Origin is reflected in Access-Control-Allow-Origin; other origins get no
+// CORS headers. "*" in the list allows every origin. Preflight requests are
+// answered here without reaching the handler.
+func CORSMiddleware(origins []string) func(http.Handler) http.Handler {
+	allowAll := slices.Contains(origins, "*")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			allowed := origin != "" && (allowAll || slices.Contains(origins, origin))
+			if allowed {
+				if allowAll {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				if allowed {
+					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		origins    []string
+		method     string
+		origin     string
+		wantOrigin string
+		wantStatus int
+	}{
+		{"listed origin", []string{"https://app.example.com"}, http.MethodGet, "https://app.example.com", "https://app.example.com", http.StatusOK},
+		{"unlisted origin", []string{"https://app.example.com"}, http.MethodGet, "https://evil.example.com", "", http.StatusOK},
+		{"no origins configured", nil, http.MethodGet, "https://app.example.com", "", http.StatusOK},
+		{"wildcard", []string{"*"}, http.MethodGet, "https://any.example.com", "*", http.StatusOK},
+		{"same-origin request", []string{"*"}, http.MethodGet, "", "", http.StatusOK},
+		{"preflight", []string{"https://app.example.com"}, http.MethodOptions, "https://app.example.com", "https://app.example.com", http.StatusNoContent},
+		{"rejected preflight", []string{"https://app.example.com"}, http.MethodOptions, "https://evil.example.com", "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/chats", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			CORSMiddleware(tt.origins)(next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", tt.wantOrigin, got)
+			}
+			allowMethods := rec.Header().Get("Access-Control-Allow-Methods")
+			if wantMethods := tt.method == http.MethodOptions && tt.wantOrigin != ""; wantMethods != (allowMethods != "") {
+				t.Errorf("Unexpected Access-Control-Allow-Methods %q", allowMethods)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// SendDocumentRequest represents the request body for sending a document
+// from a path on the bridge host
+type SendDocumentRequest struct {
+	Recipient string `json:"recipient"`
+	FilePath  string `json:"file_path"`
+	Caption   string `json:"caption,omitempty"`
+}
+
+// documentContentTypes maps each document extension that can be sent to the
+// MIME types validation.DetectMIMEType reports for such a file. Office Open
+// XML files are zip archives and legacy Office files are OLE containers.
+var documentContentTypes = map[string][]string{
+	".pdf":  {"application/pdf"},
+	".doc":  {"application/x-ole-storage"},
+	".docx": {"application/zip"},
+	".txt":  {"text/plain"},
+}
+
+// ValidateDocumentRequest checks that the document is a supported type and
+// that its contents match its extension, so an executable renamed to .pdf
+// is rejected. The document must lie within allowedDirs and may be at most
+// maxBytes long. It returns the detected MIME type.
+func ValidateDocumentRequest(req SendDocumentRequest, allowedDirs []string, maxBytes int64) (string, error) {
+	if err := validation.ValidateRecipient(req.Recipient); err != nil {
+		return "", fmt.Errorf("invalid recipient: %w", err)
+	}
+	if req.Caption != "" {
+		if err := validation.ValidateMessageContent(req.Caption); err != nil {
+			return "", fmt.Errorf("invalid caption: %w", err)
+		}
+	}
+	if err := validation.ValidateFilePath(req.FilePath, allowedDirs); err != nil {
+		return "", fmt.Errorf("invalid file_path: %w", err)
+	}
+	if err := validation.ValidateFileSize(req.FilePath, maxBytes); err != nil {
+		return "", fmt.Errorf("invalid file_path: %w", err)
+	}
+	// A script is plain text, so its contents alone would pass as a .txt
+	if err := rejectExecutable(req.FilePath); err != nil {
+		return "", fmt.Errorf("invalid file_path: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(req.FilePath))
+	allowed, ok := documentContentTypes[ext]
+	if !ok {
+		return "", fmt.Errorf("file_path must be a PDF, Word or text document")
+	}
+
+	detected, err := validation.DetectMIMEType(req.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid file_path: %w", err)
+	}
+	for _, mimeType := range allowed {
+		if detected == mimeType {
+			return detected, nil
+		}
+	}
+	return "", fmt.Errorf("file content (%s) does not match extension %s", detected, ext)
+}
+
+// handleSendDocument handles POST /messages/document
+func (h *Handler) handleSendDocument(w http.ResponseWriter, r *http.Request) {
+	var req SendDocumentRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if _, err := ValidateDocumentRequest(req, h.cfg.AllowedUploadDirs, h.cfg.MaxUploadFileSizeBytes); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.send(req.Recipient, req.Caption, req.FilePath, SendOptions{}); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to send document: %v", err))
+		return
+	}
+
+	writeSuccessResponse(w, fmt.Sprintf("Document sent to %s", req.Recipient), nil)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"whatsapp-client/pkg/config"
+)
+
+func TestValidateDocumentRequest(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	pdf := write("report.pdf", []byte("%PDF-1.7\n%âãÏÓ\n"))
+	docx := write("report.docx", []byte("PK\x03\x04\x14\x00\x06\x00"))
+	txt := write("notes.txt", []byte("meeting notes\n"))
+	exeAsPDF := write("invoice.pdf", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00"))
+	textAsPDF := write("fake.pdf", []byte("just some text"))
+	pdfAsTxt := write("report-copy.txt", []byte("%PDF-1.7\n"))
+	exe := write("setup.exe", []byte("MZ\x90\x00"))
+	scriptAsTxt := write("readme.txt", []byte("#!/bin/sh\nrm -rf ~\n"))
+	outside := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(outside, []byte("%PDF-1.7\n"), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", outside, err)
+	}
+	const recipient = "1234567890"
+
+	tests := []struct {
+		name     string
+		req      SendDocumentRequest
+		wantMIME string
+		wantErr  bool
+	}{
+		{"pdf", SendDocumentRequest{Recipient: recipient, FilePath: pdf, Caption: "Q3"}, "application/pdf", false},
+		{"docx", SendDocumentRequest{Recipient: recipient, FilePath: docx}, "application/zip", false},
+		{"txt", SendDocumentRequest{Recipient: recipient, FilePath: txt}, "text/plain", false},
+		{"executable renamed to pdf", SendDocumentRequest{Recipient: recipient, FilePath: exeAsPDF}, "", true},
+		{"text renamed to pdf", SendDocumentRequest{Recipient: recipient, FilePath: textAsPDF}, "", true},
+		{"pdf renamed to txt", SendDocumentRequest{Recipient: recipient, FilePath: pdfAsTxt}, "", true},
+		{"script renamed to txt", SendDocumentRequest{Recipient: recipient, FilePath: scriptAsTxt}, "", true},
+		{"unsupported extension", SendDocumentRequest{Recipient: recipient, FilePath: exe}, "", true},
+		{"missing file", SendDocumentRequest{Recipient: recipient, FilePath: filepath.Join(dir, "missing.pdf")}, "", true},
+		{"no file", SendDocumentRequest{Recipient: recipient}, "", true},
+		{"invalid recipient", SendDocumentRequest{Recipient: "nobody", FilePath: pdf}, "", true},
+		{"outside allowed dirs", SendDocumentRequest{Recipient: recipient, FilePath: outside}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mimeType, err := ValidateDocumentRequest(tt.req, []string{dir}, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateDocumentRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if mimeType != tt.wantMIME {
+				t.Errorf("Expected MIME type %q, got %q", tt.wantMIME, mimeType)
+			}
+		})
+	}
+}
+
+func TestHandleSendDocumentRejectsMismatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "invoice.pdf")
+	if err := os.WriteFile(path, []byte("MZ\x90\x00\x03\x00"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	called := false
+	h := &Handler{cfg: &config.Config{AllowedUploadDirs: []string{dir}}, send: func(recipient, message, mediaPath string, opts SendOptions) error {
+		called = true
+		return nil
+	}}
+
+	body := `{"recipient":"1234567890","file_path":"` + path + `"}`
+	rec := httptest.NewRecorder()
+	h.handleSendDocument(rec, httptest.NewRequest(http.MethodPost, "/messages/document", strings.NewReader(body)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if called {
+		t.Error("Expected nothing to be sent")
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// editWindow is how long after sending WhatsApp accepts an edit
+const editWindow = 15 * time.Minute
+
+// EditMessageRequest represents the request body for editing one of our
+// messages. MessageID may be omitted since the path names the message.
+type EditMessageRequest struct {
+	MessageID  string `json:"message_id,omitempty"`
+	ChatJID    string `json:"chat_jid"`
+	NewContent string `json:"new_content"`
+}
+
+// validateEditMessageRequest validates an edit message request for the
+// message named in the path
+func validateEditMessageRequest(req EditMessageRequest, pathID string) error {
+	if req.MessageID != "" && req.MessageID != pathID {
+		return fmt.Errorf("message_id does not match the path")
+	}
+	if err := validation.ValidateJID(req.ChatJID); err != nil {
+		return fmt.Errorf("invalid chat_jid: %w", err)
+	}
+	if err := validation.ValidateMessageContent(req.NewContent); err != nil {
+		return fmt.Errorf("invalid new_content: %w", err)
+	}
+	return nil
+}
+
+// handleEditMessage handles PUT /messages/{id}. The edit is sent before it
+// is recorded so that the stored history never shows an edit WhatsApp
+// rejected.
+func (h *Handler) handleEditMessage(w http.ResponseWriter, r *http.Request) {
+	var req EditMessageRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	messageID := r.PathValue("id")
+	if err := validateEditMessageRequest(req, messageID); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message, err := h.store.GetMessage(messageID, req.ChatJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "message not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get message")
+		return
+	}
+	if !message.IsFromMe {
+		writeErrorResponse(w, r, http.StatusForbidden, "only messages sent by this account can be edited")
+		return
+	}
+	if time.Since(message.Timestamp) > editWindow {
+		writeErrorResponse(w, r, http.StatusConflict, fmt.Sprintf("messages can only be edited within %v of sending", editWindow))
+		return
+	}
+
+	chat, err := types.ParseJID(req.ChatJID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid chat_jid: %v", err))
+		return
+	}
+	if h.wa == nil {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, "not connected to WhatsApp")
+		return
+	}
+	if _, err := h.wa.SendMessage(r.Context(), chat, editMessage(chat, messageID, req.NewContent)); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to send edit: %v", err))
+		return
+	}
+
+	if err := h.store.RecordMessageEdit(messageID, req.ChatJID, req.NewContent, time.Now()); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "edit sent but failed to record it")
+		return
+	}
+
+	writeSuccessResponse(w, "Message edited", nil)
+}
+
+// editMessage builds the protocol message that replaces the text of one of
+// our messages
+func editMessage(chat types.JID, messageID, newContent string) *waE2E.Message {
+	return &waE2E.Message{
+		EditedMessage: &waE2E.FutureProofMessage{
+			Message: &waE2E.Message{
+				ProtocolMessage: &waE2E.ProtocolMessage{
+					Key: &waCommon.MessageKey{
+						FromMe:    proto.Bool(true),
+						ID:        proto.String(messageID),
+						RemoteJID: proto.String(chat.String()),
+					},
+					Type:          waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
+					EditedMessage: &waE2E.Message{Conversation: proto.String(newContent)},
+					TimestampMS:   proto.Int64(time.Now().UnixMilli()),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"whatsapp-client/pkg/config"
+	"whatsapp-client/pkg/database"
+)
+
+func TestEditMessageRoute(t *testing.T) {
+	store := newTestStore(t)
+	const chatJID = "15551234567@s.whatsapp.net"
+	now := time.Now()
+	if err := store.StoreChat(&database.Chat{JID: chatJID, Name: "Alice", LastMessageTime: now}); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	for _, m := range []*database.Message{
+		{ID: "MINE", ChatJID: chatJID, Sender: "me", Content: "See you at 5", Timestamp: now.Add(-time.Minute), IsFromMe: true},
+		{ID: "OLD", ChatJID: chatJID, Sender: "me", Content: "Yesterday", Timestamp: now.Add(-time.Hour), IsFromMe: true},
+		{ID: "THEIRS", ChatJID: chatJID, Sender: "15551234567", Content: "OK", Timestamp: now},
+	} {
+		if err := store.StoreMessage(m); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+
+	sender := &mockSender{}
+	h := &Handler{store: store, wa: sender}
+	edit := func(id, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/v1/messages/"+id, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := edit("MINE", `{"chat_jid":"`+chatJID+`","new_content":"See you at 6"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	protocol := sender.msg.GetEditedMessage().GetMessage().GetProtocolMessage()
+	if protocol.GetType() != waE2E.ProtocolMessage_MESSAGE_EDIT {
+		t.Fatalf("Expected a message edit, got %v", sender.msg)
+	}
+	if key := protocol.GetKey(); key.GetID() != "MINE" || !key.GetFromMe() || key.GetRemoteJID() != chatJID {
+		t.Errorf("Unexpected message key %v", key)
+	}
+	if got := protocol.GetEditedMessage().GetConversation(); got != "See you at 6" {
+		t.Errorf("Expected edited text %q, got %q", "See you at 6", got)
+	}
+
+	message, err := store.GetMessage("MINE", chatJID)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+	if message.Content != "See you at 6" {
+		t.Errorf("Expected stored content to be updated, got %q", message.Content)
+	}
+	history, err := store.GetEditHistory("MINE", chatJID)
+	if err != nil {
+		t.Fatalf("Failed to get edit history: %v", err)
+	}
+	if len(history) != 1 || history[0].OriginalContent != "See you at 5" {
+		t.Errorf("Expected the original content in the edit history, got %+v", history)
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{"too old", "OLD", `{"chat_jid":"` + chatJID + `","new_content":"Today"}`, http.StatusConflict},
+		{"not from me", "THEIRS", `{"chat_jid":"` + chatJID + `","new_content":"Not OK"}`, http.StatusForbidden},
+		{"unknown message", "MISSING", `{"chat_jid":"` + chatJID + `","new_content":"Hi"}`, http.StatusNotFound},
+		{"empty content", "MINE", `{"chat_jid":"` + chatJID + `","new_content":""}`, http.StatusBadRequest},
+		{"mismatched id", "MINE", `{"message_id":"OTHER","chat_jid":"` + chatJID + `","new_content":"Hi"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender.msg = nil
+			if rec := edit(tt.id, tt.body); rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if sender.msg != nil {
+				t.Error("Expected no edit to be sent")
+			}
+		})
+	}
+}
+
+func TestDeleteMessageRoute(t *testing.T) {
+	store := newTestStore(t)
+	const chatJID = "123456789-123456@g.us"
+	now := time.Now()
+	if err := store.StoreChat(&database.Chat{JID: chatJID, Name: "Team", LastMessageTime: now}); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	for _, m := range []*database.Message{
+		{ID: "MINE", ChatJID: chatJID, Sender: "me", Content: "Oops", Timestamp: now.Add(-time.Hour), IsFromMe: true},
+		{ID: "OLD", ChatJID: chatJID, Sender: "me", Content: "Last week", Timestamp: now.Add(-72 * time.Hour), IsFromMe: true},
+		{ID: "THEIRS", ChatJID: chatJID, Sender: "15551234567", Content: "Hi", Timestamp: now},
+	} {
+		if err := store.StoreMessage(m); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+
+	sender := &mockSender{}
+	h := &Handler{store: store, wa: sender, cfg: &config.Config{RevokeWindow: 60 * time.Hour}}
+	del := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v1/messages/"+id+"?chat_jid="+chatJID, nil))
+		return rec
+	}
+
+	if rec := del("MINE"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	protocol := sender.msg.GetProtocolMessage()
+	if protocol.GetType() != waE2E.ProtocolMessage_REVOKE {
+		t.Fatalf("Expected a revoke, got %v", sender.msg)
+	}
+	if key := protocol.GetKey(); key.GetID() != "MINE" || !key.GetFromMe() || key.GetRemoteJID() != chatJID {
+		t.Errorf("Unexpected message key %v", key)
+	}
+	message, err := store.GetMessage("MINE", chatJID)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+	if message.DeletedAt == nil {
+		t.Error("Expected the message to be marked deleted")
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"outside window", "OLD", http.StatusForbidden},
+		{"not from me", "THEIRS", http.StatusForbidden},
+		{"unknown message", "MISSING", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender.msg = nil
+			if rec := del(tt.id); rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if sender.msg != nil {
+				t.Error("Expected no revoke to be sent")
+			}
+		})
+	}
+}
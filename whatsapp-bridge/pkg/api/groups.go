@@ -0,0 +1,289 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/validation"
+)
+
+// maxGroupSubjectLength is the longest group subject WhatsApp accepts, in
+// characters
+const maxGroupSubjectLength = 25
+
+// CreateGroupRequest represents the request body for creating a group. We
+// are added to the group implicitly and need not be listed.
+type CreateGroupRequest struct {
+	Subject      string   `json:"subject"`
+	Participants []string `json:"participants"`
+}
+
+// AddParticipantRequest represents the request body for adding a member to
+// a group; the group is taken from the path when GroupJID is empty
+type AddParticipantRequest struct {
+	GroupJID       string `json:"group_jid,omitempty"`
+	ParticipantJID string `json:"participant_jid"`
+}
+
+// RemoveParticipantRequest identifies a member to remove from a group; both
+// fields are taken from the path
+type RemoveParticipantRequest struct {
+	GroupJID       string
+	ParticipantJID string
+}
+
+// ValidateCreateGroupRequest validates a create group request
+func ValidateCreateGroupRequest(req CreateGroupRequest) error {
+	if req.Subject == "" {
+		return fmt.Errorf("subject cannot be empty")
+	}
+	if utf8.RuneCountInString(req.Subject) > maxGroupSubjectLength {
+		return fmt.Errorf("subject cannot be longer than %d characters", maxGroupSubjectLength)
+	}
+	for i, participant := range req.Participants {
+		if _, err := parseParticipantJID(participant); err != nil {
+			return fmt.Errorf("invalid participant %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// parseParticipantJID turns a group member, either a user JID or a bare
+// phone number, into a JID
+func parseParticipantJID(participant string) (types.JID, error) {
+	if validation.ValidatePhoneNumber(participant) == nil {
+		return types.NewJID(participant, types.DefaultUserServer), nil
+	}
+	if err := validation.ValidateContactJID(participant); err != nil {
+		return types.JID{}, err
+	}
+	return types.ParseJID(participant)
+}
+
+// GroupInfoResponse is a group's metadata together with everyone who has
+// been a member
+type GroupInfoResponse struct {
+	*database.GroupInfo
+	Participants []*database.GroupParticipant `json:"participants"`
+}
+
+// handleListGroups handles GET /groups?limit=...&offset=...
+func (h *Handler) handleListGroups(w http.ResponseWriter, r *http.Request) {
+	limit, offset, _, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	groups, err := h.store.ListGroups(limit, offset)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to list groups")
+		return
+	}
+
+	writeSuccessResponse(w, "", groups)
+}
+
+// handleGetGroupInfo handles GET /groups/{jid}
+func (h *Handler) handleGetGroupInfo(w http.ResponseWriter, r *http.Request) {
+	jid := r.PathValue("jid")
+	if err := validation.ValidateGroupJID(jid); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid jid: %v", err))
+		return
+	}
+
+	info, err := h.store.GetGroupInfo(jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "group not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get group info")
+		return
+	}
+	participants, err := h.store.GetParticipants(jid)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get participants")
+		return
+	}
+
+	writeSuccessResponse(w, "", GroupInfoResponse{GroupInfo: info, Participants: participants})
+}
+
+// handleGetParticipants handles GET /groups/{jid}/participants
+func (h *Handler) handleGetParticipants(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	participants, err := h.store.GetParticipants(jid)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get participants")
+		return
+	}
+
+	writeSuccessResponse(w, "", participants)
+}
+
+// handleCreateGroup handles POST /groups. The new group's metadata and
+// members are recorded locally and returned as by GET /groups/{jid}.
+func (h *Handler) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
+	var req CreateGroupRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if err := ValidateCreateGroupRequest(req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	participants := make([]types.JID, len(req.Participants))
+	for i, participant := range req.Participants {
+		participants[i], _ = parseParticipantJID(participant)
+	}
+
+	if h.groups == nil {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, "not connected to WhatsApp")
+		return
+	}
+	created, err := h.groups.CreateGroup(whatsmeow.ReqCreateGroup{Name: req.Subject, Participants: participants})
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to create group: %v", err))
+		return
+	}
+
+	info := &database.GroupInfo{JID: created.JID.String(), Subject: created.Name, Description: created.Topic}
+	if !created.OwnerJID.IsEmpty() {
+		info.OwnerJID = created.OwnerJID.String()
+	}
+	if !created.GroupCreated.IsZero() {
+		info.CreatedAt = &created.GroupCreated
+	}
+	err = h.store.StoreGroupInfo(info)
+	if err == nil {
+		err = h.recordParticipants(info.JID, created.Participants)
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "group created but failed to record it")
+		return
+	}
+
+	members, err := h.store.GetParticipants(info.JID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get participants")
+		return
+	}
+
+	writeSuccessResponse(w, fmt.Sprintf("Group %s created", info.JID), GroupInfoResponse{GroupInfo: info, Participants: members})
+}
+
+// handleAddParticipant handles POST /groups/{jid}/participants
+func (h *Handler) handleAddParticipant(w http.ResponseWriter, r *http.Request) {
+	var req AddParticipantRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if req.GroupJID == "" {
+		req.GroupJID = r.PathValue("jid")
+	} else if req.GroupJID != r.PathValue("jid") {
+		writeErrorResponse(w, r, http.StatusBadRequest, "group_jid does not match the path")
+		return
+	}
+
+	h.changeParticipant(w, r, req.GroupJID, req.ParticipantJID, whatsmeow.ParticipantChangeAdd)
+}
+
+// handleRemoveParticipant handles DELETE /groups/{jid}/participants/{participant_jid}
+func (h *Handler) handleRemoveParticipant(w http.ResponseWriter, r *http.Request) {
+	req := RemoveParticipantRequest{GroupJID: r.PathValue("jid"), ParticipantJID: r.PathValue("participant_jid")}
+
+	h.changeParticipant(w, r, req.GroupJID, req.ParticipantJID, whatsmeow.ParticipantChangeRemove)
+}
+
+// changeParticipant adds a member to or removes one from a group on
+// WhatsApp, records the change and responds with the group's participants
+func (h *Handler) changeParticipant(w http.ResponseWriter, r *http.Request, groupJID, participantJID string, action whatsmeow.ParticipantChange) {
+	if err := validation.ValidateGroupJID(groupJID); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid jid: %v", err))
+		return
+	}
+	group, err := types.ParseJID(groupJID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid jid: %v", err))
+		return
+	}
+	participant, err := parseParticipantJID(participantJID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid participant_jid: %v", err))
+		return
+	}
+
+	if h.groups == nil {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, "not connected to WhatsApp")
+		return
+	}
+	changed, err := h.groups.UpdateGroupParticipants(group, []types.JID{participant}, action)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to %s participant: %v", action, err))
+		return
+	}
+	for _, p := range changed {
+		if p.Error != 0 {
+			writeErrorResponse(w, r, http.StatusBadGateway, fmt.Sprintf("WhatsApp refused to %s participant: error %d", action, p.Error))
+			return
+		}
+	}
+
+	if action == whatsmeow.ParticipantChangeAdd {
+		err = h.recordParticipants(groupJID, changed)
+	} else {
+		// A member we never saw join has nothing to mark as left
+		err = h.store.RemoveParticipant(groupJID, participant.String(), time.Now())
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "participant change sent but failed to record it")
+		return
+	}
+
+	participants, err := h.store.GetParticipants(groupJID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get participants")
+		return
+	}
+
+	writeSuccessResponse(w, "", participants)
+}
+
+// recordParticipants records the members WhatsApp reports as added to a
+// group, skipping those it could not add
+func (h *Handler) recordParticipants(groupJID string, participants []types.GroupParticipant) error {
+	for _, p := range participants {
+		if p.Error != 0 {
+			continue
+		}
+		err := h.store.UpsertParticipant(&database.GroupParticipant{
+			GroupJID:       groupJID,
+			ParticipantJID: p.JID.String(),
+			IsAdmin:        p.IsAdmin || p.IsSuperAdmin,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+
+	"whatsapp-client/pkg/database"
+)
+
+// mockGroupManager creates groups with a fixed JID and records the last
+// participant change; refuse is reported as the error code of every change
+type mockGroupManager struct {
+	action  whatsmeow.ParticipantChange
+	changed []types.JID
+	refuse  int
+}
+
+func (m *mockGroupManager) CreateGroup(req whatsmeow.ReqCreateGroup) (*types.GroupInfo, error) {
+	own := types.NewJID("15550000000", types.DefaultUserServer)
+	info := &types.GroupInfo{
+		JID:          types.NewJID("123456789-123456", types.GroupServer),
+		OwnerJID:     own,
+		GroupName:    types.GroupName{Name: req.Name},
+		GroupCreated: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Participants: []types.GroupParticipant{{JID: own, IsSuperAdmin: true}},
+	}
+	for _, jid := range req.Participants {
+		info.Participants = append(info.Participants, types.GroupParticipant{JID: jid, Error: m.refuse})
+	}
+	return info, nil
+}
+
+func (m *mockGroupManager) UpdateGroupParticipants(jid types.JID, participantChanges []types.JID, action whatsmeow.ParticipantChange) ([]types.GroupParticipant, error) {
+	m.action, m.changed = action, participantChanges
+	result := make([]types.GroupParticipant, len(participantChanges))
+	for i, p := range participantChanges {
+		result[i] = types.GroupParticipant{JID: p, Error: m.refuse}
+	}
+	return result, nil
+}
+
+func TestGroupInfoRoute(t *testing.T) {
+	store := newTestStore(t)
+	const group = "123456789-123456@g.us"
+	created := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	if err := store.StoreGroupInfo(&database.GroupInfo{JID: group, Subject: "Climbing", Description: "Tuesdays at 7", CreatedAt: &created}); err != nil {
+		t.Fatalf("Failed to store group info: %v", err)
+	}
+	for _, jid := range []string{"15551111111@s.whatsapp.net", "15552222222@s.whatsapp.net"} {
+		if err := store.UpsertParticipant(&database.GroupParticipant{GroupJID: group, ParticipantJID: jid}); err != nil {
+			t.Fatalf("Failed to store participant: %v", err)
+		}
+	}
+
+	h := &Handler{store: store}
+	get := func(jid string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/groups/"+jid, nil))
+		return rec
+	}
+
+	rec := get(group)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data GroupInfoResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	info := resp.Data
+	if info.GroupInfo == nil || info.Subject != "Climbing" || info.Description != "Tuesdays at 7" {
+		t.Fatalf("Unexpected group info %+v", info.GroupInfo)
+	}
+	if info.CreatedAt == nil || !info.CreatedAt.Equal(created) {
+		t.Errorf("Expected creation time %v, got %v", created, info.CreatedAt)
+	}
+	if len(info.Participants) != 2 {
+		t.Errorf("Expected two participants, got %d", len(info.Participants))
+	}
+
+	if rec := get("987654321-654321@g.us"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown group, got %d", rec.Code)
+	}
+	if rec := get("15551111111@s.whatsapp.net"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a user JID, got %d", rec.Code)
+	}
+}
+
+func TestListGroupsRoute(t *testing.T) {
+	store := newTestStore(t)
+	for _, info := range []*database.GroupInfo{
+		{JID: "123456789-111111@g.us", Subject: "Climbing"},
+		{JID: "123456789-222222@g.us", Subject: "Book club"},
+	} {
+		if err := store.StoreGroupInfo(info); err != nil {
+			t.Fatalf("Failed to store group info: %v", err)
+		}
+	}
+
+	h := &Handler{store: store}
+	list := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/groups"+query, nil))
+		return rec
+	}
+
+	rec := list("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data []*database.GroupInfo `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Errorf("Expected two groups, got %d", len(resp.Data))
+	}
+
+	if rec := list("?limit=abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid limit, got %d", rec.Code)
+	}
+}
+
+func TestValidateCreateGroupRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     CreateGroupRequest
+		wantErr bool
+	}{
+		{"phone numbers and JIDs", CreateGroupRequest{Subject: "Climbing", Participants: []string{"15551111111", "15552222222@s.whatsapp.net"}}, false},
+		{"no participants", CreateGroupRequest{Subject: "Just me"}, false},
+		{"25 characters", CreateGroupRequest{Subject: strings.Repeat("é", 25)}, false},
+		{"26 characters", CreateGroupRequest{Subject: strings.Repeat("a", 26)}, true},
+		{"empty subject", CreateGroupRequest{Participants: []string{"15551111111"}}, true},
+		{"group participant", CreateGroupRequest{Subject: "Climbing", Participants: []string{"123456789-123456@g.us"}}, true},
+		{"invalid participant", CreateGroupRequest{Subject: "Climbing", Participants: []string{"alice"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateGroupRequest(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreateGroupRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateGroupRoute(t *testing.T) {
+	store := newTestStore(t)
+	h := &Handler{store: store, groups: &mockGroupManager{}}
+	rec := httptest.NewRecorder()
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/groups", strings.NewReader(`{"subject":"Climbing","participants":["15551111111"]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data GroupInfoResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Data.GroupInfo == nil || resp.Data.JID != "123456789-123456@g.us" || resp.Data.Subject != "Climbing" {
+		t.Fatalf("Unexpected group info %+v", resp.Data.GroupInfo)
+	}
+	if len(resp.Data.Participants) != 2 {
+		t.Fatalf("Expected us and the added participant, got %+v", resp.Data.Participants)
+	}
+
+	stored, err := store.GetGroupInfo("123456789-123456@g.us")
+	if err != nil || stored.OwnerJID != "15550000000@s.whatsapp.net" {
+		t.Errorf("Expected the group to be stored with its owner, got %+v (%v)", stored, err)
+	}
+	if isAdmin, _ := store.IsAdmin("123456789-123456@g.us", "15550000000@s.whatsapp.net"); !isAdmin {
+		t.Error("Expected the creator to be recorded as an admin")
+	}
+
+	h = &Handler{store: store}
+	rec = httptest.NewRecorder()
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/groups", strings.NewReader(`{"subject":"Climbing"}`)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 when not connected, got %d", rec.Code)
+	}
+}
+
+func TestGroupParticipantRoutes(t *testing.T) {
+	store := newTestStore(t)
+	const group = "123456789-123456@g.us"
+	const bob = "15552222222@s.whatsapp.net"
+	groups := &mockGroupManager{}
+	h := &Handler{store: store, groups: groups}
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(method, "/v1"+path, strings.NewReader(body)))
+		return rec
+	}
+	participants := func(rec *httptest.ResponseRecorder) []*database.GroupParticipant {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Data []*database.GroupParticipant `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp.Data
+	}
+
+	added := participants(do(http.MethodPost, "/groups/"+group+"/participants", `{"participant_jid":"15552222222"}`))
+	if groups.action != whatsmeow.ParticipantChangeAdd || len(groups.changed) != 1 || groups.changed[0].String() != bob {
+		t.Errorf("Expected %s to be added, got %s %v", bob, groups.action, groups.changed)
+	}
+	if len(added) != 1 || added[0].ParticipantJID != bob || added[0].LeftAt != nil {
+		t.Fatalf("Expected %s as the only member, got %+v", bob, added)
+	}
+
+	removed := participants(do(http.MethodDelete, "/groups/"+group+"/participants/"+bob, ""))
+	if groups.action != whatsmeow.ParticipantChangeRemove {
+		t.Errorf("Expected a remove, got %s", groups.action)
+	}
+	if len(removed) != 1 || removed[0].LeftAt == nil {
+		t.Fatalf("Expected %s to be recorded as having left, got %+v", bob, removed)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"mismatched group", http.MethodPost, "/groups/" + group + "/participants", `{"group_jid":"987654321-654321@g.us","participant_jid":"15552222222"}`, http.StatusBadRequest},
+		{"user as group", http.MethodPost, "/groups/" + bob + "/participants", `{"participant_jid":"15552222222"}`, http.StatusBadRequest},
+		{"invalid participant", http.MethodDelete, "/groups/" + group + "/participants/bob", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := do(tt.method, tt.path, tt.body); rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rec.Code)
+		}
+	}
+
+	groups.refuse = 403
+	if rec := do(http.MethodPost, "/groups/"+group+"/participants", `{"participant_jid":"15553333333"}`); rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502 when WhatsApp refuses the change, got %d", rec.Code)
+	}
+	if all, _ := store.GetParticipants(group); len(all) != 1 {
+		t.Errorf("Expected a refused participant not to be recorded, got %+v", all)
+	}
+}
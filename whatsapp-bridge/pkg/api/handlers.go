@@ -1,26 +1,91 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+
+	"whatsapp-client/pkg/config"
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/janitor"
+	"whatsapp-client/pkg/jobs"
 	"whatsapp-client/pkg/validation"
 )
 
+// SendFunc sends a WhatsApp message with an optional media file attached;
+// the message is used as the media caption
+type SendFunc func(recipient, message, mediaPath string, opts SendOptions) error
+
+// SendOptions adjusts how a SendFunc delivers its media
+type SendOptions struct {
+	// VoiceNote sends an Ogg audio attachment as a push-to-talk voice note
+	VoiceNote bool
+}
+
+// MessageSender sends prebuilt WhatsApp messages; *whatsmeow.Client
+// implements it
+type MessageSender interface {
+	SendMessage(ctx context.Context, to types.JID, message *waE2E.Message, extra ...whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error)
+}
+
+// GroupManager creates groups and changes their membership;
+// *whatsmeow.Client implements it
+type GroupManager interface {
+	CreateGroup(req whatsmeow.ReqCreateGroup) (*types.GroupInfo, error)
+	UpdateGroupParticipants(jid types.JID, participantChanges []types.JID, action whatsmeow.ParticipantChange) ([]types.GroupParticipant, error)
+}
+
+// Handler serves the REST API on top of the message store
+type Handler struct {
+	store   database.DataStore
+	client  *whatsmeow.Client
+	wa      MessageSender
+	groups  GroupManager
+	cfg     *config.Config
+	janitor *janitor.Janitor
+	send    SendFunc
+	queue   *jobs.Queue
+	// fetchMedia downloads encrypted media; nil means media.Fetch
+	fetchMedia func(ctx context.Context, url string, maxBytes int64) ([]byte, error)
+}
+
+// NewHandler creates a new API handler. The client is used for lookups the
+// store cannot answer on its own; the janitor's stats are reported on the
+// admin endpoints; send delivers outgoing messages and queue those sent in
+// the background.
+func NewHandler(store database.DataStore, client *whatsmeow.Client, cfg *config.Config, janitor *janitor.Janitor, send SendFunc, queue *jobs.Queue) *Handler {
+	h := &Handler{store: store, client: client, cfg: cfg, janitor: janitor, send: send, queue: queue}
+	if client != nil {
+		h.wa = client
+		h.groups = client
+	}
+	return h
+}
+
 // Response represents a standard API response
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success   bool        `json:"success"`
+	Message   string      `json:"message,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 // SendMessageRequest represents the request body for sending messages
 type SendMessageRequest struct {
 	Recipient string `json:"recipient"`
 	Message   string `json:"message"`
+	MediaPath string `json:"media_path,omitempty"`
 }
 
 // SendFileRequest represents the request body for sending files
@@ -42,11 +107,15 @@ func writeJSONResponse(w http.ResponseWriter, statusCode int, response Response)
 	json.NewEncoder(w).Encode(response)
 }
 
-// writeErrorResponse writes an error response
-func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
+// writeErrorResponse logs the error and writes an error response tagged
+// with the request ID
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	requestID := RequestIDFromContext(r.Context())
+	log.Printf("[%s] %s %s: %d %s", requestID, r.Method, r.URL.Path, statusCode, message)
 	writeJSONResponse(w, statusCode, Response{
-		Success: false,
-		Error:   message,
+		Success:   false,
+		Error:     message,
+		RequestID: requestID,
 	})
 }
 
@@ -69,18 +138,41 @@ func parseJSONBody(r *http.Request, v interface{}) error {
 	decoder.DisallowUnknownFields()
 	
 	if err := decoder.Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return fmt.Errorf("request body exceeds %d bytes: %w", maxErr.Limit, err)
+		}
 		return fmt.Errorf("invalid JSON: %w", err)
 	}
 	
 	return nil
 }
 
-// validateSendMessageRequest validates a send message request
-func validateSendMessageRequest(req SendMessageRequest) error {
+// jsonBodyStatus returns the status code for a parseJSONBody error
+func jsonBodyStatus(err error) int {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// validateSendMessageRequest validates a send message request. Media must
+// lie within allowedDirs and may be at most maxBytes long.
+func validateSendMessageRequest(req SendMessageRequest, allowedDirs []string, maxBytes int64) error {
 	if err := validation.ValidateRecipient(req.Recipient); err != nil {
 		return fmt.Errorf("invalid recipient: %w", err)
 	}
 	
+	if req.MediaPath != "" {
+		if err := validateMediaPath(req.MediaPath, allowedDirs, maxBytes); err != nil {
+			return fmt.Errorf("invalid media_path: %w", err)
+		}
+		if req.Message == "" {
+			return nil
+		}
+	}
+	
 	if err := validation.ValidateMessageContent(req.Message); err != nil {
 		return fmt.Errorf("invalid message: %w", err)
 	}
@@ -88,47 +180,120 @@ func validateSendMessageRequest(req SendMessageRequest) error {
 	return nil
 }
 
-// validateSendFileRequest validates a send file request
-func validateSendFileRequest(req SendFileRequest) error {
+// validateMediaPath checks a media file named by a request: it must lie
+// within allowedDirs, not be an executable, be at most maxBytes long and
+// have contents matching its extension, so a renamed file is not sent as
+// something it is not. A non-positive maxBytes allows any size.
+func validateMediaPath(path string, allowedDirs []string, maxBytes int64) error {
+	if err := validation.ValidateFilePath(path, allowedDirs); err != nil {
+		return err
+	}
+	if err := rejectExecutable(path); err != nil {
+		return err
+	}
+	return validation.ValidateMediaFileConstraints(path, &validation.FileConstraints{MaxBytes: maxBytes})
+}
+
+// rejectExecutable returns an error if the file at path is a program or
+// script, whatever its extension
+func rejectExecutable(path string) error {
+	executable, err := validation.IsExecutable(path)
+	if err != nil {
+		return err
+	}
+	if executable {
+		return fmt.Errorf("file is an executable")
+	}
+	return nil
+}
+
+// validateSendFileRequest validates a send file request. The file must lie
+// within allowedDirs and may be at most maxBytes long.
+func validateSendFileRequest(req SendFileRequest, allowedDirs []string, maxBytes int64) error {
 	if err := validation.ValidateRecipient(req.Recipient); err != nil {
 		return fmt.Errorf("invalid recipient: %w", err)
 	}
 	
-	if err := validation.ValidateFilePath(req.FilePath); err != nil {
+	if err := validation.ValidateFilePath(req.FilePath, allowedDirs); err != nil {
 		return fmt.Errorf("invalid file path: %w", err)
 	}
 	
+	if err := validation.ValidateFileSize(req.FilePath, maxBytes); err != nil {
+		return fmt.Errorf("invalid file: %w", err)
+	}
+	
+	if err := validation.ValidateMIMEContent(req.FilePath); err != nil {
+		return fmt.Errorf("invalid file: %w", err)
+	}
+	
 	return nil
 }
 
-// parseQueryParams parses common query parameters
-func parseQueryParams(r *http.Request) (limit, offset int, err error) {
-	limitStr := r.URL.Query().Get("limit")
-	if limitStr == "" {
-		limit = 20 // default
-	} else {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil || limit < 1 || limit > 100 {
-			return 0, 0, fmt.Errorf("invalid limit parameter")
-		}
+// parseRecipientJID turns a validated recipient, either a JID or a bare
+// phone number, into a JID
+func parseRecipientJID(recipient string) (types.JID, error) {
+	if normalized, err := validation.NormalizeRecipient(recipient); err == nil {
+		recipient = normalized
+	}
+	if strings.Contains(recipient, "@") {
+		return types.ParseJID(recipient)
+	}
+	return types.NewJID(recipient, types.DefaultUserServer), nil
+}
+
+// parseQueryParams parses common query parameters. The cursor parameter is
+// an opaque token taken from a previous page's next_cursor; offset is only
+// kept for endpoints that predate cursors.
+func parseQueryParams(r *http.Request) (limit, offset int, cursor database.Cursor, err error) {
+	limit, err = ParseIntParam(r, "limit", 20, 1, 100)
+	if err != nil {
+		return 0, 0, database.Cursor{}, err
+	}
+	offset, err = ParseIntParam(r, "offset", 0, 0, math.MaxInt)
+	if err != nil {
+		return 0, 0, database.Cursor{}, err
 	}
 	
-	offsetStr := r.URL.Query().Get("offset")
-	if offsetStr == "" {
-		offset = 0 // default
-	} else {
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			return 0, 0, fmt.Errorf("invalid offset parameter")
-		}
+	cursor, err = database.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		return 0, 0, database.Cursor{}, fmt.Errorf("invalid cursor parameter")
+	}
+	if !cursor.IsZero() && offset > 0 {
+		return 0, 0, database.Cursor{}, fmt.Errorf("cursor and offset cannot be combined")
 	}
 	
-	return limit, offset, nil
+	return limit, offset, cursor, nil
+}
+
+// ParseIntParam reads the integer query parameter name, returning
+// defaultVal when it is absent and an error when it is not an integer
+// between min and max inclusive
+func ParseIntParam(r *http.Request, name string, defaultVal, min, max int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return defaultVal, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("invalid %s parameter", name)
+	}
+	return n, nil
+}
+
+// chatJIDParam reads and validates the required chat_jid query parameter
+func chatJIDParam(r *http.Request) (string, error) {
+	chatJID := r.URL.Query().Get("chat_jid")
+	if err := validation.ValidateJID(chatJID); err != nil {
+		return "", fmt.Errorf("invalid chat_jid: %w", err)
+	}
+	return chatJID, nil
 }
 
-// enableCORS sets CORS headers
-func enableCORS(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-}
\ No newline at end of file
+// jidPathValue reads and validates the {jid} path value
+func jidPathValue(r *http.Request) (string, error) {
+	jid := r.PathValue("jid")
+	if err := validation.ValidateJID(jid); err != nil {
+		return "", err
+	}
+	return jid, nil
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"whatsapp-client/pkg/database"
+)
+
+// ConnectionState reports whether the bridge is connected to WhatsApp;
+// *whatsmeow.Client implements it
+type ConnectionState interface {
+	IsConnected() bool
+}
+
+// HealthResponse is the body of a health check
+type HealthResponse struct {
+	Status        string `json:"status"`
+	DB            string `json:"db"`
+	WhatsApp      string `json:"whatsapp"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// HealthHandler serves a health check for liveness and readiness probes.
+// It responds 200 with status "ok" when the database answers and WhatsApp
+// is connected, and 503 with status "degraded" otherwise. Uptime is counted
+// from when the handler is created. A nil conn counts as disconnected.
+func HealthHandler(store database.DataStore, conn ConnectionState) http.Handler {
+	started := time.Now()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := HealthResponse{
+			Status:        "ok",
+			DB:            "ok",
+			WhatsApp:      "connected",
+			UptimeSeconds: int64(time.Since(started).Seconds()),
+		}
+		if err := store.Ping(); err != nil {
+			resp.Status, resp.DB = "degraded", "unreachable"
+		}
+		if conn == nil || !conn.IsConnected() {
+			resp.Status, resp.WhatsApp = "degraded", "disconnected"
+		}
+
+		status := http.StatusOK
+		if resp.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	})
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeConnection reports a fixed WhatsApp connection state
+type fakeConnection bool
+
+func (c fakeConnection) IsConnected() bool {
+	return bool(c)
+}
+
+func TestHealthHandler(t *testing.T) {
+	store := newTestStore(t)
+	check := func(conn ConnectionState) (int, HealthResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		HealthHandler(store, conn).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var resp HealthResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return rec.Code, resp
+	}
+
+	if code, resp := check(fakeConnection(true)); code != http.StatusOK || resp != (HealthResponse{Status: "ok", DB: "ok", WhatsApp: "connected"}) {
+		t.Errorf("Expected a healthy 200, got %d %+v", code, resp)
+	}
+	if code, resp := check(fakeConnection(false)); code != http.StatusServiceUnavailable || resp.Status != "degraded" || resp.WhatsApp != "disconnected" || resp.DB != "ok" {
+		t.Errorf("Expected a degraded 503 when disconnected, got %d %+v", code, resp)
+	}
+	if code, resp := check(nil); code != http.StatusServiceUnavailable || resp.WhatsApp != "disconnected" {
+		t.Errorf("Expected a degraded 503 without a client, got %d %+v", code, resp)
+	}
+
+	store.Close()
+	if code, resp := check(fakeConnection(true)); code != http.StatusServiceUnavailable || resp.Status != "degraded" || resp.DB != "unreachable" {
+		t.Errorf("Expected a degraded 503 with the database closed, got %d %+v", code, resp)
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"whatsapp-client/pkg/database"
+)
+
+// EventNewMessage is the event type pushed for each newly stored message
+const EventNewMessage = "new_message"
+
+const (
+	// eventReplaySize is how many recent events are kept for clients that
+	// reconnect with Last-Event-ID
+	eventReplaySize = 100
+	// subscriberBuffer is how many events may queue for a subscriber before
+	// it is considered too slow and disconnected
+	subscriberBuffer = 64
+	// streamWriteWait is how long a single write to a subscriber may take
+	streamWriteWait = 10 * time.Second
+)
+
+// Event is a message pushed to WebSocket and SSE subscribers. IDs increase
+// by one per event for the lifetime of the process.
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// EventHub fans events out to WebSocket and SSE subscribers and keeps the
+// most recent ones for replay
+type EventHub struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	lastID      uint64
+	recent      [eventReplaySize]encodedEvent
+	recentStart int
+	recentLen   int
+	closed      bool
+}
+
+// NewEventHub creates an empty hub
+func NewEventHub() *EventHub {
+	return &EventHub{subscribers: make(map[*subscriber]struct{})}
+}
+
+// encodedEvent is an event serialized once for all subscribers
+type encodedEvent struct {
+	id      uint64
+	chatJID string
+	data    []byte
+}
+
+// subscriber receives the events of one chat, or of every chat when
+// chatJID is empty. Its channel is closed when it is dropped.
+type subscriber struct {
+	chatJID string
+	events  chan encodedEvent
+}
+
+func (s *subscriber) matches(ev encodedEvent) bool {
+	return s.chatJID == "" || s.chatJID == ev.chatJID
+}
+
+// PublishMessage pushes a new_message event to every subscriber whose filter
+// matches the message's chat. Subscribers that cannot keep up are dropped.
+func (h *EventHub) PublishMessage(msg *database.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	data, err := json.Marshal(Event{ID: h.lastID, Type: EventNewMessage, Data: msg})
+	if err != nil {
+		log.Printf("Failed to encode message %s for subscribers: %v", msg.ID, err)
+		return
+	}
+	ev := encodedEvent{id: h.lastID, chatJID: msg.ChatJID, data: data}
+
+	h.recent[(h.recentStart+h.recentLen)%eventReplaySize] = ev
+	if h.recentLen < eventReplaySize {
+		h.recentLen++
+	} else {
+		h.recentStart = (h.recentStart + 1) % eventReplaySize
+	}
+
+	for s := range h.subscribers {
+		if !s.matches(ev) {
+			continue
+		}
+		select {
+		case s.events <- ev:
+		default:
+			h.removeLocked(s)
+		}
+	}
+}
+
+// Close drops every subscriber and rejects new ones
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subscribers {
+		h.removeLocked(s)
+	}
+}
+
+// subscribe registers a subscriber for chatJID. When afterID is non-zero it
+// also returns the buffered events newer than afterID, so nothing published
+// in between is missed. It reports false once the hub is closed.
+func (h *EventHub) subscribe(chatJID string, afterID uint64) (*subscriber, []encodedEvent, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, nil, false
+	}
+
+	s := &subscriber{chatJID: chatJID, events: make(chan encodedEvent, subscriberBuffer)}
+	h.subscribers[s] = struct{}{}
+
+	var replay []encodedEvent
+	if afterID > 0 {
+		for i := 0; i < h.recentLen; i++ {
+			ev := h.recent[(h.recentStart+i)%eventReplaySize]
+			if ev.id > afterID && s.matches(ev) {
+				replay = append(replay, ev)
+			}
+		}
+	}
+	return s, replay, true
+}
+
+// unsubscribe drops a subscriber if it is still registered
+func (h *EventHub) unsubscribe(s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(s)
+}
+
+// removeLocked drops a subscriber and closes its channel. h.mu must be held.
+func (h *EventHub) removeLocked(s *subscriber) {
+	if _, ok := h.subscribers[s]; !ok {
+		return
+	}
+	delete(h.subscribers, s)
+	close(s.events)
+}
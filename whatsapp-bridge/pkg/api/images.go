@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// SendImageRequest represents the request body for sending an image, given
+// either inline as base64 or as a path on the bridge host
+type SendImageRequest struct {
+	Recipient   string `json:"recipient"`
+	ImageBase64 string `json:"image_base64,omitempty"`
+	ImagePath   string `json:"image_path,omitempty"`
+	Caption     string `json:"caption,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+}
+
+// imageExtensions maps the image MIME types that can be sent to the file
+// extension the sender uses to recognize them
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// ValidateImageRequest checks that exactly one image source is given, that
+// it is an image of a supported type and that it is at most maxBytes. An
+// image_path must lie within allowedDirs. It returns the decoded image when
+// the request carries one inline.
+func ValidateImageRequest(req SendImageRequest, allowedDirs []string, maxBytes int64) ([]byte, error) {
+	if err := validation.ValidateRecipient(req.Recipient); err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+	if req.Caption != "" {
+		if err := validation.ValidateMessageContent(req.Caption); err != nil {
+			return nil, fmt.Errorf("invalid caption: %w", err)
+		}
+	}
+
+	var mediaType string
+	if req.MimeType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(req.MimeType)
+		if err != nil || !strings.HasPrefix(mediaType, "image/") {
+			return nil, fmt.Errorf("mime_type must be an image type, got %q", req.MimeType)
+		}
+	}
+
+	switch {
+	case req.ImageBase64 != "" && req.ImagePath != "":
+		return nil, fmt.Errorf("only one of image_base64 and image_path may be set")
+
+	case req.ImageBase64 != "":
+		if _, ok := imageExtensions[mediaType]; !ok {
+			return nil, fmt.Errorf("mime_type must be one of image/jpeg, image/png, image/gif or image/webp")
+		}
+		if int64(base64.StdEncoding.DecodedLen(len(req.ImageBase64))) > maxBytes+2 {
+			return nil, fmt.Errorf("image exceeds %d bytes", maxBytes)
+		}
+		data, err := base64.StdEncoding.DecodeString(req.ImageBase64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image_base64: %w", err)
+		}
+		if int64(len(data)) > maxBytes {
+			return nil, fmt.Errorf("image exceeds %d bytes", maxBytes)
+		}
+		return data, nil
+
+	case req.ImagePath != "":
+		if err := validation.ValidateFilePath(req.ImagePath, allowedDirs); err != nil {
+			return nil, fmt.Errorf("invalid image_path: %w", err)
+		}
+		if err := rejectExecutable(req.ImagePath); err != nil {
+			return nil, fmt.Errorf("invalid image_path: %w", err)
+		}
+		ext := strings.ToLower(filepath.Ext(req.ImagePath))
+		if ext == ".jpeg" {
+			ext = ".jpg"
+		}
+		if !isImageExtension(ext) {
+			return nil, fmt.Errorf("image_path must be a JPEG, PNG, GIF or WebP file")
+		}
+		if err := validation.ValidateMediaType(req.ImagePath, req.MimeType); err != nil {
+			return nil, err
+		}
+		if err := validation.ValidateMIMEContent(req.ImagePath); err != nil {
+			return nil, fmt.Errorf("invalid image_path: %w", err)
+		}
+		info, err := os.Stat(req.ImagePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image_path: %w", err)
+		}
+		if info.Size() > maxBytes {
+			return nil, fmt.Errorf("image exceeds %d bytes", maxBytes)
+		}
+		return nil, nil
+
+	default:
+		return nil, fmt.Errorf("one of image_base64 or image_path is required")
+	}
+}
+
+// isImageExtension reports whether ext is one of imageExtensions' values
+func isImageExtension(ext string) bool {
+	for _, e := range imageExtensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// handleSendImage handles POST /messages/image
+func (h *Handler) handleSendImage(w http.ResponseWriter, r *http.Request) {
+	var req SendImageRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	data, err := ValidateImageRequest(req, h.cfg.AllowedUploadDirs, h.cfg.MaxImageBytes)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	path := req.ImagePath
+	if data != nil {
+		mediaType, _, _ := mime.ParseMediaType(req.MimeType)
+		path, err = writeTempFile("whatsapp-image-*"+imageExtensions[mediaType], data)
+		if err != nil {
+			writeErrorResponse(w, r, http.StatusInternalServerError, "failed to store image")
+			return
+		}
+		defer os.Remove(path)
+	}
+
+	if err := h.send(req.Recipient, req.Caption, path, SendOptions{}); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to send image: %v", err))
+		return
+	}
+
+	writeSuccessResponse(w, fmt.Sprintf("Image sent to %s", req.Recipient), nil)
+}
+
+// writeTempFile writes inline media to a temporary file named after
+// pattern, whose extension tells the sender its type
+func writeTempFile(pattern string, data []byte) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
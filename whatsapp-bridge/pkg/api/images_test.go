@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"whatsapp-client/pkg/config"
+)
+
+func TestValidateImageRequest(t *testing.T) {
+	dir := t.TempDir()
+	pngPath := filepath.Join(dir, "photo.png")
+	if err := os.WriteFile(pngPath, []byte("\x89PNG\r\n\x1a\n"), 0600); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	if err := os.WriteFile(pdfPath, []byte("pdf data"), 0600); err != nil {
+		t.Fatalf("Failed to write document: %v", err)
+	}
+
+	outsidePath := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(outsidePath, []byte("\x89PNG\r\n\x1a\n"), 0600); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	image := base64.StdEncoding.EncodeToString([]byte("0123456789"))
+	const recipient = "1234567890"
+
+	tests := []struct {
+		name    string
+		req     SendImageRequest
+		max     int64
+		wantErr bool
+	}{
+		{"inline image", SendImageRequest{Recipient: recipient, ImageBase64: image, MimeType: "image/png"}, 10, false},
+		{"image path", SendImageRequest{Recipient: recipient, ImagePath: pngPath, Caption: "Look"}, 10, false},
+		{"no source", SendImageRequest{Recipient: recipient, MimeType: "image/png"}, 10, true},
+		{"both sources", SendImageRequest{Recipient: recipient, ImageBase64: image, ImagePath: pngPath, MimeType: "image/png"}, 10, true},
+		{"inline without mime type", SendImageRequest{Recipient: recipient, ImageBase64: image}, 10, true},
+		{"non-image mime type", SendImageRequest{Recipient: recipient, ImageBase64: image, MimeType: "application/pdf"}, 10, true},
+		{"unsupported image type", SendImageRequest{Recipient: recipient, ImageBase64: image, MimeType: "image/tiff"}, 10, true},
+		{"invalid base64", SendImageRequest{Recipient: recipient, ImageBase64: "not base64!", MimeType: "image/png"}, 10, true},
+		{"inline too large", SendImageRequest{Recipient: recipient, ImageBase64: image, MimeType: "image/png"}, 9, true},
+		{"path too large", SendImageRequest{Recipient: recipient, ImagePath: pngPath}, 4, true},
+		{"path not an image", SendImageRequest{Recipient: recipient, ImagePath: pdfPath}, 10, true},
+		{"path mime mismatch", SendImageRequest{Recipient: recipient, ImagePath: pngPath, MimeType: "image/jpeg"}, 10, true},
+		{"invalid recipient", SendImageRequest{Recipient: "nobody", ImageBase64: image, MimeType: "image/png"}, 10, true},
+		{"path outside allowed dirs", SendImageRequest{Recipient: recipient, ImagePath: outsidePath}, 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateImageRequest(tt.req, []string{dir}, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateImageRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleSendImageInline(t *testing.T) {
+	var sentPath, sentCaption string
+	var sentData []byte
+	h := &Handler{
+		cfg: &config.Config{MaxImageBytes: 1 << 20},
+		send: func(recipient, message, mediaPath string, opts SendOptions) error {
+			sentPath, sentCaption = mediaPath, message
+			sentData, _ = os.ReadFile(mediaPath)
+			return nil
+		},
+	}
+
+	body := `{"recipient":"1234567890","image_base64":"` + base64.StdEncoding.EncodeToString([]byte("jpeg bytes")) +
+		`","mime_type":"image/jpeg","caption":"Hello"}`
+	rec := httptest.NewRecorder()
+	h.handleSendImage(rec, httptest.NewRequest(http.MethodPost, "/messages/image", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if filepath.Ext(sentPath) != ".jpg" || sentCaption != "Hello" || !bytes.Equal(sentData, []byte("jpeg bytes")) {
+		t.Errorf("Unexpected send of %q with caption %q and data %q", sentPath, sentCaption, sentData)
+	}
+	if _, err := os.Stat(sentPath); !os.IsNotExist(err) {
+		t.Errorf("Expected temp file %s to be removed, got %v", sentPath, err)
+	}
+}
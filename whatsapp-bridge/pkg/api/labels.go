@@ -0,0 +1,150 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"whatsapp-client/pkg/database"
+)
+
+// LabelRequest represents the request body for creating or updating a label
+type LabelRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// handleListLabels handles GET /labels
+func (h *Handler) handleListLabels(w http.ResponseWriter, r *http.Request) {
+	labels, err := h.store.GetLabels()
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get labels")
+		return
+	}
+
+	writeSuccessResponse(w, "", labels)
+}
+
+// handleCreateLabel handles POST /labels
+func (h *Handler) handleCreateLabel(w http.ResponseWriter, r *http.Request) {
+	var req LabelRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if req.Name == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	label := &database.Label{Name: req.Name, Color: req.Color}
+	err := h.store.CreateLabel(label)
+	if errors.Is(err, database.ErrLabelExists) {
+		writeErrorResponse(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to create label")
+		return
+	}
+
+	writeSuccessResponse(w, "Label created", label)
+}
+
+// handleGetLabel handles GET /labels/{id}
+func (h *Handler) handleGetLabel(w http.ResponseWriter, r *http.Request) {
+	label, err := h.store.GetLabel(r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "label not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get label")
+		return
+	}
+
+	writeSuccessResponse(w, "", label)
+}
+
+// handleUpdateLabel handles PUT /labels/{id}
+func (h *Handler) handleUpdateLabel(w http.ResponseWriter, r *http.Request) {
+	var req LabelRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if req.Name == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	label := &database.Label{ID: r.PathValue("id"), Name: req.Name, Color: req.Color}
+	err := h.store.UpdateLabel(label)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeErrorResponse(w, r, http.StatusNotFound, "label not found")
+	case errors.Is(err, database.ErrLabelExists):
+		writeErrorResponse(w, r, http.StatusConflict, err.Error())
+	case err != nil:
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to update label")
+	default:
+		writeSuccessResponse(w, "Label updated", label)
+	}
+}
+
+// handleDeleteLabel handles DELETE /labels/{id}
+func (h *Handler) handleDeleteLabel(w http.ResponseWriter, r *http.Request) {
+	err := h.store.DeleteLabel(r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "label not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to delete label")
+		return
+	}
+
+	writeSuccessResponse(w, "Label deleted", nil)
+}
+
+// handleAddChatLabel handles POST /chats/{jid}/labels/{label_id}
+func (h *Handler) handleAddChatLabel(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.store.AddChatLabel(jid, r.PathValue("label_id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat or label not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to label chat")
+		return
+	}
+
+	writeSuccessResponse(w, "Label added to chat", nil)
+}
+
+// handleRemoveChatLabel handles DELETE /chats/{jid}/labels/{label_id}
+func (h *Handler) handleRemoveChatLabel(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.store.RemoveChatLabel(jid, r.PathValue("label_id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat does not have this label")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to remove label from chat")
+		return
+	}
+
+	writeSuccessResponse(w, "Label removed from chat", nil)
+}
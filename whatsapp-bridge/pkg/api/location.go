@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// SendLocationRequest represents the request body for sharing a location
+type SendLocationRequest struct {
+	Recipient string  `json:"recipient"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+}
+
+// ValidateLocationRequest validates a send location request
+func ValidateLocationRequest(req SendLocationRequest) error {
+	if err := validation.ValidateRecipient(req.Recipient); err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	if err := validation.ValidateCoordinates(req.Latitude, req.Longitude); err != nil {
+		return err
+	}
+	if req.Name != "" {
+		if err := validation.ValidateMessageContent(req.Name); err != nil {
+			return fmt.Errorf("invalid name: %w", err)
+		}
+	}
+	if req.Address != "" {
+		if err := validation.ValidateMessageContent(req.Address); err != nil {
+			return fmt.Errorf("invalid address: %w", err)
+		}
+	}
+	return nil
+}
+
+// locationMessage builds the WhatsApp message for a location request
+func locationMessage(req SendLocationRequest) *waE2E.Message {
+	loc := &waE2E.LocationMessage{
+		DegreesLatitude:  proto.Float64(req.Latitude),
+		DegreesLongitude: proto.Float64(req.Longitude),
+	}
+	if req.Name != "" {
+		loc.Name = proto.String(req.Name)
+	}
+	if req.Address != "" {
+		loc.Address = proto.String(req.Address)
+	}
+	return &waE2E.Message{LocationMessage: loc}
+}
+
+// handleSendLocation handles POST /messages/location
+func (h *Handler) handleSendLocation(w http.ResponseWriter, r *http.Request) {
+	var req SendLocationRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if err := ValidateLocationRequest(req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	to, err := parseRecipientJID(req.Recipient)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid recipient: %v", err))
+		return
+	}
+
+	if h.wa == nil {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, "not connected to WhatsApp")
+		return
+	}
+	resp, err := h.wa.SendMessage(r.Context(), to, locationMessage(req))
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to send location: %v", err))
+		return
+	}
+
+	writeSuccessResponse(w, fmt.Sprintf("Location sent to %s", req.Recipient), map[string]string{"message_id": resp.ID})
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+
+	"whatsapp-client/pkg/database"
+)
+
+// mockSender records the messages it is asked to send
+type mockSender struct {
+	to  types.JID
+	msg *waE2E.Message
+	err error
+}
+
+func (m *mockSender) SendMessage(ctx context.Context, to types.JID, message *waE2E.Message, extra ...whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+	m.to, m.msg = to, message
+	if m.err != nil {
+		return whatsmeow.SendResponse{}, m.err
+	}
+	return whatsmeow.SendResponse{ID: "3EB0LOCATION"}, nil
+}
+
+func TestValidateLocationRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SendLocationRequest
+		wantErr bool
+	}{
+		{"coordinates only", SendLocationRequest{Recipient: "1234567890", Latitude: 52.52, Longitude: 13.405}, false},
+		{"with name and address", SendLocationRequest{Recipient: "1234567890@s.whatsapp.net", Latitude: -33.86, Longitude: 151.21, Name: "Opera House", Address: "Bennelong Point"}, false},
+		// An unset name or address is not empty content to reject
+		{"address without name", SendLocationRequest{Recipient: "1234567890", Latitude: -33.86, Longitude: 151.21, Address: "Bennelong Point"}, false},
+		{"poles and antimeridian", SendLocationRequest{Recipient: "1234567890", Latitude: 90, Longitude: -180}, false},
+		{"latitude out of range", SendLocationRequest{Recipient: "1234567890", Latitude: 90.1, Longitude: 0}, true},
+		{"longitude out of range", SendLocationRequest{Recipient: "1234567890", Latitude: 0, Longitude: 180.5}, true},
+		{"invalid recipient", SendLocationRequest{Recipient: "nobody", Latitude: 1, Longitude: 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLocationRequest(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLocationRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendLocationRoute(t *testing.T) {
+	sender := &mockSender{}
+	h := &Handler{wa: sender}
+
+	body := `{"recipient":"1234567890","latitude":48.8584,"longitude":2.2945,"name":"Eiffel Tower","address":"Champ de Mars, Paris"}`
+	rec := httptest.NewRecorder()
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/location", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "3EB0LOCATION") {
+		t.Errorf("Expected the message ID in the response, got %s", rec.Body.String())
+	}
+	if want := types.NewJID("1234567890", types.DefaultUserServer); sender.to != want {
+		t.Errorf("Expected message to %s, got %s", want, sender.to)
+	}
+
+	loc := sender.msg.GetLocationMessage()
+	if loc == nil {
+		t.Fatalf("Expected a location message, got %v", sender.msg)
+	}
+	if loc.GetDegreesLatitude() != 48.8584 || loc.GetDegreesLongitude() != 2.2945 {
+		t.Errorf("Expected coordinates 48.8584,2.2945, got %v,%v", loc.GetDegreesLatitude(), loc.GetDegreesLongitude())
+	}
+	if loc.GetName() != "Eiffel Tower" || loc.GetAddress() != "Champ de Mars, Paris" {
+		t.Errorf("Unexpected name %q or address %q", loc.GetName(), loc.GetAddress())
+	}
+}
+
+func TestSendLocationRouteErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sender MessageSender
+		body   string
+		status int
+	}{
+		{"out of range", &mockSender{}, `{"recipient":"1234567890","latitude":91,"longitude":0}`, http.StatusBadRequest},
+		{"not connected", nil, `{"recipient":"1234567890","latitude":1,"longitude":1}`, http.StatusServiceUnavailable},
+		{"send fails", &mockSender{err: errors.New("boom")}, `{"recipient":"1234567890","latitude":1,"longitude":1}`, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{wa: tt.sender}
+			rec := httptest.NewRecorder()
+			NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/location", strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestNearbyLocationsRoute(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.StoreChat(&database.Chat{JID: "123456789@s.whatsapp.net", LastMessageTime: time.Now()}); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	lat, lon := 52.5219, 13.4132
+	msg := &database.Message{ID: "alexanderplatz", ChatJID: "123456789@s.whatsapp.net", Sender: "123456789", Timestamp: time.Now(), LocationLat: &lat, LocationLon: &lon}
+	if err := store.StoreMessage(msg); err != nil {
+		t.Fatalf("Failed to store message: %v", err)
+	}
+
+	h := &Handler{store: store}
+	nearby := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/messages/locations/nearby"+query, nil))
+		return rec
+	}
+
+	rec := nearby("?lat=52.5186&lon=13.4081&radius_km=5&since=2020-01-01T00:00:00Z")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data LocationsResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Data.Locations) != 1 || resp.Data.Locations[0].MessageID != "alexanderplatz" {
+		t.Errorf("Expected alexanderplatz, got %+v", resp.Data.Locations)
+	}
+
+	for _, query := range []string{"?lat=52.5&lon=13.4&since=yesterday", "?lat=91&lon=0", "?lat=52.5&lon=13.4&radius_km=0"} {
+		if rec := nearby(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"whatsapp-client/pkg/metrics"
+)
+
+// LoggingMiddleware logs one structured record per request with its method,
+// path, status, latency and response size. When m is not nil the request is
+// also counted in the HTTP metrics, labelled by the route RouteRecorder
+// found for it.
+func LoggingMiddleware(logger *slog.Logger, m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			route := new(string)
+			if m != nil {
+				r = r.WithContext(context.WithValue(r.Context(), routeKey{}, route))
+			}
+
+			next.ServeHTTP(rec, r)
+
+			elapsed := time.Since(start)
+			logger.Info("request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.status,
+				"latency_ms", float64(elapsed.Microseconds())/1000,
+				"request_id", RequestIDFromContext(r.Context()),
+				"remote_addr", r.RemoteAddr,
+				"bytes_written", rec.bytes,
+			)
+			if *route == "" {
+				*route = "unmatched"
+			}
+			m.ObserveRequest(r.Method, *route, rec.status, elapsed)
+		})
+	}
+}
+
+// routeKey is the context key of the route RouteRecorder records
+type routeKey struct{}
+
+// RouteRecorder records the pattern mux routes each request to, without its
+// method, for LoggingMiddleware to label metrics with. Raw paths would give
+// every JID its own label. With nested muxes the innermost one decides, so a
+// request it cannot route is left unmatched.
+func RouteRecorder(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+			_, pattern := mux.Handler(r)
+			if _, path, found := strings.Cut(pattern, " "); found {
+				pattern = path
+			}
+			*route = pattern
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder captures the status code and body size written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Flush sends buffered output to the client, for streaming responses
+func (r *statusRecorder) Flush() {
+	http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack hands the connection over for protocol upgrades such as WebSocket
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.status = http.StatusSwitchingProtocols
+		r.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"whatsapp-client/pkg/metrics"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeErrorResponse(w, r, http.StatusNotFound, "chat not found")
+	})
+	handler := RequestIDMiddleware()(LoggingMiddleware(logger, nil)(next))
+
+	req := httptest.NewRequest(http.MethodGet, "/chats/123", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON log record, got %q: %v", logs.String(), err)
+	}
+
+	if record["method"] != http.MethodGet || record["path"] != "/chats/123" {
+		t.Errorf("Unexpected method/path in %v", record)
+	}
+	if record["status"] != float64(http.StatusNotFound) {
+		t.Errorf("Expected status 404, got %v", record["status"])
+	}
+	if record["bytes_written"] != float64(rec.Body.Len()) {
+		t.Errorf("Expected bytes_written %d, got %v", rec.Body.Len(), record["bytes_written"])
+	}
+	if record["request_id"] != rec.Header().Get(RequestIDHeader) {
+		t.Errorf("Expected request_id %q, got %v", rec.Header().Get(RequestIDHeader), record["request_id"])
+	}
+	if record["remote_addr"] != req.RemoteAddr {
+		t.Errorf("Expected remote_addr %q, got %v", req.RemoteAddr, record["remote_addr"])
+	}
+	if _, ok := record["latency_ms"].(float64); !ok {
+		t.Errorf("Expected numeric latency_ms, got %v", record["latency_ms"])
+	}
+}
+
+func TestLoggingMiddlewareMetrics(t *testing.T) {
+	m := metrics.New(nil)
+	h := &Handler{store: newTestStore(t)}
+	handler := LoggingMiddleware(slog.New(slog.NewJSONHandler(io.Discard, nil)), m)(NewRouter(h.Routes()))
+
+	for _, jid := range []string{"15551111111@s.whatsapp.net", "15552222222@s.whatsapp.net"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/chats/"+jid+"/stats", nil))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/nowhere", nil))
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`whatsapp_http_requests_total{method="GET",path="/chats/{jid}/stats",status="404"} 2`,
+		`whatsapp_http_requests_total{method="GET",path="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in the metrics output:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"whatsapp-client/pkg/media"
+	"whatsapp-client/pkg/validation"
+)
+
+// DownloadMediaResponse is where a downloaded attachment was saved
+type DownloadMediaResponse struct {
+	Path string `json:"path"`
+}
+
+// handleDownloadMedia handles POST /media/download. The attachment of a
+// stored message is fetched from WhatsApp, decrypted and saved at
+// Config.MediaPathFor, replacing any earlier download. The path is recorded
+// in the message's media metadata.
+func (h *Handler) handleDownloadMedia(w http.ResponseWriter, r *http.Request) {
+	var req DownloadMediaRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if req.MessageID == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "message_id is required")
+		return
+	}
+	if err := validation.ValidateJID(req.ChatJID); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid chat_jid: %v", err))
+		return
+	}
+
+	msg, err := h.store.GetMessage(req.MessageID, req.ChatJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "message not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get message")
+		return
+	}
+	if msg.URL == "" || len(msg.MediaKey) == 0 || len(msg.FileSHA256) == 0 || len(msg.FileEncSHA256) == 0 {
+		writeErrorResponse(w, r, http.StatusNotFound, "message has no downloadable media")
+		return
+	}
+
+	fetch := h.fetchMedia
+	if fetch == nil {
+		fetch = media.Fetch
+	}
+	encData, err := fetch(r.Context(), msg.URL, media.MaxEncryptedSize(msg.FileLength))
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadGateway, fmt.Sprintf("failed to fetch media: %v", err))
+		return
+	}
+	data, err := media.DecryptMedia(encData, msg.MediaKey, msg.FileSHA256, msg.FileEncSHA256)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadGateway, fmt.Sprintf("failed to decrypt media: %v", err))
+		return
+	}
+
+	path, err := filepath.Abs(h.cfg.MediaPathFor(msg.ID, msg.MediaType) + mediaExtension(msg.Filename))
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to resolve media path")
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to create media directory")
+		return
+	}
+	if err := os.WriteFile(path, data, 0640); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to save media")
+		return
+	}
+	if err := h.store.UpdateLocalPath(msg.ID, msg.ChatJID, path); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to record media path")
+		return
+	}
+
+	writeSuccessResponse(w, fmt.Sprintf("Downloaded %s media", msg.MediaType), DownloadMediaResponse{Path: path})
+}
+
+// handleGetMediaMetadata handles GET /messages/{id}/media_metadata?chat_jid=...
+func (h *Handler) handleGetMediaMetadata(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := chatJIDParam(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	md, err := h.store.GetMediaMetadata(r.PathValue("id"), chatJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "media not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get media metadata")
+		return
+	}
+
+	writeSuccessResponse(w, "", md)
+}
+
+// mediaExtension is the extension of an attachment's file name, kept on
+// the saved file so it opens with the right application. The name comes
+// from the sender, so only its last element is used.
+func mediaExtension(filename string) string {
+	return filepath.Ext(filepath.Base(filename))
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"whatsapp-client/pkg/config"
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/media"
+)
+
+func TestDownloadMediaRoute(t *testing.T) {
+	plaintext := []byte("not really a jpeg")
+	key, _ := media.NewMediaKey()
+	enc, sum, encSum, err := media.EncryptMedia(plaintext, key, media.ImageKeys)
+	if err != nil {
+		t.Fatalf("Failed to encrypt media: %v", err)
+	}
+	const mediaURL = "https://mmg.whatsapp.net/d/f/photo.enc"
+	// fetchMedia stands in for WhatsApp's media server
+	fetchMedia := func(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+		if url != mediaURL {
+			return nil, fmt.Errorf("unexpected URL %s", url)
+		}
+		if int64(len(enc)) > maxBytes {
+			return nil, media.ErrMediaTooLarge
+		}
+		return enc, nil
+	}
+	internalRequested := false
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalRequested = true
+		w.Write(enc)
+	}))
+	defer internal.Close()
+
+	store := newTestStore(t)
+	const chatJID = "15551234567@s.whatsapp.net"
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.StoreChat(&database.Chat{JID: chatJID, Name: "Alice", LastMessageTime: base}); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	for _, m := range []*database.Message{
+		{ID: "photo", ChatJID: chatJID, Sender: "15551234567", MediaType: "image", Filename: "../../photo.jpg", Timestamp: base,
+			URL: mediaURL, MediaKey: key, FileSHA256: sum, FileEncSHA256: encSum, FileLength: uint64(len(plaintext))},
+		{ID: "corrupt", ChatJID: chatJID, Sender: "15551234567", MediaType: "image", Timestamp: base,
+			URL: mediaURL, MediaKey: key, FileSHA256: encSum, FileEncSHA256: encSum, FileLength: uint64(len(plaintext))},
+		{ID: "oversized", ChatJID: chatJID, Sender: "15551234567", MediaType: "image", Timestamp: base,
+			URL: mediaURL, MediaKey: key, FileSHA256: sum, FileEncSHA256: encSum, FileLength: 1},
+		{ID: "internal", ChatJID: chatJID, Sender: "15551234567", MediaType: "image", Timestamp: base,
+			URL: internal.URL + "/photo.enc", MediaKey: key, FileSHA256: sum, FileEncSHA256: encSum, FileLength: uint64(len(plaintext))},
+		{ID: "text", ChatJID: chatJID, Sender: "15551234567", Content: "Hi", Timestamp: base},
+	} {
+		if err := store.StoreMessage(m); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	h := &Handler{store: store, cfg: &config.Config{MediaStorageDir: dir}, fetchMedia: fetchMedia}
+	download := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/media/download", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := download(`{"message_id":"photo","chat_jid":"` + chatJID + `"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data DownloadMediaResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if want := filepath.Join(dir, "images", "photo.jpg"); resp.Data.Path != want {
+		t.Errorf("Expected the file at %s, got %s", want, resp.Data.Path)
+	}
+	if data, err := os.ReadFile(resp.Data.Path); err != nil || string(data) != string(plaintext) {
+		t.Errorf("Expected the decrypted media on disk, got %q (%v)", data, err)
+	}
+
+	rec = httptest.NewRecorder()
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/messages/photo/media_metadata?chat_jid="+chatJID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var mdResp struct {
+		Data database.MediaMetadata `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&mdResp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if mdResp.Data.LocalPath != resp.Data.Path {
+		t.Errorf("Expected the download path %s in the media metadata, got %q", resp.Data.Path, mdResp.Data.LocalPath)
+	}
+	rec = httptest.NewRecorder()
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/messages/text/media_metadata?chat_jid="+chatJID, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a message without media, got %d", rec.Code)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"no media", `{"message_id":"text","chat_jid":"` + chatJID + `"}`, http.StatusNotFound},
+		{"unknown message", `{"message_id":"nope","chat_jid":"` + chatJID + `"}`, http.StatusNotFound},
+		{"hash mismatch", `{"message_id":"corrupt","chat_jid":"` + chatJID + `"}`, http.StatusBadGateway},
+		{"larger than the file length", `{"message_id":"oversized","chat_jid":"` + chatJID + `"}`, http.StatusBadGateway},
+		{"missing id", `{"chat_jid":"` + chatJID + `"}`, http.StatusBadRequest},
+		{"invalid chat", `{"message_id":"photo","chat_jid":"alice"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := download(tt.body); rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Without the stand-in, the URL a sender chose is checked before any
+	// request is made
+	h.fetchMedia = nil
+	if rec := download(`{"message_id":"internal","chat_jid":"` + chatJID + `"}`); rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502 for media on another host, got %d", rec.Code)
+	}
+	if internalRequested {
+		t.Error("Expected no request to a host other than WhatsApp's media servers")
+	}
+}
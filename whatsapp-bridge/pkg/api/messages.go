@@ -0,0 +1,388 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/validation"
+)
+
+// MessagesResponse represents a page of messages
+type MessagesResponse struct {
+	Messages []*database.Message `json:"messages"`
+	Limit    int                 `json:"limit"`
+	Offset   int                 `json:"offset"`
+}
+
+// UpdateMessageStatusRequest represents the request body for updating a message's delivery status
+type UpdateMessageStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// handleListMessages handles GET /messages?chat_jid=...&cursor=...&include_deleted=...
+func (h *Handler) handleListMessages(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := chatJIDParam(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, offset, cursor, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := database.MessageOptions{Offset: offset}
+	if v := r.URL.Query().Get("include_deleted"); v != "" {
+		opts.IncludeDeleted, err = strconv.ParseBool(v)
+		if err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "invalid include_deleted parameter")
+			return
+		}
+	}
+
+	page, err := h.store.GetMessagesPage(chatJID, limit, cursor, opts)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get messages")
+		return
+	}
+	total, err := h.store.CountMessages(chatJID, opts)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to count messages")
+		return
+	}
+
+	warnOffsetDeprecated(w, r)
+	writeSuccessResponse(w, "", newPagedResponse(page, total))
+}
+
+// handleSearchMessages handles GET /messages/search?q=...&chat_jid=...&sender=...
+// &from_time=...&to_time=...&media_type=...&is_from_me=...&cursor=...
+func (h *Handler) handleSearchMessages(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseSearchFilter(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, offset, cursor, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if offset > 0 {
+		writeErrorResponse(w, r, http.StatusBadRequest, "offset is not supported, use cursor")
+		return
+	}
+
+	messages, next, err := h.store.SearchMessagesFiltered(filter, limit, cursor)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to search messages")
+		return
+	}
+	total, err := h.store.CountMessagesFiltered(filter)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to count messages")
+		return
+	}
+
+	if messages == nil {
+		messages = []*database.Message{}
+	}
+	page := &database.PageResult[*database.Message]{Items: messages, NextCursor: next.Encode()}
+	writeSuccessResponse(w, "", newPagedResponse(page, total))
+}
+
+// parseSearchFilter reads and validates the filters of a message search.
+// A sender given as a user JID is matched by its phone number, the way
+// senders are stored.
+func parseSearchFilter(r *http.Request) (database.SearchMessagesFilter, error) {
+	query := r.URL.Query()
+	filter := database.SearchMessagesFilter{
+		Query:     strings.TrimSpace(query.Get("q")),
+		ChatJID:   query.Get("chat_jid"),
+		MediaType: query.Get("media_type"),
+	}
+
+	if filter.ChatJID != "" {
+		if err := validation.ValidateJID(filter.ChatJID); err != nil {
+			return filter, fmt.Errorf("invalid chat_jid: %w", err)
+		}
+	}
+	if sender := query.Get("sender"); sender != "" {
+		if err := validation.ValidateRecipient(sender); err != nil {
+			return filter, fmt.Errorf("invalid sender: %w", err)
+		}
+		filter.Sender = strings.TrimSuffix(sender, "@"+types.DefaultUserServer)
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from_time", &filter.FromTime}, {"to_time", &filter.ToTime}} {
+		if v := query.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s parameter: must be RFC3339", p.name)
+			}
+			*p.dst = t
+		}
+	}
+
+	if v := query.Get("is_from_me"); v != "" {
+		fromMe, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid is_from_me parameter")
+		}
+		filter.IsFromMe = &fromMe
+	}
+
+	return filter, filter.Validate()
+}
+
+// handleGetEditHistory handles GET /messages/{id}/history?chat_jid=...
+func (h *Handler) handleGetEditHistory(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := chatJIDParam(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history, err := h.store.GetEditHistory(r.PathValue("id"), chatJID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get edit history")
+		return
+	}
+
+	writeSuccessResponse(w, "", history)
+}
+
+// maxNearbyRadiusKm is half the Earth's circumference; every point is closer
+const maxNearbyRadiusKm = 20038
+
+// LocationsResponse represents the response for listing shared locations
+type LocationsResponse struct {
+	Locations []*database.LocationMessage `json:"locations"`
+	Limit     int                         `json:"limit"`
+}
+
+// nearbyParams holds the parameters of a nearby query
+type nearbyParams struct {
+	lat, lon, radiusKm float64
+	limit              int
+}
+
+// parseNearbyParams parses the lat, lon, radius_km and limit query parameters
+func parseNearbyParams(r *http.Request) (nearbyParams, error) {
+	query := r.URL.Query()
+	var p nearbyParams
+	var err error
+	if p.lat, err = strconv.ParseFloat(query.Get("lat"), 64); err != nil {
+		return p, fmt.Errorf("invalid lat parameter")
+	}
+	if p.lon, err = strconv.ParseFloat(query.Get("lon"), 64); err != nil {
+		return p, fmt.Errorf("invalid lon parameter")
+	}
+	if err := validation.ValidateCoordinates(p.lat, p.lon); err != nil {
+		return p, err
+	}
+
+	p.radiusKm = 1.0
+	if v := query.Get("radius_km"); v != "" {
+		p.radiusKm, err = strconv.ParseFloat(v, 64)
+		if err != nil || !(p.radiusKm > 0 && p.radiusKm <= maxNearbyRadiusKm) {
+			return p, fmt.Errorf("invalid radius_km parameter")
+		}
+	}
+
+	p.limit, _, _, err = parseQueryParams(r)
+	return p, err
+}
+
+// handleNearbyMessages handles GET /messages/nearby?lat=...&lon=...&radius_km=...
+func (h *Handler) handleNearbyMessages(w http.ResponseWriter, r *http.Request) {
+	p, err := parseNearbyParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := h.store.GetNearbyMessages(p.lat, p.lon, p.radiusKm, p.limit)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get nearby messages")
+		return
+	}
+
+	writeSuccessResponse(w, "", MessagesResponse{
+		Messages: messages,
+		Limit:    p.limit,
+	})
+}
+
+// handleNearbyLocations handles GET /messages/locations/nearby?lat=...&lon=...&radius_km=...&since=...
+func (h *Handler) handleNearbyLocations(w http.ResponseWriter, r *http.Request) {
+	p, err := parseNearbyParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			writeErrorResponse(w, r, http.StatusBadRequest, "invalid since parameter: must be RFC3339")
+			return
+		}
+	}
+
+	locations, err := h.store.GetNearbyLocations(p.lat, p.lon, p.radiusKm, since, p.limit)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get nearby locations")
+		return
+	}
+
+	writeSuccessResponse(w, "", LocationsResponse{
+		Locations: locations,
+		Limit:     p.limit,
+	})
+}
+
+// handleUpdateMessageStatus handles PUT /messages/{id}/status?chat_jid=...
+func (h *Handler) handleUpdateMessageStatus(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := chatJIDParam(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req UpdateMessageStatusRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	status, err := database.ParseMessageStatus(req.Status)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid status: %s", req.Status))
+		return
+	}
+
+	err = h.store.UpdateMessageStatus(r.PathValue("id"), chatJID, status)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "message not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to update message status")
+		return
+	}
+
+	writeSuccessResponse(w, "Message status updated", nil)
+}
+
+// DeleteMessageRequest identifies a message to delete for everyone
+type DeleteMessageRequest struct {
+	MessageID string `json:"message_id"`
+	ChatJID   string `json:"chat_jid"`
+}
+
+// handleDeleteMessage handles DELETE /messages/{id}?chat_jid=... It
+// retracts one of our messages for every participant, then marks it
+// deleted in the store.
+func (h *Handler) handleDeleteMessage(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := chatJIDParam(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	req := DeleteMessageRequest{MessageID: r.PathValue("id"), ChatJID: chatJID}
+	log.Printf("[%s] Deleting message %s in %s for everyone", RequestIDFromContext(r.Context()), req.MessageID, req.ChatJID)
+
+	message, err := h.store.GetMessage(req.MessageID, req.ChatJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "message not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get message")
+		return
+	}
+	if !message.IsFromMe {
+		writeErrorResponse(w, r, http.StatusForbidden, "only messages sent by this account can be deleted for everyone")
+		return
+	}
+	if time.Since(message.Timestamp) > h.cfg.RevokeWindow {
+		writeErrorResponse(w, r, http.StatusForbidden, fmt.Sprintf("messages can only be deleted for everyone within %v of sending", h.cfg.RevokeWindow))
+		return
+	}
+
+	chat, err := types.ParseJID(req.ChatJID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid chat_jid: %v", err))
+		return
+	}
+	if h.wa == nil {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, "not connected to WhatsApp")
+		return
+	}
+	if _, err := h.wa.SendMessage(r.Context(), chat, revokeMessage(chat, req.MessageID)); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to delete message: %v", err))
+		return
+	}
+
+	if err := h.store.SoftDeleteMessage(req.MessageID, req.ChatJID, time.Now()); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "message deleted but failed to record it")
+		return
+	}
+
+	writeSuccessResponse(w, "Message deleted", nil)
+}
+
+// revokeMessage builds the protocol message that retracts one of our
+// messages for everyone in the chat
+func revokeMessage(chat types.JID, messageID string) *waE2E.Message {
+	return &waE2E.Message{
+		ProtocolMessage: &waE2E.ProtocolMessage{
+			Type: waE2E.ProtocolMessage_REVOKE.Enum(),
+			Key: &waCommon.MessageKey{
+				FromMe:    proto.Bool(true),
+				ID:        proto.String(messageID),
+				RemoteJID: proto.String(chat.String()),
+			},
+		},
+	}
+}
+
+// handleGetMessageThread handles GET /messages/{id}/thread?chat_jid=...
+func (h *Handler) handleGetMessageThread(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := chatJIDParam(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	thread, err := h.store.GetMessageThread(r.PathValue("id"), chatJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "message not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get message thread")
+		return
+	}
+
+	writeSuccessResponse(w, "", thread)
+}
@@ -0,0 +1,372 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"whatsapp-client/pkg/config"
+	"whatsapp-client/pkg/database"
+)
+
+func TestSearchMessagesRoute(t *testing.T) {
+	store := newTestStore(t)
+	const chatJID = "15551234567@s.whatsapp.net"
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.StoreChat(&database.Chat{JID: chatJID, Name: "Alice", LastMessageTime: base}); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	for _, m := range []*database.Message{
+		{ID: "m1", ChatJID: chatJID, Sender: "15551234567", Content: "Lunch?", Timestamp: base},
+		{ID: "m2", ChatJID: chatJID, Sender: "me", Content: "Lunch!", Timestamp: base.Add(time.Hour), IsFromMe: true},
+		{ID: "m3", ChatJID: chatJID, Sender: "15551234567", Content: "Photo", Timestamp: base.Add(2 * time.Hour), MediaType: "image"},
+	} {
+		if err := store.StoreMessage(m); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+
+	h := &Handler{store: store}
+	search := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/messages/search?"+query, nil))
+		return rec
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"q=lunch", []string{"m2", "m1"}},
+		{"sender=15551234567@s.whatsapp.net", []string{"m3", "m1"}},
+		{"q=lunch&is_from_me=false", []string{"m1"}},
+		{"media_type=image&chat_jid=" + chatJID, []string{"m3"}},
+		{"from_time=2024-03-01T12:30:00Z&to_time=2024-03-01T14:00:00Z", []string{"m3", "m2"}},
+	}
+	for _, tt := range tests {
+		rec := search(tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Data PagedResponse[*database.Message] `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.query, err)
+		}
+		var ids []string
+		for _, msg := range resp.Data.Data {
+			ids = append(ids, msg.ID)
+		}
+		if len(ids) != len(tt.want) || resp.Data.Meta.TotalCount != len(tt.want) {
+			t.Errorf("%s: got %v (total %d), want %v", tt.query, ids, resp.Data.Meta.TotalCount, tt.want)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.query, ids, tt.want)
+				break
+			}
+		}
+	}
+
+	for _, query := range []string{
+		"from_time=yesterday",
+		"from_time=2024-03-02T00:00:00Z&to_time=2024-03-01T00:00:00Z",
+		"media_type=hologram",
+		"is_from_me=maybe",
+		"sender=nobody",
+		"chat_jid=not-a-jid",
+		"q=lunch&offset=10",
+	} {
+		if rec := search(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestChatMediaRoute(t *testing.T) {
+	store := newTestStore(t)
+	const chatJID = "15551234567@s.whatsapp.net"
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.StoreChat(&database.Chat{JID: chatJID, Name: "Alice", LastMessageTime: base}); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	for _, m := range []*database.Message{
+		{ID: "text", ChatJID: chatJID, Sender: "15551234567", Content: "Hi", Timestamp: base},
+		{ID: "photo", ChatJID: chatJID, Sender: "15551234567", MediaType: "image", Filename: "a.jpg", FileLength: 2048, Timestamp: base.Add(time.Minute)},
+		{ID: "memo", ChatJID: chatJID, Sender: "15551234567", MediaType: "audio", Filename: "b.ogg", Timestamp: base.Add(2 * time.Minute)},
+	} {
+		if err := store.StoreMessage(m); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+
+	h := &Handler{store: store}
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/chats/"+chatJID+"/media"+query, nil))
+		return rec
+	}
+
+	rec := get("?media_type=image")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data MessagesResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Data.Messages) != 1 || resp.Data.Messages[0].ID != "photo" {
+		t.Fatalf("Expected only the photo, got %+v", resp.Data.Messages)
+	}
+	if msg := resp.Data.Messages[0]; msg.Filename != "a.jpg" || msg.FileLength != 2048 {
+		t.Errorf("Expected media fields to be populated, got %+v", msg)
+	}
+
+	rec = get("")
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Data.Messages) != 2 {
+		t.Errorf("Expected both media messages, got %d", len(resp.Data.Messages))
+	}
+
+	if rec := get("?media_type=hologram"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown media type, got %d", rec.Code)
+	}
+}
+
+func TestChatStatsRoute(t *testing.T) {
+	store := newTestStore(t)
+	const chatJID = "15551234567@s.whatsapp.net"
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.StoreChat(&database.Chat{JID: chatJID, Name: "Alice", LastMessageTime: base}); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	for _, m := range []*database.Message{
+		{ID: "m1", ChatJID: chatJID, Sender: "15551234567", Content: "Lunch?", Timestamp: base},
+		{ID: "m2", ChatJID: chatJID, Sender: "me", Content: "Sure", Timestamp: base.Add(2 * time.Minute), IsFromMe: true},
+	} {
+		if err := store.StoreMessage(m); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+
+	h := &Handler{store: store}
+	rec := httptest.NewRecorder()
+	NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/chats/"+chatJID+"/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data ChatStatsResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	stats := resp.Data
+	if stats.ChatJID != chatJID || stats.ChatStatistics == nil || stats.TotalMessages != 2 || stats.FromMeCount != 1 {
+		t.Fatalf("Unexpected statistics %+v", stats)
+	}
+	if stats.AvgResponseTimeSeconds != 120 || stats.ByHour[12] != 2 {
+		t.Errorf("Expected a 120s response time and two messages at noon, got %+v", stats)
+	}
+}
+
+func TestUnreadChatsRoute(t *testing.T) {
+	store := newTestStore(t)
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	chats := []struct {
+		jid    string
+		unread int
+	}{
+		{"15551111111@s.whatsapp.net", 1},
+		{"15552222222@s.whatsapp.net", 3},
+		{"15553333333@s.whatsapp.net", 0},
+	}
+	for i, c := range chats {
+		if err := store.StoreChat(&database.Chat{JID: c.jid, Name: c.jid, LastMessageTime: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatalf("Failed to store chat: %v", err)
+		}
+		for j := 0; j < c.unread; j++ {
+			msg := &database.Message{ID: fmt.Sprintf("%s-%d", c.jid, j), ChatJID: c.jid, Sender: "sender", Content: "Hi", Timestamp: base.Add(time.Duration(j) * time.Minute)}
+			if err := store.StoreMessage(msg); err != nil {
+				t.Fatalf("Failed to store message: %v", err)
+			}
+		}
+	}
+
+	h := &Handler{store: store}
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/chats/unread"+query, nil))
+		return rec
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{chats[1].jid, chats[0].jid}},
+		{"?min_unread=2", []string{chats[1].jid}},
+		{"?min_unread=10", nil},
+	}
+	for _, tt := range tests {
+		rec := get(tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Data []*database.ChatWithLastMessage `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%q: failed to decode response: %v", tt.query, err)
+		}
+		var jids []string
+		for _, chat := range resp.Data {
+			jids = append(jids, chat.JID)
+			if chat.LastMessage == nil || chat.LastMessage.ChatJID != chat.JID {
+				t.Errorf("%q: expected %s to carry its last message", tt.query, chat.JID)
+			}
+		}
+		if !slices.Equal(jids, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.query, jids, tt.want)
+		}
+	}
+
+	for _, query := range []string{"?min_unread=0", "?min_unread=lots"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestSendRoutesRejectDisguisedMedia(t *testing.T) {
+	dir := t.TempDir()
+	// Plain text renamed to each media type the routes accept
+	disguised := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("not what the extension says"), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name string
+		path string
+		body map[string]any
+	}{
+		{"async", "/messages/async", map[string]any{"recipient": "15551234567", "media_path": disguised("photo.jpg")}},
+		{"schedule", "/messages/schedule", map[string]any{"recipient": "15551234567", "media_path": disguised("clip.mp4"), "scheduled_at": future}},
+		{"image", "/messages/image", map[string]any{"recipient": "15551234567", "image_path": disguised("photo.png")}},
+		{"audio", "/messages/audio", map[string]any{"recipient": "15551234567", "audio_path": disguised("clip.mp3")}},
+		{"document", "/messages/document", map[string]any{"recipient": "15551234567", "file_path": disguised("report.pdf")}},
+	}
+
+	h := &Handler{store: newTestStore(t), cfg: &config.Config{AllowedUploadDirs: []string{dir}, MaxImageBytes: 1 << 20}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("Failed to encode request: %v", err)
+			}
+			rec := httptest.NewRecorder()
+			NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1"+tt.path, bytes.NewReader(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSendRoutesRejectOversizedMedia(t *testing.T) {
+	dir := t.TempDir()
+	const limit = 100
+	// Genuine files of each type, each over the upload limit
+	oversized := func(name string, header []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, append(header, bytes.Repeat([]byte{0}, limit)...), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name string
+		path string
+		body map[string]any
+	}{
+		{"async", "/messages/async", map[string]any{"recipient": "15551234567", "media_path": oversized("report.pdf", []byte("%PDF-1.7\n"))}},
+		{"schedule", "/messages/schedule", map[string]any{"recipient": "15551234567", "media_path": oversized("later.pdf", []byte("%PDF-1.7\n")), "scheduled_at": future}},
+		{"audio", "/messages/audio", map[string]any{"recipient": "15551234567", "audio_path": oversized("clip.wav", testWAV())}},
+		{"document", "/messages/document", map[string]any{"recipient": "15551234567", "file_path": oversized("invoice.pdf", []byte("%PDF-1.7\n"))}},
+	}
+
+	h := &Handler{store: newTestStore(t), cfg: &config.Config{AllowedUploadDirs: []string{dir}, MaxUploadFileSizeBytes: limit}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("Failed to encode request: %v", err)
+			}
+			rec := httptest.NewRecorder()
+			NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1"+tt.path, bytes.NewReader(body)))
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "byte limit") {
+				t.Errorf("Expected status 400 for the size limit, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSendRoutesRejectExecutables(t *testing.T) {
+	dir := t.TempDir()
+	executable := func(name string, magic []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, append(magic, 0x02, 0x01, 0x01, 0x00), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name string
+		path string
+		body map[string]any
+	}{
+		{"async elf", "/messages/async", map[string]any{"recipient": "15551234567", "media_path": executable("photo.jpg", []byte("\x7fELF"))}},
+		{"schedule pe", "/messages/schedule", map[string]any{"recipient": "15551234567", "media_path": executable("clip.mp4", []byte("MZ")), "scheduled_at": future}},
+		{"image mach-o", "/messages/image", map[string]any{"recipient": "15551234567", "image_path": executable("photo.png", []byte{0xCF, 0xFA, 0xED, 0xFE})}},
+		{"audio shebang", "/messages/audio", map[string]any{"recipient": "15551234567", "audio_path": executable("clip.ogg", []byte("#!/bin/sh\n"))}},
+		{"document shebang", "/messages/document", map[string]any{"recipient": "15551234567", "file_path": executable("notes.txt", []byte("#!/bin/sh\n"))}},
+	}
+
+	h := &Handler{store: newTestStore(t), cfg: &config.Config{AllowedUploadDirs: []string{dir}, MaxImageBytes: 1 << 20}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("Failed to encode request: %v", err)
+			}
+			rec := httptest.NewRecorder()
+			NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1"+tt.path, bytes.NewReader(body)))
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "executable") {
+				t.Errorf("Expected status 400 for an executable, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
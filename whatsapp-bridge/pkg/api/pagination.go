@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"whatsapp-client/pkg/database"
+)
+
+// PageMeta describes where a page sits in the full result set.
+// NextCursor is passed back as the cursor parameter to fetch the next page.
+type PageMeta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	TotalCount int    `json:"total_count"`
+}
+
+// PagedResponse is one page of a paginated list endpoint
+type PagedResponse[T any] struct {
+	Data []T      `json:"data"`
+	Meta PageMeta `json:"meta"`
+}
+
+// newPagedResponse wraps a store page with the total number of results
+func newPagedResponse[T any](page *database.PageResult[T], totalCount int) PagedResponse[T] {
+	return PagedResponse[T]{
+		Data: page.Items,
+		Meta: PageMeta{
+			NextCursor: page.NextCursor,
+			HasMore:    page.NextCursor != "",
+			TotalCount: totalCount,
+		},
+	}
+}
+
+// warnOffsetDeprecated flags responses to requests paginated by offset
+// instead of cursor
+func warnOffsetDeprecated(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("offset") == "" {
+		return
+	}
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Warning", `299 - "offset is deprecated, use cursor with meta.next_cursor"`)
+}
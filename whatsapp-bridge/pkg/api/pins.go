@@ -0,0 +1,85 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"whatsapp-client/pkg/database"
+)
+
+// PinMessageRequest represents the request body for pinning a message
+type PinMessageRequest struct {
+	MessageID string `json:"message_id"`
+	PinnedBy  string `json:"pinned_by"`
+}
+
+// handleGetPinnedMessages handles GET /chats/{jid}/pinned
+func (h *Handler) handleGetPinnedMessages(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := h.store.GetPinnedMessages(chatJID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get pinned messages")
+		return
+	}
+
+	writeSuccessResponse(w, "", messages)
+}
+
+// handlePinMessage handles POST /chats/{jid}/pinned
+func (h *Handler) handlePinMessage(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req PinMessageRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if req.MessageID == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "message_id is required")
+		return
+	}
+
+	err = h.store.PinMessage(req.MessageID, chatJID, req.PinnedBy, time.Now())
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeErrorResponse(w, r, http.StatusNotFound, "message not found")
+	case errors.Is(err, database.ErrPinLimitReached):
+		writeErrorResponse(w, r, http.StatusConflict, err.Error())
+	case err != nil:
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to pin message")
+	default:
+		writeSuccessResponse(w, "Message pinned", nil)
+	}
+}
+
+// handleUnpinMessage handles DELETE /chats/{jid}/pinned/{msg_id}
+func (h *Handler) handleUnpinMessage(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.store.UnpinMessage(r.PathValue("msg_id"), chatJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "message is not pinned")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to unpin message")
+		return
+	}
+
+	writeSuccessResponse(w, "Message unpinned", nil)
+}
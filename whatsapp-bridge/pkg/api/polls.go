@@ -0,0 +1,28 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+)
+
+// handleGetPoll handles GET /messages/{id}/poll?chat_jid=...
+func (h *Handler) handleGetPoll(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := chatJIDParam(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results, err := h.store.GetPollResults(r.PathValue("id"), chatJID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "poll not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get poll")
+		return
+	}
+
+	writeSuccessResponse(w, "", results)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/database/testutil"
+)
+
+// TestGetPollRoute runs the handler against a MockStore, which can fail in
+// ways a real database rarely does
+func TestGetPollRoute(t *testing.T) {
+	const chatJID = "123456789@s.whatsapp.net"
+	store := &testutil.MockStore{}
+	store.On("GetPollResults", "poll", chatJID).Return(&database.PollResults{
+		PollMessage: &database.PollMessage{MessageID: "poll", ChatJID: chatJID, Question: "Lunch?", Options: database.NewPollOptions("Pizza", "Sushi")},
+		Results:     []database.PollOptionCount{{ID: database.NewPollOption("Pizza").ID, Option: "Pizza", Votes: 1}},
+		TotalVoters: 1,
+	}, nil)
+	store.On("GetPollResults", "missing", chatJID).Return(nil, sql.ErrNoRows)
+	store.On("GetPollResults", "broken", chatJID).Return(nil, errors.New("disk I/O error"))
+
+	h := NewHandler(store, nil, nil, nil, nil, nil)
+	get := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/messages/"+id+"/poll?chat_jid="+chatJID, nil))
+		return rec
+	}
+
+	rec := get("poll")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data database.PollResults `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Data.Question != "Lunch?" || resp.Data.TotalVoters != 1 || resp.Data.Options[1] != database.NewPollOption("Sushi") {
+		t.Errorf("Unexpected poll results: %+v", resp.Data)
+	}
+
+	if rec := get("missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing poll, got %d", rec.Code)
+	}
+	if rec := get("broken"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 when the store fails, got %d", rec.Code)
+	}
+
+	store.AssertExpectations(t)
+}
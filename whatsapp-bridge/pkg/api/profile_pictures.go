@@ -0,0 +1,91 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+
+	"whatsapp-client/pkg/database"
+)
+
+// errNoProfilePicture is returned when a JID has no picture visible to us
+var errNoProfilePicture = errors.New("profile picture not available")
+
+// handleGetProfilePicture handles GET /contacts/{jid}/profile_picture
+func (h *Handler) handleGetProfilePicture(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cached, err := h.store.GetProfilePicture(jid)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get profile picture")
+		return
+	}
+	if cached != nil && !cached.IsExpired() {
+		writeSuccessResponse(w, "", cached)
+		return
+	}
+
+	pp, err := h.refreshProfilePicture(jid, cached)
+	switch {
+	case errors.Is(err, errNoProfilePicture):
+		writeErrorResponse(w, r, http.StatusNotFound, err.Error())
+	case err != nil && cached != nil:
+		// Serve the stale URL rather than nothing while WhatsApp is unreachable
+		writeSuccessResponse(w, "", cached)
+	case err != nil:
+		writeErrorResponse(w, r, http.StatusBadGateway, "failed to fetch profile picture")
+	default:
+		writeSuccessResponse(w, "", pp)
+	}
+}
+
+// refreshProfilePicture fetches the current profile picture of jid from
+// WhatsApp and caches it. When the picture is unchanged since cached was
+// stored, cached is kept and only its lifetime is extended.
+func (h *Handler) refreshProfilePicture(jid string, cached *database.ProfilePicture) (*database.ProfilePicture, error) {
+	if h.client == nil || !h.client.IsConnected() {
+		return nil, errors.New("not connected to WhatsApp")
+	}
+
+	parsed, err := types.ParseJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &whatsmeow.GetProfilePictureParams{}
+	if cached != nil {
+		params.ExistingID = cached.ETag
+	}
+
+	info, err := h.client.GetProfilePictureInfo(parsed, params)
+	if errors.Is(err, whatsmeow.ErrProfilePictureNotSet) || errors.Is(err, whatsmeow.ErrProfilePictureUnauthorized) {
+		h.store.InvalidateProfilePicture(jid)
+		return nil, errNoProfilePicture
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pp := &database.ProfilePicture{JID: jid, CachedAt: now, ExpiresAt: now.Add(h.cfg.ProfilePictureCacheTTL)}
+	if info == nil {
+		// Unchanged since the cached ID
+		pp.URL, pp.ETag = cached.URL, cached.ETag
+	} else {
+		pp.URL, pp.ETag = info.URL, info.ID
+	}
+
+	if err := h.store.StoreProfilePicture(pp); err != nil {
+		return nil, err
+	}
+
+	return pp, nil
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"whatsapp-client/pkg/config"
+)
+
+// RateLimitMiddleware limits each client IP to cfg.GlobalRPS requests per
+// second with bursts of up to cfg.GlobalBurst requests. Endpoints listed in
+// cfg.Endpoints have their own limit and bucket instead. Requests over the
+// limit receive 429 with a Retry-After header. A non-positive RPS disables
+// the corresponding limit. Clients are told apart by their connection
+// address, or by X-Forwarded-For when the connection comes from one of
+// cfg.TrustedProxies.
+func RateLimitMiddleware(cfg config.RateLimitConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if cfg.GlobalRPS <= 0 && len(cfg.Endpoints) == 0 {
+			return next
+		}
+		proxies := trustedProxies(cfg.TrustedProxies)
+		global := newIPRateLimiter(cfg.GlobalRPS, cfg.GlobalBurst)
+		endpoints := make(map[string]*ipRateLimiter, len(cfg.Endpoints))
+		for path, limit := range cfg.Endpoints {
+			endpoints[path] = newIPRateLimiter(limit.RPS, limit.Burst)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter, ok := endpoints[rateLimitPath(r.URL.Path)]
+			if !ok {
+				limiter = global
+			}
+
+			if delay, ok := limiter.allow(clientIP(r, proxies)); !ok {
+				retryAfter := int(math.Ceil(delay.Seconds()))
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				writeErrorResponse(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limiterIdleTTL is how long a client's bucket is kept after its last
+// request. Buckets that take longer to refill are kept until they have.
+const limiterIdleTTL = 10 * time.Minute
+
+// ipRateLimiter keeps a token bucket per client IP, dropping the buckets of
+// clients that have gone idle so the map does not grow without bound
+type ipRateLimiter struct {
+	rps       float64
+	burst     int
+	idleTTL   time.Duration
+	limiters  sync.Map     // client IP -> *clientLimiter
+	lastSweep atomic.Int64 // Unix nanoseconds
+}
+
+// clientLimiter is the bucket of one client IP
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen atomic.Int64 // Unix nanoseconds
+}
+
+func newIPRateLimiter(rps float64, burst int) *ipRateLimiter {
+	l := &ipRateLimiter{rps: rps, burst: burst, idleTTL: limiterIdleTTL}
+	// A dropped bucket is recreated full, so it must have refilled first
+	if rps > 0 {
+		if refill := time.Duration(float64(burst) / rps * float64(time.Second)); refill > l.idleTTL {
+			l.idleTTL = refill
+		}
+	}
+	l.lastSweep.Store(time.Now().UnixNano())
+	return l
+}
+
+// allow takes a token from ip's bucket. When none is left it returns how
+// long until one is.
+func (l *ipRateLimiter) allow(ip string) (time.Duration, bool) {
+	if l.rps <= 0 {
+		return 0, true
+	}
+	now := time.Now()
+	l.sweep(now)
+
+	entry, ok := l.limiters.Load(ip)
+	if !ok {
+		entry, _ = l.limiters.LoadOrStore(ip, &clientLimiter{limiter: rate.NewLimiter(rate.Limit(l.rps), l.burst)})
+	}
+	client := entry.(*clientLimiter)
+	client.lastSeen.Store(now.UnixNano())
+
+	reservation := client.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); !reservation.OK() || delay > 0 {
+		reservation.CancelAt(now)
+		return delay, false
+	}
+	return 0, true
+}
+
+// sweep drops the buckets of clients idle for longer than idleTTL. It does
+// the work at most once per idleTTL, on whichever request first finds it
+// due.
+func (l *ipRateLimiter) sweep(now time.Time) {
+	last := l.lastSweep.Load()
+	if now.UnixNano()-last < int64(l.idleTTL) || !l.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	cutoff := now.Add(-l.idleTTL).UnixNano()
+	l.limiters.Range(func(ip, entry any) bool {
+		if entry.(*clientLimiter).lastSeen.Load() < cutoff {
+			l.limiters.Delete(ip)
+		}
+		return true
+	})
+}
+
+// rateLimitPath is the API path an endpoint override is keyed by: the
+// request path without the /api prefix or an API version
+func rateLimitPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "/api"); ok && (rest == "" || rest[0] == '/') {
+		path = rest
+	}
+	if loc := versionPrefix.FindStringIndex(path); loc != nil {
+		path = "/" + path[loc[1]:]
+	}
+	return path
+}
+
+// trustedProxies parses the configured proxy IPs and CIDR ranges.
+// Entries that parse as neither are skipped; Config.Validate reports them.
+func trustedProxies(entries []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return prefixes
+}
+
+// isTrustedProxy reports whether ip is one of the trusted proxies
+func isTrustedProxy(ip string, proxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the originating client address. The connection address
+// is used unless it is a trusted proxy, in which case X-Forwarded-For is
+// read from the right, skipping further trusted proxies, since the entries
+// to their left were supplied by the client and may be forged.
+func clientIP(r *http.Request, proxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, proxies) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if ip == "" {
+			continue
+		}
+		if !isTrustedProxy(ip, proxies) {
+			return ip
+		}
+		host = ip
+	}
+	return host
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"whatsapp-client/pkg/config"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	const burst = 3
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := RateLimitMiddleware(config.RateLimitConfig{
+		GlobalRPS:      0.001,
+		GlobalBurst:    burst,
+		TrustedProxies: []string{"203.0.113.0/24"},
+	})(next)
+
+	request := func(remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/send", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < burst; i++ {
+		if rec := request("192.0.2.1:1234", ""); rec.Code != http.StatusNoContent {
+			t.Fatalf("Request %d: expected %d, got %d", i+1, http.StatusNoContent, rec.Code)
+		}
+	}
+
+	rec := request("192.0.2.1:5678", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected request over the burst to get %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+
+	// Other clients have their own bucket
+	if rec := request("192.0.2.2:1234", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected a different IP to be allowed, got %d", rec.Code)
+	}
+	if rec := request("203.0.113.5:1234", "198.51.100.7, 203.0.113.9"); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected a different client forwarded by a trusted proxy to be allowed, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitMiddlewareIgnoresUntrustedForwardedFor(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := RateLimitMiddleware(config.RateLimitConfig{
+		GlobalRPS:      0.001,
+		GlobalBurst:    1,
+		TrustedProxies: []string{"203.0.113.1"},
+	})(next)
+
+	request := func(remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/send", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := request("192.0.2.1:1234", "198.51.100.1"); code != http.StatusNoContent {
+		t.Fatalf("Expected the first request to be allowed, got %d", code)
+	}
+	// A client connecting directly cannot escape its limit by claiming to
+	// be someone else
+	if code := request("192.0.2.1:1234", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected a spoofed X-Forwarded-For to still be limited, got %d", code)
+	}
+	// Behind a trusted proxy, entries the client prepended are skipped
+	if code := request("203.0.113.1:1234", "198.51.100.3, 192.0.2.1"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the client address appended by the proxy to be limited, got %d", code)
+	}
+}
+
+func TestIPRateLimiterSweep(t *testing.T) {
+	limiter := newIPRateLimiter(1, 1)
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2"} {
+		if _, ok := limiter.allow(ip); !ok {
+			t.Fatalf("Expected the first request from %s to be allowed", ip)
+		}
+	}
+
+	// A recently seen client keeps its bucket
+	entry, _ := limiter.limiters.Load("192.0.2.2")
+	entry.(*clientLimiter).lastSeen.Store(time.Now().Add(2 * limiterIdleTTL).UnixNano())
+
+	limiter.sweep(time.Now().Add(limiterIdleTTL + time.Minute))
+	if _, ok := limiter.limiters.Load("192.0.2.1"); ok {
+		t.Error("Expected the idle client's bucket to be dropped")
+	}
+	if _, ok := limiter.limiters.Load("192.0.2.2"); !ok {
+		t.Error("Expected the active client's bucket to be kept")
+	}
+}
+
+func TestRateLimitMiddlewareEndpointOverride(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := RateLimitMiddleware(config.RateLimitConfig{
+		GlobalRPS:   0.001,
+		GlobalBurst: 5,
+		Endpoints: map[string]config.EndpointRateLimit{
+			"/messages/bulk": {RPS: 0.001, Burst: 1},
+			"/chats":         {RPS: 0},
+		},
+	})(next)
+
+	request := func(path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// The override is tighter than the global limit and applies under
+	// every prefix the API is served at
+	if code := request("/api/v1/messages/bulk"); code != http.StatusNoContent {
+		t.Fatalf("Expected the first bulk request to be allowed, got %d", code)
+	}
+	for _, path := range []string{"/api/v1/messages/bulk", "/api/messages/bulk", "/messages/bulk"} {
+		if code := request(path); code != http.StatusTooManyRequests {
+			t.Errorf("%s: expected the endpoint limit to apply, got %d", path, code)
+		}
+	}
+
+	// Other endpoints still share the global bucket, untouched by the
+	// bulk requests
+	for i := 0; i < 5; i++ {
+		if code := request("/api/v1/messages"); code != http.StatusNoContent {
+			t.Fatalf("Request %d: expected the global burst to allow it, got %d", i+1, code)
+		}
+	}
+	if code := request("/api/v1/contacts"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the global limit to apply, got %d", code)
+	}
+
+	// A zero-RPS override lifts the limit for its endpoint
+	for i := 0; i < 10; i++ {
+		if code := request("/api/chats"); code != http.StatusNoContent {
+			t.Fatalf("Request %d: expected an unlimited endpoint, got %d", i+1, code)
+		}
+	}
+}
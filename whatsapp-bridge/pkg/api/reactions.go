@@ -0,0 +1,221 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/validation"
+)
+
+// ReactionRequest represents the request body for reacting to a message.
+// An empty emoji removes the sender's reaction, as in WhatsApp.
+type ReactionRequest struct {
+	ChatJID string `json:"chat_jid"`
+	Sender  string `json:"sender"`
+	Emoji   string `json:"emoji"`
+}
+
+// SendReactionRequest represents the request body for reacting to a message
+// in the recipient's chat
+type SendReactionRequest struct {
+	Recipient string `json:"recipient"`
+	MessageID string `json:"message_id"`
+	Emoji     string `json:"emoji"`
+}
+
+// DeleteReactionRequest represents the request body for removing our
+// reaction from a message in the recipient's chat
+type DeleteReactionRequest struct {
+	Recipient string `json:"recipient"`
+	MessageID string `json:"message_id"`
+}
+
+// ValidateReactionRequest validates a send reaction request
+func ValidateReactionRequest(req SendReactionRequest) error {
+	if err := validation.ValidateRecipient(req.Recipient); err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	if req.MessageID == "" {
+		return fmt.Errorf("message_id is required")
+	}
+	if err := validation.ValidateEmoji(req.Emoji); err != nil {
+		return fmt.Errorf("invalid emoji: %w", err)
+	}
+	return nil
+}
+
+// handleGetReactions handles GET /messages/{id}/reactions?chat_jid=...
+func (h *Handler) handleGetReactions(w http.ResponseWriter, r *http.Request) {
+	chatJID, err := chatJIDParam(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reactions, err := h.store.GetReactions(r.PathValue("id"), chatJID)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get reactions")
+		return
+	}
+
+	writeSuccessResponse(w, "", reactions)
+}
+
+// handleStoreReaction handles POST /messages/{id}/reactions
+func (h *Handler) handleStoreReaction(w http.ResponseWriter, r *http.Request) {
+	var req ReactionRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if err := validation.ValidateJID(req.ChatJID); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "invalid chat_jid: "+err.Error())
+		return
+	}
+	if req.Sender == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "sender is required")
+		return
+	}
+
+	messageID := r.PathValue("id")
+	if req.Emoji == "" {
+		err := h.store.DeleteReaction(messageID, req.ChatJID, req.Sender)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeErrorResponse(w, r, http.StatusNotFound, "reaction not found")
+			return
+		}
+		if err != nil {
+			writeErrorResponse(w, r, http.StatusInternalServerError, "failed to remove reaction")
+			return
+		}
+		writeSuccessResponse(w, "Reaction removed", nil)
+		return
+	}
+
+	err := h.store.StoreReaction(&database.Reaction{
+		MessageID: messageID,
+		ChatJID:   req.ChatJID,
+		Sender:    req.Sender,
+		Emoji:     req.Emoji,
+		Timestamp: time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "message not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to store reaction")
+		return
+	}
+
+	writeSuccessResponse(w, "Reaction stored", nil)
+}
+
+// handleSendReaction handles POST /messages/reaction
+func (h *Handler) handleSendReaction(w http.ResponseWriter, r *http.Request) {
+	var req SendReactionRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if err := ValidateReactionRequest(req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.sendReaction(w, r, req.Recipient, req.MessageID, req.Emoji)
+}
+
+// handleDeleteReaction handles DELETE /messages/reaction
+func (h *Handler) handleDeleteReaction(w http.ResponseWriter, r *http.Request) {
+	var req DeleteReactionRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+
+	if err := validation.ValidateRecipient(req.Recipient); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, "invalid recipient: "+err.Error())
+		return
+	}
+	if req.MessageID == "" {
+		writeErrorResponse(w, r, http.StatusBadRequest, "message_id is required")
+		return
+	}
+
+	// WhatsApp removes a reaction when an empty one is sent in its place
+	h.sendReaction(w, r, req.Recipient, req.MessageID, "")
+}
+
+// sendReaction sends emoji as our reaction to a stored message in the
+// recipient's chat
+func (h *Handler) sendReaction(w http.ResponseWriter, r *http.Request, recipient, messageID, emoji string) {
+	chat, err := parseRecipientJID(recipient)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid recipient: %v", err))
+		return
+	}
+
+	target, err := h.store.GetMessage(messageID, chat.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "message not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get message")
+		return
+	}
+
+	if h.wa == nil {
+		writeErrorResponse(w, r, http.StatusServiceUnavailable, "not connected to WhatsApp")
+		return
+	}
+	resp, err := h.wa.SendMessage(r.Context(), chat, reactionMessage(chat, target, emoji))
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to send reaction: %v", err))
+		return
+	}
+
+	message := "Reaction sent"
+	if emoji == "" {
+		message = "Reaction removed"
+	}
+	writeSuccessResponse(w, message, map[string]string{"message_id": resp.ID})
+}
+
+// reactionMessage builds a reaction to target; in groups the key names the
+// participant who sent the message being reacted to
+func reactionMessage(chat types.JID, target *database.Message, emoji string) *waE2E.Message {
+	key := &waCommon.MessageKey{
+		RemoteJID: proto.String(chat.String()),
+		FromMe:    proto.Bool(target.IsFromMe),
+		ID:        proto.String(target.ID),
+	}
+	if chat.Server == types.GroupServer && !target.IsFromMe {
+		sender := target.Sender
+		if !strings.Contains(sender, "@") {
+			sender += "@" + types.DefaultUserServer
+		}
+		key.Participant = proto.String(sender)
+	}
+
+	return &waE2E.Message{
+		ReactionMessage: &waE2E.ReactionMessage{
+			Key:               key,
+			Text:              proto.String(emoji),
+			SenderTimestampMS: proto.Int64(time.Now().UnixMilli()),
+		},
+	}
+}
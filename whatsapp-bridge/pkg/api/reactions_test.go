@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"whatsapp-client/pkg/database"
+)
+
+func TestSendReactionRoutes(t *testing.T) {
+	store := newTestStore(t)
+	const groupJID = "123456789-123456@g.us"
+	if err := store.StoreChat(&database.Chat{JID: groupJID, Name: "Team", LastMessageTime: time.Now()}); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	if err := store.StoreMessage(&database.Message{ID: "MSG1", ChatJID: groupJID, Sender: "15551234567", Content: "Ship it", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Failed to store message: %v", err)
+	}
+
+	sender := &mockSender{}
+	h := &Handler{store: store, wa: sender}
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(method, "/v1/messages/reaction", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, `{"recipient":"`+groupJID+`","message_id":"MSG1","emoji":"👍🏽"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	reaction := sender.msg.GetReactionMessage()
+	if reaction.GetText() != "👍🏽" {
+		t.Errorf("Expected reaction 👍🏽, got %q", reaction.GetText())
+	}
+	key := reaction.GetKey()
+	if key.GetID() != "MSG1" || key.GetRemoteJID() != groupJID || key.GetFromMe() {
+		t.Errorf("Unexpected message key %v", key)
+	}
+	if key.GetParticipant() != "15551234567@s.whatsapp.net" {
+		t.Errorf("Expected participant 15551234567@s.whatsapp.net, got %q", key.GetParticipant())
+	}
+
+	rec = do(http.MethodDelete, `{"recipient":"`+groupJID+`","message_id":"MSG1"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if reaction := sender.msg.GetReactionMessage(); reaction == nil || reaction.GetText() != "" {
+		t.Errorf("Expected an empty reaction to remove ours, got %v", sender.msg)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{"two emoji", http.MethodPost, `{"recipient":"` + groupJID + `","message_id":"MSG1","emoji":"👍👍"}`, http.StatusBadRequest},
+		{"text", http.MethodPost, `{"recipient":"` + groupJID + `","message_id":"MSG1","emoji":"ok"}`, http.StatusBadRequest},
+		{"no message id", http.MethodDelete, `{"recipient":"` + groupJID + `"}`, http.StatusBadRequest},
+		{"unknown message", http.MethodPost, `{"recipient":"` + groupJID + `","message_id":"MISSING","emoji":"🎉"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := do(tt.method, tt.body); rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
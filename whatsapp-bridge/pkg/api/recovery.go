@@ -0,0 +1,44 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// RecoveryMiddleware turns a panicking handler into a 500 JSON response
+// instead of a dropped connection, logging the panic with its stack trace.
+func RecoveryMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				// The server uses this panic to abort a response on purpose
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				requestID := RequestIDFromContext(r.Context())
+				logger.Error("panic serving request",
+					"request_id", requestID,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"panic", v,
+					"stack", string(debug.Stack()),
+				)
+
+				// The handler may have set other headers before panicking
+				writeJSONResponse(w, http.StatusInternalServerError, Response{
+					Success:   false,
+					Error:     "internal server error",
+					RequestID: requestID,
+				})
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		var client *http.Client
+		client.Do(r) // nil pointer dereference
+	})
+	handler := RequestIDMiddleware()(RecoveryMiddleware(logger)(next))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/chats", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", got)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Expected a JSON body: %v", err)
+	}
+	if resp.Success || resp.Error == "" {
+		t.Errorf("Expected an error response, got %+v", resp)
+	}
+	if resp.RequestID == "" || resp.RequestID != rec.Header().Get(RequestIDHeader) {
+		t.Errorf("Expected request_id to match the response header, got %q", resp.RequestID)
+	}
+
+	if !strings.Contains(logs.String(), "panic serving request") || !strings.Contains(logs.String(), "goroutine") {
+		t.Errorf("Expected the panic and stack trace to be logged, got %q", logs.String())
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var fromContext string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = RequestIDFromContext(r.Context())
+		if got := r.Header.Get(RequestIDHeader); got != fromContext {
+			t.Errorf("Expected request header %q to match context %q", got, fromContext)
+		}
+		writeErrorResponse(w, r, http.StatusBadRequest, "bad input")
+	})
+	handler := RequestIDMiddleware()(next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/chats", nil))
+
+	if fromContext == "" {
+		t.Fatal("Expected a request ID in the context")
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != fromContext {
+		t.Errorf("Expected response header %q, got %q", fromContext, got)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode error body: %v", err)
+	}
+	if resp.RequestID != fromContext {
+		t.Errorf("Expected request_id %q in the error body, got %q", fromContext, resp.RequestID)
+	}
+
+	// Each request gets its own ID
+	previous := fromContext
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/chats", nil))
+	if fromContext == previous {
+		t.Error("Expected a new request ID for each request")
+	}
+}
+
+func TestRequestIDFromContextMissing(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/chats", nil)
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		t.Errorf("Expected no request ID, got %q", id)
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Routes returns an http.Handler with all API routes registered
+func (h *Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Admin routes
+	mux.HandleFunc("POST /admin/backup", h.requireAdminKey(h.handleBackup))
+	mux.HandleFunc("GET /admin/db_stats", h.requireAdminKey(h.handleGetDBStats))
+	mux.HandleFunc("GET /admin/janitor_stats", h.requireAdminKey(h.handleGetJanitorStats))
+	mux.HandleFunc("POST /admin/vacuum", h.requireAdminKey(h.handleVacuum))
+
+	// Broadcast routes
+	mux.HandleFunc("GET /broadcasts", h.handleListBroadcasts)
+	mux.HandleFunc("GET /broadcasts/{jid}/recipients", h.handleGetBroadcastRecipients)
+
+	// Call routes
+	mux.HandleFunc("GET /calls", h.handleListCalls)
+	mux.HandleFunc("GET /calls/missed", h.handleListMissedCalls)
+	mux.HandleFunc("GET /calls/{id}", h.handleGetCall)
+
+	// Chat routes
+	mux.HandleFunc("GET /chats", h.handleListChats)
+	mux.HandleFunc("GET /chats/archived", h.handleListArchivedChats)
+	mux.HandleFunc("GET /chats/muted", h.handleListMutedChats)
+	mux.HandleFunc("GET /chats/unread", h.handleListUnreadChats)
+	mux.HandleFunc("DELETE /chats/{jid}", h.handleDeleteChat)
+	mux.HandleFunc("DELETE /chats/{jid}/messages", h.handleClearChat)
+	mux.HandleFunc("POST /chats/{jid}/read", h.handleMarkChatRead)
+	mux.HandleFunc("PUT /chats/{jid}/read_position", h.handleSetReadPosition)
+	mux.HandleFunc("GET /chats/{jid}/media", h.handleGetChatMedia)
+	mux.HandleFunc("GET /chats/{jid}/stats", h.handleGetChatStats)
+	mux.HandleFunc("POST /chats/{jid}/archive", h.handleArchiveChat)
+	mux.HandleFunc("DELETE /chats/{jid}/archive", h.handleUnarchiveChat)
+	mux.HandleFunc("POST /chats/{jid}/mute", h.handleMuteChat)
+	mux.HandleFunc("DELETE /chats/{jid}/mute", h.handleUnmuteChat)
+	mux.HandleFunc("GET /chats/{jid}/pinned", h.handleGetPinnedMessages)
+	mux.HandleFunc("POST /chats/{jid}/pinned", h.handlePinMessage)
+	mux.HandleFunc("DELETE /chats/{jid}/pinned/{msg_id}", h.handleUnpinMessage)
+	mux.HandleFunc("POST /chats/{jid}/labels/{label_id}", h.handleAddChatLabel)
+	mux.HandleFunc("DELETE /chats/{jid}/labels/{label_id}", h.handleRemoveChatLabel)
+
+	// Contact routes
+	mux.HandleFunc("GET /contacts", h.handleListContacts)
+	mux.HandleFunc("GET /contacts/blocked", h.handleListBlockedContacts)
+	mux.HandleFunc("GET /contacts/search", h.handleSearchContacts)
+	// The number is a query parameter: a /contacts/by_phone/{phone} pattern
+	// would be ambiguous with /contacts/{jid}/mentions and its siblings
+	mux.HandleFunc("GET /contacts/by_phone", h.handleGetContactByPhone)
+	mux.HandleFunc("GET /contacts/{jid}", h.handleGetContact)
+	mux.HandleFunc("POST /contacts/{jid}/block", h.handleBlockContact)
+	mux.HandleFunc("DELETE /contacts/{jid}/block", h.handleUnblockContact)
+	mux.HandleFunc("GET /contacts/{jid}/mentions", h.handleGetContactMentions)
+	mux.HandleFunc("GET /contacts/{jid}/profile_picture", h.handleGetProfilePicture)
+	mux.HandleFunc("GET /contacts/{jid}/statuses", h.handleGetContactStatuses)
+
+	// Group routes
+	mux.HandleFunc("GET /groups", h.handleListGroups)
+	mux.HandleFunc("POST /groups", h.handleCreateGroup)
+	mux.HandleFunc("GET /groups/{jid}", h.handleGetGroupInfo)
+	mux.HandleFunc("GET /groups/{jid}/participants", h.handleGetParticipants)
+	mux.HandleFunc("POST /groups/{jid}/participants", h.handleAddParticipant)
+	mux.HandleFunc("DELETE /groups/{jid}/participants/{participant_jid}", h.handleRemoveParticipant)
+
+	// Job routes
+	mux.HandleFunc("GET /jobs/{id}", h.handleGetJob)
+
+	// Label routes
+	mux.HandleFunc("GET /labels", h.handleListLabels)
+	mux.HandleFunc("POST /labels", h.handleCreateLabel)
+	mux.HandleFunc("GET /labels/{id}", h.handleGetLabel)
+	mux.HandleFunc("PUT /labels/{id}", h.handleUpdateLabel)
+	mux.HandleFunc("DELETE /labels/{id}", h.handleDeleteLabel)
+
+	// Media routes
+	mux.HandleFunc("POST /media/download", h.handleDownloadMedia)
+
+	// Message routes
+	mux.HandleFunc("GET /messages", h.handleListMessages)
+	mux.HandleFunc("POST /messages/async", h.handleSendAsync)
+	mux.Handle("POST /messages/bulk", h.bulkSendHandler())
+	mux.HandleFunc("POST /messages/image", h.handleSendImage)
+	mux.HandleFunc("POST /messages/audio", h.handleSendAudio)
+	mux.HandleFunc("POST /messages/document", h.handleSendDocument)
+	mux.HandleFunc("POST /messages/contact", h.handleSendContact)
+	mux.HandleFunc("POST /messages/location", h.handleSendLocation)
+	mux.HandleFunc("POST /messages/reaction", h.handleSendReaction)
+	mux.HandleFunc("DELETE /messages/reaction", h.handleDeleteReaction)
+	mux.HandleFunc("GET /messages/nearby", h.handleNearbyMessages)
+	mux.HandleFunc("GET /messages/locations/nearby", h.handleNearbyLocations)
+	mux.HandleFunc("GET /messages/search", h.handleSearchMessages)
+	mux.HandleFunc("POST /messages/schedule", h.handleScheduleMessage)
+	mux.HandleFunc("GET /messages/scheduled", h.handleListScheduledMessages)
+	mux.HandleFunc("GET /messages/{id}/thread", h.handleGetMessageThread)
+	mux.HandleFunc("GET /messages/{id}/history", h.handleGetEditHistory)
+	mux.HandleFunc("GET /messages/{id}/media_metadata", h.handleGetMediaMetadata)
+	mux.HandleFunc("PUT /messages/{id}/status", h.handleUpdateMessageStatus)
+	mux.HandleFunc("PUT /messages/{id}", h.handleEditMessage)
+	mux.HandleFunc("DELETE /messages/{id}", h.handleDeleteMessage)
+
+	// Poll routes
+	mux.HandleFunc("GET /messages/{id}/poll", h.handleGetPoll)
+
+	// Reaction routes
+	mux.HandleFunc("GET /messages/{id}/reactions", h.handleGetReactions)
+	mux.HandleFunc("POST /messages/{id}/reactions", h.handleStoreReaction)
+
+	// Status routes
+	mux.HandleFunc("GET /statuses", h.handleListStatuses)
+
+	// Webhook routes
+	mux.HandleFunc("GET /webhooks", h.handleListWebhooks)
+	mux.HandleFunc("POST /webhooks", h.handleRegisterWebhook)
+	mux.HandleFunc("DELETE /webhooks/{id}", h.handleDeleteWebhook)
+
+	return RouteRecorder(mux)
+}
+
+// apiVersions lists the API versions that can be routed to
+var apiVersions = []string{"v1"}
+
+// versionMediaType matches the Accept media type that asks for an API
+// version, capturing the version
+var versionMediaType = regexp.MustCompile(`^application/vnd\.whatsapp\.(v\d+)\+json$`)
+
+// versionPrefix matches a path that already names an API version
+var versionPrefix = regexp.MustCompile(`^/v\d+(/|$)`)
+
+// NewRouter serves v1Handler under /v1/. Unversioned paths are served by
+// v1Handler as well, so that clients predating versioning keep working;
+// VersionMiddleware routes them by their Accept header instead when it
+// names a version.
+func NewRouter(v1Handler http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", http.StripPrefix("/v1", v1Handler))
+	mux.Handle("/", v1Handler)
+	return mux
+}
+
+// VersionMiddleware routes a request whose Accept header asks for an API
+// version, as in "Accept: application/vnd.whatsapp.v1+json", to that
+// version by prefixing its path, unless the path names a version already.
+// A request asking only for versions the API does not have is rejected with
+// 406 in strict mode and served as if it named no version otherwise.
+func VersionMiddleware(strict bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested, version := acceptedVersion(r.Header.Get("Accept"))
+			if requested && version == "" && strict {
+				writeErrorResponse(w, r, http.StatusNotAcceptable,
+					fmt.Sprintf("unsupported API version, supported versions: %s", strings.Join(apiVersions, ", ")))
+				return
+			}
+			if version == "" || versionPrefix.MatchString(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = "/" + version + r.URL.Path
+			if r.URL.RawPath != "" {
+				r2.URL.RawPath = "/" + version + r.URL.RawPath
+			}
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
+
+// acceptedVersion reports whether an Accept header asks for any API
+// version, and the first of those the API has
+func acceptedVersion(accept string) (requested bool, version string) {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(mediaRange, ";")
+		match := versionMediaType.FindStringSubmatch(strings.ToLower(strings.TrimSpace(mediaType)))
+		if match == nil {
+			continue
+		}
+		requested = true
+		if slices.Contains(apiVersions, match[1]) {
+			return true, match[1]
+		}
+	}
+	return requested, ""
+}
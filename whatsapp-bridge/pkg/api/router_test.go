@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewRouter(t *testing.T) {
+	var got string
+	v1 := http.NewServeMux()
+	v1.HandleFunc("GET /chats", func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path
+	})
+	router := NewRouter(v1)
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/v1/chats", http.StatusOK},
+		{"/chats", http.StatusOK},
+		{"/v2/chats", http.StatusNotFound},
+		{"/v1/nowhere", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		got = ""
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, rec.Code)
+		}
+		if tt.status == http.StatusOK && got != "/chats" {
+			t.Errorf("%s: expected the v1 handler to see /chats, got %q", tt.path, got)
+		}
+	}
+}
+
+func TestVersionMiddleware(t *testing.T) {
+	var got string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path
+	})
+
+	tests := []struct {
+		name   string
+		strict bool
+		path   string
+		accept string
+		status int
+		want   string
+	}{
+		{"no version", true, "/chats", "application/json", http.StatusOK, "/chats"},
+		{"v1 accept", true, "/chats", "application/vnd.whatsapp.v1+json", http.StatusOK, "/v1/chats"},
+		{"v1 among others", true, "/chats", "application/json, application/vnd.whatsapp.v2+json, application/vnd.whatsapp.v1+json; q=0.5", http.StatusOK, "/v1/chats"},
+		{"versioned path", true, "/v1/chats", "application/vnd.whatsapp.v1+json", http.StatusOK, "/v1/chats"},
+		{"unknown version", false, "/chats", "application/vnd.whatsapp.v2+json", http.StatusOK, "/chats"},
+		{"unknown version strict", true, "/chats", "application/vnd.whatsapp.v2+json", http.StatusNotAcceptable, ""},
+		{"unknown version strict versioned path", true, "/v1/chats", "application/vnd.whatsapp.v9+json", http.StatusNotAcceptable, ""},
+	}
+	for _, tt := range tests {
+		got = ""
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Accept", tt.accept)
+		rec := httptest.NewRecorder()
+		VersionMiddleware(tt.strict)(next).ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rec.Code)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected path %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/validation"
+)
+
+// ScheduleMessageRequest represents the request body for scheduling a message
+type ScheduleMessageRequest struct {
+	Recipient   string    `json:"recipient"`
+	Message     string    `json:"message"`
+	MediaPath   string    `json:"media_path"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+// validateScheduleMessageRequest validates a schedule message request. Media
+// must lie within allowedDirs and may be at most maxBytes long.
+func validateScheduleMessageRequest(req ScheduleMessageRequest, allowedDirs []string, maxBytes int64) error {
+	if err := validation.ValidateRecipient(req.Recipient); err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	if req.Message == "" && req.MediaPath == "" {
+		return fmt.Errorf("message or media_path is required")
+	}
+	if req.Message != "" {
+		if err := validation.ValidateMessageContent(req.Message); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+	}
+	if req.MediaPath != "" {
+		if err := validateMediaPath(req.MediaPath, allowedDirs, maxBytes); err != nil {
+			return fmt.Errorf("invalid media_path: %w", err)
+		}
+	}
+
+	if req.ScheduledAt.IsZero() {
+		return fmt.Errorf("scheduled_at is required")
+	}
+	if !req.ScheduledAt.After(time.Now()) {
+		return fmt.Errorf("scheduled_at must be in the future")
+	}
+
+	return nil
+}
+
+// handleScheduleMessage handles POST /messages/schedule
+func (h *Handler) handleScheduleMessage(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleMessageRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if err := validateScheduleMessageRequest(req, h.cfg.AllowedUploadDirs, h.cfg.MaxUploadFileSizeBytes); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	msg := &database.ScheduledMessage{
+		Recipient:   req.Recipient,
+		Content:     req.Message,
+		MediaPath:   req.MediaPath,
+		ScheduledAt: req.ScheduledAt,
+	}
+	if err := h.store.ScheduleMessage(msg); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to schedule message")
+		return
+	}
+
+	writeSuccessResponse(w, "Message scheduled", msg)
+}
+
+// handleListScheduledMessages handles GET /messages/scheduled?status=...
+func (h *Handler) handleListScheduledMessages(w http.ResponseWriter, r *http.Request) {
+	status := database.ScheduledStatus(r.URL.Query().Get("status"))
+	if status != "" && !status.IsValid() {
+		writeErrorResponse(w, r, http.StatusBadRequest, "invalid status parameter")
+		return
+	}
+
+	limit, offset, _, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := h.store.GetScheduledMessages(status, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get scheduled messages")
+		return
+	}
+
+	writeSuccessResponse(w, "", messages)
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"whatsapp-client/pkg/config"
+)
+
+// NewServer returns an HTTP server for handler with the read, write and idle
+// timeouts from cfg, so slow or stalled clients cannot hold connections open
+// indefinitely. The caller sets Addr.
+func NewServer(cfg *config.Config, handler http.Handler) *http.Server {
+	readTimeout := time.Duration(cfg.HTTPReadTimeoutSeconds) * time.Second
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      time.Duration(cfg.HTTPWriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(cfg.HTTPIdleTimeoutSeconds) * time.Second,
+	}
+}
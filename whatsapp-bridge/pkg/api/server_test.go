@@ -0,0 +1,46 @@
+package api
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"whatsapp-client/pkg/config"
+)
+
+func TestNewServerTimesOutSlowClients(t *testing.T) {
+	cfg := &config.Config{HTTPReadTimeoutSeconds: 1, HTTPWriteTimeoutSeconds: 1, HTTPIdleTimeoutSeconds: 1}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Config = NewServer(cfg, handler)
+	srv.Start()
+	defer srv.Close()
+
+	if srv.Config.ReadTimeout != time.Second || srv.Config.WriteTimeout != time.Second || srv.Config.IdleTimeout != time.Second {
+		t.Fatalf("Unexpected timeouts: read %v, write %v, idle %v",
+			srv.Config.ReadTimeout, srv.Config.WriteTimeout, srv.Config.IdleTimeout)
+	}
+
+	// Send a partial request line and stall, as a slowloris client would
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n")); err != nil {
+		t.Fatalf("Failed to write partial request: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	start := time.Now()
+	io.Copy(io.Discard, conn)
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("Expected the server to drop the stalled connection, waited %v", elapsed)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// sseKeepAlivePeriod is how often an idle stream gets a comment line, which
+// keeps proxies from timing it out and detects departed clients
+const sseKeepAlivePeriod = 30 * time.Second
+
+// SSEHandler streams hub events as Server-Sent Events. The optional chat_jid
+// query parameter limits events to one chat. A client reconnecting with a
+// Last-Event-ID header first receives the buffered events it missed.
+func SSEHandler(hub *EventHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatJID := r.URL.Query().Get("chat_jid")
+		if chatJID != "" {
+			if err := validation.ValidateJID(chatJID); err != nil {
+				writeErrorResponse(w, r, http.StatusBadRequest, "invalid chat_jid: "+err.Error())
+				return
+			}
+		}
+
+		// An unparsable Last-Event-ID is treated as a fresh connection
+		lastEventID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+		sub, replay, ok := hub.subscribe(chatJID, lastEventID)
+		if !ok {
+			writeErrorResponse(w, r, http.StatusServiceUnavailable, "server is shutting down")
+			return
+		}
+		defer hub.unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		rc := http.NewResponseController(w)
+		send := func(payload string) bool {
+			// The server write timeout would otherwise end the stream
+			rc.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if _, err := fmt.Fprint(w, payload); err != nil {
+				return false
+			}
+			return rc.Flush() == nil
+		}
+
+		if !send(": connected\n\n") {
+			return
+		}
+		for _, ev := range replay {
+			if !send(sseFrame(ev)) {
+				return
+			}
+		}
+
+		keepAlive := time.NewTicker(sseKeepAlivePeriod)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case ev, ok := <-sub.events:
+				if !ok || !send(sseFrame(ev)) {
+					return
+				}
+			case <-keepAlive.C:
+				if !send(": keep-alive\n\n") {
+					return
+				}
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// sseFrame formats an event as an SSE message with its ID
+func sseFrame(ev encodedEvent) string {
+	return fmt.Sprintf("id: %d\ndata: %s\n\n", ev.id, ev.data)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"whatsapp-client/pkg/database"
+)
+
+// readSSEEvents reads n data events from an SSE stream
+func readSSEEvents(t *testing.T, resp *http.Response, n int) []Event {
+	t.Helper()
+	events := make(chan Event)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var ev Event
+			if err := json.Unmarshal([]byte(data), &ev); err == nil {
+				events <- ev
+			}
+		}
+		close(events)
+	}()
+
+	var got []Event
+	timeout := time.After(2 * time.Second)
+	for len(got) < n {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatalf("Stream ended after %d events, expected %d", len(got), n)
+			}
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("Timed out after %d events, expected %d", len(got), n)
+		}
+	}
+	return got
+}
+
+func TestSSEHandler(t *testing.T) {
+	hub := NewEventHub()
+	// Streaming must survive the middleware that wraps the response writer
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := httptest.NewServer(LoggingMiddleware(logger, nil)(CompressionMiddleware(0)(SSEHandler(hub))))
+	defer server.Close()
+	defer hub.Close()
+
+	// Events published before anyone connects are kept for replay
+	for _, id := range []string{"msg1", "msg2", "msg3"} {
+		hub.PublishMessage(&database.Message{ID: id, ChatJID: "111111111@s.whatsapp.net"})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", got)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Expected Cache-Control no-cache, got %q", got)
+	}
+
+	replayed := readSSEEvents(t, resp, 2)
+	if replayed[0].ID != 2 || replayed[1].ID != 3 {
+		t.Fatalf("Expected events 2 and 3 to be replayed, got %d and %d", replayed[0].ID, replayed[1].ID)
+	}
+
+	hub.PublishMessage(&database.Message{ID: "msg4", ChatJID: "111111111@s.whatsapp.net", Content: "live"})
+	live := readSSEEvents(t, resp, 1)[0]
+	data, _ := live.Data.(map[string]interface{})
+	if live.ID != 4 || live.Type != EventNewMessage || data["id"] != "msg4" {
+		t.Errorf("Unexpected live event %+v", live)
+	}
+}
+
+func TestEventHubReplayBuffer(t *testing.T) {
+	hub := NewEventHub()
+	for i := 0; i < eventReplaySize+20; i++ {
+		hub.PublishMessage(&database.Message{ID: "msg", ChatJID: "111111111@s.whatsapp.net"})
+	}
+	hub.PublishMessage(&database.Message{ID: "other", ChatJID: "222222222@s.whatsapp.net"})
+
+	_, replay, ok := hub.subscribe("111111111@s.whatsapp.net", 1)
+	if !ok {
+		t.Fatal("Expected to subscribe")
+	}
+	// The oldest 21 events have been overwritten and the newest is filtered out
+	if len(replay) != eventReplaySize-1 || replay[0].id != 22 {
+		t.Errorf("Expected %d events starting at 22, got %d starting at %d", eventReplaySize-1, len(replay), replay[0].id)
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+)
+
+// handleListStatuses handles GET /statuses
+func (h *Handler) handleListStatuses(w http.ResponseWriter, r *http.Request) {
+	limit, offset, _, err := parseQueryParams(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	statuses, err := h.store.GetStatuses(limit, offset)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get statuses")
+		return
+	}
+
+	writeSuccessResponse(w, "", statuses)
+}
+
+// handleGetContactStatuses handles GET /contacts/{jid}/statuses
+func (h *Handler) handleGetContactStatuses(w http.ResponseWriter, r *http.Request) {
+	jid, err := jidPathValue(r)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	statuses, err := h.store.GetStatusesByAuthor(jid)
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get statuses")
+		return
+	}
+
+	writeSuccessResponse(w, "", statuses)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/webhook"
+)
+
+// RegisterWebhookRequest represents the request body for registering a
+// webhook. A secret is generated when none is given, and an empty Events
+// subscribes to every event.
+type RegisterWebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// RegisterWebhookResponse is a newly registered webhook along with the
+// secret its deliveries are signed with, which is not shown again
+type RegisterWebhookResponse struct {
+	*database.Webhook
+	Secret string `json:"secret"`
+}
+
+// ValidateRegisterWebhookRequest checks that the URL is an absolute HTTP
+// or HTTPS URL and that every event can be subscribed to
+func ValidateRegisterWebhookRequest(req RegisterWebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(webhook.Events, event) {
+			return fmt.Errorf("unknown event: %s", event)
+		}
+	}
+	return nil
+}
+
+// handleListWebhooks handles GET /webhooks
+func (h *Handler) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.store.GetWebhooks()
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to get webhooks")
+		return
+	}
+
+	writeSuccessResponse(w, "", webhooks)
+}
+
+// handleRegisterWebhook handles POST /webhooks
+func (h *Handler) handleRegisterWebhook(w http.ResponseWriter, r *http.Request) {
+	var req RegisterWebhookRequest
+	if err := parseJSONBody(r, &req); err != nil {
+		writeErrorResponse(w, r, jsonBodyStatus(err), err.Error())
+		return
+	}
+	if err := ValidateRegisterWebhookRequest(req); err != nil {
+		writeErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			writeErrorResponse(w, r, http.StatusInternalServerError, "failed to generate webhook secret")
+			return
+		}
+		req.Secret = hex.EncodeToString(secret)
+	}
+
+	hook := &database.Webhook{URL: req.URL, Secret: req.Secret, Events: req.Events}
+	if err := h.store.RegisterWebhook(hook); err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to register webhook")
+		return
+	}
+
+	writeSuccessResponse(w, "Webhook registered", RegisterWebhookResponse{Webhook: hook, Secret: hook.Secret})
+}
+
+// handleDeleteWebhook handles DELETE /webhooks/{id}
+func (h *Handler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	err := h.store.DeleteWebhook(r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErrorResponse(w, r, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, r, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+
+	writeSuccessResponse(w, "Webhook deleted", nil)
+}
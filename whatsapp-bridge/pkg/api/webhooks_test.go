@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookRoutes(t *testing.T) {
+	h := &Handler{store: newTestStore(t)}
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewRouter(h.Routes()).ServeHTTP(rec, httptest.NewRequest(method, "/v1"+path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/webhooks", `{"url":"https://example.com/hook","events":["new_message"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Data struct {
+			ID     string   `json:"id"`
+			Secret string   `json:"secret"`
+			Events []string `json:"events"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.Data.ID == "" || len(created.Data.Secret) != 64 {
+		t.Errorf("Expected an ID and a generated secret, got %+v", created.Data)
+	}
+
+	rec = serve(http.MethodGet, "/webhooks", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, created.Data.ID) || strings.Contains(body, created.Data.Secret) {
+		t.Errorf("Expected the webhook without its secret, got %s", body)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"relative url", http.MethodPost, "/webhooks", `{"url":"/hook"}`, http.StatusBadRequest},
+		{"unsupported scheme", http.MethodPost, "/webhooks", `{"url":"ftp://example.com/hook"}`, http.StatusBadRequest},
+		{"unknown event", http.MethodPost, "/webhooks", `{"url":"https://example.com/hook","events":["typing"]}`, http.StatusBadRequest},
+		{"delete", http.MethodDelete, "/webhooks/" + created.Data.ID, "", http.StatusOK},
+		{"delete again", http.MethodDelete, "/webhooks/" + created.Data.ID, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := serve(tt.method, tt.path, tt.body); rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, rec.Code, rec.Body.String())
+		}
+	}
+}
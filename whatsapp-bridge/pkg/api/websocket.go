@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"whatsapp-client/pkg/validation"
+)
+
+const (
+	// wsPongWait is how long a client may stay silent before it is dropped
+	wsPongWait = 60 * time.Second
+	// wsPingPeriod must be shorter than wsPongWait so pongs arrive in time
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
+// WebSocketHandler upgrades requests to WebSocket connections subscribed to
+// hub. The optional chat_jid query parameter limits events to one chat.
+// Browsers may connect from the same host or from one of allowedOrigins.
+func WebSocketHandler(hub *EventHub, allowedOrigins []string) http.HandlerFunc {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return originAllowed(r, allowedOrigins)
+		},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		chatJID := r.URL.Query().Get("chat_jid")
+		if chatJID != "" {
+			if err := validation.ValidateJID(chatJID); err != nil {
+				writeErrorResponse(w, r, http.StatusBadRequest, "invalid chat_jid: "+err.Error())
+				return
+			}
+		}
+
+		// Upgrade writes its own error response on failure
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		sub, _, ok := hub.subscribe(chatJID, 0)
+		if !ok {
+			conn.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+			conn.Close()
+			return
+		}
+
+		client := &wsClient{conn: conn, sub: sub}
+		go client.writeLoop()
+		go client.readLoop(hub)
+	}
+}
+
+// wsClient is one WebSocket connection and its hub subscription
+type wsClient struct {
+	conn *websocket.Conn
+	sub  *subscriber
+}
+
+// readLoop discards client messages, keeping the read deadline fresh on
+// every pong, until the connection fails or is closed
+func (c *wsClient) readLoop(hub *EventHub) {
+	defer func() {
+		hub.unsubscribe(c.sub)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(512)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writeLoop sends events and periodic pings until the subscription is
+// dropped or a write fails
+func (c *wsClient) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case ev, ok := <-c.sub.events:
+			c.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "subscription closed"))
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, ev.data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// originAllowed accepts requests without an Origin, from the same host, or
+// from an origin in allowed ("*" allows all)
+func originAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(allowed, "*") || slices.Contains(allowed, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
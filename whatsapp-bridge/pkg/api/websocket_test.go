@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"whatsapp-client/pkg/database"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	hub := NewEventHub()
+	server := httptest.NewServer(WebSocketHandler(hub, nil))
+	defer server.Close()
+
+	dial := func(query string) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/messages" + query
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		return conn
+	}
+
+	all := dial("")
+	defer all.Close()
+	filtered := dial("?chat_jid=222222222@s.whatsapp.net")
+	defer filtered.Close()
+
+	// Registration happens after the handshake completes
+	deadline := time.Now().Add(time.Second)
+	for {
+		hub.mu.Lock()
+		n := len(hub.subscribers)
+		hub.mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 2 subscribers, got %d", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	hub.PublishMessage(&database.Message{ID: "msg1", ChatJID: "111111111@s.whatsapp.net", Content: "Hello"})
+
+	var event struct {
+		Type string           `json:"type"`
+		Data database.Message `json:"data"`
+	}
+	all.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := all.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read event: %v", err)
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("Failed to decode event: %v", err)
+	}
+	if event.Type != EventNewMessage || event.Data.ID != "msg1" || event.Data.Content != "Hello" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	// The filtered subscriber only sees its own chat
+	filtered.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, _, err := filtered.ReadMessage(); err == nil {
+		t.Error("Expected no event for a different chat")
+	}
+
+	hub.Close()
+	all.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err = all.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("Expected a going-away close on shutdown, got %v", err)
+	}
+}
+
+func TestWebSocketHandlerRejectsInvalidChatJID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ws/messages?chat_jid=not-a-jid", nil)
+	WebSocketHandler(NewEventHub(), nil).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -1,27 +1,320 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds application configuration
 type Config struct {
-	DatabasePath string
-	APIPort      int
-	StoreDir     string
-	LogLevel     string
+	// ConfigFilePath is the YAML file the configuration was loaded from,
+	// if any
+	ConfigFilePath string `yaml:"-"`
+
+	DatabasePath string `yaml:"database_path"`
+	APIPort      int    `yaml:"api_port"`
+	StoreDir     string `yaml:"store_dir"`
+	LogLevel     string `yaml:"log_level"`
+	// LogFormat selects "json" or "text" request logs
+	LogFormat string `yaml:"log_format"`
+	// ProfilePictureCacheTTL is how long a cached profile picture URL is
+	// served before it is fetched from WhatsApp again
+	ProfilePictureCacheTTL time.Duration `yaml:"profile_picture_cache_ttl"`
+	// HTTP server timeouts in seconds; zero means no timeout
+	HTTPReadTimeoutSeconds  int `yaml:"http_read_timeout_seconds"`
+	HTTPWriteTimeoutSeconds int `yaml:"http_write_timeout_seconds"`
+	HTTPIdleTimeoutSeconds  int `yaml:"http_idle_timeout_seconds"`
+	// TLS certificate and key files; the API is served over HTTPS when both
+	// are set
+	TLSCertFile string `yaml:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file"`
+	// APIKey must be sent as a bearer token on every API request.
+	// Authentication is disabled when it is empty.
+	APIKey string `yaml:"api_key"`
+	// AdminKey must be sent in the X-Admin-Key header to call /admin
+	// endpoints. They are disabled when it is empty.
+	AdminKey string `yaml:"admin_key"`
+	// MetricsEnabled serves Prometheus metrics at /metrics
+	MetricsEnabled bool `yaml:"metrics_enabled"`
+	// ServeOpenAPI serves the OpenAPI description of the API at
+	// /openapi.json
+	ServeOpenAPI bool `yaml:"serve_openapi"`
+	// HealthCheckPath is where the unauthenticated health check is served,
+	// outside the /api prefix
+	HealthCheckPath string `yaml:"health_check_path"`
+	// StrictVersioning rejects requests whose Accept header asks only for
+	// API versions that do not exist with 406 instead of serving them v1
+	StrictVersioning bool `yaml:"strict_versioning"`
+	// MaxRequestBodyBytes caps the size of API request bodies; zero disables it
+	MaxRequestBodyBytes int64 `yaml:"max_request_body_bytes"`
+	// CORSAllowedOrigins lists the browser origins allowed to call the API;
+	// "*" allows any origin
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins"`
+	// MaxImageBytes caps the size of images sent through the API
+	MaxImageBytes int64 `yaml:"max_image_bytes"`
+	// MaxUploadFileSizeBytes caps the size of files sent from a path on
+	// the bridge host
+	MaxUploadFileSizeBytes int64 `yaml:"max_upload_file_size_bytes"`
+	// AllowedUploadDirs are the directories on the bridge host that files
+	// may be sent from
+	AllowedUploadDirs []string `yaml:"allowed_upload_dirs"`
+	// RevokeWindow is how long after sending a message may still be
+	// deleted for everyone
+	RevokeWindow time.Duration `yaml:"revoke_window"`
+	// FFmpegPath is the ffmpeg binary used to convert audio to Ogg Opus
+	FFmpegPath string `yaml:"ffmpeg_path"`
+	// DefaultCountryCode is the ISO 3166-1 region, such as "US", of
+	// recipient phone numbers given without a country code
+	DefaultCountryCode string `yaml:"default_country_code"`
+	// MediaStorageDir is where media downloaded through the API is saved,
+	// in one subdirectory per media type
+	MediaStorageDir string `yaml:"media_storage_dir"`
+	// CompressionMinBytes is the smallest response that is gzipped
+	CompressionMinBytes int `yaml:"compression_min_bytes"`
+	// RateLimit is the per-client-IP request rate limit
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	// Bulk sends: recipients per request and per-client-IP requests per
+	// minute; zero disables the bulk rate limit
+	BulkSendMaxRecipients int     `yaml:"bulk_send_max_recipients"`
+	BulkSendPerMinute     float64 `yaml:"bulk_send_per_minute"`
+	// AsyncWorkers is how many messages queued with POST /messages/async
+	// are sent at once
+	AsyncWorkers int `yaml:"async_workers"`
+	// Background cleanup; zero disables the corresponding job
+	StatusTTL               time.Duration `yaml:"status_ttl"`
+	SoftDeleteRetentionDays int           `yaml:"soft_delete_retention_days"`
+	VacuumInterval          time.Duration `yaml:"vacuum_interval"`
+	WALCheckpointInterval   time.Duration `yaml:"wal_checkpoint_interval"`
 }
 
-// LoadConfig loads configuration from environment variables with defaults
-func LoadConfig() *Config {
+// LoadConfig loads configuration from the YAML file named by
+// WHATSAPP_CONFIG_FILE, if set, and from environment variables, which take
+// precedence over the file. Settings in neither keep their defaults. The
+// configuration is returned along with any error from Validate.
+func LoadConfig() (*Config, error) {
+	base := defaultConfig()
+	configFile := os.Getenv("WHATSAPP_CONFIG_FILE")
+	if configFile != "" {
+		var err error
+		if base, err = LoadConfigFromFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
 	config := &Config{
-		DatabasePath: getEnv("WHATSAPP_DB_PATH", "store/messages.db"),
-		APIPort:      getEnvAsInt("WHATSAPP_API_PORT", 8080),
-		StoreDir:     getEnv("WHATSAPP_STORE_DIR", "store"),
-		LogLevel:     getEnv("WHATSAPP_LOG_LEVEL", "info"),
+		ConfigFilePath: configFile,
+
+		DatabasePath: getEnv("WHATSAPP_DB_PATH", base.DatabasePath),
+		APIPort:      getEnvAsInt("WHATSAPP_API_PORT", base.APIPort),
+		StoreDir:     getEnv("WHATSAPP_STORE_DIR", base.StoreDir),
+		LogLevel:     getEnv("WHATSAPP_LOG_LEVEL", base.LogLevel),
+		LogFormat:    getEnv("WHATSAPP_LOG_FORMAT", base.LogFormat),
+
+		ProfilePictureCacheTTL: getEnvAsDuration("WHATSAPP_PROFILE_PICTURE_CACHE_TTL", base.ProfilePictureCacheTTL),
+
+		HTTPReadTimeoutSeconds:  getEnvAsInt("WHATSAPP_HTTP_READ_TIMEOUT", base.HTTPReadTimeoutSeconds),
+		HTTPWriteTimeoutSeconds: getEnvAsInt("WHATSAPP_HTTP_WRITE_TIMEOUT", base.HTTPWriteTimeoutSeconds),
+		HTTPIdleTimeoutSeconds:  getEnvAsInt("WHATSAPP_HTTP_IDLE_TIMEOUT", base.HTTPIdleTimeoutSeconds),
+
+		TLSCertFile: getEnv("WHATSAPP_TLS_CERT", base.TLSCertFile),
+		TLSKeyFile:  getEnv("WHATSAPP_TLS_KEY", base.TLSKeyFile),
+
+		APIKey:   getEnv("WHATSAPP_API_KEY", base.APIKey),
+		AdminKey: getEnv("WHATSAPP_ADMIN_KEY", base.AdminKey),
+
+		HealthCheckPath: getEnv("WHATSAPP_HEALTH_CHECK_PATH", base.HealthCheckPath),
+		MetricsEnabled:  getEnvAsBool("WHATSAPP_METRICS_ENABLED", base.MetricsEnabled),
+		ServeOpenAPI:    getEnvAsBool("WHATSAPP_SERVE_OPENAPI", base.ServeOpenAPI),
+
+		StrictVersioning: getEnvAsBool("WHATSAPP_STRICT_VERSIONING", base.StrictVersioning),
+
+		MaxRequestBodyBytes: getEnvAsInt64("WHATSAPP_MAX_REQUEST_BODY", base.MaxRequestBodyBytes),
+		MaxImageBytes:       getEnvAsInt64("WHATSAPP_MAX_IMAGE_BYTES", base.MaxImageBytes),
+		RevokeWindow:        getEnvAsDuration("WHATSAPP_REVOKE_WINDOW", base.RevokeWindow),
+		FFmpegPath:          getEnv("WHATSAPP_FFMPEG_PATH", base.FFmpegPath),
+		MediaStorageDir:     getEnv("WHATSAPP_MEDIA_STORAGE_DIR", base.MediaStorageDir),
+		DefaultCountryCode:  getEnv("WHATSAPP_DEFAULT_COUNTRY_CODE", base.DefaultCountryCode),
+		CompressionMinBytes: getEnvAsInt("WHATSAPP_COMPRESSION_MIN_BYTES", base.CompressionMinBytes),
+		CORSAllowedOrigins:  getEnvAsList("WHATSAPP_CORS_ORIGINS", base.CORSAllowedOrigins),
+
+		MaxUploadFileSizeBytes: getEnvAsInt64("WHATSAPP_MAX_UPLOAD_FILE_SIZE", base.MaxUploadFileSizeBytes),
+		AllowedUploadDirs:      getEnvAsList("WHATSAPP_ALLOWED_UPLOAD_DIRS", base.AllowedUploadDirs),
+
+		RateLimit: RateLimitConfig{
+			GlobalRPS:   getEnvAsFloat("WHATSAPP_RATE_LIMIT_RPS", base.RateLimit.GlobalRPS),
+			GlobalBurst: getEnvAsInt("WHATSAPP_RATE_LIMIT_BURST", base.RateLimit.GlobalBurst),
+
+			TrustedProxies: getEnvAsList("WHATSAPP_TRUSTED_PROXIES", base.RateLimit.TrustedProxies),
+		},
+
+		BulkSendMaxRecipients: getEnvAsInt("WHATSAPP_BULK_SEND_MAX_RECIPIENTS", base.BulkSendMaxRecipients),
+		BulkSendPerMinute:     getEnvAsFloat("WHATSAPP_BULK_SEND_PER_MINUTE", base.BulkSendPerMinute),
+
+		AsyncWorkers: getEnvAsInt("WHATSAPP_ASYNC_WORKERS", base.AsyncWorkers),
+
+		StatusTTL:               getEnvAsDuration("WHATSAPP_STATUS_TTL", base.StatusTTL),
+		SoftDeleteRetentionDays: getEnvAsInt("WHATSAPP_SOFT_DELETE_RETENTION_DAYS", base.SoftDeleteRetentionDays),
+		VacuumInterval:          getEnvAsDuration("WHATSAPP_VACUUM_INTERVAL", base.VacuumInterval),
+		WALCheckpointInterval:   getEnvAsDuration("WHATSAPP_WAL_CHECKPOINT_INTERVAL", base.WALCheckpointInterval),
+	}
+
+	endpoints, err := getEnvAsEndpointRateLimits("WHATSAPP_RATE_LIMIT_ENDPOINTS", base.RateLimit.Endpoints)
+	config.RateLimit.Endpoints = endpoints
+	return config, errors.Join(err, config.Validate())
+}
+
+// LoadConfigFromFile loads configuration from a YAML file whose keys are
+// the snake_case names of the Config fields, e.g. api_port. Durations are
+// written like "24h". Settings missing from the file keep their defaults;
+// unknown keys are an error. Environment variables are not consulted.
+func LoadConfigFromFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	config := defaultConfig()
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	config.ConfigFilePath = path
+	return config, nil
+}
+
+// defaultConfig returns the configuration used for settings that are not
+// configured
+func defaultConfig() *Config {
+	return &Config{
+		DatabasePath: "store/messages.db",
+		APIPort:      8080,
+		StoreDir:     "store",
+		LogLevel:     "info",
+		LogFormat:    "text",
+
+		ProfilePictureCacheTTL: 24 * time.Hour,
+
+		HTTPReadTimeoutSeconds:  15,
+		HTTPWriteTimeoutSeconds: 30,
+		HTTPIdleTimeoutSeconds:  120,
+
+		HealthCheckPath: "/health",
+		ServeOpenAPI:    true,
+
+		MaxRequestBodyBytes: 10 << 20,
+		MaxImageBytes:       16 << 20,
+		RevokeWindow:        60 * time.Hour,
+		FFmpegPath:          "ffmpeg",
+		MediaStorageDir:     "store/media",
+		CompressionMinBytes: 1024,
+
+		MaxUploadFileSizeBytes: 64 << 20,
+		AllowedUploadDirs:      []string{os.TempDir(), "uploads"},
+
+		RateLimit: RateLimitConfig{GlobalRPS: 10, GlobalBurst: 20},
+
+		BulkSendMaxRecipients: 50,
+		BulkSendPerMinute:     1,
+
+		AsyncWorkers: 4,
+
+		StatusTTL:               24 * time.Hour,
+		SoftDeleteRetentionDays: 30,
+		WALCheckpointInterval:   15 * time.Minute,
 	}
-	return config
+}
+
+// RateLimitConfig limits how often each client IP may call the API
+type RateLimitConfig struct {
+	// GlobalRPS and GlobalBurst apply to every endpoint without an
+	// override; zero RPS disables the global limit
+	GlobalRPS   float64 `yaml:"global_rps"`
+	GlobalBurst int     `yaml:"global_burst"`
+	// Endpoints overrides the global limit for API paths such as
+	// "/messages/bulk", given without the /api prefix or API version
+	Endpoints map[string]EndpointRateLimit `yaml:"endpoints"`
+	// TrustedProxies lists the IPs and CIDR ranges of reverse proxies whose
+	// X-Forwarded-For header identifies the client. Requests from any other
+	// address are limited by their connection address.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// EndpointRateLimit is the rate limit of a single endpoint. Zero RPS
+// leaves the endpoint unlimited.
+type EndpointRateLimit struct {
+	RPS   float64 `json:"rps" yaml:"rps"`
+	Burst int     `json:"burst" yaml:"burst"`
+}
+
+// logLevels are the accepted values of LogLevel
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// Validate checks the settings that would otherwise only fail once in use,
+// reporting every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	if c.DatabasePath == "" {
+		errs = append(errs, fmt.Errorf("WHATSAPP_DB_PATH must not be empty"))
+	}
+	if c.APIPort < 1 || c.APIPort > 65535 {
+		errs = append(errs, fmt.Errorf("WHATSAPP_API_PORT must be between 1 and 65535, got %d", c.APIPort))
+	}
+	if !slices.Contains(logLevels, strings.ToLower(c.LogLevel)) {
+		errs = append(errs, fmt.Errorf("WHATSAPP_LOG_LEVEL must be one of %s, got %q", strings.Join(logLevels, ", "), c.LogLevel))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, fmt.Errorf("WHATSAPP_TLS_CERT and WHATSAPP_TLS_KEY must be set together"))
+	}
+	if c.MaxRequestBodyBytes < 0 {
+		errs = append(errs, fmt.Errorf("WHATSAPP_MAX_REQUEST_BODY must not be negative, got %d", c.MaxRequestBodyBytes))
+	}
+	if len(c.AllowedUploadDirs) == 0 {
+		errs = append(errs, fmt.Errorf("WHATSAPP_ALLOWED_UPLOAD_DIRS must list at least one directory"))
+	}
+	for _, proxy := range c.RateLimit.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("WHATSAPP_TRUSTED_PROXIES must list IPs or CIDR ranges, got %q", proxy))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TLSEnabled reports whether the API should be served over HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// ValidateTLSConfig checks that, if either TLS file is configured, both are
+// set and readable
+func ValidateTLSConfig(cfg *Config) error {
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return nil
+	}
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return fmt.Errorf("WHATSAPP_TLS_CERT and WHATSAPP_TLS_KEY must be set together")
+	}
+	for _, path := range []string{cfg.TLSCertFile, cfg.TLSKeyFile} {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("TLS file is not readable: %w", err)
+		}
+		f.Close()
+	}
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -31,6 +324,34 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvAsList splits a comma-separated variable, dropping empty entries
+func getEnvAsList(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
+// getEnvAsEndpointRateLimits parses a JSON object mapping API paths to
+// their rate limits, e.g. {"/messages/bulk": {"rps": 0.1, "burst": 1}}
+func getEnvAsEndpointRateLimits(key string, defaultValue map[string]EndpointRateLimit) (map[string]EndpointRateLimit, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	var endpoints map[string]EndpointRateLimit
+	if err := json.Unmarshal([]byte(value), &endpoints); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object of rate limits by path: %w", key, err)
+	}
+	return endpoints, nil
+}
+
 func getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -38,4 +359,40 @@ func getEnvAsInt(key string, defaultValue int) int {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvAsInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
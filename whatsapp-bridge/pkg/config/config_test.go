@@ -0,0 +1,231 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	for _, path := range []string{certPath, keyPath} {
+		if err := os.WriteFile(path, []byte("pem"), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"disabled", Config{}, false},
+		{"both files", Config{TLSCertFile: certPath, TLSKeyFile: keyPath}, false},
+		{"cert only", Config{TLSCertFile: certPath}, true},
+		{"key only", Config{TLSKeyFile: keyPath}, true},
+		{"missing cert", Config{TLSCertFile: filepath.Join(dir, "missing.pem"), TLSKeyFile: keyPath}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTLSConfig(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigAllowedUploadDirs(t *testing.T) {
+	t.Setenv("WHATSAPP_ALLOWED_UPLOAD_DIRS", "")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected the default configuration to be valid, got %v", err)
+	}
+	if want := []string{os.TempDir(), "uploads"}; !slices.Equal(cfg.AllowedUploadDirs, want) {
+		t.Errorf("Expected default upload dirs %v, got %v", want, cfg.AllowedUploadDirs)
+	}
+
+	t.Setenv("WHATSAPP_ALLOWED_UPLOAD_DIRS", "/srv/media, /data/uploads")
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if want := []string{"/srv/media", "/data/uploads"}; !slices.Equal(cfg.AllowedUploadDirs, want) {
+		t.Errorf("Expected upload dirs %v, got %v", want, cfg.AllowedUploadDirs)
+	}
+}
+
+func TestLoadConfigValidates(t *testing.T) {
+	t.Setenv("WHATSAPP_API_PORT", "70000")
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "WHATSAPP_API_PORT") {
+		t.Errorf("Expected LoadConfig to reject the port, got %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := func() Config {
+		return Config{
+			DatabasePath:        "store/messages.db",
+			APIPort:             8080,
+			LogLevel:            "info",
+			MaxRequestBodyBytes: 10 << 20,
+			AllowedUploadDirs:   []string{"uploads"},
+		}
+	}
+
+	allSettings := []string{"WHATSAPP_DB_PATH", "WHATSAPP_API_PORT", "WHATSAPP_LOG_LEVEL", "WHATSAPP_TLS_CERT", "WHATSAPP_MAX_REQUEST_BODY", "WHATSAPP_ALLOWED_UPLOAD_DIRS"}
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"uppercase log level", func(c *Config) { c.LogLevel = "DEBUG" }, nil},
+		{"both TLS files", func(c *Config) { c.TLSCertFile, c.TLSKeyFile = "cert.pem", "key.pem" }, nil},
+		{"body limit disabled", func(c *Config) { c.MaxRequestBodyBytes = 0 }, nil},
+		{"empty database path", func(c *Config) { c.DatabasePath = "" }, []string{"WHATSAPP_DB_PATH"}},
+		{"port zero", func(c *Config) { c.APIPort = 0 }, []string{"WHATSAPP_API_PORT"}},
+		{"negative port", func(c *Config) { c.APIPort = -1 }, []string{"WHATSAPP_API_PORT"}},
+		{"port too high", func(c *Config) { c.APIPort = 65536 }, []string{"WHATSAPP_API_PORT"}},
+		{"unknown log level", func(c *Config) { c.LogLevel = "verbose" }, []string{"WHATSAPP_LOG_LEVEL"}},
+		{"empty log level", func(c *Config) { c.LogLevel = "" }, []string{"WHATSAPP_LOG_LEVEL"}},
+		{"cert only", func(c *Config) { c.TLSCertFile = "cert.pem" }, []string{"WHATSAPP_TLS_CERT"}},
+		{"key only", func(c *Config) { c.TLSKeyFile = "key.pem" }, []string{"WHATSAPP_TLS_KEY"}},
+		{"negative body limit", func(c *Config) { c.MaxRequestBodyBytes = -1 }, []string{"WHATSAPP_MAX_REQUEST_BODY"}},
+		{"no upload dirs", func(c *Config) { c.AllowedUploadDirs = nil }, []string{"WHATSAPP_ALLOWED_UPLOAD_DIRS"}},
+		{"everything invalid", func(c *Config) {
+			*c = Config{APIPort: 65536, LogLevel: "loud", TLSKeyFile: "key.pem", MaxRequestBodyBytes: -1}
+		}, allSettings},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if (err != nil) != (len(tt.want) > 0) {
+				t.Fatalf("Validate() error = %v, want errors about %v", err, tt.want)
+			}
+			for _, setting := range tt.want {
+				if !strings.Contains(err.Error(), setting) {
+					t.Errorf("Expected an error about %s, got %v", setting, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigRateLimitEndpoints(t *testing.T) {
+	t.Setenv("WHATSAPP_RATE_LIMIT_ENDPOINTS", `{"/messages/bulk": {"rps": 0.1, "burst": 2}}`)
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got, want := cfg.RateLimit.Endpoints["/messages/bulk"], (EndpointRateLimit{RPS: 0.1, Burst: 2}); got != want {
+		t.Errorf("Expected bulk limit %+v, got %+v", want, got)
+	}
+
+	t.Setenv("WHATSAPP_RATE_LIMIT_ENDPOINTS", `{"/messages/bulk": 1}`)
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "WHATSAPP_RATE_LIMIT_ENDPOINTS") {
+		t.Errorf("Expected invalid JSON to be rejected, got %v", err)
+	}
+}
+
+func TestLoadConfigTrustedProxies(t *testing.T) {
+	t.Setenv("WHATSAPP_TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if want := []string{"10.0.0.0/8", "192.0.2.1"}; !slices.Equal(cfg.RateLimit.TrustedProxies, want) {
+		t.Errorf("Expected trusted proxies %v, got %v", want, cfg.RateLimit.TrustedProxies)
+	}
+
+	t.Setenv("WHATSAPP_TRUSTED_PROXIES", "proxy.internal")
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "WHATSAPP_TRUSTED_PROXIES") {
+		t.Errorf("Expected a hostname to be rejected, got %v", err)
+	}
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := `database_path: /data/messages.db
+api_port: 9090
+log_level: debug
+tls_cert_file: /etc/tls/cert.pem
+tls_key_file: /etc/tls/key.pem
+metrics_enabled: true
+serve_openapi: false
+cors_allowed_origins:
+  - https://app.example.com
+allowed_upload_dirs: [/srv/uploads]
+revoke_window: 2h
+rate_limit:
+  global_rps: 5
+  endpoints:
+    /messages/bulk: {rps: 0.5, burst: 1}
+`
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load config file: %v", err)
+	}
+	if cfg.DatabasePath != "/data/messages.db" || cfg.APIPort != 9090 || cfg.LogLevel != "debug" {
+		t.Errorf("Expected database, port and log level from the file, got %q, %d, %q", cfg.DatabasePath, cfg.APIPort, cfg.LogLevel)
+	}
+	if !cfg.TLSEnabled() || !cfg.MetricsEnabled || cfg.ServeOpenAPI {
+		t.Errorf("Expected TLS and metrics enabled and the OpenAPI spec disabled, got %+v", cfg)
+	}
+	if !slices.Equal(cfg.CORSAllowedOrigins, []string{"https://app.example.com"}) || !slices.Equal(cfg.AllowedUploadDirs, []string{"/srv/uploads"}) {
+		t.Errorf("Expected lists from the file, got %v and %v", cfg.CORSAllowedOrigins, cfg.AllowedUploadDirs)
+	}
+	if cfg.RevokeWindow != 2*time.Hour {
+		t.Errorf("Expected a revoke window of 2h, got %v", cfg.RevokeWindow)
+	}
+	if cfg.RateLimit.GlobalRPS != 5 || cfg.RateLimit.Endpoints["/messages/bulk"] != (EndpointRateLimit{RPS: 0.5, Burst: 1}) {
+		t.Errorf("Expected rate limits from the file, got %+v", cfg.RateLimit)
+	}
+	// Settings missing from the file keep their defaults
+	if cfg.StoreDir != "store" || cfg.RateLimit.GlobalBurst != 20 {
+		t.Errorf("Expected defaults for unset settings, got store %q and burst %d", cfg.StoreDir, cfg.RateLimit.GlobalBurst)
+	}
+	if cfg.ConfigFilePath != path {
+		t.Errorf("Expected ConfigFilePath %q, got %q", path, cfg.ConfigFilePath)
+	}
+
+	// Environment variables take precedence over the file
+	t.Setenv("WHATSAPP_CONFIG_FILE", path)
+	t.Setenv("WHATSAPP_API_PORT", "7070")
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.APIPort != 7070 {
+		t.Errorf("Expected the port from the environment, got %d", cfg.APIPort)
+	}
+	if cfg.DatabasePath != "/data/messages.db" || cfg.TLSCertFile != "/etc/tls/cert.pem" {
+		t.Errorf("Expected unset variables to keep the file's values, got %q and %q", cfg.DatabasePath, cfg.TLSCertFile)
+	}
+
+	if err := os.WriteFile(path, []byte("api_prot: 9090\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if _, err := LoadConfigFromFile(path); err == nil {
+		t.Error("Expected an unknown key to be rejected")
+	}
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected LoadConfig to fail on an invalid config file")
+	}
+	if _, err := LoadConfigFromFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// mediaSubdirs maps a message's media type to the subdirectory of
+// MediaStorageDir its attachment is saved in. Other types are saved with
+// the documents.
+var mediaSubdirs = map[string]string{
+	"image":    "images",
+	"sticker":  "images",
+	"video":    "videos",
+	"audio":    "audio",
+	"document": "documents",
+}
+
+// InitMediaDir creates MediaStorageDir and its subdirectory per media type,
+// readable only by the bridge's user and group. It fails if the directory
+// already exists and can be read by anyone else.
+func (c *Config) InitMediaDir() error {
+	for _, subdir := range mediaSubdirs {
+		if err := os.MkdirAll(filepath.Join(c.MediaStorageDir, subdir), 0750); err != nil {
+			return fmt.Errorf("failed to create media directory: %w", err)
+		}
+	}
+
+	info, err := os.Stat(c.MediaStorageDir)
+	if err != nil {
+		return fmt.Errorf("failed to check media directory: %w", err)
+	}
+	if info.Mode().Perm()&0007 != 0 {
+		return fmt.Errorf("media directory %s must not be accessible to other users, has mode %v", c.MediaStorageDir, info.Mode().Perm())
+	}
+	return nil
+}
+
+// MediaPathFor returns where the attachment of a message is saved: in the
+// subdirectory for its media type, named after the message ID. The ID comes
+// from the sender, so only its last element is used.
+func (c *Config) MediaPathFor(msgID, mediaType string) string {
+	subdir, ok := mediaSubdirs[mediaType]
+	if !ok {
+		subdir = mediaSubdirs["document"]
+	}
+	name := filepath.Base(msgID)
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		name = "media"
+	}
+	return filepath.Join(c.MediaStorageDir, subdir, name)
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitMediaDir(t *testing.T) {
+	cfg := &Config{MediaStorageDir: filepath.Join(t.TempDir(), "store", "media")}
+	if err := cfg.InitMediaDir(); err != nil {
+		t.Fatalf("Failed to create media directory: %v", err)
+	}
+
+	for _, subdir := range []string{"", "images", "videos", "audio", "documents"} {
+		info, err := os.Stat(filepath.Join(cfg.MediaStorageDir, subdir))
+		if err != nil {
+			t.Errorf("Expected %q to be created: %v", subdir, err)
+			continue
+		}
+		if !info.IsDir() {
+			t.Errorf("Expected %q to be a directory", subdir)
+		}
+		if perm := info.Mode().Perm(); perm&0007 != 0 {
+			t.Errorf("Expected %q not to be accessible to other users, got mode %v", subdir, perm)
+		}
+	}
+
+	// Creating it again is fine
+	if err := cfg.InitMediaDir(); err != nil {
+		t.Errorf("Expected an existing media directory to be accepted: %v", err)
+	}
+
+	if err := os.Chmod(cfg.MediaStorageDir, 0755); err != nil {
+		t.Fatalf("Failed to change mode: %v", err)
+	}
+	if err := cfg.InitMediaDir(); err == nil {
+		t.Error("Expected a world-readable media directory to be rejected")
+	}
+}
+
+func TestMediaPathFor(t *testing.T) {
+	cfg := &Config{MediaStorageDir: "store/media"}
+	tests := []struct {
+		msgID     string
+		mediaType string
+		want      string
+	}{
+		{"3EB0ABC", "image", "store/media/images/3EB0ABC"},
+		{"3EB0ABC", "sticker", "store/media/images/3EB0ABC"},
+		{"3EB0ABC", "video", "store/media/videos/3EB0ABC"},
+		{"3EB0ABC", "audio", "store/media/audio/3EB0ABC"},
+		{"3EB0ABC", "document", "store/media/documents/3EB0ABC"},
+		{"3EB0ABC", "", "store/media/documents/3EB0ABC"},
+		{"../../etc/passwd", "image", "store/media/images/passwd"},
+		{"..", "image", "store/media/images/media"},
+	}
+	for _, tt := range tests {
+		if got := cfg.MediaPathFor(tt.msgID, tt.mediaType); got != filepath.FromSlash(tt.want) {
+			t.Errorf("MediaPathFor(%q, %q) = %q, want %q", tt.msgID, tt.mediaType, got, tt.want)
+		}
+	}
+}
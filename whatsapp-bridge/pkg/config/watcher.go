@@ -0,0 +1,109 @@
+package config
+
+import (
+	"context"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"syscall"
+)
+
+// ConfigWatcher loads the configuration again whenever the process receives
+// SIGHUP. Only the log level, CORS origins and rate limits take effect
+// without a restart; changes to any other setting are logged and ignored.
+type ConfigWatcher struct {
+	current *Config
+	signals chan os.Signal
+}
+
+// NewConfigWatcher starts listening for SIGHUP, with cfg as the
+// configuration in effect. Call Watch to act on the signals.
+func NewConfigWatcher(cfg *Config) *ConfigWatcher {
+	w := &ConfigWatcher{
+		current: cfg.Clone(),
+		signals: make(chan os.Signal, 1),
+	}
+	signal.Notify(w.signals, syscall.SIGHUP)
+	return w
+}
+
+// Watch reloads the configuration on each SIGHUP until ctx is done, calling
+// onChange with the configuration now in effect whenever a mutable setting
+// has changed. A configuration that fails to load is logged and the current
+// one kept.
+func (w *ConfigWatcher) Watch(ctx context.Context, onChange func(*Config)) {
+	defer signal.Stop(w.signals)
+	for {
+		select {
+		case <-w.signals:
+			next, err := LoadConfig()
+			if err != nil {
+				log.Printf("Failed to reload configuration, keeping the current one: %v", err)
+				continue
+			}
+			if w.apply(next) {
+				onChange(w.current.Clone())
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// apply copies the mutable settings of next into the current configuration,
+// logging any other setting that differs, and reports whether a mutable
+// setting changed
+func (w *ConfigWatcher) apply(next *Config) bool {
+	for _, name := range ignoredChanges(w.current, next) {
+		log.Printf("Ignoring change to %s, which takes effect after a restart", name)
+	}
+
+	changed := w.current.LogLevel != next.LogLevel ||
+		!slices.Equal(w.current.CORSAllowedOrigins, next.CORSAllowedOrigins) ||
+		!reflect.DeepEqual(w.current.RateLimit, next.RateLimit)
+	if changed {
+		w.current.LogLevel = next.LogLevel
+		w.current.CORSAllowedOrigins = slices.Clone(next.CORSAllowedOrigins)
+		w.current.RateLimit = next.RateLimit.clone()
+	}
+	return changed
+}
+
+// mutableSettings are the fields apply takes from a reloaded configuration
+var mutableSettings = []string{"LogLevel", "CORSAllowedOrigins", "RateLimit"}
+
+// ignoredChanges names the settings other than the mutable ones that differ
+// between two configurations
+func ignoredChanges(current, next *Config) []string {
+	var names []string
+	a, b := reflect.ValueOf(*current), reflect.ValueOf(*next)
+	for i := range a.NumField() {
+		name := a.Type().Field(i).Name
+		if slices.Contains(mutableSettings, name) {
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Clone returns a copy of the configuration that shares no lists or maps
+// with it
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.CORSAllowedOrigins = slices.Clone(c.CORSAllowedOrigins)
+	clone.AllowedUploadDirs = slices.Clone(c.AllowedUploadDirs)
+	clone.RateLimit = c.RateLimit.clone()
+	return &clone
+}
+
+func (r RateLimitConfig) clone() RateLimitConfig {
+	r.Endpoints = maps.Clone(r.Endpoints)
+	r.TrustedProxies = slices.Clone(r.TrustedProxies)
+	return r
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"os"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcherReloadsOnSIGHUP(t *testing.T) {
+	t.Setenv("WHATSAPP_LOG_LEVEL", "info")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan *Config, 1)
+	w := NewConfigWatcher(cfg)
+	go w.Watch(ctx, func(c *Config) { changes <- c })
+
+	t.Setenv("WHATSAPP_LOG_LEVEL", "debug")
+	t.Setenv("WHATSAPP_CORS_ORIGINS", "https://app.example.com")
+	t.Setenv("WHATSAPP_RATE_LIMIT_RPS", "2")
+	t.Setenv("WHATSAPP_API_PORT", "9090")
+	t.Setenv("WHATSAPP_DB_PATH", "other.db")
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find own process: %v", err)
+	}
+	if err := self.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+
+	select {
+	case got := <-changes:
+		if got.LogLevel != "debug" || !slices.Equal(got.CORSAllowedOrigins, []string{"https://app.example.com"}) || got.RateLimit.GlobalRPS != 2 {
+			t.Errorf("Expected the new log level, origins and rate limit, got %q, %v and %v", got.LogLevel, got.CORSAllowedOrigins, got.RateLimit.GlobalRPS)
+		}
+		if got.APIPort != cfg.APIPort || got.DatabasePath != cfg.DatabasePath {
+			t.Errorf("Expected the port and database path to be kept, got %d and %q", got.APIPort, got.DatabasePath)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected onChange to be called after SIGHUP")
+	}
+
+	// Reloading an unchanged configuration is not a change
+	if err := self.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+	select {
+	case got := <-changes:
+		t.Errorf("Expected no change, got %+v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestConfigClone(t *testing.T) {
+	cfg := &Config{
+		CORSAllowedOrigins: []string{"https://app.example.com"},
+		AllowedUploadDirs:  []string{"uploads"},
+		RateLimit: RateLimitConfig{
+			Endpoints: map[string]EndpointRateLimit{"/messages/bulk": {RPS: 1, Burst: 1}},
+		},
+	}
+	clone := cfg.Clone()
+	clone.CORSAllowedOrigins[0] = "*"
+	clone.AllowedUploadDirs[0] = "/"
+	clone.RateLimit.Endpoints["/messages/bulk"] = EndpointRateLimit{}
+
+	if cfg.CORSAllowedOrigins[0] != "https://app.example.com" || cfg.AllowedUploadDirs[0] != "uploads" {
+		t.Errorf("Expected the original lists to be unchanged, got %v and %v", cfg.CORSAllowedOrigins, cfg.AllowedUploadDirs)
+	}
+	if cfg.RateLimit.Endpoints["/messages/bulk"].RPS != 1 {
+		t.Errorf("Expected the original endpoint limits to be unchanged, got %v", cfg.RateLimit.Endpoints)
+	}
+}
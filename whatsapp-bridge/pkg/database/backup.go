@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-sqlite3"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// ErrInvalidBackupDestination is returned by Backup when destPath cannot be
+// used as a backup target
+var ErrInvalidBackupDestination = errors.New("invalid backup destination")
+
+// Backup writes a consistent copy of the live database to destPath using
+// SQLite's online backup API, so it is safe while other connections keep
+// writing. The destination directory must exist and the file must not, which
+// also keeps a backup from overwriting the live database. It fails with
+// ErrMaintenanceInProgress while a Vacuum is running.
+func (s *Store) Backup(destPath string) (err error) {
+	if !s.maintenanceMu.TryLock() {
+		return ErrMaintenanceInProgress
+	}
+	defer s.maintenanceMu.Unlock()
+
+	if err := validation.ValidateFilePath(filepath.Dir(destPath), nil); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBackupDestination, err)
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("%w: %s already exists", ErrInvalidBackupDestination, destPath)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrInvalidBackupDestination, err)
+	}
+
+	dest, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup destination: %w", err)
+	}
+	defer func() {
+		if closeErr := dest.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(destPath)
+		}
+	}()
+
+	ctx := context.Background()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open backup destination: %w", err)
+	}
+	defer destConn.Close()
+
+	srcConn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destRaw interface{}) error {
+		return srcConn.Raw(func(srcRaw interface{}) error {
+			destSQLite, ok := destRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected destination connection type %T", destRaw)
+			}
+			srcSQLite, ok := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected source connection type %T", srcRaw)
+			}
+
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return fmt.Errorf("failed to start backup: %w", err)
+			}
+
+			// Copy every page in one step
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return fmt.Errorf("backup failed: %w", err)
+			}
+
+			return backup.Finish()
+		})
+	})
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackup(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	if err := store.BulkStoreMessages(testMessages(chat.JID, 25)); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "backup.db")
+	if err := store.Backup(destPath); err != nil {
+		t.Fatalf("Failed to back up database: %v", err)
+	}
+
+	backup, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	defer backup.Close()
+
+	var integrity string
+	if err := backup.QueryRow("PRAGMA integrity_check").Scan(&integrity); err != nil || integrity != "ok" {
+		t.Fatalf("Expected a valid SQLite database, got %q, %v", integrity, err)
+	}
+
+	for _, table := range []string{"chats", "messages", "migrations"} {
+		var want, got int
+		store.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&want)
+		if err := backup.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&got); err != nil {
+			t.Fatalf("Failed to count %s in backup: %v", table, err)
+		}
+		if got != want {
+			t.Errorf("Expected %d rows in %s, got %d", want, table, got)
+		}
+	}
+
+	// An existing file is never overwritten
+	if err := store.Backup(destPath); !errors.Is(err, ErrInvalidBackupDestination) {
+		t.Errorf("Expected ErrInvalidBackupDestination backing up onto an existing file, got %v", err)
+	}
+	if err := store.Backup(filepath.Join(t.TempDir(), "missing", "backup.db")); !errors.Is(err, ErrInvalidBackupDestination) {
+		t.Errorf("Expected ErrInvalidBackupDestination for a missing directory, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"time"
+)
+
+// BlockContact records a contact as blocked. Blocking a contact again keeps
+// the original block time.
+func (s *Store) BlockContact(jid string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO blocked_contacts (jid, blocked_at) VALUES (?, ?)
+		ON CONFLICT (jid) DO NOTHING`,
+		jid, time.Now().UTC(),
+	)
+	return err
+}
+
+// UnblockContact removes a contact's block. It returns sql.ErrNoRows if the
+// contact is not blocked.
+func (s *Store) UnblockContact(jid string) error {
+	result, err := s.db.Exec("DELETE FROM blocked_contacts WHERE jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// IsBlocked reports whether a contact is blocked
+func (s *Store) IsBlocked(jid string) (bool, error) {
+	var blocked bool
+	err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM blocked_contacts WHERE jid = ?)", jid).Scan(&blocked)
+	return blocked, err
+}
+
+// GetBlockedContacts retrieves the blocked contacts, most recently blocked
+// first. A blocked JID with no stored contact is returned with only its JID
+// set.
+func (s *Store) GetBlockedContacts() ([]*Contact, error) {
+	rows, err := s.db.Query(`
+		SELECT b.jid, COALESCE(c.phone, ''), COALESCE(c.display_name, ''), COALESCE(c.push_name, ''),
+			COALESCE(c.business_name, ''), COALESCE(c.about, ''), COALESCE(c.country_code, ''), c.last_sync
+		FROM blocked_contacts b
+		LEFT JOIN contacts c ON c.jid = b.jid
+		ORDER BY b.blocked_at DESC, b.jid`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanContacts(rows)
+}
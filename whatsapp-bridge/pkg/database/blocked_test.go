@@ -0,0 +1,55 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestBlockedContacts(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	alice := "111111111@s.whatsapp.net"
+	bob := "222222222@s.whatsapp.net"
+	if err := store.StoreContact(&Contact{JID: alice, PushName: "Alice"}); err != nil {
+		t.Fatalf("Failed to store contact: %v", err)
+	}
+
+	for _, jid := range []string{alice, bob, alice} {
+		if err := store.BlockContact(jid); err != nil {
+			t.Fatalf("Failed to block %s: %v", jid, err)
+		}
+	}
+	if blocked, err := store.IsBlocked(alice); err != nil || !blocked {
+		t.Errorf("Expected alice to be blocked, got %v (err %v)", blocked, err)
+	}
+
+	contacts, err := store.GetBlockedContacts()
+	if err != nil {
+		t.Fatalf("Failed to get blocked contacts: %v", err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("Expected 2 blocked contacts, got %d", len(contacts))
+	}
+	byJID := map[string]*Contact{}
+	for _, c := range contacts {
+		byJID[c.JID] = c
+	}
+	if byJID[alice] == nil || byJID[alice].PushName != "Alice" {
+		t.Errorf("Expected alice with her stored name, got %+v", byJID[alice])
+	}
+	if byJID[bob] == nil || byJID[bob].PushName != "" {
+		t.Errorf("Expected bob with only his JID, got %+v", byJID[bob])
+	}
+
+	if err := store.UnblockContact(alice); err != nil {
+		t.Fatalf("Failed to unblock alice: %v", err)
+	}
+	if blocked, _ := store.IsBlocked(alice); blocked {
+		t.Error("Expected alice to be unblocked")
+	}
+	if err := store.UnblockContact(alice); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows unblocking twice, got %v", err)
+	}
+}
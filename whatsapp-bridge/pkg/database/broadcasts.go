@@ -0,0 +1,119 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// StoreBroadcastList inserts a broadcast list or renames an existing one.
+// A zero CreatedAt is recorded as now; the creation time of an existing list
+// is kept.
+func (s *Store) StoreBroadcastList(list *BroadcastList) error {
+	if list.CreatedAt.IsZero() {
+		list.CreatedAt = time.Now()
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO broadcast_lists (jid, name, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (jid) DO UPDATE SET name = excluded.name`,
+		list.JID, list.Name, list.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// GetBroadcastList retrieves a single broadcast list. It returns
+// sql.ErrNoRows if the list does not exist.
+func (s *Store) GetBroadcastList(jid string) (*BroadcastList, error) {
+	rows, err := s.db.Query("SELECT jid, COALESCE(name, ''), created_at FROM broadcast_lists WHERE jid = ?", jid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists, err := scanBroadcastLists(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return lists[0], nil
+}
+
+// GetBroadcastLists retrieves all broadcast lists ordered by name
+func (s *Store) GetBroadcastLists() ([]*BroadcastList, error) {
+	rows, err := s.db.Query("SELECT jid, COALESCE(name, ''), created_at FROM broadcast_lists ORDER BY name, jid")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanBroadcastLists(rows)
+}
+
+// AddBroadcastRecipient adds a recipient to a broadcast list. Adding an
+// existing recipient is a no-op. It returns sql.ErrNoRows if the list does
+// not exist.
+func (s *Store) AddBroadcastRecipient(listJID, recipientJID string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO broadcast_recipients (list_jid, recipient_jid) VALUES (?, ?)
+		ON CONFLICT (list_jid, recipient_jid) DO NOTHING`,
+		listJID, recipientJID,
+	)
+	if isForeignKeyViolation(err) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// RemoveBroadcastRecipient removes a recipient from a broadcast list. It
+// returns sql.ErrNoRows if the recipient is not on the list.
+func (s *Store) RemoveBroadcastRecipient(listJID, recipientJID string) error {
+	result, err := s.db.Exec(
+		"DELETE FROM broadcast_recipients WHERE list_jid = ? AND recipient_jid = ?",
+		listJID, recipientJID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetBroadcastRecipients retrieves the recipient JIDs of a broadcast list
+func (s *Store) GetBroadcastRecipients(listJID string) ([]string, error) {
+	rows, err := s.db.Query(
+		"SELECT recipient_jid FROM broadcast_recipients WHERE list_jid = ? ORDER BY recipient_jid",
+		listJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := []string{}
+	for rows.Next() {
+		var jid string
+		if err := rows.Scan(&jid); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, jid)
+	}
+
+	return recipients, rows.Err()
+}
+
+// scanBroadcastLists reads jid, name and created_at rows
+func scanBroadcastLists(rows *sql.Rows) ([]*BroadcastList, error) {
+	lists := []*BroadcastList{}
+	for rows.Next() {
+		l := &BroadcastList{}
+		if err := rows.Scan(&l.JID, &l.Name, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+
+	return lists, rows.Err()
+}
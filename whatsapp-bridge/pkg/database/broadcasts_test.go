@@ -0,0 +1,65 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBroadcastLists(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	createdAt := time.Now().Add(-time.Hour)
+	list := &BroadcastList{JID: "1234567890@broadcast", Name: "Family", CreatedAt: createdAt}
+	if err := store.StoreBroadcastList(list); err != nil {
+		t.Fatalf("Failed to store broadcast list: %v", err)
+	}
+
+	// Renaming keeps the creation time
+	if err := store.StoreBroadcastList(&BroadcastList{JID: list.JID, Name: "Relatives"}); err != nil {
+		t.Fatalf("Failed to rename broadcast list: %v", err)
+	}
+	got, err := store.GetBroadcastList(list.JID)
+	if err != nil {
+		t.Fatalf("Failed to get broadcast list: %v", err)
+	}
+	if got.Name != "Relatives" || !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected renamed list created at %v, got %+v", createdAt, got)
+	}
+
+	alice := "111111111@s.whatsapp.net"
+	bob := "222222222@s.whatsapp.net"
+	for _, jid := range []string{bob, alice, alice} {
+		if err := store.AddBroadcastRecipient(list.JID, jid); err != nil {
+			t.Fatalf("Failed to add recipient: %v", err)
+		}
+	}
+
+	recipients, err := store.GetBroadcastRecipients(list.JID)
+	if err != nil {
+		t.Fatalf("Failed to get recipients: %v", err)
+	}
+	if len(recipients) != 2 || recipients[0] != alice || recipients[1] != bob {
+		t.Errorf("Expected [%s %s], got %v", alice, bob, recipients)
+	}
+
+	if err := store.RemoveBroadcastRecipient(list.JID, bob); err != nil {
+		t.Fatalf("Failed to remove recipient: %v", err)
+	}
+	if err := store.RemoveBroadcastRecipient(list.JID, bob); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows removing an absent recipient, got %v", err)
+	}
+	if err := store.AddBroadcastRecipient("missing@broadcast", alice); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing list, got %v", err)
+	}
+
+	lists, err := store.GetBroadcastLists()
+	if err != nil {
+		t.Fatalf("Failed to get broadcast lists: %v", err)
+	}
+	if len(lists) != 1 {
+		t.Errorf("Expected 1 broadcast list, got %d", len(lists))
+	}
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// callLogColumns lists the call_logs columns in the order scanCallLogs expects
+const callLogColumns = "id, chat_jid, caller_jid, call_type, call_status, started_at, duration_seconds"
+
+// StoreCallLog inserts or updates a call log entry
+func (s *Store) StoreCallLog(call *CallLog) error {
+	if !call.CallType.IsValid() {
+		return fmt.Errorf("invalid call type: %s", call.CallType)
+	}
+	if !call.CallStatus.IsValid() {
+		return fmt.Errorf("invalid call status: %s", call.CallStatus)
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO call_logs (`+callLogColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			chat_jid = excluded.chat_jid, caller_jid = excluded.caller_jid, call_type = excluded.call_type,
+			call_status = excluded.call_status, started_at = excluded.started_at, duration_seconds = excluded.duration_seconds`,
+		call.ID, call.ChatJID, call.CallerJID, call.CallType, call.CallStatus, call.StartedAt.UTC(), call.DurationSeconds,
+	)
+	return err
+}
+
+// GetCallLogs retrieves calls with pagination, newest first. When chatJID is
+// set only calls in that chat are returned.
+func (s *Store) GetCallLogs(chatJID string, limit, offset int) ([]*CallLog, error) {
+	rows, err := s.db.Query(`
+		SELECT `+callLogColumns+`
+		FROM call_logs
+		WHERE ? = '' OR chat_jid = ?
+		ORDER BY started_at DESC
+		LIMIT ? OFFSET ?`,
+		chatJID, chatJID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCallLogs(rows)
+}
+
+// GetMissedCalls retrieves the most recent missed calls, newest first
+func (s *Store) GetMissedCalls(limit int) ([]*CallLog, error) {
+	rows, err := s.db.Query(`
+		SELECT `+callLogColumns+`
+		FROM call_logs
+		WHERE call_status = ?
+		ORDER BY started_at DESC
+		LIMIT ?`,
+		CallStatusMissed, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCallLogs(rows)
+}
+
+// GetCallLogByID retrieves a single call. It returns sql.ErrNoRows if the
+// call does not exist.
+func (s *Store) GetCallLogByID(id string) (*CallLog, error) {
+	rows, err := s.db.Query("SELECT "+callLogColumns+" FROM call_logs WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calls, err := scanCallLogs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(calls) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return calls[0], nil
+}
+
+// scanCallLogs reads rows selected with callLogColumns
+func scanCallLogs(rows *sql.Rows) ([]*CallLog, error) {
+	calls := []*CallLog{}
+	for rows.Next() {
+		c := &CallLog{}
+		err := rows.Scan(&c.ID, &c.ChatJID, &c.CallerJID, &c.CallType, &c.CallStatus, &c.StartedAt, &c.DurationSeconds)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, c)
+	}
+
+	return calls, rows.Err()
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCallLogs(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	me := "100000000@s.whatsapp.net"
+	alice := "111111111@s.whatsapp.net"
+	bob := "222222222@s.whatsapp.net"
+	start := time.Now().Add(-time.Hour)
+
+	calls := []*CallLog{
+		{ID: "call1", ChatJID: alice, CallerJID: alice, CallType: CallTypeVoice, CallStatus: CallStatusMissed, StartedAt: start},
+		{ID: "call2", ChatJID: bob, CallerJID: me, CallType: CallTypeVideo, CallStatus: CallStatusAnswered, StartedAt: start.Add(time.Minute), DurationSeconds: 90},
+		{ID: "call3", ChatJID: bob, CallerJID: bob, CallType: CallTypeVoice, CallStatus: CallStatusDeclined, StartedAt: start.Add(2 * time.Minute)},
+	}
+	for _, call := range calls {
+		if err := store.StoreCallLog(call); err != nil {
+			t.Fatalf("Failed to store call log: %v", err)
+		}
+	}
+
+	// Updating a call replaces its entry
+	calls[2].DurationSeconds = 30
+	if err := store.StoreCallLog(calls[2]); err != nil {
+		t.Fatalf("Failed to update call log: %v", err)
+	}
+
+	all, err := store.GetCallLogs("", 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get call logs: %v", err)
+	}
+	if len(all) != 3 || all[0].ID != "call3" || all[0].DurationSeconds != 30 {
+		t.Errorf("Expected 3 calls newest first with updated duration, got %+v", all)
+	}
+
+	withBob, err := store.GetCallLogs(bob, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get call logs: %v", err)
+	}
+	if len(withBob) != 2 {
+		t.Errorf("Expected 2 calls with bob, got %d", len(withBob))
+	}
+
+	call, err := store.GetCallLogByID("call2")
+	if err != nil {
+		t.Fatalf("Failed to get call log: %v", err)
+	}
+	if call.CallType != CallTypeVideo || call.CallStatus != CallStatusAnswered || call.CallerJID != me {
+		t.Errorf("Unexpected call %+v", call)
+	}
+
+	if _, err := store.GetCallLogByID("missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing call, got %v", err)
+	}
+
+	invalid := &CallLog{ID: "call4", CallType: "fax", CallStatus: CallStatusMissed}
+	if err := store.StoreCallLog(invalid); err == nil {
+		t.Errorf("Expected error for invalid call type")
+	}
+	invalid = &CallLog{ID: "call4", CallType: CallTypeVoice, CallStatus: "incoming"}
+	if err := store.StoreCallLog(invalid); err == nil {
+		t.Errorf("Expected error for invalid call status")
+	}
+}
+
+func TestGetMissedCalls(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	alice := "111111111@s.whatsapp.net"
+	start := time.Now().Add(-time.Hour)
+	for i, status := range []CallStatus{CallStatusMissed, CallStatusAnswered, CallStatusMissed, CallStatusMissed, CallStatusDeclined} {
+		call := &CallLog{ID: fmt.Sprintf("call%d", i), ChatJID: alice, CallerJID: alice, CallType: CallTypeVoice, CallStatus: status, StartedAt: start.Add(time.Duration(i) * time.Minute)}
+		if err := store.StoreCallLog(call); err != nil {
+			t.Fatalf("Failed to store call log: %v", err)
+		}
+	}
+
+	missed, err := store.GetMissedCalls(2)
+	if err != nil {
+		t.Fatalf("Failed to get missed calls: %v", err)
+	}
+	if len(missed) != 2 || missed[0].ID != "call3" || missed[1].ID != "call2" {
+		t.Errorf("Expected the two newest missed calls, got %+v", missed)
+	}
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// contactColumns lists the contacts columns in the order scanContacts expects
+const contactColumns = `jid, COALESCE(phone, ''), COALESCE(display_name, ''), COALESCE(push_name, ''),
+	COALESCE(business_name, ''), COALESCE(about, ''), COALESCE(country_code, ''), last_sync`
+
+// StoreContact inserts or updates a contact. A zero LastSync is recorded as
+// now. CountryCode is derived from the phone number, or the JID when there
+// is none, and left empty if neither is a valid international number.
+func (s *Store) StoreContact(c *Contact) error {
+	lastSync := c.LastSync
+	if lastSync.IsZero() {
+		lastSync = time.Now()
+	}
+	c.CountryCode = contactCountryCode(c.JID, c.Phone)
+
+	_, err := s.db.Exec(`
+		INSERT INTO contacts (jid, phone, display_name, push_name, business_name, about, country_code, last_sync)
+		VALUES (?, NULLIF(?, ''), ?, ?, ?, ?, NULLIF(?, ''), ?)
+		ON CONFLICT (jid) DO UPDATE SET
+			phone = excluded.phone, display_name = excluded.display_name, push_name = excluded.push_name,
+			business_name = excluded.business_name, about = excluded.about, country_code = excluded.country_code,
+			last_sync = excluded.last_sync`,
+		c.JID, c.Phone, c.DisplayName, c.PushName, c.BusinessName, c.About, c.CountryCode, lastSync.UTC(),
+	)
+	return err
+}
+
+// contactCountryCode is the country of a contact's phone number, or of the
+// number in its JID when it has none, or empty if that is not known
+func contactCountryCode(jid, phone string) string {
+	if phone == "" {
+		user, server, ok := strings.Cut(jid, "@")
+		if !ok || server != "s.whatsapp.net" {
+			return ""
+		}
+		phone = user
+	}
+	country, err := validation.ExtractCountryCode(phone)
+	if err != nil {
+		return ""
+	}
+	return country
+}
+
+// GetContact retrieves a contact by JID. It returns sql.ErrNoRows if the
+// contact does not exist.
+func (s *Store) GetContact(jid string) (*Contact, error) {
+	return s.getContact("jid", jid)
+}
+
+// GetContactByPhone retrieves a contact by phone number. It returns
+// sql.ErrNoRows if no contact has that number.
+func (s *Store) GetContactByPhone(phone string) (*Contact, error) {
+	return s.getContact("phone", phone)
+}
+
+// getContact retrieves the contact whose unique column equals value
+func (s *Store) getContact(column, value string) (*Contact, error) {
+	rows, err := s.db.Query("SELECT "+contactColumns+" FROM contacts WHERE "+column+" = ?", value)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts, err := scanContacts(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(contacts) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return contacts[0], nil
+}
+
+// GetContacts retrieves all contacts ordered by name
+func (s *Store) GetContacts() ([]*Contact, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + contactColumns + `
+		FROM contacts
+		ORDER BY COALESCE(NULLIF(display_name, ''), NULLIF(push_name, ''), jid)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanContacts(rows)
+}
+
+// GetContactsByCountry retrieves the contacts whose number belongs to a
+// country, given as an ISO 3166-1 alpha-2 code, ordered by name
+func (s *Store) GetContactsByCountry(code string) ([]*Contact, error) {
+	rows, err := s.db.Query(`
+		SELECT `+contactColumns+`
+		FROM contacts
+		WHERE country_code = ?
+		ORDER BY COALESCE(NULLIF(display_name, ''), NULLIF(push_name, ''), jid)`,
+		strings.ToUpper(code),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanContacts(rows)
+}
+
+// SearchContacts finds up to limit contacts for autocompletion. With FTS5
+// every word of the query must prefix a word of the contact's display name,
+// push name or phone number, and results are ordered by relevance. Without
+// it the whole query may appear anywhere in a name, phone number or JID,
+// and results are ordered by name.
+func (s *Store) SearchContacts(query string, limit int) ([]*Contact, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	var rows *sql.Rows
+	var err error
+	if s.fts5 {
+		rows, err = s.db.Query(`
+			SELECT `+contactColumns+`
+			FROM contacts
+			JOIN (
+				SELECT rowid AS fts_rowid, rank
+				FROM contacts_fts
+				WHERE contacts_fts MATCH ?
+				ORDER BY rank
+				LIMIT ?
+			) matches ON contacts.rowid = matches.fts_rowid
+			ORDER BY matches.rank`,
+			ftsPrefixQuery(terms), limit,
+		)
+	} else {
+		pattern := "%" + likeEscaper.Replace(strings.TrimSpace(query)) + "%"
+		rows, err = s.db.Query(`
+			SELECT `+contactColumns+`
+			FROM contacts
+			WHERE display_name LIKE ? ESCAPE '\'
+				OR push_name LIKE ? ESCAPE '\'
+				OR business_name LIKE ? ESCAPE '\'
+				OR phone LIKE ? ESCAPE '\'
+				OR jid LIKE ? ESCAPE '\'
+			ORDER BY COALESCE(NULLIF(display_name, ''), NULLIF(push_name, ''), jid)
+			LIMIT ?`,
+			pattern, pattern, pattern, pattern, pattern, limit,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanContacts(rows)
+}
+
+// DeleteContact removes a contact. It returns sql.ErrNoRows if the contact
+// does not exist.
+func (s *Store) DeleteContact(jid string) error {
+	result, err := s.db.Exec("DELETE FROM contacts WHERE jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// scanContacts reads rows selected with contactColumns
+func scanContacts(rows *sql.Rows) ([]*Contact, error) {
+	contacts := []*Contact{}
+	for rows.Next() {
+		c := &Contact{}
+		var lastSync sql.NullTime
+		err := rows.Scan(&c.JID, &c.Phone, &c.DisplayName, &c.PushName, &c.BusinessName, &c.About, &c.CountryCode, &lastSync)
+		if err != nil {
+			return nil, err
+		}
+		c.LastSync = lastSync.Time
+		contacts = append(contacts, c)
+	}
+
+	return contacts, rows.Err()
+}
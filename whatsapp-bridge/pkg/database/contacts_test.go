@@ -0,0 +1,178 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestContacts(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	contacts := []*Contact{
+		{JID: "123456789@s.whatsapp.net", Phone: "123456789", DisplayName: "Alice Smith", PushName: "Ali"},
+		{JID: "987654321@s.whatsapp.net", Phone: "987654321", PushName: "Bob"},
+		{JID: "555000111@s.whatsapp.net", BusinessName: "Corner Shop_1"},
+	}
+	for _, c := range contacts {
+		if err := store.StoreContact(c); err != nil {
+			t.Fatalf("Failed to store contact: %v", err)
+		}
+	}
+
+	got, err := store.GetContact(contacts[0].JID)
+	if err != nil {
+		t.Fatalf("Failed to get contact: %v", err)
+	}
+	if got.DisplayName != "Alice Smith" || got.LastSync.IsZero() {
+		t.Errorf("Unexpected contact %+v", got)
+	}
+
+	got, err = store.GetContactByPhone("987654321")
+	if err != nil {
+		t.Fatalf("Failed to get contact by phone: %v", err)
+	}
+	if got.JID != contacts[1].JID {
+		t.Errorf("Expected %s, got %s", contacts[1].JID, got.JID)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"alice", 1},
+		{"ali", 1},
+		{"smi", 1},
+		{"bob", 1},
+		{"9876", 1},
+		{"carol", 0},
+	}
+	if !store.fts5 {
+		// LIKE matching also covers business names and JIDs
+		tests = append(tests, []struct {
+			query string
+			want  int
+		}{
+			{"shop_1", 1},
+			{"shop%", 0},
+			{"whatsapp", 3},
+		}...)
+	}
+	for _, test := range tests {
+		results, err := store.SearchContacts(test.query, 10)
+		if err != nil {
+			t.Fatalf("Failed to search contacts for %q: %v", test.query, err)
+		}
+		if len(results) != test.want {
+			t.Errorf("Search %q: expected %d contacts, got %d", test.query, test.want, len(results))
+		}
+	}
+
+	if results, err := store.SearchContacts("a", 1); err != nil || len(results) != 1 {
+		t.Errorf("Expected the limit to cap results at 1, got %d (%v)", len(results), err)
+	}
+	if _, err := store.SearchContacts("  ", 10); err == nil {
+		t.Error("Expected an error for an empty query")
+	}
+
+	// Renamed contacts are found by their new name only
+	contacts[1].PushName = "Robert"
+	if err := store.StoreContact(contacts[1]); err != nil {
+		t.Fatalf("Failed to update contact: %v", err)
+	}
+	for query, want := range map[string]int{"rob": 1, "bob": 0} {
+		if results, err := store.SearchContacts(query, 10); err != nil || len(results) != want {
+			t.Errorf("Search %q after rename: expected %d contacts, got %d (%v)", query, want, len(results), err)
+		}
+	}
+
+	if err := store.DeleteContact(contacts[1].JID); err != nil {
+		t.Fatalf("Failed to delete contact: %v", err)
+	}
+	if results, _ := store.SearchContacts("rob", 10); len(results) != 0 {
+		t.Errorf("Expected deleted contacts not to be found, got %d", len(results))
+	}
+	if _, err := store.GetContact(contacts[1].JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for deleted contact, got %v", err)
+	}
+	if err := store.DeleteContact(contacts[1].JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows deleting missing contact, got %v", err)
+	}
+}
+
+func TestGetContactsByCountry(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	contacts := []*Contact{
+		{JID: "12025550123@s.whatsapp.net", Phone: "12025550123", DisplayName: "Bob"},
+		{JID: "14165550123@s.whatsapp.net", DisplayName: "Carol"},
+		{JID: "447400123456@s.whatsapp.net", Phone: "+447400123456", DisplayName: "Dave"},
+		{JID: "12125550123@s.whatsapp.net", DisplayName: "Alice"},
+		{JID: "999@s.whatsapp.net", DisplayName: "Unknown"},
+	}
+	for _, c := range contacts {
+		if err := store.StoreContact(c); err != nil {
+			t.Fatalf("Failed to store contact: %v", err)
+		}
+	}
+	if contacts[1].CountryCode != "CA" {
+		t.Errorf("Expected the country to be derived from the JID, got %q", contacts[1].CountryCode)
+	}
+
+	tests := []struct {
+		code string
+		want []string
+	}{
+		{"US", []string{"Alice", "Bob"}},
+		{"ca", []string{"Carol"}},
+		{"GB", []string{"Dave"}},
+		{"FR", nil},
+	}
+	for _, tt := range tests {
+		got, err := store.GetContactsByCountry(tt.code)
+		if err != nil {
+			t.Fatalf("Failed to get contacts in %s: %v", tt.code, err)
+		}
+		var names []string
+		for _, c := range got {
+			names = append(names, c.DisplayName)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.code, tt.want, names)
+		}
+	}
+
+	unknown, err := store.GetContact("999@s.whatsapp.net")
+	if err != nil || unknown.CountryCode != "" {
+		t.Errorf("Expected no country for an invalid number, got %+v (%v)", unknown, err)
+	}
+}
+
+func TestGetChatsPageResolveContactNames(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	store.StoreChat(&Chat{JID: "123456789@s.whatsapp.net", Name: "123456789", LastMessageTime: time.Now()})
+	store.StoreChat(&Chat{JID: "987654321@s.whatsapp.net", Name: "Chat Name", LastMessageTime: time.Now()})
+	store.StoreContact(&Contact{JID: "123456789@s.whatsapp.net", DisplayName: "Alice Smith"})
+
+	page, err := store.GetChatsPage(10, Cursor{}, ChatOptions{ResolveContactNames: true})
+	if err != nil {
+		t.Fatalf("Failed to get chats: %v", err)
+	}
+
+	names := map[string]string{}
+	for _, chat := range page.Items {
+		names[chat.JID] = chat.Name
+	}
+	if names["123456789@s.whatsapp.net"] != "Alice Smith" {
+		t.Errorf("Expected contact name, got %q", names["123456789@s.whatsapp.net"])
+	}
+	if names["987654321@s.whatsapp.net"] != "Chat Name" {
+		t.Errorf("Expected chat name without contact, got %q", names["987654321@s.whatsapp.net"])
+	}
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cursor marks the position after which the next page starts. The zero
+// value starts from the first page.
+type Cursor struct {
+	AfterTimestamp time.Time
+	// AfterID breaks ties between rows sharing the same timestamp
+	AfterID string
+}
+
+// IsZero reports whether the cursor points at the first page
+func (c Cursor) IsZero() bool {
+	return c.AfterTimestamp.IsZero() && c.AfterID == ""
+}
+
+// cursorToken is the JSON payload behind an encoded cursor
+type cursorToken struct {
+	TS int64  `json:"ts"`
+	ID string `json:"id"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c Cursor) Encode() string {
+	if c.IsZero() {
+		return ""
+	}
+	data, _ := json.Marshal(cursorToken{TS: c.AfterTimestamp.UnixNano(), ID: c.AfterID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode. An empty token
+// decodes to the zero cursor.
+func DecodeCursor(token string) (Cursor, error) {
+	if token == "" {
+		return Cursor{}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var t cursorToken
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if t.TS <= 0 || t.ID == "" {
+		return Cursor{}, fmt.Errorf("invalid cursor: missing position")
+	}
+
+	return Cursor{AfterTimestamp: time.Unix(0, t.TS).UTC(), AfterID: t.ID}, nil
+}
+
+// PageResult is one page of results plus the token for the next page.
+// NextCursor is empty on the last page.
+type PageResult[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// newPageResult trims items fetched with limit+1 rows to limit and, when a
+// further page exists, sets NextCursor from the last kept item
+func newPageResult[T any](items []T, limit int, position func(T) Cursor) *PageResult[T] {
+	page := &PageResult[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = position(page.Items[limit-1]).Encode()
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
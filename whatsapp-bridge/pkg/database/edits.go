@@ -0,0 +1,77 @@
+package database
+
+import (
+	"time"
+)
+
+// RecordMessageEdit replaces the content of a message, keeping its previous
+// content as the next revision in the edit history. Repeating an edit that
+// is already applied changes nothing. It returns sql.ErrNoRows if the message
+// does not exist.
+func (s *Store) RecordMessageEdit(messageID, chatJID, newContent string, editedAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var content string
+	err = tx.QueryRow(
+		"SELECT COALESCE(content, '') FROM messages WHERE id = ? AND chat_jid = ?",
+		messageID, chatJID,
+	).Scan(&content)
+	if err != nil {
+		return err
+	}
+	if content == newContent {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO edit_history (message_id, chat_jid, edited_at, original_content, revision)
+		SELECT ?, ?, ?, ?, COALESCE(MAX(revision), 0) + 1
+		FROM edit_history
+		WHERE message_id = ? AND chat_jid = ?`,
+		messageID, chatJID, editedAt.UTC(), content, messageID, chatJID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE messages SET content = ?, is_edited = 1, edited_at = ? WHERE id = ? AND chat_jid = ?",
+		newContent, editedAt.UTC(), messageID, chatJID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetEditHistory retrieves the earlier versions of a message, first revision
+// first
+func (s *Store) GetEditHistory(messageID, chatJID string) ([]*EditRecord, error) {
+	rows, err := s.db.Query(`
+		SELECT message_id, chat_jid, revision, original_content, edited_at
+		FROM edit_history
+		WHERE message_id = ? AND chat_jid = ?
+		ORDER BY revision`,
+		messageID, chatJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*EditRecord{}
+	for rows.Next() {
+		r := &EditRecord{}
+		if err := rows.Scan(&r.MessageID, &r.ChatJID, &r.Revision, &r.OriginalContent, &r.EditedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+
+	return records, rows.Err()
+}
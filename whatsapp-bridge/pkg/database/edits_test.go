@@ -0,0 +1,70 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecordMessageEdit(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	msg := testMessages(chat.JID, 1)[0]
+	msg.Content = "see you at 5"
+	store.StoreMessage(msg)
+
+	firstEdit := time.Now().Add(time.Minute)
+	if err := store.RecordMessageEdit(msg.ID, chat.JID, "see you at 6", firstEdit); err != nil {
+		t.Fatalf("Failed to record edit: %v", err)
+	}
+	if err := store.RecordMessageEdit(msg.ID, chat.JID, "see you at 7", firstEdit.Add(time.Minute)); err != nil {
+		t.Fatalf("Failed to record edit: %v", err)
+	}
+	// Redelivery of the last edit is not a new revision
+	if err := store.RecordMessageEdit(msg.ID, chat.JID, "see you at 7", firstEdit.Add(2*time.Minute)); err != nil {
+		t.Fatalf("Failed to record edit: %v", err)
+	}
+
+	messages, _ := store.GetMessages(chat.JID, 1, 0)
+	if messages[0].Content != "see you at 7" || !messages[0].IsEdited || messages[0].EditedAt == nil {
+		t.Errorf("Expected edited message, got %+v", messages[0])
+	}
+
+	history, err := store.GetEditHistory(msg.ID, chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get edit history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(history))
+	}
+	if history[0].Revision != 1 || history[0].OriginalContent != "see you at 5" {
+		t.Errorf("Unexpected first revision: %+v", history[0])
+	}
+	if history[1].Revision != 2 || history[1].OriginalContent != "see you at 6" {
+		t.Errorf("Unexpected second revision: %+v", history[1])
+	}
+
+	// Re-syncing the original message keeps the edited content
+	store.StoreMessage(msg)
+	messages, _ = store.GetMessages(chat.JID, 1, 0)
+	if messages[0].Content != "see you at 7" {
+		t.Errorf("Expected re-sync to keep edited content, got %q", messages[0].Content)
+	}
+
+	if err := store.RecordMessageEdit("missing", chat.JID, "text", firstEdit); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for unknown message, got %v", err)
+	}
+
+	// History goes with the message
+	if err := store.DeleteMessage(msg.ID, chat.JID); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+	history, _ = store.GetEditHistory(msg.ID, chat.JID)
+	if len(history) != 0 {
+		t.Errorf("Expected history to be deleted with the message, got %d records", len(history))
+	}
+}
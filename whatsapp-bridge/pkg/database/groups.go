@@ -0,0 +1,174 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UpsertParticipant records a participant as a current member of a group and
+// updates their admin flag. A zero JoinedAt is recorded as now. The join time
+// of an existing member is kept; a participant who had left rejoins with the
+// new join time.
+func (s *Store) UpsertParticipant(p *GroupParticipant) error {
+	joinedAt := p.JoinedAt
+	if joinedAt.IsZero() {
+		joinedAt = time.Now()
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO group_participants (group_jid, participant_jid, is_admin, joined_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (group_jid, participant_jid) DO UPDATE SET
+			is_admin = excluded.is_admin,
+			joined_at = CASE WHEN left_at IS NULL THEN joined_at ELSE excluded.joined_at END,
+			left_at = NULL`,
+		p.GroupJID, p.ParticipantJID, p.IsAdmin, joinedAt.UTC(),
+	)
+	return err
+}
+
+// RemoveParticipant records that a participant left a group, keeping the
+// membership history. It returns sql.ErrNoRows if the participant is not a
+// current member.
+func (s *Store) RemoveParticipant(groupJID, participantJID string, leftAt time.Time) error {
+	result, err := s.db.Exec(`
+		UPDATE group_participants SET left_at = ?, is_admin = 0
+		WHERE group_jid = ? AND participant_jid = ? AND left_at IS NULL`,
+		leftAt.UTC(), groupJID, participantJID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetParticipants retrieves everyone who has been a member of a group:
+// current members first, each group ordered by join time
+func (s *Store) GetParticipants(groupJID string) ([]*GroupParticipant, error) {
+	rows, err := s.db.Query(`
+		SELECT group_jid, participant_jid, is_admin, joined_at, left_at
+		FROM group_participants
+		WHERE group_jid = ?
+		ORDER BY left_at IS NOT NULL, joined_at`,
+		groupJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := []*GroupParticipant{}
+	for rows.Next() {
+		p := &GroupParticipant{}
+		if err := rows.Scan(&p.GroupJID, &p.ParticipantJID, &p.IsAdmin, &p.JoinedAt, &p.LeftAt); err != nil {
+			return nil, err
+		}
+		participants = append(participants, p)
+	}
+
+	return participants, rows.Err()
+}
+
+// IsAdmin reports whether a participant is a current admin of a group
+func (s *Store) IsAdmin(groupJID, participantJID string) (bool, error) {
+	var isAdmin bool
+	err := s.db.QueryRow(`
+		SELECT is_admin FROM group_participants
+		WHERE group_jid = ? AND participant_jid = ? AND left_at IS NULL`,
+		groupJID, participantJID,
+	).Scan(&isAdmin)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return isAdmin, err
+}
+
+// StoreGroupInfo inserts or replaces the metadata of a group. A zero
+// LastSyncedAt is recorded as now.
+func (s *Store) StoreGroupInfo(info *GroupInfo) error {
+	if !(&Chat{JID: info.JID}).IsGroup() {
+		return fmt.Errorf("not a group JID: %s", info.JID)
+	}
+	if info.LastSyncedAt.IsZero() {
+		info.LastSyncedAt = time.Now()
+	}
+	var createdAt *time.Time
+	if info.CreatedAt != nil {
+		t := info.CreatedAt.UTC()
+		createdAt = &t
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO group_info (jid, subject, description, owner_jid, created_at, last_synced_at)
+		VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?)
+		ON CONFLICT (jid) DO UPDATE SET
+			subject = excluded.subject, description = excluded.description,
+			owner_jid = excluded.owner_jid, created_at = excluded.created_at,
+			last_synced_at = excluded.last_synced_at`,
+		info.JID, info.Subject, info.Description, info.OwnerJID, createdAt, info.LastSyncedAt.UTC(),
+	)
+	return err
+}
+
+// GetGroupInfo retrieves the metadata of a group. It returns sql.ErrNoRows
+// if none is stored and an error if jid is not a group.
+func (s *Store) GetGroupInfo(jid string) (*GroupInfo, error) {
+	if !(&Chat{JID: jid}).IsGroup() {
+		return nil, fmt.Errorf("not a group JID: %s", jid)
+	}
+
+	info := &GroupInfo{}
+	var createdAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT jid, COALESCE(subject, ''), COALESCE(description, ''), COALESCE(owner_jid, ''),
+			created_at, last_synced_at
+		FROM group_info
+		WHERE jid = ?`,
+		jid,
+	).Scan(&info.JID, &info.Subject, &info.Description, &info.OwnerJID, &createdAt, &info.LastSyncedAt)
+	if err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		info.CreatedAt = &createdAt.Time
+	}
+
+	return info, nil
+}
+
+// ListGroups retrieves the metadata of every known group, those with the
+// most recent chat activity first. A group whose subject is not stored is
+// listed under its chat name.
+func (s *Store) ListGroups(limit, offset int) ([]*GroupInfo, error) {
+	rows, err := s.db.Query(`
+		SELECT g.jid, COALESCE(NULLIF(g.subject, ''), c.name, ''), COALESCE(g.description, ''),
+			COALESCE(g.owner_jid, ''), g.created_at, g.last_synced_at
+		FROM group_info g
+		LEFT JOIN chats c ON c.jid = g.jid
+		ORDER BY c.last_message_time IS NULL, c.last_message_time DESC, g.jid
+		LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*GroupInfo{}
+	for rows.Next() {
+		info := &GroupInfo{}
+		var createdAt sql.NullTime
+		if err := rows.Scan(&info.JID, &info.Subject, &info.Description, &info.OwnerJID, &createdAt, &info.LastSyncedAt); err != nil {
+			return nil, err
+		}
+		if createdAt.Valid {
+			info.CreatedAt = &createdAt.Time
+		}
+		groups = append(groups, info)
+	}
+
+	return groups, rows.Err()
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGroupParticipants(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	group := "120363000000000000@g.us"
+	alice := "111111111@s.whatsapp.net"
+	bob := "222222222@s.whatsapp.net"
+	joined := time.Now().Add(-time.Hour)
+
+	store.UpsertParticipant(&GroupParticipant{GroupJID: group, ParticipantJID: alice, IsAdmin: true, JoinedAt: joined})
+	if err := store.UpsertParticipant(&GroupParticipant{GroupJID: group, ParticipantJID: bob}); err != nil {
+		t.Fatalf("Failed to upsert participant: %v", err)
+	}
+
+	isAdmin, err := store.IsAdmin(group, alice)
+	if err != nil || !isAdmin {
+		t.Errorf("Expected alice to be admin, got %v (err %v)", isAdmin, err)
+	}
+
+	// Demoting keeps the original join time
+	store.UpsertParticipant(&GroupParticipant{GroupJID: group, ParticipantJID: alice, JoinedAt: time.Now()})
+	if isAdmin, _ := store.IsAdmin(group, alice); isAdmin {
+		t.Errorf("Expected alice to be demoted")
+	}
+
+	if err := store.RemoveParticipant(group, bob, time.Now()); err != nil {
+		t.Fatalf("Failed to remove participant: %v", err)
+	}
+	if err := store.RemoveParticipant(group, bob, time.Now()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows removing former member, got %v", err)
+	}
+
+	participants, err := store.GetParticipants(group)
+	if err != nil {
+		t.Fatalf("Failed to get participants: %v", err)
+	}
+	if len(participants) != 2 {
+		t.Fatalf("Expected 2 participants, got %d", len(participants))
+	}
+	if participants[0].ParticipantJID != alice || participants[0].LeftAt != nil {
+		t.Errorf("Expected current member alice first, got %+v", participants[0])
+	}
+	if !participants[0].JoinedAt.Equal(joined) {
+		t.Errorf("Expected join time %v, got %v", joined, participants[0].JoinedAt)
+	}
+	if participants[1].ParticipantJID != bob || participants[1].LeftAt == nil {
+		t.Errorf("Expected former member bob with left_at, got %+v", participants[1])
+	}
+
+	// Rejoining clears left_at
+	store.UpsertParticipant(&GroupParticipant{GroupJID: group, ParticipantJID: bob})
+	participants, _ = store.GetParticipants(group)
+	for _, p := range participants {
+		if p.LeftAt != nil {
+			t.Errorf("Expected %s to be a current member", p.ParticipantJID)
+		}
+	}
+}
+
+func TestGroupInfo(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	group := "120363000000000000@g.us"
+	if _, err := store.GetGroupInfo(group); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows before syncing, got %v", err)
+	}
+
+	created := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	info := &GroupInfo{JID: group, Subject: "Climbing", Description: "Tuesdays at 7", OwnerJID: "111111111@s.whatsapp.net", CreatedAt: &created}
+	if err := store.StoreGroupInfo(info); err != nil {
+		t.Fatalf("Failed to store group info: %v", err)
+	}
+
+	got, err := store.GetGroupInfo(group)
+	if err != nil {
+		t.Fatalf("Failed to get group info: %v", err)
+	}
+	if got.Subject != "Climbing" || got.Description != "Tuesdays at 7" || got.OwnerJID != info.OwnerJID {
+		t.Errorf("Unexpected group info %+v", got)
+	}
+	if got.CreatedAt == nil || !got.CreatedAt.Equal(created) || got.LastSyncedAt.IsZero() {
+		t.Errorf("Expected creation and sync times, got %+v", got)
+	}
+
+	// Storing again replaces the metadata
+	info.Subject, info.Description = "Bouldering", ""
+	if err := store.StoreGroupInfo(info); err != nil {
+		t.Fatalf("Failed to update group info: %v", err)
+	}
+	got, err = store.GetGroupInfo(group)
+	if err != nil {
+		t.Fatalf("Failed to get group info: %v", err)
+	}
+	if got.Subject != "Bouldering" || got.Description != "" {
+		t.Errorf("Expected the updated subject and no description, got %+v", got)
+	}
+
+	if err := store.StoreGroupInfo(&GroupInfo{JID: "111111111@s.whatsapp.net", Subject: "Nope"}); err == nil {
+		t.Error("Expected an error storing group info for a user JID")
+	}
+	if _, err := store.GetGroupInfo("111111111@s.whatsapp.net"); err == nil || errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected a not-a-group error, got %v", err)
+	}
+}
+
+func TestListGroups(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, chat := range []*Chat{
+		{JID: "120363000000000001@g.us", Name: "Quiet group", LastMessageTime: base},
+		{JID: "120363000000000002@g.us", Name: "Busy group", LastMessageTime: base.Add(time.Hour)},
+	} {
+		if err := store.StoreChat(chat); err != nil {
+			t.Fatalf("Failed to store chat: %v", err)
+		}
+	}
+	for _, info := range []*GroupInfo{
+		{JID: "120363000000000001@g.us"},
+		{JID: "120363000000000002@g.us", Subject: "Climbing"},
+		{JID: "120363000000000003@g.us", Subject: "No chat yet"},
+	} {
+		if err := store.StoreGroupInfo(info); err != nil {
+			t.Fatalf("Failed to store group info: %v", err)
+		}
+	}
+
+	groups, err := store.ListGroups(10, 0)
+	if err != nil {
+		t.Fatalf("Failed to list groups: %v", err)
+	}
+	var subjects []string
+	for _, g := range groups {
+		subjects = append(subjects, g.Subject)
+	}
+	if want := []string{"Climbing", "Quiet group", "No chat yet"}; !slices.Equal(subjects, want) {
+		t.Errorf("Expected groups %v by activity, falling back to the chat name, got %v", want, subjects)
+	}
+
+	page, err := store.ListGroups(1, 1)
+	if err != nil {
+		t.Fatalf("Failed to list groups: %v", err)
+	}
+	if len(page) != 1 || page[0].JID != "120363000000000001@g.us" {
+		t.Errorf("Expected the second group alone, got %v", page)
+	}
+}
+
+func TestGroupInfoAge(t *testing.T) {
+	created := time.Now().Add(-48 * time.Hour)
+	if age := (&GroupInfo{CreatedAt: &created}).Age(); age < 48*time.Hour || age > 49*time.Hour {
+		t.Errorf("Expected an age of about 48h, got %v", age)
+	}
+	if age := (&GroupInfo{}).Age(); age != 0 {
+		t.Errorf("Expected no age without a creation time, got %v", age)
+	}
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"whatsapp-client/pkg/metrics"
+)
+
+// ChatStore reads and updates chats
+type ChatStore interface {
+	StoreChat(chat *Chat) error
+	StoreChatTx(tx *sql.Tx, chat *Chat) error
+	DeleteChat(jid string) error
+	GetChatsPage(limit int, cursor Cursor, opts ChatOptions) (*PageResult[*Chat], error)
+	CountChats(opts ChatOptions) (int, error)
+	GetChats(limit, offset int) ([]*Chat, error)
+	GetChatsWithLastMessage(limit, offset int) ([]*ChatWithLastMessage, error)
+	WithLastMessages(chats []*Chat) ([]*ChatWithLastMessage, error)
+	GetChat(jid string) (*Chat, error)
+	MarkChatRead(jid string) error
+	SetReadPosition(chatJID, messageID string, at time.Time) error
+	GetReadPosition(chatJID string) (messageID string, at time.Time, err error)
+	ArchiveChat(jid string, at time.Time) error
+	UnarchiveChat(jid string) error
+	GetArchivedChats(limit, offset int) ([]*Chat, error)
+	UpdateChatName(jid, name string) error
+	MuteChat(jid string, until time.Time) error
+	UnmuteChat(jid string) error
+	GetMutedChats() ([]*Chat, error)
+	GetUnreadChats() ([]*Chat, error)
+}
+
+// MessageStore reads and updates messages and what is attached to them:
+// edits, pins, reactions, polls, locations and media
+type MessageStore interface {
+	StoreMessage(msg *Message) error
+	StoreMessageTx(tx *sql.Tx, msg *Message) error
+	BulkStoreMessages(msgs []*Message) error
+	GetMessagesPage(chatJID string, limit int, cursor Cursor, opts MessageOptions) (*PageResult[*Message], error)
+	CountMessages(chatJID string, opts MessageOptions) (int, error)
+	GetMessages(chatJID string, limit, offset int) ([]*Message, error)
+	UpdateMessageStatus(id, chatJID string, status MessageStatus) error
+	GetMessageThread(id, chatJID string) ([]*Message, error)
+	SoftDeleteMessage(id, chatJID string, deletedAt time.Time) error
+	PurgeDeletedMessages(cutoff time.Time) (int64, error)
+	DeleteMessage(id, chatJID string) error
+	BulkDeleteMessages(ids []string, chatJID string) error
+	DeleteChatMessages(chatJID string) error
+	GetMessage(id, chatJID string) (*Message, error)
+	SearchMessages(query, chatJID string, limit, offset int) ([]*Message, error)
+	GetLastMessagePerChat(jids []string) (map[string]*Message, error)
+
+	RecordMessageEdit(messageID, chatJID, newContent string, editedAt time.Time) error
+	GetEditHistory(messageID, chatJID string) ([]*EditRecord, error)
+
+	GetNearbyMessages(lat, lon float64, radiusKm float64, limit int) ([]*Message, error)
+	GetNearbyLocations(lat, lon, radiusKm float64, since time.Time, limit int) ([]*LocationMessage, error)
+	StoreLocation(loc *LocationMessage) error
+	GetLocation(messageID, chatJID string) (*LocationMessage, error)
+
+	StoreMediaMetadata(md *MediaMetadata) error
+	UpdateLocalPath(messageID, chatJID, path string) error
+	GetMediaMetadata(messageID, chatJID string) (*MediaMetadata, error)
+
+	GetMentionsForJID(jid string, limit, offset int) ([]*Message, error)
+
+	PinMessage(messageID, chatJID, pinnedBy string, pinnedAt time.Time) error
+	UnpinMessage(messageID, chatJID string) error
+	GetPinnedMessages(chatJID string) ([]*Message, error)
+
+	StorePoll(poll *PollMessage) error
+	StorePollVote(vote *PollVote) error
+	UpdatePollResults(messageID, chatJID string, voterJID string, selectedIDs []string) error
+	GetPoll(messageID, chatJID string) (*PollMessage, error)
+	GetPollResults(messageID, chatJID string) (*PollResults, error)
+
+	StoreReaction(r *Reaction) error
+	DeleteReaction(messageID, chatJID, sender string) error
+	GetReactions(messageID, chatJID string) ([]*Reaction, error)
+
+	SearchMessagesFiltered(f SearchMessagesFilter, limit int, cursor Cursor) ([]*Message, Cursor, error)
+	CountMessagesFiltered(f SearchMessagesFilter) (int, error)
+	GetMediaMessages(chatJID string, mediaType string, limit, offset int) ([]*Message, error)
+
+	GetMessageStats(chatJID string) (*MessageStats, error)
+	GetChatStatistics(chatJID string) (*ChatStatistics, error)
+	GetChatActivityByDay(chatJID string, days int) ([]DayCount, error)
+
+	GetContactShareMessages(chatJID string) ([]*Message, error)
+}
+
+// DataStore is everything Store provides. Packages that take a DataStore
+// rather than a *Store can be tested against a mock, such as
+// testutil.MockStore.
+type DataStore interface {
+	ChatStore
+	MessageStore
+
+	Close() error
+	SetMetrics(m *metrics.Metrics)
+	DBStats() sql.DBStats
+	Ping() error
+	SetBulkBatchSize(n int)
+	WithTransaction(fn func(tx *sql.Tx) error) error
+	Backup(destPath string) error
+
+	BlockContact(jid string) error
+	UnblockContact(jid string) error
+	IsBlocked(jid string) (bool, error)
+	GetBlockedContacts() ([]*Contact, error)
+
+	StoreBroadcastList(list *BroadcastList) error
+	GetBroadcastList(jid string) (*BroadcastList, error)
+	GetBroadcastLists() ([]*BroadcastList, error)
+	AddBroadcastRecipient(listJID, recipientJID string) error
+	RemoveBroadcastRecipient(listJID, recipientJID string) error
+	GetBroadcastRecipients(listJID string) ([]string, error)
+
+	StoreCallLog(call *CallLog) error
+	GetCallLogs(chatJID string, limit, offset int) ([]*CallLog, error)
+	GetMissedCalls(limit int) ([]*CallLog, error)
+	GetCallLogByID(id string) (*CallLog, error)
+
+	StoreContact(c *Contact) error
+	GetContact(jid string) (*Contact, error)
+	GetContactByPhone(phone string) (*Contact, error)
+	GetContacts() ([]*Contact, error)
+	GetContactsByCountry(code string) ([]*Contact, error)
+	SearchContacts(query string, limit int) ([]*Contact, error)
+	DeleteContact(jid string) error
+
+	UpsertParticipant(p *GroupParticipant) error
+	RemoveParticipant(groupJID, participantJID string, leftAt time.Time) error
+	GetParticipants(groupJID string) ([]*GroupParticipant, error)
+	IsAdmin(groupJID, participantJID string) (bool, error)
+	StoreGroupInfo(info *GroupInfo) error
+	GetGroupInfo(jid string) (*GroupInfo, error)
+	ListGroups(limit, offset int) ([]*GroupInfo, error)
+
+	CreateJob(job *Job) error
+	UpdateJobStatus(id string, status JobStatus, jobErr string, at time.Time) error
+	GetJob(id string) (*Job, error)
+
+	CreateLabel(l *Label) error
+	UpdateLabel(l *Label) error
+	DeleteLabel(id string) error
+	GetLabel(id string) (*Label, error)
+	GetLabels() ([]*Label, error)
+	AddChatLabel(chatJID, labelID string) error
+	RemoveChatLabel(chatJID, labelID string) error
+	GetChatsByLabel(labelID string, limit, offset int) ([]*Chat, error)
+
+	Vacuum() error
+	CheckpointWAL() error
+	WALCheckpointInterval() time.Duration
+	DatabaseStats() (*DBStats, error)
+
+	StoreProfilePicture(pp *ProfilePicture) error
+	GetProfilePicture(jid string) (*ProfilePicture, error)
+	InvalidateProfilePicture(jid string) error
+
+	ScheduleMessage(msg *ScheduledMessage) error
+	GetDueMessages(now time.Time) ([]*ScheduledMessage, error)
+	GetScheduledMessages(status ScheduledStatus, limit, offset int) ([]*ScheduledMessage, error)
+	MarkScheduledSent(id string, sentAt time.Time) error
+	MarkScheduledFailed(id string) error
+
+	StoreStatus(status *StatusMessage) error
+	GetStatuses(limit, offset int) ([]*StatusMessage, error)
+	GetStatusesByAuthor(authorJID string) ([]*StatusMessage, error)
+	DeleteExpiredStatuses() (int64, error)
+	DeleteStatusesBefore(cutoff time.Time) (int64, error)
+
+	RegisterWebhook(w *Webhook) error
+	DeleteWebhook(id string) error
+	GetWebhooks() ([]*Webhook, error)
+	RecordWebhookDelivery(id string, success bool) error
+}
+
+var _ DataStore = (*Store)(nil)
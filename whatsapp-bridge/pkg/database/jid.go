@@ -0,0 +1,72 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Servers of the kinds of JID the bridge distinguishes
+const (
+	ContactServer    = "s.whatsapp.net"
+	GroupServer      = "g.us"
+	BroadcastServer  = "broadcast"
+	NewsletterServer = "newsletter"
+)
+
+// JID is a parsed WhatsApp JID. Device is 0 for the primary device and for
+// JIDs that do not name a device.
+type JID struct {
+	User   string
+	Server string
+	Device int
+}
+
+// ParseJID parses a JID of the form user@server or, for a specific device
+// of a multi-device account, user:device@server
+func ParseJID(s string) (JID, error) {
+	user, server, ok := strings.Cut(s, "@")
+	if !ok || user == "" || server == "" || strings.Contains(server, "@") {
+		return JID{}, fmt.Errorf("invalid JID %q: expected user@server", s)
+	}
+
+	jid := JID{User: user, Server: server}
+	if user, device, ok := strings.Cut(user, ":"); ok {
+		n, err := strconv.Atoi(device)
+		if err != nil || n < 0 || user == "" {
+			return JID{}, fmt.Errorf("invalid JID %q: expected user:device@server", s)
+		}
+		jid.User, jid.Device = user, n
+	}
+	return jid, nil
+}
+
+// String formats the JID as ParseJID reads it, leaving out device 0
+func (j JID) String() string {
+	if j.Device != 0 {
+		return fmt.Sprintf("%s:%d@%s", j.User, j.Device, j.Server)
+	}
+	return j.User + "@" + j.Server
+}
+
+// IsGroup reports whether the JID is a group
+func (j JID) IsGroup() bool {
+	return j.Server == GroupServer
+}
+
+// IsContact reports whether the JID is a user's account or one of its
+// devices
+func (j JID) IsContact() bool {
+	return j.Server == ContactServer
+}
+
+// IsBroadcast reports whether the JID is a broadcast list or the status
+// broadcast
+func (j JID) IsBroadcast() bool {
+	return j.Server == BroadcastServer
+}
+
+// IsNewsletter reports whether the JID is a channel
+func (j JID) IsNewsletter() bool {
+	return j.Server == NewsletterServer
+}
@@ -0,0 +1,57 @@
+package database
+
+import "testing"
+
+func TestParseJID(t *testing.T) {
+	tests := []struct {
+		input      string
+		want       JID
+		group      bool
+		contact    bool
+		broadcast  bool
+		newsletter bool
+	}{
+		{"15551234567@s.whatsapp.net", JID{User: "15551234567", Server: ContactServer}, false, true, false, false},
+		{"15551234567:12@s.whatsapp.net", JID{User: "15551234567", Server: ContactServer, Device: 12}, false, true, false, false},
+		{"15551234567:0@s.whatsapp.net", JID{User: "15551234567", Server: ContactServer}, false, true, false, false},
+		{"123456789-987654321@g.us", JID{User: "123456789-987654321", Server: GroupServer}, true, false, false, false},
+		{"1234567890@broadcast", JID{User: "1234567890", Server: BroadcastServer}, false, false, true, false},
+		{"status@broadcast", JID{User: "status", Server: BroadcastServer}, false, false, true, false},
+		{"120363012345678901@newsletter", JID{User: "120363012345678901", Server: NewsletterServer}, false, false, false, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseJID(tt.input)
+		if err != nil {
+			t.Errorf("ParseJID(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseJID(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+		if got.IsGroup() != tt.group || got.IsContact() != tt.contact || got.IsBroadcast() != tt.broadcast || got.IsNewsletter() != tt.newsletter {
+			t.Errorf("Unexpected kind of JID for %q: group %v, contact %v, broadcast %v, newsletter %v",
+				tt.input, got.IsGroup(), got.IsContact(), got.IsBroadcast(), got.IsNewsletter())
+		}
+	}
+
+	for _, input := range []string{"", "15551234567", "@s.whatsapp.net", "15551234567@", "a@b@c", ":1@s.whatsapp.net", "15551234567:x@s.whatsapp.net", "15551234567:-1@s.whatsapp.net"} {
+		if _, err := ParseJID(input); err == nil {
+			t.Errorf("Expected ParseJID(%q) to fail", input)
+		}
+	}
+}
+
+func TestJIDString(t *testing.T) {
+	for _, s := range []string{"15551234567@s.whatsapp.net", "15551234567:12@s.whatsapp.net", "123456789-987654321@g.us", "status@broadcast"} {
+		jid, err := ParseJID(s)
+		if err != nil {
+			t.Fatalf("ParseJID(%q) failed: %v", s, err)
+		}
+		if got := jid.String(); got != s {
+			t.Errorf("Expected %q to format as itself, got %q", s, got)
+		}
+	}
+	if got := (JID{User: "15551234567", Server: ContactServer}).String(); got != "15551234567@s.whatsapp.net" {
+		t.Errorf("Expected device 0 to be left out, got %q", got)
+	}
+}
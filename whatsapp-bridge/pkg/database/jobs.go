@@ -0,0 +1,69 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateJob records a new queued job, generating its ID if empty and
+// setting its creation time
+func (s *Store) CreateJob(job *Job) error {
+	if job.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return fmt.Errorf("failed to generate job ID: %w", err)
+		}
+		job.ID = id
+	}
+	job.Status = JobStatusQueued
+	job.CreatedAt = time.Now().UTC()
+	job.CompletedAt = nil
+	job.Error = ""
+
+	_, err := s.db.Exec(
+		"INSERT INTO jobs (id, status, created_at) VALUES (?, ?, ?)",
+		job.ID, job.Status, job.CreatedAt,
+	)
+	return err
+}
+
+// UpdateJobStatus moves a job to status. Finishing a job records at as its
+// completion time along with jobErr, which is empty on success. It returns
+// sql.ErrNoRows if the job does not exist or has already finished.
+func (s *Store) UpdateJobStatus(id string, status JobStatus, jobErr string, at time.Time) error {
+	var completedAt *time.Time
+	if status.IsFinal() {
+		at = at.UTC()
+		completedAt = &at
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE jobs SET status = ?, completed_at = ?, error = NULLIF(?, '')
+		WHERE id = ? AND status NOT IN (?, ?)`,
+		status, completedAt, jobErr, id, JobStatusCompleted, JobStatusFailed,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetJob retrieves a job by ID, returning sql.ErrNoRows if it does not exist
+func (s *Store) GetJob(id string) (*Job, error) {
+	job := &Job{}
+	var completedAt sql.NullTime
+	err := s.db.QueryRow(
+		"SELECT id, status, created_at, completed_at, COALESCE(error, '') FROM jobs WHERE id = ?",
+		id,
+	).Scan(&job.ID, &job.Status, &job.CreatedAt, &completedAt, &job.Error)
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+
+	return job, nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobs(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ok, failed := &Job{}, &Job{}
+	for _, job := range []*Job{ok, failed} {
+		if err := store.CreateJob(job); err != nil {
+			t.Fatalf("Failed to create job: %v", err)
+		}
+		if job.ID == "" || job.Status != JobStatusQueued || job.CreatedAt.IsZero() {
+			t.Errorf("Expected a generated ID and queued status, got %+v", job)
+		}
+	}
+
+	now := time.Now()
+	if err := store.UpdateJobStatus(ok.ID, JobStatusRunning, "", now); err != nil {
+		t.Fatalf("Failed to mark job running: %v", err)
+	}
+	job, err := store.GetJob(ok.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.Status != JobStatusRunning || job.CompletedAt != nil {
+		t.Errorf("Expected a running job without completion time, got %+v", job)
+	}
+
+	if err := store.UpdateJobStatus(ok.ID, JobStatusCompleted, "", now); err != nil {
+		t.Fatalf("Failed to complete job: %v", err)
+	}
+	if err := store.UpdateJobStatus(failed.ID, JobStatusFailed, "not connected", now); err != nil {
+		t.Fatalf("Failed to fail job: %v", err)
+	}
+
+	job, err = store.GetJob(ok.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.Status != JobStatusCompleted || job.CompletedAt == nil || !job.CompletedAt.Equal(now.UTC()) || job.Error != "" {
+		t.Errorf("Expected a completed job, got %+v", job)
+	}
+	job, err = store.GetJob(failed.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.Status != JobStatusFailed || job.Error != "not connected" {
+		t.Errorf("Expected a failed job with its error, got %+v", job)
+	}
+
+	// Finished jobs stay finished
+	if err := store.UpdateJobStatus(ok.ID, JobStatusRunning, "", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows updating a finished job, got %v", err)
+	}
+	if _, err := store.GetJob("missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for an unknown job, got %v", err)
+	}
+}
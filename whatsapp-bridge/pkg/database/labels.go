@@ -0,0 +1,141 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLabelExists is returned when a label name is already taken
+var ErrLabelExists = errors.New("label already exists")
+
+// CreateLabel stores a new label, generating its ID if empty. It returns
+// ErrLabelExists if the name or ID is already used.
+func (s *Store) CreateLabel(l *Label) error {
+	l.Name = strings.TrimSpace(l.Name)
+	if l.Name == "" {
+		return fmt.Errorf("label name cannot be empty")
+	}
+	if l.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return fmt.Errorf("failed to generate label ID: %w", err)
+		}
+		l.ID = id
+	}
+
+	_, err := s.db.Exec("INSERT INTO labels (id, name, color) VALUES (?, ?, ?)", l.ID, l.Name, l.Color)
+	if isUniqueViolation(err) {
+		return ErrLabelExists
+	}
+	return err
+}
+
+// UpdateLabel renames or recolors a label. It returns sql.ErrNoRows if the
+// label does not exist and ErrLabelExists if the new name is taken.
+func (s *Store) UpdateLabel(l *Label) error {
+	l.Name = strings.TrimSpace(l.Name)
+	if l.Name == "" {
+		return fmt.Errorf("label name cannot be empty")
+	}
+
+	result, err := s.db.Exec("UPDATE labels SET name = ?, color = ? WHERE id = ?", l.Name, l.Color, l.ID)
+	if isUniqueViolation(err) {
+		return ErrLabelExists
+	}
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// DeleteLabel removes a label and its chat associations. It returns
+// sql.ErrNoRows if the label does not exist.
+func (s *Store) DeleteLabel(id string) error {
+	result, err := s.db.Exec("DELETE FROM labels WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetLabel retrieves a label by ID. It returns sql.ErrNoRows if the label
+// does not exist.
+func (s *Store) GetLabel(id string) (*Label, error) {
+	l := &Label{}
+	err := s.db.QueryRow(
+		"SELECT id, name, COALESCE(color, '') FROM labels WHERE id = ?", id,
+	).Scan(&l.ID, &l.Name, &l.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// GetLabels retrieves all labels ordered by name
+func (s *Store) GetLabels() ([]*Label, error) {
+	rows, err := s.db.Query("SELECT id, name, COALESCE(color, '') FROM labels ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := []*Label{}
+	for rows.Next() {
+		l := &Label{}
+		if err := rows.Scan(&l.ID, &l.Name, &l.Color); err != nil {
+			return nil, err
+		}
+		labels = append(labels, l)
+	}
+
+	return labels, rows.Err()
+}
+
+// AddChatLabel attaches a label to a chat; attaching it twice is a no-op. It
+// returns sql.ErrNoRows if the chat or label does not exist.
+func (s *Store) AddChatLabel(chatJID, labelID string) error {
+	_, err := s.db.Exec(
+		"INSERT INTO chat_labels (chat_jid, label_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		chatJID, labelID,
+	)
+	if isForeignKeyViolation(err) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// RemoveChatLabel detaches a label from a chat. It returns sql.ErrNoRows if
+// the chat does not carry the label.
+func (s *Store) RemoveChatLabel(chatJID, labelID string) error {
+	result, err := s.db.Exec(
+		"DELETE FROM chat_labels WHERE chat_jid = ? AND label_id = ?",
+		chatJID, labelID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetChatsByLabel retrieves the chats carrying a label with offset
+// pagination, most recent activity first
+func (s *Store) GetChatsByLabel(labelID string, limit, offset int) ([]*Chat, error) {
+	opts := ChatOptions{LabelID: labelID}
+	conds, args := chatConditions(opts)
+	rows, err := s.db.Query(
+		chatSelect(opts)+whereClause(conds)+` ORDER BY c.last_message_time DESC LIMIT ? OFFSET ?`,
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChats(rows)
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLabels(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	now := time.Now()
+	chats := []string{"111111111@s.whatsapp.net", "222222222@s.whatsapp.net", "333333333@s.whatsapp.net"}
+	for i, jid := range chats {
+		store.StoreChat(&Chat{JID: jid, Name: jid, LastMessageTime: now.Add(time.Duration(i) * time.Minute)})
+	}
+
+	work := &Label{Name: "Work", Color: "#0000ff"}
+	if err := store.CreateLabel(work); err != nil {
+		t.Fatalf("Failed to create label: %v", err)
+	}
+	if work.ID == "" {
+		t.Fatalf("Expected generated label ID")
+	}
+	if err := store.CreateLabel(&Label{Name: "Work"}); !errors.Is(err, ErrLabelExists) {
+		t.Errorf("Expected ErrLabelExists for duplicate name, got %v", err)
+	}
+
+	for _, jid := range chats[:2] {
+		if err := store.AddChatLabel(jid, work.ID); err != nil {
+			t.Fatalf("Failed to label chat: %v", err)
+		}
+	}
+	if err := store.AddChatLabel(chats[0], work.ID); err != nil {
+		t.Errorf("Expected labeling twice to be a no-op, got %v", err)
+	}
+	if err := store.AddChatLabel(chats[0], "missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing label, got %v", err)
+	}
+
+	labeled, err := store.GetChatsByLabel(work.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get chats by label: %v", err)
+	}
+	if len(labeled) != 2 || labeled[0].JID != chats[1] {
+		t.Errorf("Expected 2 labeled chats newest first, got %d", len(labeled))
+	}
+
+	page, err := store.GetChatsPage(10, Cursor{}, ChatOptions{LabelID: work.ID})
+	if err != nil {
+		t.Fatalf("Failed to get chats page: %v", err)
+	}
+	if len(page.Items) != 2 {
+		t.Errorf("Expected 2 chats filtered by label, got %d", len(page.Items))
+	}
+
+	if err := store.RemoveChatLabel(chats[0], work.ID); err != nil {
+		t.Fatalf("Failed to remove chat label: %v", err)
+	}
+	if err := store.RemoveChatLabel(chats[0], work.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows removing missing label, got %v", err)
+	}
+
+	work.Name = "Office"
+	if err := store.UpdateLabel(work); err != nil {
+		t.Fatalf("Failed to update label: %v", err)
+	}
+	got, err := store.GetLabel(work.ID)
+	if err != nil || got.Name != "Office" {
+		t.Errorf("Expected renamed label, got %+v (err %v)", got, err)
+	}
+
+	// Deleting a label removes its associations
+	if err := store.DeleteLabel(work.ID); err != nil {
+		t.Fatalf("Failed to delete label: %v", err)
+	}
+	labels, _ := store.GetLabels()
+	labeled, _ = store.GetChatsByLabel(work.ID, 10, 0)
+	if len(labels) != 0 || len(labeled) != 0 {
+		t.Errorf("Expected no labels or labeled chats, got %d and %d", len(labels), len(labeled))
+	}
+}
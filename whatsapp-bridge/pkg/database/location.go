@@ -0,0 +1,120 @@
+package database
+
+import (
+	"database/sql"
+	"math"
+	"time"
+)
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// haversineKm returns the great-circle distance in kilometres between two
+// points given in decimal degrees. It is registered as the haversine_km SQL
+// function because SQLite is built without trigonometric functions.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// boundingBox returns the latitudes and longitudes within which every point
+// radiusKm from a point lies, so the location index can rule out most rows
+// before the haversine distance is computed. One degree of latitude is the
+// same distance everywhere; degrees of longitude shrink towards the poles.
+// Near a pole or across the antimeridian every longitude is included.
+func boundingBox(lat, lon, radiusKm float64) (minLat, maxLat, minLon, maxLon float64) {
+	latDelta := radiusKm / (earthRadiusKm * math.Pi / 180)
+	minLat, maxLat = lat-latDelta, lat+latDelta
+	minLon, maxLon = -180, 180
+	if minLat > -90 && maxLat < 90 {
+		lonDelta := latDelta / math.Cos(math.Max(math.Abs(minLat), math.Abs(maxLat))*math.Pi/180)
+		if lon-lonDelta >= -180 && lon+lonDelta <= 180 {
+			minLon, maxLon = lon-lonDelta, lon+lonDelta
+		}
+	}
+	return minLat, maxLat, minLon, maxLon
+}
+
+// GetNearbyMessages retrieves shared locations within radiusKm of a point,
+// nearest first. Soft-deleted messages are excluded.
+func (s *Store) GetNearbyMessages(lat, lon float64, radiusKm float64, limit int) ([]*Message, error) {
+	return s.nearbyMessages(lat, lon, radiusKm, time.Time{}, limit)
+}
+
+// GetNearbyLocations retrieves the locations shared within radiusKm of a
+// point since the given time, nearest first. A zero since includes every
+// location. Soft-deleted messages are excluded.
+func (s *Store) GetNearbyLocations(lat, lon, radiusKm float64, since time.Time, limit int) ([]*LocationMessage, error) {
+	messages, err := s.nearbyMessages(lat, lon, radiusKm, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]*LocationMessage, len(messages))
+	for i, msg := range messages {
+		locations[i] = msg.Location()
+	}
+	return locations, nil
+}
+
+// nearbyMessages retrieves messages sharing a location within radiusKm of a
+// point, sent at or after since, nearest first
+func (s *Store) nearbyMessages(lat, lon, radiusKm float64, since time.Time, limit int) ([]*Message, error) {
+	minLat, maxLat, minLon, maxLon := boundingBox(lat, lon, radiusKm)
+
+	rows, err := s.db.Query(`
+		SELECT `+messageColumns+`
+		FROM (
+			SELECT `+messageColumns+`, haversine_km(?, ?, location_lat, location_lon) AS distance_km
+			FROM messages
+			WHERE location_lat BETWEEN ? AND ? AND location_lon BETWEEN ? AND ?
+				AND timestamp >= ? AND is_deleted = 0
+		)
+		WHERE distance_km <= ?
+		ORDER BY distance_km
+		LIMIT ?`,
+		lat, lon, minLat, maxLat, minLon, maxLon, since.UTC(), radiusKm, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+// StoreLocation sets the location shared by a stored message. It returns
+// sql.ErrNoRows if the message does not exist.
+func (s *Store) StoreLocation(loc *LocationMessage) error {
+	result, err := s.db.Exec(`
+		UPDATE messages SET
+			location_lat = ?, location_lon = ?, location_address = NULLIF(?, ''), location_name = NULLIF(?, ''),
+			location_is_live = ?, location_live_expiry = ?
+		WHERE id = ? AND chat_jid = ?`,
+		loc.Latitude, loc.Longitude, loc.Address, loc.Name, loc.IsLive, utcTime(loc.LiveExpiry),
+		loc.MessageID, loc.ChatJID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetLocation retrieves the location shared by a message. It returns
+// sql.ErrNoRows if the message does not exist or shares no location.
+func (s *Store) GetLocation(messageID, chatJID string) (*LocationMessage, error) {
+	msg, err := s.GetMessage(messageID, chatJID)
+	if err != nil {
+		return nil, err
+	}
+	loc := msg.Location()
+	if loc == nil {
+		return nil, sql.ErrNoRows
+	}
+	return loc, nil
+}
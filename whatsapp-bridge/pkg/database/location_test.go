@@ -0,0 +1,177 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGetNearbyMessages(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+
+	place := func(id string, lat, lon float64) *Message {
+		return &Message{ID: id, ChatJID: chat.JID, Sender: "123456789", Timestamp: time.Now(), LocationLat: &lat, LocationLon: &lon}
+	}
+	msgs := []*Message{
+		place("brandenburg-gate", 52.5163, 13.3777),
+		place("alexanderplatz", 52.5219, 13.4132),
+		place("potsdam", 52.3906, 13.0645),
+		place("paris", 48.8566, 2.3522),
+		{ID: "text", ChatJID: chat.JID, Sender: "123456789", Content: "hello", Timestamp: time.Now()},
+	}
+	msgs[0].LocationName = "Brandenburger Tor"
+	msgs[0].LocationAddress = "Pariser Platz, 10117 Berlin"
+	for _, msg := range msgs {
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message %s: %v", msg.ID, err)
+		}
+	}
+
+	// From Berlin's town hall
+	nearby, err := store.GetNearbyMessages(52.5186, 13.4081, 5, 10)
+	if err != nil {
+		t.Fatalf("Failed to get nearby messages: %v", err)
+	}
+	if len(nearby) != 2 || nearby[0].ID != "alexanderplatz" || nearby[1].ID != "brandenburg-gate" {
+		t.Fatalf("Expected alexanderplatz then brandenburg-gate, got %v", nearby)
+	}
+	if !nearby[1].IsLocation() || *nearby[1].LocationLat != 52.5163 || nearby[1].LocationName != "Brandenburger Tor" {
+		t.Errorf("Unexpected location fields: %+v", nearby[1])
+	}
+
+	nearby, err = store.GetNearbyMessages(52.5186, 13.4081, 50, 10)
+	if err != nil {
+		t.Fatalf("Failed to get nearby messages: %v", err)
+	}
+	if len(nearby) != 3 {
+		t.Errorf("Expected Potsdam within 50km, got %d messages", len(nearby))
+	}
+
+	messages, _ := store.GetMessages(chat.JID, 10, 0)
+	for _, msg := range messages {
+		if msg.ID == "text" && msg.IsLocation() {
+			t.Errorf("Expected a text message not to be a location")
+		}
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	// Berlin to Paris is about 878km
+	if d := haversineKm(52.5200, 13.4050, 48.8566, 2.3522); math.Abs(d-878) > 5 {
+		t.Errorf("Expected about 878km, got %.1f", d)
+	}
+	if d := haversineKm(10, 20, 10, 20); d != 0 {
+		t.Errorf("Expected 0km between identical points, got %v", d)
+	}
+}
+
+func TestStoreAndGetLocation(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	for _, id := range []string{"live", "text"} {
+		msg := &Message{ID: id, ChatJID: chat.JID, Sender: "123456789", Content: "hello", Timestamp: time.Now()}
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message %s: %v", id, err)
+		}
+	}
+
+	if _, err := store.GetLocation("text", chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a message without a location, got %v", err)
+	}
+
+	expiry := time.Date(2026, 3, 1, 18, 0, 0, 0, time.FixedZone("CET", 3600))
+	loc := &LocationMessage{
+		MessageID: "live", ChatJID: chat.JID,
+		Latitude: 52.5163, Longitude: 13.3777, Name: "Brandenburger Tor",
+		IsLive: true, LiveExpiry: &expiry,
+	}
+	if err := store.StoreLocation(loc); err != nil {
+		t.Fatalf("Failed to store location: %v", err)
+	}
+
+	got, err := store.GetLocation("live", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get location: %v", err)
+	}
+	if got.Latitude != 52.5163 || got.Longitude != 13.3777 || got.Name != "Brandenburger Tor" || got.Address != "" || !got.IsLive {
+		t.Errorf("Unexpected location: %+v", got)
+	}
+	if got.LiveExpiry == nil || !got.LiveExpiry.Equal(expiry) {
+		t.Errorf("Expected live expiry %v, got %v", expiry, got.LiveExpiry)
+	}
+
+	if err := store.StoreLocation(&LocationMessage{MessageID: "missing", ChatJID: chat.JID}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing message, got %v", err)
+	}
+	if _, err := store.GetLocation("missing", chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing message, got %v", err)
+	}
+}
+
+func TestGetNearbyLocations(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+
+	now := time.Now()
+	place := func(id string, lat, lon float64, ts time.Time) *Message {
+		return &Message{ID: id, ChatJID: chat.JID, Sender: "123456789", Timestamp: ts, LocationLat: &lat, LocationLon: &lon}
+	}
+	for _, msg := range []*Message{
+		place("alexanderplatz", 52.5219, 13.4132, now),
+		place("brandenburg-gate", 52.5163, 13.3777, now.Add(-48*time.Hour)),
+		// Same latitude, outside the longitude bounds
+		place("rheinsberger-see", 52.5186, 12.8, now),
+	} {
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message %s: %v", msg.ID, err)
+		}
+	}
+
+	locations, err := store.GetNearbyLocations(52.5186, 13.4081, 5, time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("Failed to get nearby locations: %v", err)
+	}
+	if len(locations) != 2 || locations[0].MessageID != "alexanderplatz" || locations[1].MessageID != "brandenburg-gate" {
+		t.Fatalf("Expected alexanderplatz then brandenburg-gate, got %+v", locations)
+	}
+
+	locations, err = store.GetNearbyLocations(52.5186, 13.4081, 5, now.Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("Failed to get nearby locations: %v", err)
+	}
+	if len(locations) != 1 || locations[0].MessageID != "alexanderplatz" {
+		t.Errorf("Expected only alexanderplatz in the last hour, got %+v", locations)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	minLat, maxLat, minLon, maxLon := boundingBox(52.5, 13.4, 10)
+	for _, p := range [][2]float64{{52.59, 13.4}, {52.5, 13.54}, {52.41, 13.26}} {
+		if haversineKm(52.5, 13.4, p[0], p[1]) <= 10 &&
+			!(p[0] >= minLat && p[0] <= maxLat && p[1] >= minLon && p[1] <= maxLon) {
+			t.Errorf("Expected %v within 10km to be inside the box", p)
+		}
+	}
+	if maxLon-minLon >= 360 {
+		t.Errorf("Expected the box to narrow the longitude, got %v to %v", minLon, maxLon)
+	}
+
+	// Near a pole and across the antimeridian every longitude is searched
+	for _, c := range [][2]float64{{89.99, 0}, {0, 179.99}} {
+		if _, _, minLon, maxLon := boundingBox(c[0], c[1], 10); minLon != -180 || maxLon != 180 {
+			t.Errorf("Expected all longitudes around %v, got %v to %v", c, minLon, maxLon)
+		}
+	}
+}
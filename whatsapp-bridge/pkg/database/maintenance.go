@@ -0,0 +1,85 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrMaintenanceInProgress is returned by Backup and Vacuum when another
+// maintenance operation is already running on the store
+var ErrMaintenanceInProgress = errors.New("database maintenance already in progress")
+
+// Vacuum rebuilds the database file to return the space freed by deleted rows
+// to the filesystem. VACUUM locks the whole file while it runs, so it fails
+// with ErrMaintenanceInProgress rather than queueing behind a running Backup
+// or Vacuum.
+func (s *Store) Vacuum() error {
+	if !s.maintenanceMu.TryLock() {
+		return ErrMaintenanceInProgress
+	}
+	defer s.maintenanceMu.Unlock()
+
+	// Fold the WAL into the main file first so VACUUM sees every page, and
+	// again afterwards so the rebuilt, smaller file is written back and the
+	// WAL truncated
+	if err := s.CheckpointWAL(); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+
+	return s.CheckpointWAL()
+}
+
+// CheckpointWAL copies every committed page from the write-ahead log into the
+// database file and truncates the log. It waits for readers of old pages to
+// finish, up to the busy timeout.
+func (s *Store) CheckpointWAL() error {
+	if _, err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint WAL: %w", err)
+	}
+
+	return nil
+}
+
+// WALCheckpointInterval returns how often the WAL should be checkpointed,
+// as set by WithWALCheckpointInterval, or zero if it was not set
+func (s *Store) WALCheckpointInterval() time.Duration {
+	return s.walCheckpointInterval
+}
+
+// DatabaseStats reports the database file and WAL sizes and the number of
+// messages and chats. It runs only readers and a passive checkpoint, so it
+// never blocks writers and is safe to call under load.
+func (s *Store) DatabaseStats() (*DBStats, error) {
+	stats := &DBStats{}
+
+	var pageCount int64
+	if err := s.db.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return nil, fmt.Errorf("failed to get page count: %w", err)
+	}
+	if err := s.db.QueryRow("PRAGMA page_size").Scan(&stats.PageSize); err != nil {
+		return nil, fmt.Errorf("failed to get page size: %w", err)
+	}
+	stats.FileSizeBytes = pageCount * stats.PageSize
+
+	// A passive checkpoint reports the number of frames in the WAL
+	var busy, walFrames, checkpointed int64
+	if err := s.db.QueryRow("PRAGMA wal_checkpoint").Scan(&busy, &walFrames, &checkpointed); err != nil {
+		return nil, fmt.Errorf("failed to get WAL size: %w", err)
+	}
+	if walFrames > 0 {
+		stats.WALSizeBytes = walFrames * stats.PageSize
+	}
+
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM messages").Scan(&stats.MessageCount); err != nil {
+		return nil, fmt.Errorf("failed to count messages: %w", err)
+	}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM chats").Scan(&stats.ChatCount); err != nil {
+		return nil, fmt.Errorf("failed to count chats: %w", err)
+	}
+
+	return stats, nil
+}
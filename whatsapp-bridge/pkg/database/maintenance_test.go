@@ -0,0 +1,103 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVacuum(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	var seq int
+	var name, dbPath string
+	if err := store.db.QueryRow("PRAGMA database_list").Scan(&seq, &name, &dbPath); err != nil {
+		t.Fatalf("Failed to get database path: %v", err)
+	}
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	msgs := testMessages(chat.JID, 2000)
+	for _, msg := range msgs {
+		msg.Content = strings.Repeat("x", 1000)
+	}
+	if err := store.BulkStoreMessages(msgs); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+
+	ids := make([]string, 0, len(msgs)/2)
+	for i := 0; i < len(msgs)/2; i++ {
+		ids = append(ids, fmt.Sprintf("msg%d", i))
+	}
+	if err := store.BulkDeleteMessages(ids, chat.JID); err != nil {
+		t.Fatalf("Failed to delete messages: %v", err)
+	}
+
+	// Measure after a checkpoint so the deleted rows' pages are in the file
+	if _, err := store.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		t.Fatalf("Failed to checkpoint: %v", err)
+	}
+	before, err := os.Stat(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to stat database: %v", err)
+	}
+
+	if err := store.Vacuum(); err != nil {
+		t.Fatalf("Failed to vacuum: %v", err)
+	}
+
+	after, err := os.Stat(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to stat database: %v", err)
+	}
+	if after.Size() >= before.Size() {
+		t.Errorf("Expected database to shrink from %d bytes, got %d", before.Size(), after.Size())
+	}
+
+	remaining, err := store.GetMessages(chat.JID, len(msgs), 0)
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(remaining) != len(msgs)/2 {
+		t.Errorf("Expected %d messages after vacuum, got %d", len(msgs)/2, len(remaining))
+	}
+
+	// Maintenance operations do not overlap
+	store.maintenanceMu.Lock()
+	if err := store.Vacuum(); !errors.Is(err, ErrMaintenanceInProgress) {
+		t.Errorf("Expected ErrMaintenanceInProgress, got %v", err)
+	}
+	if err := store.Backup(t.TempDir() + "/backup.db"); !errors.Is(err, ErrMaintenanceInProgress) {
+		t.Errorf("Expected ErrMaintenanceInProgress, got %v", err)
+	}
+	store.maintenanceMu.Unlock()
+}
+
+func TestDatabaseStats(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	if err := store.BulkStoreMessages(testMessages(chat.JID, 10)); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+
+	stats, err := store.DatabaseStats()
+	if err != nil {
+		t.Fatalf("Failed to get database stats: %v", err)
+	}
+	if stats.MessageCount != 10 || stats.ChatCount != 1 {
+		t.Errorf("Expected 10 messages in 1 chat, got %d in %d", stats.MessageCount, stats.ChatCount)
+	}
+	if stats.PageSize <= 0 || stats.FileSizeBytes < stats.PageSize {
+		t.Errorf("Expected a positive page and file size, got %+v", stats)
+	}
+	if stats.WALSizeBytes <= 0 {
+		t.Errorf("Expected pending WAL frames after writes, got %d", stats.WALSizeBytes)
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import "database/sql"
+
+// StoreMediaMetadata inserts or replaces the metadata the bridge holds for a
+// message's attachment. Zero fields fall back to what the message reported.
+// It returns sql.ErrNoRows if the message does not exist.
+func (s *Store) StoreMediaMetadata(md *MediaMetadata) error {
+	_, err := s.db.Exec(`
+		INSERT INTO media_metadata (message_id, chat_jid, mime_type, width, height, duration_ms, thumbnail, local_path)
+		VALUES (?, ?, NULLIF(?, ''), NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, x''), NULLIF(?, ''))
+		ON CONFLICT (message_id, chat_jid) DO UPDATE SET
+			mime_type = excluded.mime_type, width = excluded.width, height = excluded.height,
+			duration_ms = excluded.duration_ms, thumbnail = excluded.thumbnail, local_path = excluded.local_path`,
+		md.MessageID, md.ChatJID, md.MimeType, md.Width, md.Height, md.DurationMs, md.ThumbnailData, md.LocalPath,
+	)
+	if isForeignKeyViolation(err) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// UpdateLocalPath records where a message's attachment was downloaded,
+// leaving the rest of its metadata unchanged. It returns sql.ErrNoRows if the
+// message does not exist.
+func (s *Store) UpdateLocalPath(messageID, chatJID, path string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO media_metadata (message_id, chat_jid, local_path) VALUES (?, ?, NULLIF(?, ''))
+		ON CONFLICT (message_id, chat_jid) DO UPDATE SET local_path = excluded.local_path`,
+		messageID, chatJID, path,
+	)
+	if isForeignKeyViolation(err) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// GetMediaMetadata retrieves the metadata of a message's attachment. WhatsApp
+// reports durations in whole seconds, so unless the bridge stored a more
+// precise one DurationMs is a multiple of 1000. It returns sql.ErrNoRows if
+// the message does not exist or has no attachment.
+func (s *Store) GetMediaMetadata(messageID, chatJID string) (*MediaMetadata, error) {
+	md := &MediaMetadata{MessageID: messageID, ChatJID: chatJID}
+	var localPath sql.NullString
+	err := s.db.QueryRow(`
+		SELECT
+			COALESCE(md.mime_type, m.media_mime_type, ''),
+			COALESCE(md.width, m.media_width, 0),
+			COALESCE(md.height, m.media_height, 0),
+			COALESCE(md.duration_ms, m.media_duration_seconds * 1000, 0),
+			COALESCE(md.thumbnail, m.thumbnail),
+			md.local_path
+		FROM messages m
+		LEFT JOIN media_metadata md ON md.message_id = m.id AND md.chat_jid = m.chat_jid
+		WHERE m.id = ? AND m.chat_jid = ? AND (m.media_type != '' OR md.message_id IS NOT NULL)`,
+		messageID, chatJID,
+	).Scan(&md.MimeType, &md.Width, &md.Height, &md.DurationMs, &md.ThumbnailData, &localPath)
+	if err != nil {
+		return nil, err
+	}
+	md.LocalPath = localPath.String
+	return md, nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStoreMediaMetadata(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	for _, msg := range []*Message{
+		{ID: "video", ChatJID: chat.JID, Sender: "123456789", Timestamp: time.Now(), MediaType: "video",
+			MediaMimeType: "video/mp4", MediaWidth: 1280, MediaHeight: 720, MediaDurationSeconds: 12, Thumbnail: []byte{0xff, 0xd8}},
+		{ID: "text", ChatJID: chat.JID, Sender: "123456789", Content: "hello", Timestamp: time.Now()},
+	} {
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message %s: %v", msg.ID, err)
+		}
+	}
+
+	// Before the bridge stores anything, the message's own fields are used
+	md, err := store.GetMediaMetadata("video", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get media metadata: %v", err)
+	}
+	want := MediaMetadata{MessageID: "video", ChatJID: chat.JID, MimeType: "video/mp4", Width: 1280, Height: 720, DurationMs: 12000}
+	if md.MimeType != want.MimeType || md.Width != want.Width || md.Height != want.Height || md.DurationMs != want.DurationMs ||
+		!bytes.Equal(md.ThumbnailData, []byte{0xff, 0xd8}) || md.LocalPath != "" {
+		t.Errorf("Expected %+v with the message's thumbnail, got %+v", want, md)
+	}
+
+	if err := store.UpdateLocalPath("video", chat.JID, "/media/videos/video.mp4"); err != nil {
+		t.Fatalf("Failed to update local path: %v", err)
+	}
+	if err := store.StoreMediaMetadata(&MediaMetadata{MessageID: "video", ChatJID: chat.JID, Width: 640, Height: 360, DurationMs: 12345, LocalPath: "/media/videos/video.mp4"}); err != nil {
+		t.Fatalf("Failed to store media metadata: %v", err)
+	}
+	// Re-syncing the message does not overwrite what the bridge stored
+	if err := store.StoreMessage(&Message{ID: "video", ChatJID: chat.JID, Sender: "123456789", Timestamp: time.Now(), MediaType: "video", MediaWidth: 1280}); err != nil {
+		t.Fatalf("Failed to store message: %v", err)
+	}
+
+	md, err = store.GetMediaMetadata("video", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get media metadata: %v", err)
+	}
+	if md.Width != 640 || md.Height != 360 || md.DurationMs != 12345 || md.LocalPath != "/media/videos/video.mp4" {
+		t.Errorf("Expected the stored metadata, got %+v", md)
+	}
+
+	if err := store.UpdateLocalPath("video", chat.JID, "/media/videos/moved.mp4"); err != nil {
+		t.Fatalf("Failed to update local path: %v", err)
+	}
+	if md, _ := store.GetMediaMetadata("video", chat.JID); md.LocalPath != "/media/videos/moved.mp4" || md.Width != 640 {
+		t.Errorf("Expected only the local path to change, got %+v", md)
+	}
+
+	if _, err := store.GetMediaMetadata("text", chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a message without media, got %v", err)
+	}
+	if _, err := store.GetMediaMetadata("missing", chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing message, got %v", err)
+	}
+	if err := store.StoreMediaMetadata(&MediaMetadata{MessageID: "missing", ChatJID: chat.JID}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows storing metadata for a missing message, got %v", err)
+	}
+	if err := store.UpdateLocalPath("missing", chat.JID, "/tmp/x"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows updating a missing message, got %v", err)
+	}
+}
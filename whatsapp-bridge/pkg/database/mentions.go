@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database/sql"
+
+	"whatsapp-client/pkg/validation"
+)
+
+// storeMentions replaces the recorded mentions of a message with the ones
+// found in its current content
+func storeMentions(tx *sql.Tx, msg *Message) error {
+	_, err := tx.Exec("DELETE FROM mentions WHERE message_id = ? AND chat_jid = ?", msg.ID, msg.ChatJID)
+	if err != nil {
+		return err
+	}
+
+	for _, jid := range validation.ExtractMentions(msg.Content) {
+		_, err := tx.Exec(
+			"INSERT INTO mentions (message_id, chat_jid, mentioned_jid) VALUES (?, ?, ?)",
+			msg.ID, msg.ChatJID, jid,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetMentionsForJID retrieves messages mentioning a user with pagination,
+// newest first. Soft-deleted messages are excluded.
+func (s *Store) GetMentionsForJID(jid string, limit, offset int) ([]*Message, error) {
+	rows, err := s.db.Query(`
+		SELECT `+qualifiedMessageColumns+`
+		FROM mentions mn
+		JOIN messages m ON m.id = mn.message_id AND m.chat_jid = mn.chat_jid
+		WHERE mn.mentioned_jid = ? AND m.is_deleted = 0
+		ORDER BY m.timestamp DESC, m.id DESC
+		LIMIT ? OFFSET ?`,
+		jid, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []*Message{}
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMentions(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	group := &Chat{JID: "123456789-123456@g.us", Name: "Test Group", LastMessageTime: time.Now()}
+	store.StoreChat(group)
+
+	alice := "1234567890@s.whatsapp.net"
+	bob := "15551234567@s.whatsapp.net"
+
+	msgs := testMessages(group.JID, 3)
+	msgs[0].Content = "hi @1234567890"
+	msgs[1].Content = "@1234567890 and @15551234567, lunch?"
+	msgs[2].Content = "no mentions"
+	if err := store.BulkStoreMessages(msgs[:2]); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+	if err := store.StoreMessage(msgs[2]); err != nil {
+		t.Fatalf("Failed to store message: %v", err)
+	}
+
+	mentions, err := store.GetMentionsForJID(alice, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get mentions: %v", err)
+	}
+	if len(mentions) != 2 || mentions[0].ID != "msg1" || mentions[1].ID != "msg0" {
+		t.Errorf("Expected msg1 and msg0 to mention alice, got %d messages", len(mentions))
+	}
+
+	// Editing a message replaces its mentions
+	msgs[1].Content = "just @15551234567"
+	if err := store.StoreMessage(msgs[1]); err != nil {
+		t.Fatalf("Failed to update message: %v", err)
+	}
+	mentions, err = store.GetMentionsForJID(alice, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get mentions: %v", err)
+	}
+	if len(mentions) != 1 || mentions[0].ID != "msg0" {
+		t.Errorf("Expected only msg0 to mention alice after the edit, got %d messages", len(mentions))
+	}
+
+	// Deleted messages are hidden
+	if err := store.SoftDeleteMessage("msg1", group.JID, time.Now()); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+	mentions, err = store.GetMentionsForJID(bob, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get mentions: %v", err)
+	}
+	if len(mentions) != 0 {
+		t.Errorf("Expected no mentions of bob after deletion, got %d", len(mentions))
+	}
+}
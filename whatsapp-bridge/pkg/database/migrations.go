@@ -0,0 +1,515 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migration is a numbered schema change. Migrations are applied in order and
+// each one exactly once per database.
+type migration struct {
+	version int
+	name    string
+	apply   func(ctx context.Context, conn *sql.Conn) error
+}
+
+// migrations lists every schema change. Append new migrations with the next
+// version number; never edit or reorder one that has shipped.
+var migrations = []migration{
+	{1, "initial_schema", execMigration(`
+		CREATE TABLE IF NOT EXISTS chats (
+			jid TEXT PRIMARY KEY,
+			name TEXT,
+			last_message_time TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS messages (
+			id TEXT,
+			chat_jid TEXT,
+			sender TEXT,
+			content TEXT,
+			timestamp TIMESTAMP,
+			is_from_me BOOLEAN,
+			media_type TEXT,
+			filename TEXT,
+			url TEXT,
+			media_key BLOB,
+			file_sha256 BLOB,
+			file_enc_sha256 BLOB,
+			file_length INTEGER,
+			PRIMARY KEY (id, chat_jid),
+			FOREIGN KEY (chat_jid) REFERENCES chats(jid)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_messages_chat_jid ON messages(chat_jid);
+		CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
+		CREATE INDEX IF NOT EXISTS idx_messages_sender ON messages(sender);
+		CREATE INDEX IF NOT EXISTS idx_chats_last_message_time ON chats(last_message_time);
+		CREATE INDEX IF NOT EXISTS idx_messages_chat_timestamp_id ON messages(chat_jid, timestamp DESC, id);
+	`)},
+	{2, "message_status", addMessageStatus},
+	{3, "message_soft_delete", execMigration(`
+		ALTER TABLE messages ADD COLUMN is_deleted BOOLEAN NOT NULL DEFAULT 0;
+		ALTER TABLE messages ADD COLUMN deleted_at TIMESTAMP;
+	`)},
+	{4, "chat_unread_count", execMigration(`
+		ALTER TABLE chats ADD COLUMN unread_count INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE chats ADD COLUMN last_read_at TIMESTAMP;
+	`)},
+	{5, "message_quotes", execMigration(`
+		ALTER TABLE messages ADD COLUMN quoted_message_id TEXT;
+		ALTER TABLE messages ADD COLUMN quoted_message_content TEXT;
+	`)},
+	{6, "reactions", execMigration(`
+		CREATE TABLE reactions (
+			message_id TEXT,
+			chat_jid TEXT,
+			sender TEXT,
+			emoji TEXT NOT NULL,
+			timestamp TIMESTAMP,
+			PRIMARY KEY (message_id, chat_jid, sender),
+			FOREIGN KEY (message_id, chat_jid) REFERENCES messages(id, chat_jid) ON DELETE CASCADE
+		);
+	`)},
+	{7, "contacts", execMigration(`
+		CREATE TABLE contacts (
+			jid TEXT PRIMARY KEY,
+			phone TEXT UNIQUE,
+			display_name TEXT,
+			push_name TEXT,
+			business_name TEXT,
+			about TEXT,
+			last_sync TIMESTAMP
+		);
+	`)},
+	{8, "group_participants", execMigration(`
+		CREATE TABLE group_participants (
+			group_jid TEXT,
+			participant_jid TEXT,
+			is_admin BOOLEAN NOT NULL DEFAULT 0,
+			joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			left_at TIMESTAMP,
+			PRIMARY KEY (group_jid, participant_jid)
+		);
+	`)},
+	{9, "call_logs", execMigration(`
+		CREATE TABLE call_logs (
+			id TEXT PRIMARY KEY,
+			from_jid TEXT,
+			to_jid TEXT,
+			call_type TEXT,
+			status TEXT,
+			started_at TIMESTAMP,
+			duration_seconds INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE INDEX idx_call_logs_started_at ON call_logs(started_at);
+	`)},
+	{10, "pinned_messages", execMigration(`
+		CREATE TABLE pinned_messages (
+			message_id TEXT,
+			chat_jid TEXT,
+			pinned_by TEXT,
+			pinned_at TIMESTAMP,
+			PRIMARY KEY (message_id, chat_jid),
+			FOREIGN KEY (message_id, chat_jid) REFERENCES messages(id, chat_jid) ON DELETE CASCADE
+		);
+
+		CREATE INDEX idx_pinned_messages_chat_jid ON pinned_messages(chat_jid);
+	`)},
+	{11, "labels", execMigration(`
+		CREATE TABLE labels (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			color TEXT
+		);
+
+		CREATE TABLE chat_labels (
+			chat_jid TEXT,
+			label_id TEXT,
+			PRIMARY KEY (chat_jid, label_id),
+			FOREIGN KEY (chat_jid) REFERENCES chats(jid) ON DELETE CASCADE,
+			FOREIGN KEY (label_id) REFERENCES labels(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX idx_chat_labels_label_id ON chat_labels(label_id);
+	`)},
+	{12, "scheduled_messages", execMigration(`
+		CREATE TABLE scheduled_messages (
+			id TEXT PRIMARY KEY,
+			recipient TEXT NOT NULL,
+			content TEXT,
+			media_path TEXT,
+			scheduled_at TIMESTAMP NOT NULL,
+			sent_at TIMESTAMP,
+			status TEXT NOT NULL DEFAULT 'pending'
+		);
+
+		CREATE INDEX idx_scheduled_messages_status_scheduled_at ON scheduled_messages(status, scheduled_at);
+	`)},
+	{13, "message_media_metadata", execMigration(`
+		ALTER TABLE messages ADD COLUMN media_width INTEGER;
+		ALTER TABLE messages ADD COLUMN media_height INTEGER;
+		ALTER TABLE messages ADD COLUMN media_duration_seconds INTEGER;
+		ALTER TABLE messages ADD COLUMN thumbnail BLOB;
+		ALTER TABLE messages ADD COLUMN media_mime_type TEXT;
+	`)},
+	{14, "chat_archive", execMigration(`
+		ALTER TABLE chats ADD COLUMN archived_at TIMESTAMP;
+	`)},
+	{15, "chat_mute", execMigration(`
+		ALTER TABLE chats ADD COLUMN mute_until TIMESTAMP;
+	`)},
+	{16, "mentions", execMigration(`
+		CREATE TABLE mentions (
+			message_id TEXT,
+			chat_jid TEXT,
+			mentioned_jid TEXT,
+			PRIMARY KEY (message_id, chat_jid, mentioned_jid),
+			FOREIGN KEY (message_id, chat_jid) REFERENCES messages(id, chat_jid) ON DELETE CASCADE
+		);
+
+		CREATE INDEX idx_mentions_mentioned_jid ON mentions(mentioned_jid);
+	`)},
+	{17, "profile_pictures", execMigration(`
+		CREATE TABLE profile_pictures (
+			jid TEXT PRIMARY KEY,
+			url TEXT,
+			etag TEXT,
+			cached_at TIMESTAMP,
+			expires_at TIMESTAMP
+		);
+	`)},
+	{18, "chat_read_position", execMigration(`
+		ALTER TABLE chats ADD COLUMN last_read_message_id TEXT;
+	`)},
+	{19, "broadcast_lists", execMigration(`
+		CREATE TABLE broadcast_lists (
+			jid TEXT PRIMARY KEY,
+			name TEXT,
+			created_at TIMESTAMP
+		);
+
+		CREATE TABLE broadcast_recipients (
+			list_jid TEXT,
+			recipient_jid TEXT,
+			PRIMARY KEY (list_jid, recipient_jid),
+			FOREIGN KEY (list_jid) REFERENCES broadcast_lists(jid) ON DELETE CASCADE
+		);
+	`)},
+	{20, "polls", execMigration(`
+		CREATE TABLE poll_messages (
+			message_id TEXT,
+			chat_jid TEXT,
+			question TEXT,
+			options JSON,
+			allow_multiple BOOLEAN NOT NULL DEFAULT 0,
+			PRIMARY KEY (message_id, chat_jid)
+		);
+
+		CREATE TABLE poll_votes (
+			message_id TEXT,
+			chat_jid TEXT,
+			voter_jid TEXT,
+			selected_options JSON,
+			voted_at TIMESTAMP,
+			PRIMARY KEY (message_id, chat_jid, voter_jid),
+			FOREIGN KEY (message_id, chat_jid) REFERENCES poll_messages(message_id, chat_jid) ON DELETE CASCADE
+		);
+	`)},
+	{21, "message_location", execMigration(`
+		ALTER TABLE messages ADD COLUMN location_lat REAL;
+		ALTER TABLE messages ADD COLUMN location_lon REAL;
+		ALTER TABLE messages ADD COLUMN location_address TEXT;
+		ALTER TABLE messages ADD COLUMN location_name TEXT;
+
+		CREATE INDEX idx_messages_location ON messages(location_lat, location_lon) WHERE location_lat IS NOT NULL;
+	`)},
+	{22, "message_vcard", execMigration(`
+		ALTER TABLE messages ADD COLUMN vcard_name TEXT;
+		ALTER TABLE messages ADD COLUMN vcard_phone TEXT;
+	`)},
+	{23, "status_messages", execMigration(`
+		CREATE TABLE status_messages (
+			id TEXT PRIMARY KEY,
+			author_jid TEXT,
+			content TEXT,
+			media_type TEXT,
+			url TEXT,
+			media_key BLOB,
+			timestamp TIMESTAMP,
+			expires_at TIMESTAMP
+		);
+
+		CREATE INDEX idx_status_messages_author_jid ON status_messages(author_jid, timestamp);
+		CREATE INDEX idx_status_messages_expires_at ON status_messages(expires_at);
+	`)},
+	{24, "edit_history", execMigration(`
+		ALTER TABLE messages ADD COLUMN is_edited BOOLEAN NOT NULL DEFAULT 0;
+		ALTER TABLE messages ADD COLUMN edited_at TIMESTAMP;
+
+		CREATE TABLE edit_history (
+			message_id TEXT,
+			chat_jid TEXT,
+			edited_at TIMESTAMP,
+			original_content TEXT,
+			revision INTEGER,
+			PRIMARY KEY (message_id, chat_jid, revision),
+			FOREIGN KEY (message_id, chat_jid) REFERENCES messages(id, chat_jid) ON DELETE CASCADE
+		);
+	`)},
+	// The chat/timestamp index matches the keyset order (timestamp DESC, id
+	// DESC) so paging needs no sort step; it also covers chat_jid lookups.
+	// idx_messages_timestamp stays for cross-chat search.
+	{25, "message_chat_timestamp_index", execMigration(`
+		CREATE INDEX IF NOT EXISTS idx_messages_chat_timestamp ON messages(chat_jid, timestamp DESC, id DESC);
+		DROP INDEX IF EXISTS idx_messages_chat_timestamp_id;
+		DROP INDEX IF EXISTS idx_messages_chat_jid;
+		ANALYZE;
+	`)},
+	// Media galleries only walk the messages with attachments
+	{26, "message_chat_media_index", execMigration(`
+		CREATE INDEX IF NOT EXISTS idx_messages_chat_media ON messages(chat_jid, timestamp DESC, id DESC)
+		WHERE media_type != '';
+	`)},
+	{27, "jobs", execMigration(`
+		CREATE TABLE jobs (
+			id TEXT PRIMARY KEY,
+			status TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			completed_at TIMESTAMP,
+			error TEXT
+		);
+	`)},
+	{28, "group_info", execMigration(`
+		CREATE TABLE group_info (
+			jid TEXT PRIMARY KEY,
+			subject TEXT,
+			description TEXT,
+			owner_jid TEXT,
+			created_at TIMESTAMP,
+			last_synced_at TIMESTAMP
+		);
+	`)},
+	{29, "blocked_contacts", execMigration(`
+		CREATE TABLE blocked_contacts (
+			jid TEXT PRIMARY KEY,
+			blocked_at TIMESTAMP
+		);
+	`)},
+	{30, "webhooks", execMigration(`
+		CREATE TABLE webhooks (
+			id TEXT PRIMARY KEY,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			events JSON,
+			created_at TIMESTAMP NOT NULL,
+			last_delivery_at TIMESTAMP,
+			failure_count INTEGER NOT NULL DEFAULT 0
+		);
+	`)},
+	{31, "contact_country_code", addContactCountryCode},
+	{32, "live_location", execMigration(`
+		ALTER TABLE messages ADD COLUMN location_is_live BOOLEAN NOT NULL DEFAULT 0;
+		ALTER TABLE messages ADD COLUMN location_live_expiry TIMESTAMP;
+	`)},
+	// Metadata the bridge computes or changes after the message arrives.
+	// NULL columns fall back to what the message reported.
+	{33, "media_metadata", execMigration(`
+		CREATE TABLE media_metadata (
+			message_id TEXT,
+			chat_jid TEXT,
+			mime_type TEXT,
+			width INTEGER,
+			height INTEGER,
+			duration_ms INTEGER,
+			thumbnail BLOB,
+			local_path TEXT,
+			PRIMARY KEY (message_id, chat_jid),
+			FOREIGN KEY (message_id, chat_jid) REFERENCES messages(id, chat_jid) ON DELETE CASCADE
+		);
+	`)},
+	// Calls were logged by from and to JID with an incoming, outgoing or
+	// missed status. They are now kept by chat and caller. The chat of an
+	// outgoing or group call is its to JID and otherwise the caller.
+	{34, "call_logs_chat_caller", execMigration(`
+		CREATE TABLE call_logs_new (
+			id TEXT PRIMARY KEY,
+			chat_jid TEXT NOT NULL,
+			caller_jid TEXT NOT NULL,
+			call_type TEXT NOT NULL,
+			call_status TEXT NOT NULL,
+			started_at TIMESTAMP,
+			duration_seconds INTEGER NOT NULL DEFAULT 0
+		);
+
+		INSERT INTO call_logs_new (id, chat_jid, caller_jid, call_type, call_status, started_at, duration_seconds)
+		SELECT id,
+			COALESCE(CASE WHEN status = 'outgoing' OR to_jid LIKE '%@g.us' THEN to_jid ELSE from_jid END, ''),
+			COALESCE(from_jid, ''),
+			COALESCE(call_type, 'voice'),
+			CASE status
+				WHEN 'incoming' THEN 'answered'
+				WHEN 'outgoing' THEN CASE WHEN duration_seconds > 0 THEN 'answered' ELSE 'unanswered' END
+				ELSE 'missed'
+			END,
+			started_at, duration_seconds
+		FROM call_logs;
+
+		DROP TABLE call_logs;
+		ALTER TABLE call_logs_new RENAME TO call_logs;
+
+		CREATE INDEX idx_call_logs_started_at ON call_logs(started_at);
+		CREATE INDEX idx_call_logs_chat_jid_started_at ON call_logs(chat_jid, started_at);
+		CREATE INDEX idx_call_logs_call_status_started_at ON call_logs(call_status, started_at);
+	`)},
+}
+
+// execMigration returns a migration step that executes a fixed SQL script
+func execMigration(query string) func(ctx context.Context, conn *sql.Conn) error {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, query)
+		return err
+	}
+}
+
+// addMessageStatus adds the delivery status column to messages. Databases
+// created before migrations were tracked may already have the column.
+func addMessageStatus(ctx context.Context, conn *sql.Conn) error {
+	exists, err := columnExists(ctx, conn, "messages", "status")
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		_, err := conn.ExecContext(ctx, `ALTER TABLE messages ADD COLUMN status TEXT NOT NULL DEFAULT 'sent'`)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = conn.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_messages_status ON messages(status)")
+	return err
+}
+
+// addContactCountryCode adds the country column to contacts and fills it in
+// for the contacts already stored
+func addContactCountryCode(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		ALTER TABLE contacts ADD COLUMN country_code TEXT;
+		CREATE INDEX idx_contacts_country_code ON contacts(country_code);
+	`)
+	if err != nil {
+		return err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT jid, COALESCE(phone, '') FROM contacts")
+	if err != nil {
+		return err
+	}
+	countries := map[string]string{}
+	for rows.Next() {
+		var jid, phone string
+		if err := rows.Scan(&jid, &phone); err != nil {
+			rows.Close()
+			return err
+		}
+		if country := contactCountryCode(jid, phone); country != "" {
+			countries[jid] = country
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for jid, country := range countries {
+		if _, err := conn.ExecContext(ctx, "UPDATE contacts SET country_code = ? WHERE jid = ?", country, jid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Migrate applies all pending migrations in order and records each applied
+// version in the migrations table. It is idempotent and safe to run from
+// several processes at once: every migration re-checks the recorded version
+// and applies inside a BEGIN EXCLUSIVE transaction.
+func Migrate(db *sql.DB) error {
+	ctx := context.Background()
+
+	// Raw BEGIN/COMMIT statements must all run on the same connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	for _, m := range migrations {
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration applies a single migration unless it is already recorded
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) (err error) {
+	if _, err := conn.ExecContext(ctx, "BEGIN EXCLUSIVE"); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			conn.ExecContext(ctx, "ROLLBACK")
+		}
+	}()
+
+	var applied int
+	err = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM migrations WHERE version = ?", m.version).Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		_, err = conn.ExecContext(ctx, "COMMIT")
+		return err
+	}
+
+	if err = m.apply(ctx, conn); err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, "INSERT INTO migrations (version, name) VALUES (?, ?)", m.version, m.name)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, "COMMIT")
+	return err
+}
+
+// rowQuerier is implemented by *sql.DB, *sql.Conn and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// columnExists reports whether a table has a column with the given name
+func columnExists(ctx context.Context, q rowQuerier, table, column string) (bool, error) {
+	var count int
+	err := q.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?",
+		table, column,
+	).Scan(&count)
+	return count > 0, err
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMigrateIdempotent(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	// NewStore already migrated; running again must be a no-op
+	if err := Migrate(store.db.DB); err != nil {
+		t.Fatalf("Failed to re-run migrations: %v", err)
+	}
+
+	var count int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&count); err != nil {
+		t.Fatalf("Failed to count migrations: %v", err)
+	}
+	if count != len(migrations) {
+		t.Errorf("Expected %d recorded migrations, got %d", len(migrations), count)
+	}
+}
+
+func TestMigrateExistingDatabase(t *testing.T) {
+	dbPath := t.TempDir() + "/legacy.db"
+	db, err := sql.Open("sqlite3", "file:"+dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// Schema as created before migrations existed, with data to keep
+	_, err = db.Exec(`
+		CREATE TABLE chats (jid TEXT PRIMARY KEY, name TEXT, last_message_time TIMESTAMP);
+		CREATE TABLE messages (
+			id TEXT, chat_jid TEXT, sender TEXT, content TEXT, timestamp TIMESTAMP,
+			is_from_me BOOLEAN, media_type TEXT, filename TEXT, url TEXT, media_key BLOB,
+			file_sha256 BLOB, file_enc_sha256 BLOB, file_length INTEGER,
+			PRIMARY KEY (id, chat_jid), FOREIGN KEY (chat_jid) REFERENCES chats(jid)
+		);
+		INSERT INTO chats (jid, name) VALUES ('123456789@s.whatsapp.net', 'Test Contact');
+		INSERT INTO messages (id, chat_jid, content) VALUES ('msg1', '123456789@s.whatsapp.net', 'hello');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Failed to migrate legacy database: %v", err)
+	}
+
+	var status string
+	if err := db.QueryRow("SELECT status FROM messages WHERE id = 'msg1'").Scan(&status); err != nil {
+		t.Fatalf("Failed to read migrated message: %v", err)
+	}
+	if status != string(StatusSent) {
+		t.Errorf("Expected status %s, got %s", StatusSent, status)
+	}
+}
+
+func TestMigrateCallLogsToChatAndCaller(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+t.TempDir()+"/calls.db")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// Apply every migration before the call log reshape, then log calls in
+	// the old from/to shape
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatalf("Failed to create migrations table: %v", err)
+	}
+	for _, m := range migrations {
+		if m.version >= 34 {
+			break
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			t.Fatalf("Failed to apply migration %d: %v", m.version, err)
+		}
+	}
+	_, err = conn.ExecContext(ctx, `
+		INSERT INTO call_logs (id, from_jid, to_jid, call_type, status, started_at, duration_seconds) VALUES
+			('missed', '111@s.whatsapp.net', '100@s.whatsapp.net', 'voice', 'missed', '2024-01-01 10:00:00', 0),
+			('incoming', '111@s.whatsapp.net', '100@s.whatsapp.net', 'video', 'incoming', '2024-01-01 11:00:00', 60),
+			('outgoing', '100@s.whatsapp.net', '222@s.whatsapp.net', 'voice', 'outgoing', '2024-01-01 12:00:00', 0),
+			('group', '111@s.whatsapp.net', '999@g.us', 'voice', 'missed', '2024-01-01 13:00:00', 0)`)
+	if err != nil {
+		t.Fatalf("Failed to log calls: %v", err)
+	}
+	conn.Close()
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	want := map[string][3]string{
+		"missed":   {"111@s.whatsapp.net", "111@s.whatsapp.net", "missed"},
+		"incoming": {"111@s.whatsapp.net", "111@s.whatsapp.net", "answered"},
+		"outgoing": {"222@s.whatsapp.net", "100@s.whatsapp.net", "unanswered"},
+		"group":    {"999@g.us", "111@s.whatsapp.net", "missed"},
+	}
+	for id, fields := range want {
+		var got [3]string
+		err := db.QueryRow("SELECT chat_jid, caller_jid, call_status FROM call_logs WHERE id = ?", id).Scan(&got[0], &got[1], &got[2])
+		if err != nil {
+			t.Fatalf("Failed to read call %s: %v", id, err)
+		}
+		if got != fields {
+			t.Errorf("Call %s: expected chat, caller and status %v, got %v", id, fields, got)
+		}
+	}
+}
+
+func TestMigrateConcurrent(t *testing.T) {
+	dbPath := t.TempDir() + "/concurrent.db"
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db, err := sql.Open("sqlite3", "file:"+dbPath+"?_busy_timeout=5000")
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer db.Close()
+			errs <- Migrate(db)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Concurrent migration failed: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	exists, err := columnExists(context.Background(), db, "messages", "status")
+	if err != nil || !exists {
+		t.Errorf("Expected status column after concurrent migration, got %v (err %v)", exists, err)
+	}
+}
+
+func TestMessageQueriesUseChatTimestampIndex(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	for _, chatJID := range []string{"111@s.whatsapp.net", "222@s.whatsapp.net"} {
+		if err := store.StoreChat(&Chat{JID: chatJID, LastMessageTime: time.Now()}); err != nil {
+			t.Fatalf("Failed to store chat: %v", err)
+		}
+		if err := store.BulkStoreMessages(testMessages(chatJID, 200)); err != nil {
+			t.Fatalf("Failed to store messages: %v", err)
+		}
+	}
+	if _, err := store.db.Exec("ANALYZE"); err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+
+	queries := map[string]string{
+		"offset": `SELECT ` + messageColumns + ` FROM messages
+			WHERE chat_jid = ? AND is_deleted = 0
+			ORDER BY timestamp DESC LIMIT 20 OFFSET 40`,
+		"cursor": `SELECT ` + messageColumns + ` FROM messages
+			WHERE chat_jid = ? AND is_deleted = 0 AND (timestamp, id) < (?, ?)
+			ORDER BY timestamp DESC, id DESC LIMIT 21`,
+	}
+	for name, query := range queries {
+		rows, err := store.db.Query("EXPLAIN QUERY PLAN "+query, "111@s.whatsapp.net", time.Now().UTC(), "msg")
+		if err != nil {
+			t.Fatalf("%s: failed to explain query: %v", name, err)
+		}
+
+		var plan []string
+		for rows.Next() {
+			var id, parent, notUsed int
+			var detail string
+			if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+				t.Fatalf("%s: failed to scan plan: %v", name, err)
+			}
+			plan = append(plan, detail)
+		}
+		rows.Close()
+
+		joined := strings.Join(plan, "\n")
+		if !strings.Contains(joined, "idx_messages_chat_timestamp ") {
+			t.Errorf("%s: expected idx_messages_chat_timestamp to be used, got plan:\n%s", name, joined)
+		}
+		if strings.Contains(joined, "SCAN messages") || strings.Contains(joined, "TEMP B-TREE") {
+			t.Errorf("%s: expected no table scan or sort step, got plan:\n%s", name, joined)
+		}
+	}
+}
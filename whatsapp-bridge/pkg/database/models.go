@@ -1,39 +1,666 @@
 package database
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
 	"time"
+	"unicode/utf8"
 )
 
+// MessageStatus is the delivery state of a message
+type MessageStatus string
+
+// Message delivery states
+const (
+	StatusPending   MessageStatus = "pending"
+	StatusSent      MessageStatus = "sent"
+	StatusDelivered MessageStatus = "delivered"
+	StatusRead      MessageStatus = "read"
+	StatusFailed    MessageStatus = "failed"
+	// StatusUnknown is returned by ParseMessageStatus for unrecognized
+	// values; it is never stored
+	StatusUnknown MessageStatus = "unknown"
+)
+
+// ParseMessageStatus returns the delivery status named s, or StatusUnknown
+// and an error if there is none
+func ParseMessageStatus(s string) (MessageStatus, error) {
+	status := MessageStatus(s)
+	if !status.IsValid() {
+		return StatusUnknown, fmt.Errorf("invalid message status: %s", s)
+	}
+	return status, nil
+}
+
+// IsValid reports whether s is a known delivery status
+func (s MessageStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusSent, StatusDelivered, StatusRead, StatusFailed:
+		return true
+	}
+	return false
+}
+
 // Message represents a chat message
 type Message struct {
-	ID            string    `db:"id"`
-	ChatJID       string    `db:"chat_jid"`
-	Sender        string    `db:"sender"`
-	Content       string    `db:"content"`
-	Timestamp     time.Time `db:"timestamp"`
-	IsFromMe      bool      `db:"is_from_me"`
-	MediaType     string    `db:"media_type"`
-	Filename      string    `db:"filename"`
-	URL           string    `db:"url"`
-	MediaKey      []byte    `db:"media_key"`
-	FileSHA256    []byte    `db:"file_sha256"`
-	FileEncSHA256 []byte    `db:"file_enc_sha256"`
-	FileLength    uint64    `db:"file_length"`
+	ID            string        `db:"id" json:"id"`
+	ChatJID       string        `db:"chat_jid" json:"chat_jid"`
+	Sender        string        `db:"sender" json:"sender"`
+	Content       string        `db:"content" json:"content"`
+	Timestamp     time.Time     `db:"timestamp" json:"timestamp"`
+	IsFromMe      bool          `db:"is_from_me" json:"is_from_me"`
+	MediaType     string        `db:"media_type" json:"media_type,omitempty"`
+	Filename      string        `db:"filename" json:"filename,omitempty"`
+	URL           string        `db:"url" json:"-"`
+	MediaKey      []byte        `db:"media_key" json:"-"`
+	FileSHA256    []byte        `db:"file_sha256" json:"-"`
+	FileEncSHA256 []byte        `db:"file_enc_sha256" json:"-"`
+	FileLength    uint64        `db:"file_length" json:"file_length,omitempty"`
+	Status        MessageStatus `db:"status" json:"status"`
+	IsDeleted     bool          `db:"is_deleted" json:"is_deleted"`
+	DeletedAt     *time.Time    `db:"deleted_at" json:"deleted_at,omitempty"`
+	// QuotedMessageID and QuotedMessageContent describe the message this one replies to
+	QuotedMessageID      string `db:"quoted_message_id" json:"quoted_message_id,omitempty"`
+	QuotedMessageContent string `db:"quoted_message_content" json:"quoted_message_content,omitempty"`
+	// Media metadata lets clients render previews without downloading the file
+	MediaMimeType        string `db:"media_mime_type" json:"media_mime_type,omitempty"`
+	MediaWidth           int    `db:"media_width" json:"media_width,omitempty"`
+	MediaHeight          int    `db:"media_height" json:"media_height,omitempty"`
+	MediaDurationSeconds int    `db:"media_duration_seconds" json:"media_duration_seconds,omitempty"`
+	Thumbnail            []byte `db:"thumbnail" json:"thumbnail,omitempty"`
+	// LocationLat and LocationLon are only set on shared locations
+	LocationLat     *float64 `db:"location_lat" json:"location_lat,omitempty"`
+	LocationLon     *float64 `db:"location_lon" json:"location_lon,omitempty"`
+	LocationAddress string   `db:"location_address" json:"location_address,omitempty"`
+	LocationName    string   `db:"location_name" json:"location_name,omitempty"`
+	// LocationIsLive is set on live locations, which the sender keeps
+	// updating until LocationLiveExpiry, if known
+	LocationIsLive     bool       `db:"location_is_live" json:"location_is_live,omitempty"`
+	LocationLiveExpiry *time.Time `db:"location_live_expiry" json:"location_live_expiry,omitempty"`
+	// VCard is the raw card of a shared contact (media type "vcard"). Only
+	// its name and phone, parsed when the message is stored, are persisted.
+	VCard      string `db:"-" json:"-"`
+	VCardName  string `db:"vcard_name" json:"vcard_name,omitempty"`
+	VCardPhone string `db:"vcard_phone" json:"vcard_phone,omitempty"`
+	// IsEdited is set once the sender has changed the content; earlier
+	// versions are kept as EditRecords
+	IsEdited bool       `db:"is_edited" json:"is_edited"`
+	EditedAt *time.Time `db:"edited_at" json:"edited_at,omitempty"`
+}
+
+// MessageType is what a message contains, as reported by Message.Type
+type MessageType string
+
+// Message types
+const (
+	TypeText     MessageType = "text"
+	TypeImage    MessageType = "image"
+	TypeVideo    MessageType = "video"
+	TypeAudio    MessageType = "audio"
+	TypeDocument MessageType = "document"
+	TypeLocation MessageType = "location"
+	TypeContact  MessageType = "contact"
+	TypePoll     MessageType = "poll"
+	TypeReaction MessageType = "reaction"
+	TypeSticker  MessageType = "sticker"
+	TypeUnknown  MessageType = "unknown"
+)
+
+// mediaMessageTypes maps the media_type values of messages to their type.
+// Shared contacts are stored with the media type "vcard".
+var mediaMessageTypes = map[string]MessageType{
+	"image":    TypeImage,
+	"video":    TypeVideo,
+	"audio":    TypeAudio,
+	"document": TypeDocument,
+	"vcard":    TypeContact,
+	"poll":     TypePoll,
+	"reaction": TypeReaction,
+	"sticker":  TypeSticker,
+}
+
+// IsMedia reports whether the message carries an attachment
+func (m *Message) IsMedia() bool {
+	return m.MediaType != ""
+}
+
+// IsText reports whether the message is plain text, without an attachment
+// or location
+func (m *Message) IsText() bool {
+	return m.Content != "" && m.MediaType == "" && !m.IsLocation()
+}
+
+// IsLocation reports whether the message shares a location
+func (m *Message) IsLocation() bool {
+	return m.LocationLat != nil && m.LocationLon != nil
+}
+
+// IsContact reports whether the message shares a contact card
+func (m *Message) IsContact() bool {
+	return m.Type() == TypeContact
+}
+
+// IsPoll reports whether the message is a poll
+func (m *Message) IsPoll() bool {
+	return m.Type() == TypePoll
+}
+
+// IsReaction reports whether the message is a reaction to another message
+func (m *Message) IsReaction() bool {
+	return m.Type() == TypeReaction
+}
+
+// Type reports what the message contains, from its media type. A message
+// with neither content, media nor location is TypeUnknown.
+func (m *Message) Type() MessageType {
+	if m.IsLocation() {
+		return TypeLocation
+	}
+	if m.IsText() {
+		return TypeText
+	}
+	if t, ok := mediaMessageTypes[m.MediaType]; ok {
+		return t
+	}
+	return TypeUnknown
+}
+
+// Location returns the location the message shares, or nil if it shares
+// none
+func (m *Message) Location() *LocationMessage {
+	if !m.IsLocation() {
+		return nil
+	}
+	return &LocationMessage{
+		MessageID:  m.ID,
+		ChatJID:    m.ChatJID,
+		Latitude:   *m.LocationLat,
+		Longitude:  *m.LocationLon,
+		Address:    m.LocationAddress,
+		Name:       m.LocationName,
+		IsLive:     m.LocationIsLive,
+		LiveExpiry: m.LocationLiveExpiry,
+	}
+}
+
+// MediaDuration returns the playback length of audio and video attachments
+func (m *Message) MediaDuration() time.Duration {
+	return time.Duration(m.MediaDurationSeconds) * time.Second
+}
+
+// MessageOptions controls which messages a query returns
+type MessageOptions struct {
+	// IncludeDeleted also returns soft-deleted messages
+	IncludeDeleted bool
+	// Offset skips this many messages before the page starts.
+	//
+	// Deprecated: Offsets drift as new messages arrive; use a Cursor.
+	Offset int
+}
+
+// EditRecord is the content a message had before one of its edits.
+// Revisions count from 1 for the first edit.
+type EditRecord struct {
+	MessageID       string    `db:"message_id" json:"message_id"`
+	ChatJID         string    `db:"chat_jid" json:"chat_jid"`
+	Revision        int       `db:"revision" json:"revision"`
+	OriginalContent string    `db:"original_content" json:"original_content"`
+	EditedAt        time.Time `db:"edited_at" json:"edited_at"`
+}
+
+// Reaction represents an emoji reaction to a message. Each sender has at
+// most one reaction per message.
+type Reaction struct {
+	MessageID string    `db:"message_id" json:"message_id"`
+	ChatJID   string    `db:"chat_jid" json:"chat_jid"`
+	Sender    string    `db:"sender" json:"sender"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+	Timestamp time.Time `db:"timestamp" json:"timestamp"`
+}
+
+// LocationMessage is the location shared by a message. LiveExpiry is nil
+// unless a live location's end is known.
+type LocationMessage struct {
+	MessageID  string     `json:"message_id"`
+	ChatJID    string     `json:"chat_jid"`
+	Latitude   float64    `json:"latitude"`
+	Longitude  float64    `json:"longitude"`
+	Address    string     `json:"address,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	IsLive     bool       `json:"is_live"`
+	LiveExpiry *time.Time `json:"live_expiry,omitempty"`
+}
+
+// MediaMetadata describes the attachment of a message. Fields the bridge has
+// not set itself are those the message reported; LocalPath is empty until
+// the attachment is downloaded.
+type MediaMetadata struct {
+	MessageID     string `db:"message_id" json:"message_id"`
+	ChatJID       string `db:"chat_jid" json:"chat_jid"`
+	MimeType      string `db:"mime_type" json:"mime_type,omitempty"`
+	Width         int    `db:"width" json:"width,omitempty"`
+	Height        int    `db:"height" json:"height,omitempty"`
+	DurationMs    int    `db:"duration_ms" json:"duration_ms,omitempty"`
+	ThumbnailData []byte `db:"thumbnail" json:"thumbnail,omitempty"`
+	LocalPath     string `db:"local_path" json:"local_path,omitempty"`
+}
+
+// PollMessage is a poll created in a chat. Options are in the order they
+// were offered.
+type PollMessage struct {
+	MessageID     string       `db:"message_id" json:"message_id"`
+	ChatJID       string       `db:"chat_jid" json:"chat_jid"`
+	Question      string       `db:"question" json:"question"`
+	Options       []PollOption `db:"options" json:"options"`
+	AllowMultiple bool         `db:"allow_multiple" json:"allow_multiple"`
+}
+
+// PollOption is one option of a poll. Its ID is the hex SHA-256 hash of its
+// text, which is how WhatsApp identifies the options selected in a vote.
+type PollOption struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// NewPollOption returns the option with the given text
+func NewPollOption(text string) PollOption {
+	sum := sha256.Sum256([]byte(text))
+	return PollOption{ID: hex.EncodeToString(sum[:]), Text: text}
+}
+
+// NewPollOptions returns the options with the given texts, in order
+func NewPollOptions(texts ...string) []PollOption {
+	options := make([]PollOption, len(texts))
+	for i, text := range texts {
+		options[i] = NewPollOption(text)
+	}
+	return options
+}
+
+// OptionTexts returns the text of each option, in the order they were
+// offered
+func (p *PollMessage) OptionTexts() []string {
+	texts := make([]string, len(p.Options))
+	for i, option := range p.Options {
+		texts[i] = option.Text
+	}
+	return texts
+}
+
+// PollVote is one voter's current selection in a poll. Voting again replaces
+// the earlier selection.
+type PollVote struct {
+	MessageID       string    `db:"message_id" json:"message_id"`
+	ChatJID         string    `db:"chat_jid" json:"chat_jid"`
+	VoterJID        string    `db:"voter_jid" json:"voter_jid"`
+	SelectedOptions []string  `db:"selected_options" json:"selected_options"`
+	VotedAt         time.Time `db:"voted_at" json:"voted_at"`
+}
+
+// PollOptionCount is the number of voters who selected one poll option
+type PollOptionCount struct {
+	ID     string `json:"id"`
+	Option string `json:"option"`
+	Votes  int    `json:"votes"`
+}
+
+// PollResults tallies the votes of a poll
+type PollResults struct {
+	*PollMessage
+	Results     []PollOptionCount `json:"results"`
+	TotalVoters int               `json:"total_voters"`
+	Votes       []*PollVote       `json:"votes"`
+}
+
+// Contact represents a WhatsApp user, independent of any conversation with them
+type Contact struct {
+	JID          string    `db:"jid" json:"jid"`
+	Phone        string    `db:"phone" json:"phone,omitempty"`
+	DisplayName  string    `db:"display_name" json:"display_name,omitempty"`
+	PushName     string    `db:"push_name" json:"push_name,omitempty"`
+	BusinessName string    `db:"business_name" json:"business_name,omitempty"`
+	About        string    `db:"about" json:"about,omitempty"`
+	LastSync     time.Time `db:"last_sync" json:"last_sync"`
+	// CountryCode is the ISO 3166-1 alpha-2 code of the country of the
+	// contact's number, derived when the contact is stored
+	CountryCode string `db:"country_code" json:"country_code,omitempty"`
+}
+
+// ProfilePicture is a cached profile picture URL of a user or group. ETag
+// is WhatsApp's picture ID and changes whenever the picture does.
+type ProfilePicture struct {
+	JID       string    `db:"jid" json:"jid"`
+	URL       string    `db:"url" json:"url"`
+	ETag      string    `db:"etag" json:"etag"`
+	CachedAt  time.Time `db:"cached_at" json:"cached_at"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// IsExpired reports whether the cached URL should be fetched again
+func (p *ProfilePicture) IsExpired() bool {
+	return !time.Now().Before(p.ExpiresAt)
+}
+
+// GroupParticipant represents a membership of a group. LeftAt is nil while
+// the participant is still a member.
+type GroupParticipant struct {
+	GroupJID       string     `db:"group_jid" json:"group_jid"`
+	ParticipantJID string     `db:"participant_jid" json:"participant_jid"`
+	IsAdmin        bool       `db:"is_admin" json:"is_admin"`
+	JoinedAt       time.Time  `db:"joined_at" json:"joined_at"`
+	LeftAt         *time.Time `db:"left_at" json:"left_at,omitempty"`
+}
+
+// GroupInfo is the metadata of a group. CreatedAt is nil when WhatsApp
+// did not report it.
+type GroupInfo struct {
+	JID          string     `db:"jid" json:"jid"`
+	Subject      string     `db:"subject" json:"subject"`
+	Description  string     `db:"description" json:"description,omitempty"`
+	OwnerJID     string     `db:"owner_jid" json:"owner_jid,omitempty"`
+	CreatedAt    *time.Time `db:"created_at" json:"created_at,omitempty"`
+	LastSyncedAt time.Time  `db:"last_synced_at" json:"last_synced_at"`
+}
+
+// Age returns how long ago the group was created, or 0 if that is unknown
+func (g *GroupInfo) Age() time.Duration {
+	if g.CreatedAt == nil {
+		return 0
+	}
+	return time.Since(*g.CreatedAt)
+}
+
+// Webhook is a URL that events are POSTed to. An empty Events receives
+// every event. The secret its deliveries are signed with is never encoded.
+type Webhook struct {
+	ID             string     `db:"id" json:"id"`
+	URL            string     `db:"url" json:"url"`
+	Secret         string     `db:"secret" json:"-"`
+	Events         []string   `db:"events" json:"events"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	LastDeliveryAt *time.Time `db:"last_delivery_at" json:"last_delivery_at,omitempty"`
+	// FailureCount counts deliveries that failed since the last success
+	FailureCount int `db:"failure_count" json:"failure_count"`
+}
+
+// Subscribes reports whether the webhook receives events of the given type
+func (w *Webhook) Subscribes(eventType string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, eventType)
+}
+
+// CallType is the media of a call
+type CallType string
+
+// Call media types
+const (
+	CallTypeVoice CallType = "voice"
+	CallTypeVideo CallType = "video"
+)
+
+// IsValid reports whether t is a known call type
+func (t CallType) IsValid() bool {
+	return t == CallTypeVoice || t == CallTypeVideo
+}
+
+// CallStatus is the outcome of a call
+type CallStatus string
+
+// Call outcomes. Missed and declined calls were made to the user; an
+// unanswered call is one the user made that nobody picked up.
+const (
+	CallStatusMissed     CallStatus = "missed"
+	CallStatusAnswered   CallStatus = "answered"
+	CallStatusDeclined   CallStatus = "declined"
+	CallStatusUnanswered CallStatus = "unanswered"
+)
+
+// IsValid reports whether s is a known call status
+func (s CallStatus) IsValid() bool {
+	switch s {
+	case CallStatusMissed, CallStatusAnswered, CallStatusDeclined, CallStatusUnanswered:
+		return true
+	}
+	return false
+}
+
+// CallLog represents an incoming or outgoing call. ChatJID is the contact
+// or group the call took place in and CallerJID the user who started it.
+type CallLog struct {
+	ID              string     `db:"id" json:"id"`
+	ChatJID         string     `db:"chat_jid" json:"chat_jid"`
+	CallerJID       string     `db:"caller_jid" json:"caller_jid"`
+	CallType        CallType   `db:"call_type" json:"call_type"`
+	CallStatus      CallStatus `db:"call_status" json:"call_status"`
+	StartedAt       time.Time  `db:"started_at" json:"started_at"`
+	DurationSeconds int        `db:"duration_seconds" json:"duration_seconds"`
+}
+
+// ScheduledStatus is the dispatch state of a scheduled message
+type ScheduledStatus string
+
+// Scheduled message states
+const (
+	ScheduledStatusPending ScheduledStatus = "pending"
+	ScheduledStatusSent    ScheduledStatus = "sent"
+	ScheduledStatusFailed  ScheduledStatus = "failed"
+)
+
+// IsValid reports whether s is a known scheduled message state
+func (s ScheduledStatus) IsValid() bool {
+	switch s {
+	case ScheduledStatusPending, ScheduledStatusSent, ScheduledStatusFailed:
+		return true
+	}
+	return false
+}
+
+// ScheduledMessage is an outgoing message queued for a future time
+type ScheduledMessage struct {
+	ID          string          `db:"id" json:"id"`
+	Recipient   string          `db:"recipient" json:"recipient"`
+	Content     string          `db:"content" json:"content,omitempty"`
+	MediaPath   string          `db:"media_path" json:"media_path,omitempty"`
+	ScheduledAt time.Time       `db:"scheduled_at" json:"scheduled_at"`
+	SentAt      *time.Time      `db:"sent_at" json:"sent_at,omitempty"`
+	Status      ScheduledStatus `db:"status" json:"status"`
+}
+
+// JobStatus is the progress of an asynchronous job
+type JobStatus string
+
+// Job states
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// IsFinal reports whether a job in state s has finished
+func (s JobStatus) IsFinal() bool {
+	return s == JobStatusCompleted || s == JobStatusFailed
+}
+
+// Job tracks work accepted by the API and carried out in the background.
+// CompletedAt and Error are only set once the job has finished.
+type Job struct {
+	ID          string     `db:"id" json:"id"`
+	Status      JobStatus  `db:"status" json:"status"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+	Error       string     `db:"error" json:"error,omitempty"`
+}
+
+// MessageStats summarizes the messages of a chat. Hours are UTC.
+type MessageStats struct {
+	ChatJID       string        `json:"chat_jid"`
+	TotalMessages int           `json:"total_messages"`
+	TopSenders    []SenderCount `json:"top_senders"`
+	// ByHour counts messages per hour of day, index 0 being 00:00-00:59
+	ByHour [24]int `json:"by_hour"`
+}
+
+// ChatStatistics summarizes who talks in a chat and how quickly they reply.
+// The first and last message times are zero for an empty chat.
+type ChatStatistics struct {
+	TotalMessages   int           `json:"total_messages"`
+	FromMeCount     int           `json:"from_me_count"`
+	FromOthersCount int           `json:"from_others_count"`
+	TopSenders      []SenderCount `json:"top_senders"`
+	// AvgResponseTimeSeconds averages the gaps between consecutive messages
+	// from different parties
+	AvgResponseTimeSeconds float64   `json:"avg_response_time_seconds"`
+	FirstMessageAt         time.Time `json:"first_message_at"`
+	LastMessageAt          time.Time `json:"last_message_at"`
+}
+
+// SenderCount is the number of messages one sender wrote
+type SenderCount struct {
+	Sender string `json:"sender"`
+	Count  int    `json:"count"`
+}
+
+// DayCount is the number of messages on one UTC day, formatted 2006-01-02
+type DayCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// DBStats describes the size and contents of the database file
+type DBStats struct {
+	FileSizeBytes int64 `json:"file_size_bytes"`
+	// WALSizeBytes is the size of the frames in the write-ahead log not yet
+	// reset by a checkpoint
+	WALSizeBytes int64 `json:"wal_size_bytes"`
+	MessageCount int   `json:"message_count"`
+	ChatCount    int   `json:"chat_count"`
+	PageSize     int64 `json:"page_size"`
+}
+
+// ChatOptions controls how chat queries are resolved
+type ChatOptions struct {
+	// ResolveContactNames names direct chats after the matching contact's
+	// display or push name when one is stored
+	ResolveContactNames bool
+	// LabelID restricts results to chats carrying this label
+	LabelID string
+	// IncludeArchived also returns archived chats
+	IncludeArchived bool
+	// Offset skips this many chats before the page starts.
+	//
+	// Deprecated: Offsets drift as chats receive messages; use a Cursor.
+	Offset int
+}
+
+// Label is a user-defined tag for categorizing chats
+type Label struct {
+	ID    string `db:"id" json:"id"`
+	Name  string `db:"name" json:"name"`
+	Color string `db:"color" json:"color,omitempty"`
+}
+
+// StatusBroadcastJID is the pseudo-chat that status updates are posted to
+const StatusBroadcastJID = "status@broadcast"
+
+// StatusTTL is how long a status update stays visible after it is posted
+const StatusTTL = 24 * time.Hour
+
+// StatusMessage is a status update (story) posted to StatusBroadcastJID.
+// Status updates are kept apart from chat messages and expire.
+type StatusMessage struct {
+	ID        string    `db:"id" json:"id"`
+	AuthorJID string    `db:"author_jid" json:"author_jid"`
+	Content   string    `db:"content" json:"content"`
+	MediaType string    `db:"media_type" json:"media_type,omitempty"`
+	URL       string    `db:"url" json:"-"`
+	MediaKey  []byte    `db:"media_key" json:"-"`
+	Timestamp time.Time `db:"timestamp" json:"timestamp"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// BroadcastList is a named list of recipients that a message can be sent to
+// in one go. Replies arrive in each recipient's direct chat.
+type BroadcastList struct {
+	JID       string    `db:"jid" json:"jid"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
 
 // Chat represents a WhatsApp chat
 type Chat struct {
-	JID             string    `db:"jid"`
-	Name            string    `db:"name"`
-	LastMessageTime time.Time `db:"last_message_time"`
+	JID             string     `db:"jid" json:"jid"`
+	Name            string     `db:"name" json:"name"`
+	LastMessageTime time.Time  `db:"last_message_time" json:"last_message_time"`
+	UnreadCount     int        `db:"unread_count" json:"unread_count"`
+	LastReadAt      *time.Time `db:"last_read_at" json:"last_read_at,omitempty"`
+	// LastReadMessageID is the newest message the user has read
+	LastReadMessageID string     `db:"last_read_message_id" json:"last_read_message_id,omitempty"`
+	ArchivedAt        *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+	MuteUntil         *time.Time `db:"mute_until" json:"mute_until,omitempty"`
+}
+
+// ChatWithLastMessage is a chat together with its most recent message, as
+// shown in an inbox. LastMessage is nil for a chat without messages.
+type ChatWithLastMessage struct {
+	*Chat
+	LastMessage *Message `json:"last_message,omitempty"`
 }
 
-// IsGroup determines if a chat is a group based on JID pattern
+// IsGroup determines if a chat is a group based on JID pattern. Broadcast
+// lists send to many recipients too but are not groups.
 func (c *Chat) IsGroup() bool {
-	return len(c.JID) > 6 && c.JID[len(c.JID)-6:] == "@g.us"
+	jid, err := ParseJID(c.JID)
+	return err == nil && jid.IsGroup()
+}
+
+// IsBroadcast determines if a chat is a broadcast list. The status
+// broadcast is not a list.
+func (c *Chat) IsBroadcast() bool {
+	jid, err := ParseJID(c.JID)
+	return err == nil && jid.IsBroadcast() && c.JID != StatusBroadcastJID
+}
+
+// IsMuted reports whether notifications for the chat are currently muted
+func (c *Chat) IsMuted() bool {
+	return c.MuteUntil != nil && c.MuteUntil.After(time.Now())
 }
 
 // IsContact determines if a chat is a direct contact
 func (c *Chat) IsContact() bool {
-	return len(c.JID) > 13 && c.JID[len(c.JID)-13:] == "@s.whatsapp.net"
-}
\ No newline at end of file
+	jid, err := ParseJID(c.JID)
+	return err == nil && jid.IsContact()
+}
+
+// DisplayName returns the chat's name or, for a chat whose name was never
+// resolved, a name derived from its JID: the phone number of a contact or
+// the ID of a group. Other chats fall back to the JID itself.
+func (c *Chat) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	jid, err := ParseJID(c.JID)
+	switch {
+	case err != nil:
+		return c.JID
+	case jid.IsGroup():
+		return "Group (" + jid.User + ")"
+	case jid.IsContact():
+		return "+" + jid.User
+	}
+	return c.JID
+}
+
+// ShortName returns DisplayName cut to at most maxLen characters, ending in
+// an ellipsis when it was cut
+func (c *Chat) ShortName(maxLen int) string {
+	name := c.DisplayName()
+	if utf8.RuneCountInString(name) <= maxLen {
+		return name
+	}
+	if maxLen <= 0 {
+		return ""
+	}
+	return string([]rune(name)[:maxLen-1]) + "…"
+}
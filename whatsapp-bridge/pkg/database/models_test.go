@@ -0,0 +1,66 @@
+package database
+
+import "testing"
+
+func TestMessageType(t *testing.T) {
+	lat, lon := 52.5163, 13.3777
+	tests := []struct {
+		name string
+		msg  Message
+		want MessageType
+	}{
+		{"text", Message{Content: "Hi"}, TypeText},
+		{"image", Message{MediaType: "image", Content: "Caption"}, TypeImage},
+		{"video", Message{MediaType: "video"}, TypeVideo},
+		{"audio", Message{MediaType: "audio"}, TypeAudio},
+		{"document", Message{MediaType: "document", Filename: "report.pdf"}, TypeDocument},
+		{"location", Message{LocationLat: &lat, LocationLon: &lon, Content: "Meet here"}, TypeLocation},
+		{"contact", Message{MediaType: "vcard", VCardName: "Alice"}, TypeContact},
+		{"poll", Message{MediaType: "poll", Content: "Lunch?"}, TypePoll},
+		{"reaction", Message{MediaType: "reaction", Content: "👍"}, TypeReaction},
+		{"sticker", Message{MediaType: "sticker"}, TypeSticker},
+		{"empty", Message{}, TypeUnknown},
+		{"unknown media", Message{MediaType: "hologram"}, TypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.msg.Type(); got != tt.want {
+				t.Errorf("Expected type %q, got %q", tt.want, got)
+			}
+			checks := []struct {
+				name string
+				got  bool
+				want bool
+			}{
+				{"IsText", tt.msg.IsText(), tt.want == TypeText},
+				{"IsMedia", tt.msg.IsMedia(), tt.msg.MediaType != ""},
+				{"IsLocation", tt.msg.IsLocation(), tt.want == TypeLocation},
+				{"IsContact", tt.msg.IsContact(), tt.want == TypeContact},
+				{"IsPoll", tt.msg.IsPoll(), tt.want == TypePoll},
+				{"IsReaction", tt.msg.IsReaction(), tt.want == TypeReaction},
+			}
+			for _, c := range checks {
+				if c.got != c.want {
+					t.Errorf("Expected %s to be %v", c.name, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMessageStatus(t *testing.T) {
+	for _, want := range []MessageStatus{StatusPending, StatusSent, StatusDelivered, StatusRead, StatusFailed} {
+		got, err := ParseMessageStatus(string(want))
+		if err != nil || got != want {
+			t.Errorf("ParseMessageStatus(%q) = %q, %v; want %q", want, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "READ", "seen", string(StatusUnknown)} {
+		got, err := ParseMessageStatus(s)
+		if err == nil || got != StatusUnknown {
+			t.Errorf("ParseMessageStatus(%q) = %q, %v; want %q and an error", s, got, err, StatusUnknown)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// storeConfig holds the connection settings NewStore applies
+type storeConfig struct {
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+	queryTimeout    time.Duration
+
+	walCheckpointInterval time.Duration
+}
+
+// defaultStoreConfig returns the settings used when NewStore is given no
+// options
+func defaultStoreConfig() storeConfig {
+	return storeConfig{maxOpenConns: 10, maxIdleConns: 5}
+}
+
+// StoreOption configures a Store created by NewStore
+type StoreOption func(*storeConfig)
+
+// WithMaxOpenConns caps the number of open database connections; zero or
+// less means no limit. The default is 10.
+func WithMaxOpenConns(n int) StoreOption {
+	return func(c *storeConfig) { c.maxOpenConns = n }
+}
+
+// WithMaxIdleConns sets how many idle connections are kept for reuse; zero
+// or less keeps none. The default is 5.
+func WithMaxIdleConns(n int) StoreOption {
+	return func(c *storeConfig) { c.maxIdleConns = n }
+}
+
+// WithConnMaxLifetime closes connections once they have been open for d;
+// zero, the default, keeps them open indefinitely
+func WithConnMaxLifetime(d time.Duration) StoreOption {
+	return func(c *storeConfig) { c.connMaxLifetime = d }
+}
+
+// WithQueryTimeout bounds every statement and transaction the store runs
+// after NewStore returns; zero, the default, means no timeout. A statement
+// that runs out of time fails with context.DeadlineExceeded.
+func WithQueryTimeout(d time.Duration) StoreOption {
+	return func(c *storeConfig) { c.queryTimeout = d }
+}
+
+// WithWALCheckpointInterval sets how often the write-ahead log should be
+// checkpointed into the database file; zero, the default, leaves it to
+// SQLite's automatic checkpoints. The store does not run the schedule
+// itself: it is reported by WALCheckpointInterval for the janitor to follow.
+func WithWALCheckpointInterval(d time.Duration) StoreOption {
+	return func(c *storeConfig) { c.walCheckpointInterval = d }
+}
+
+// queryDB runs the store's statements under the query timeout
+type queryDB struct {
+	*sql.DB
+	timeout time.Duration
+}
+
+// context returns the context a statement runs under
+func (db *queryDB) context() (context.Context, context.CancelFunc) {
+	if db.timeout <= 0 {
+		return context.Background(), func() {}
+	}
+	return context.WithTimeout(context.Background(), db.timeout)
+}
+
+// resultContext returns the context for a result that keeps using it after
+// the call returns: Rows until they are closed, a Row until it is scanned
+// and a Tx until it ends. It cannot be cancelled on return, so it is
+// released when its deadline passes.
+func (db *queryDB) resultContext() context.Context {
+	ctx, cancel := db.context()
+	context.AfterFunc(ctx, cancel)
+	return ctx
+}
+
+// Exec executes a statement that returns no rows
+func (db *queryDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := db.context()
+	defer cancel()
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+// Query executes a statement that returns rows
+func (db *queryDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.QueryContext(db.resultContext(), query, args...)
+}
+
+// QueryRow executes a statement that returns at most one row
+func (db *queryDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRowContext(db.resultContext(), query, args...)
+}
+
+// Begin starts a transaction, which is rolled back if it is still open when
+// the query timeout passes
+func (db *queryDB) Begin() (*sql.Tx, error) {
+	return db.DB.BeginTx(db.resultContext(), nil)
+}
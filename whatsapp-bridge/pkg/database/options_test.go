@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newStoreWithOptions opens a store in a temporary directory with opts
+func newStoreWithOptions(t *testing.T, opts ...StoreOption) *Store {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := NewStore(dir+"/test.db", dir, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestDefaultStoreConfig(t *testing.T) {
+	store := newStoreWithOptions(t)
+	if n := store.DBStats().MaxOpenConnections; n != 10 {
+		t.Errorf("Expected 10 max open connections by default, got %d", n)
+	}
+	if store.db.timeout != 0 {
+		t.Errorf("Expected no query timeout by default, got %v", store.db.timeout)
+	}
+}
+
+func TestWithMaxOpenConns(t *testing.T) {
+	store := newStoreWithOptions(t, WithMaxOpenConns(3))
+	if n := store.DBStats().MaxOpenConnections; n != 3 {
+		t.Errorf("Expected 3 max open connections, got %d", n)
+	}
+}
+
+func TestWithMaxIdleConns(t *testing.T) {
+	store := newStoreWithOptions(t, WithMaxIdleConns(1))
+
+	ctx := context.Background()
+	first, err := store.db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	second, err := store.db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	first.Close()
+	second.Close()
+
+	if stats := store.DBStats(); stats.Idle != 1 || stats.MaxIdleClosed == 0 {
+		t.Errorf("Expected one idle connection kept and the other closed, got %+v", stats)
+	}
+}
+
+func TestWithConnMaxLifetime(t *testing.T) {
+	store := newStoreWithOptions(t, WithConnMaxLifetime(time.Millisecond))
+
+	if err := store.Ping(); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := store.Ping(); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+
+	if closed := store.DBStats().MaxLifetimeClosed; closed == 0 {
+		t.Error("Expected an expired connection to be closed")
+	}
+}
+
+func TestWithQueryTimeout(t *testing.T) {
+	store := newStoreWithOptions(t, WithQueryTimeout(time.Minute))
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	if err := store.StoreChat(chat); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	if err := store.BulkStoreMessages(testMessages(chat.JID, 3)); err != nil {
+		t.Fatalf("Failed to store messages: %v", err)
+	}
+	// Rows are read after Query returns, under the same timeout
+	if messages, err := store.GetMessages(chat.JID, 10, 0); err != nil || len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d: %v", len(messages), err)
+	}
+
+	store.db.timeout = time.Nanosecond
+	if err := store.Ping(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a query to time out, got %v", err)
+	}
+	if err := store.StoreChat(chat); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a statement to time out, got %v", err)
+	}
+	if _, err := store.GetMessages(chat.JID, 10, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a query to time out, got %v", err)
+	}
+}
+
+func TestWithWALCheckpointInterval(t *testing.T) {
+	if d := newStoreWithOptions(t).WALCheckpointInterval(); d != 0 {
+		t.Errorf("Expected no checkpoint interval by default, got %v", d)
+	}
+	store := newStoreWithOptions(t, WithWALCheckpointInterval(time.Minute))
+	if d := store.WALCheckpointInterval(); d != time.Minute {
+		t.Errorf("Expected a checkpoint interval of 1m, got %v", d)
+	}
+}
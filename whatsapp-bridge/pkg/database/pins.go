@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// MaxPinnedMessages is how many messages WhatsApp lets a chat pin at once
+const MaxPinnedMessages = 3
+
+// ErrPinLimitReached is returned by PinMessage when the chat already has
+// MaxPinnedMessages other pinned messages
+var ErrPinLimitReached = errors.New("chat already has the maximum number of pinned messages")
+
+// PinMessage pins a message in its chat, or updates who pinned it and when if
+// it is already pinned. It returns sql.ErrNoRows if the message does not
+// exist and ErrPinLimitReached if the chat has no free pin slot.
+func (s *Store) PinMessage(messageID, chatJID, pinnedBy string, pinnedAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM messages WHERE id = ? AND chat_jid = ?",
+		messageID, chatJID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return sql.ErrNoRows
+	}
+
+	// The limit check and insert are one statement so concurrent pins cannot
+	// both take the last slot
+	result, err := tx.Exec(`
+		INSERT INTO pinned_messages (message_id, chat_jid, pinned_by, pinned_at)
+		SELECT ?, ?, ?, ?
+		WHERE (SELECT COUNT(*) FROM pinned_messages WHERE chat_jid = ? AND message_id != ?) < ?
+		ON CONFLICT (message_id, chat_jid) DO UPDATE SET pinned_by = excluded.pinned_by, pinned_at = excluded.pinned_at`,
+		messageID, chatJID, pinnedBy, pinnedAt.UTC(), chatJID, messageID, MaxPinnedMessages,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPinLimitReached
+	}
+
+	return tx.Commit()
+}
+
+// UnpinMessage unpins a message. It returns sql.ErrNoRows if the message is
+// not pinned.
+func (s *Store) UnpinMessage(messageID, chatJID string) error {
+	result, err := s.db.Exec(
+		"DELETE FROM pinned_messages WHERE message_id = ? AND chat_jid = ?",
+		messageID, chatJID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetPinnedMessages retrieves the pinned messages of a chat, most recently
+// pinned first. Soft-deleted messages are excluded.
+func (s *Store) GetPinnedMessages(chatJID string) ([]*Message, error) {
+	rows, err := s.db.Query(`
+		SELECT `+qualifiedMessageColumns+`
+		FROM pinned_messages p
+		JOIN messages m ON m.id = p.message_id AND m.chat_jid = p.chat_jid
+		WHERE p.chat_jid = ? AND m.is_deleted = 0
+		ORDER BY p.pinned_at DESC`,
+		chatJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []*Message{}
+	}
+
+	return messages, nil
+}
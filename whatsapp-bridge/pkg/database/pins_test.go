@@ -0,0 +1,55 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPinnedMessages(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	if err := store.BulkStoreMessages(testMessages(chat.JID, 5)); err != nil {
+		t.Fatalf("Failed to store messages: %v", err)
+	}
+
+	now := time.Now()
+	for i, id := range []string{"msg0", "msg1", "msg2"} {
+		if err := store.PinMessage(id, chat.JID, "111", now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("Failed to pin %s: %v", id, err)
+		}
+	}
+
+	if err := store.PinMessage("msg3", chat.JID, "111", now); !errors.Is(err, ErrPinLimitReached) {
+		t.Errorf("Expected ErrPinLimitReached for fourth pin, got %v", err)
+	}
+	// Re-pinning an already pinned message does not need a free slot
+	if err := store.PinMessage("msg0", chat.JID, "222", now.Add(time.Minute)); err != nil {
+		t.Errorf("Failed to re-pin message: %v", err)
+	}
+	if err := store.PinMessage("missing", chat.JID, "111", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows pinning missing message, got %v", err)
+	}
+
+	pinned, err := store.GetPinnedMessages(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get pinned messages: %v", err)
+	}
+	if len(pinned) != 3 || pinned[0].ID != "msg0" {
+		t.Errorf("Expected 3 pinned messages with msg0 most recent, got %d", len(pinned))
+	}
+
+	if err := store.UnpinMessage("msg1", chat.JID); err != nil {
+		t.Fatalf("Failed to unpin message: %v", err)
+	}
+	if err := store.UnpinMessage("msg1", chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows unpinning twice, got %v", err)
+	}
+	if err := store.PinMessage("msg3", chat.JID, "111", now); err != nil {
+		t.Errorf("Expected free slot after unpin, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// StorePoll inserts or replaces a poll. Options without an ID are given
+// the one WhatsApp derives from their text.
+func (s *Store) StorePoll(poll *PollMessage) error {
+	if len(poll.Options) == 0 {
+		return fmt.Errorf("poll must have at least one option")
+	}
+	for i, option := range poll.Options {
+		if option.ID == "" {
+			poll.Options[i] = NewPollOption(option.Text)
+		}
+	}
+
+	options, err := json.Marshal(poll.Options)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO poll_messages (message_id, chat_jid, question, options, allow_multiple) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (message_id, chat_jid) DO UPDATE SET
+			question = excluded.question, options = excluded.options, allow_multiple = excluded.allow_multiple`,
+		poll.MessageID, poll.ChatJID, poll.Question, string(options), poll.AllowMultiple,
+	)
+	return err
+}
+
+// StorePollVote records a voter's current selection, replacing any earlier
+// vote. An empty selection withdraws the vote. It returns sql.ErrNoRows if the
+// poll does not exist.
+func (s *Store) StorePollVote(vote *PollVote) error {
+	if len(vote.SelectedOptions) == 0 {
+		_, err := s.db.Exec(
+			"DELETE FROM poll_votes WHERE message_id = ? AND chat_jid = ? AND voter_jid = ?",
+			vote.MessageID, vote.ChatJID, vote.VoterJID,
+		)
+		return err
+	}
+
+	selected, err := json.Marshal(vote.SelectedOptions)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO poll_votes (message_id, chat_jid, voter_jid, selected_options, voted_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (message_id, chat_jid, voter_jid) DO UPDATE SET
+			selected_options = excluded.selected_options, voted_at = excluded.voted_at`,
+		vote.MessageID, vote.ChatJID, vote.VoterJID, string(selected), vote.VotedAt.UTC(),
+	)
+	if isForeignKeyViolation(err) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// UpdatePollResults records a voter's current selection by option ID,
+// replacing any earlier vote. IDs of options the poll does not offer are
+// ignored, and selecting none withdraws the vote. It returns sql.ErrNoRows
+// if the poll does not exist.
+func (s *Store) UpdatePollResults(messageID, chatJID string, voterJID string, selectedIDs []string) error {
+	poll, err := s.GetPoll(messageID, chatJID)
+	if err != nil {
+		return err
+	}
+
+	var selected []string
+	for _, option := range poll.Options {
+		if slices.Contains(selectedIDs, option.ID) {
+			selected = append(selected, option.Text)
+		}
+	}
+
+	return s.StorePollVote(&PollVote{
+		MessageID:       messageID,
+		ChatJID:         chatJID,
+		VoterJID:        voterJID,
+		SelectedOptions: selected,
+		VotedAt:         time.Now(),
+	})
+}
+
+// GetPoll retrieves a poll. It returns sql.ErrNoRows if it does not exist.
+func (s *Store) GetPoll(messageID, chatJID string) (*PollMessage, error) {
+	poll := &PollMessage{}
+	var options string
+	err := s.db.QueryRow(`
+		SELECT message_id, chat_jid, question, options, allow_multiple
+		FROM poll_messages
+		WHERE message_id = ? AND chat_jid = ?`,
+		messageID, chatJID,
+	).Scan(&poll.MessageID, &poll.ChatJID, &poll.Question, &options, &poll.AllowMultiple)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.Options, err = decodePollOptions(options); err != nil {
+		return nil, fmt.Errorf("invalid options of poll %s: %w", messageID, err)
+	}
+
+	return poll, nil
+}
+
+// decodePollOptions reads the options column. Polls stored before options
+// carried their IDs hold an array of option texts instead, whose IDs are
+// derived from the texts.
+func decodePollOptions(data string) ([]PollOption, error) {
+	var options []PollOption
+	err := json.Unmarshal([]byte(data), &options)
+	if err == nil {
+		return options, nil
+	}
+
+	var texts []string
+	if json.Unmarshal([]byte(data), &texts) != nil {
+		return nil, err
+	}
+	return NewPollOptions(texts...), nil
+}
+
+// GetPollResults retrieves a poll with its votes, oldest first, and the
+// number of voters per option in the order the options were offered.
+// Selections of options the poll no longer offers are not counted. It
+// returns sql.ErrNoRows if the poll does not exist.
+func (s *Store) GetPollResults(messageID, chatJID string) (*PollResults, error) {
+	poll, err := s.GetPoll(messageID, chatJID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT message_id, chat_jid, voter_jid, selected_options, voted_at
+		FROM poll_votes
+		WHERE message_id = ? AND chat_jid = ?
+		ORDER BY voted_at`,
+		messageID, chatJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := &PollResults{PollMessage: poll, Votes: []*PollVote{}}
+	counts := make(map[string]int, len(poll.Options))
+	for rows.Next() {
+		v := &PollVote{}
+		var selected string
+		if err := rows.Scan(&v.MessageID, &v.ChatJID, &v.VoterJID, &selected, &v.VotedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(selected), &v.SelectedOptions); err != nil {
+			return nil, fmt.Errorf("invalid vote of %s: %w", v.VoterJID, err)
+		}
+
+		for _, option := range v.SelectedOptions {
+			counts[option]++
+		}
+		results.Votes = append(results.Votes, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results.TotalVoters = len(results.Votes)
+	results.Results = make([]PollOptionCount, len(poll.Options))
+	for i, option := range poll.Options {
+		results.Results[i] = PollOptionCount{ID: option.ID, Option: option.Text, Votes: counts[option.Text]}
+	}
+
+	return results, nil
+}
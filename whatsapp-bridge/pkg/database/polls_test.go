@@ -0,0 +1,155 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolls(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chatJID := "123456789-1234567890@g.us"
+	poll := &PollMessage{
+		MessageID:     "poll1",
+		ChatJID:       chatJID,
+		Question:      "Lunch?",
+		Options:       NewPollOptions("Pizza", "Sushi", "Salad"),
+		AllowMultiple: true,
+	}
+	if err := store.StorePoll(poll); err != nil {
+		t.Fatalf("Failed to store poll: %v", err)
+	}
+
+	got, err := store.GetPoll("poll1", chatJID)
+	if err != nil {
+		t.Fatalf("Failed to get poll: %v", err)
+	}
+	if got.Question != "Lunch?" || len(got.Options) != 3 || got.Options[1] != NewPollOption("Sushi") || !got.AllowMultiple {
+		t.Errorf("Unexpected poll: %+v", got)
+	}
+
+	now := time.Now()
+	votes := []*PollVote{
+		{VoterJID: "alice@s.whatsapp.net", SelectedOptions: []string{"Pizza"}, VotedAt: now},
+		{VoterJID: "bob@s.whatsapp.net", SelectedOptions: []string{"Pizza", "Salad"}, VotedAt: now.Add(time.Second)},
+		{VoterJID: "carol@s.whatsapp.net", SelectedOptions: []string{"Sushi"}, VotedAt: now.Add(2 * time.Second)},
+		// Voting again replaces the earlier selection
+		{VoterJID: "alice@s.whatsapp.net", SelectedOptions: []string{"Salad"}, VotedAt: now.Add(3 * time.Second)},
+		// An empty selection withdraws the vote
+		{VoterJID: "carol@s.whatsapp.net", VotedAt: now.Add(4 * time.Second)},
+	}
+	for _, v := range votes {
+		v.MessageID, v.ChatJID = "poll1", chatJID
+		if err := store.StorePollVote(v); err != nil {
+			t.Fatalf("Failed to store vote: %v", err)
+		}
+	}
+
+	results, err := store.GetPollResults("poll1", chatJID)
+	if err != nil {
+		t.Fatalf("Failed to get poll results: %v", err)
+	}
+	if results.TotalVoters != 2 {
+		t.Errorf("Expected 2 voters, got %d", results.TotalVoters)
+	}
+	want := []PollOptionCount{
+		{NewPollOption("Pizza").ID, "Pizza", 1},
+		{NewPollOption("Sushi").ID, "Sushi", 0},
+		{NewPollOption("Salad").ID, "Salad", 2},
+	}
+	for i, w := range want {
+		if results.Results[i] != w {
+			t.Errorf("Expected %v at %d, got %v", w, i, results.Results[i])
+		}
+	}
+	if results.Votes[0].VoterJID != "bob@s.whatsapp.net" {
+		t.Errorf("Expected votes oldest first, got %s first", results.Votes[0].VoterJID)
+	}
+
+	err = store.StorePollVote(&PollVote{MessageID: "missing", ChatJID: chatJID, VoterJID: "alice@s.whatsapp.net", SelectedOptions: []string{"Pizza"}})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows voting in an unknown poll, got %v", err)
+	}
+	if _, err := store.GetPollResults("missing", chatJID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for an unknown poll, got %v", err)
+	}
+}
+
+func TestUpdatePollResults(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chatJID := "123456789-1234567890@g.us"
+	poll := &PollMessage{MessageID: "poll1", ChatJID: chatJID, Question: "Lunch?", Options: []PollOption{{Text: "Pizza"}, {Text: "Sushi"}, {Text: "Salad"}}, AllowMultiple: true}
+	if err := store.StorePoll(poll); err != nil {
+		t.Fatalf("Failed to store poll: %v", err)
+	}
+
+	// Options stored without IDs get the SHA-256 hashes WhatsApp uses
+	stored, err := store.GetPoll("poll1", chatJID)
+	if err != nil {
+		t.Fatalf("Failed to get poll: %v", err)
+	}
+	options := stored.Options
+	if len(options) != 3 || options[0].Text != "Pizza" || options[0].ID != "f12958816a49adfa2c6c8de8dd2144c163e92c5e375de964d533187c7d236c36" {
+		t.Fatalf("Unexpected options %+v", options)
+	}
+
+	if err := store.UpdatePollResults("poll1", chatJID, "alice@s.whatsapp.net", []string{options[2].ID, options[0].ID, "unknown"}); err != nil {
+		t.Fatalf("Failed to update poll results: %v", err)
+	}
+	results, err := store.GetPollResults("poll1", chatJID)
+	if err != nil {
+		t.Fatalf("Failed to get poll results: %v", err)
+	}
+	if results.TotalVoters != 1 || results.Results[0].Votes != 1 || results.Results[1].Votes != 0 || results.Results[2].Votes != 1 {
+		t.Errorf("Expected one vote for Pizza and Salad, got %+v", results.Results)
+	}
+	if results.Results[1].ID != options[1].ID {
+		t.Errorf("Expected results to carry option IDs, got %+v", results.Results[1])
+	}
+
+	// Selecting nothing withdraws the vote
+	if err := store.UpdatePollResults("poll1", chatJID, "alice@s.whatsapp.net", nil); err != nil {
+		t.Fatalf("Failed to withdraw vote: %v", err)
+	}
+	if results, _ := store.GetPollResults("poll1", chatJID); results.TotalVoters != 0 {
+		t.Errorf("Expected the vote to be withdrawn, got %d voters", results.TotalVoters)
+	}
+
+	if err := store.UpdatePollResults("missing", chatJID, "alice@s.whatsapp.net", []string{options[0].ID}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for an unknown poll, got %v", err)
+	}
+}
+
+func TestGetPollLegacyOptions(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	// Polls stored before options carried their IDs hold only the texts
+	chatJID := "123456789-1234567890@g.us"
+	_, err := store.db.Exec(`INSERT INTO poll_messages (message_id, chat_jid, question, options, allow_multiple) VALUES (?, ?, ?, ?, ?)`,
+		"poll1", chatJID, "Lunch?", `["Pizza","Sushi"]`, false)
+	if err != nil {
+		t.Fatalf("Failed to insert legacy poll: %v", err)
+	}
+
+	poll, err := store.GetPoll("poll1", chatJID)
+	if err != nil {
+		t.Fatalf("Failed to get legacy poll: %v", err)
+	}
+	want := NewPollOptions("Pizza", "Sushi")
+	if len(poll.Options) != len(want) || poll.Options[0] != want[0] || poll.Options[1] != want[1] {
+		t.Errorf("Expected %+v, got %+v", want, poll.Options)
+	}
+
+	if _, err := store.db.Exec(`UPDATE poll_messages SET options = '{"bad": true}'`); err != nil {
+		t.Fatalf("Failed to corrupt poll: %v", err)
+	}
+	if _, err := store.GetPoll("poll1", chatJID); err == nil {
+		t.Error("Expected an error for options that are neither form")
+	}
+}
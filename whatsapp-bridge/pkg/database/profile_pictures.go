@@ -0,0 +1,46 @@
+package database
+
+import (
+	"time"
+)
+
+// StoreProfilePicture inserts or replaces the cached profile picture of a
+// JID. A zero CachedAt is set to the current time.
+func (s *Store) StoreProfilePicture(pp *ProfilePicture) error {
+	if pp.CachedAt.IsZero() {
+		pp.CachedAt = time.Now()
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO profile_pictures (jid, url, etag, cached_at, expires_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (jid) DO UPDATE SET
+			url = excluded.url, etag = excluded.etag,
+			cached_at = excluded.cached_at, expires_at = excluded.expires_at`,
+		pp.JID, pp.URL, pp.ETag, pp.CachedAt.UTC(), pp.ExpiresAt.UTC(),
+	)
+	return err
+}
+
+// GetProfilePicture retrieves the cached profile picture of a JID, expired
+// or not. It returns sql.ErrNoRows if nothing is cached.
+func (s *Store) GetProfilePicture(jid string) (*ProfilePicture, error) {
+	pp := &ProfilePicture{}
+	err := s.db.QueryRow(`
+		SELECT jid, COALESCE(url, ''), COALESCE(etag, ''), cached_at, expires_at
+		FROM profile_pictures
+		WHERE jid = ?`,
+		jid,
+	).Scan(&pp.JID, &pp.URL, &pp.ETag, &pp.CachedAt, &pp.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return pp, nil
+}
+
+// InvalidateProfilePicture drops the cached profile picture of a JID so the
+// next lookup fetches it again. Invalidating an uncached JID is not an error.
+func (s *Store) InvalidateProfilePicture(jid string) error {
+	_, err := s.db.Exec("DELETE FROM profile_pictures WHERE jid = ?", jid)
+	return err
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProfilePictures(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	jid := "123456789@s.whatsapp.net"
+	if _, err := store.GetProfilePicture(jid); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows before caching, got %v", err)
+	}
+
+	pp := &ProfilePicture{JID: jid, URL: "https://pps.whatsapp.net/v/1", ETag: "1001", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.StoreProfilePicture(pp); err != nil {
+		t.Fatalf("Failed to store profile picture: %v", err)
+	}
+
+	got, err := store.GetProfilePicture(jid)
+	if err != nil {
+		t.Fatalf("Failed to get profile picture: %v", err)
+	}
+	if got.URL != pp.URL || got.ETag != "1001" || got.IsExpired() || got.CachedAt.IsZero() {
+		t.Errorf("Expected a fresh cached picture, got %+v", got)
+	}
+
+	// Storing again replaces the entry
+	pp.URL = "https://pps.whatsapp.net/v/2"
+	pp.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := store.StoreProfilePicture(pp); err != nil {
+		t.Fatalf("Failed to update profile picture: %v", err)
+	}
+	got, err = store.GetProfilePicture(jid)
+	if err != nil {
+		t.Fatalf("Failed to get profile picture: %v", err)
+	}
+	if got.URL != pp.URL || !got.IsExpired() {
+		t.Errorf("Expected the replaced picture to be expired, got %+v", got)
+	}
+
+	if err := store.InvalidateProfilePicture(jid); err != nil {
+		t.Fatalf("Failed to invalidate profile picture: %v", err)
+	}
+	if _, err := store.GetProfilePicture(jid); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows after invalidation, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// StoreReaction inserts or replaces the sender's reaction to a message. It
+// returns sql.ErrNoRows if the message does not exist.
+func (s *Store) StoreReaction(r *Reaction) error {
+	if r.Emoji == "" {
+		return fmt.Errorf("reaction emoji cannot be empty")
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO reactions (message_id, chat_jid, sender, emoji, timestamp) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (message_id, chat_jid, sender) DO UPDATE SET emoji = excluded.emoji, timestamp = excluded.timestamp`,
+		r.MessageID, r.ChatJID, r.Sender, r.Emoji, r.Timestamp.UTC(),
+	)
+	if isForeignKeyViolation(err) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// DeleteReaction removes the sender's reaction to a message. It returns
+// sql.ErrNoRows if there is no such reaction.
+func (s *Store) DeleteReaction(messageID, chatJID, sender string) error {
+	result, err := s.db.Exec(
+		"DELETE FROM reactions WHERE message_id = ? AND chat_jid = ? AND sender = ?",
+		messageID, chatJID, sender,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetReactions retrieves all reactions to a message, oldest first
+func (s *Store) GetReactions(messageID, chatJID string) ([]*Reaction, error) {
+	rows, err := s.db.Query(`
+		SELECT message_id, chat_jid, sender, emoji, timestamp
+		FROM reactions
+		WHERE message_id = ? AND chat_jid = ?
+		ORDER BY timestamp`,
+		messageID, chatJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := []*Reaction{}
+	for rows.Next() {
+		r := &Reaction{}
+		if err := rows.Scan(&r.MessageID, &r.ChatJID, &r.Sender, &r.Emoji, &r.Timestamp); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, r)
+	}
+
+	return reactions, rows.Err()
+}
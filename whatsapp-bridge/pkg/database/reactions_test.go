@@ -0,0 +1,64 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReactions(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	if err := store.BulkStoreMessages(testMessages(chat.JID, 1)); err != nil {
+		t.Fatalf("Failed to store message: %v", err)
+	}
+
+	reactions := []*Reaction{
+		{MessageID: "msg0", ChatJID: chat.JID, Sender: "111", Emoji: "👍", Timestamp: time.Now()},
+		{MessageID: "msg0", ChatJID: chat.JID, Sender: "222", Emoji: "❤️", Timestamp: time.Now()},
+		// A second reaction from the same sender replaces the first
+		{MessageID: "msg0", ChatJID: chat.JID, Sender: "111", Emoji: "😂", Timestamp: time.Now()},
+	}
+	for _, r := range reactions {
+		if err := store.StoreReaction(r); err != nil {
+			t.Fatalf("Failed to store reaction: %v", err)
+		}
+	}
+
+	got, err := store.GetReactions("msg0", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get reactions: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 reactions, got %d", len(got))
+	}
+	for _, r := range got {
+		if r.Sender == "111" && r.Emoji != "😂" {
+			t.Errorf("Expected replaced reaction 😂, got %s", r.Emoji)
+		}
+	}
+
+	if err := store.DeleteReaction("msg0", chat.JID, "222"); err != nil {
+		t.Fatalf("Failed to delete reaction: %v", err)
+	}
+	if err := store.DeleteReaction("msg0", chat.JID, "222"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows deleting missing reaction, got %v", err)
+	}
+
+	got, err = store.GetReactions("msg0", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get reactions: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("Expected 1 reaction after delete, got %d", len(got))
+	}
+
+	missing := &Reaction{MessageID: "missing", ChatJID: chat.JID, Sender: "111", Emoji: "👍", Timestamp: time.Now()}
+	if err := store.StoreReaction(missing); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows reacting to missing message, got %v", err)
+	}
+}
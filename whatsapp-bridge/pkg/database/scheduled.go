@@ -0,0 +1,123 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// scheduledColumns lists the scheduled_messages columns in the order
+// scanScheduledMessages expects
+const scheduledColumns = "id, recipient, COALESCE(content, ''), COALESCE(media_path, ''), scheduled_at, sent_at, status"
+
+// ScheduleMessage queues a message for sending at msg.ScheduledAt,
+// generating its ID if empty. New messages always start out pending.
+func (s *Store) ScheduleMessage(msg *ScheduledMessage) error {
+	if msg.Recipient == "" {
+		return fmt.Errorf("recipient cannot be empty")
+	}
+	if msg.Content == "" && msg.MediaPath == "" {
+		return fmt.Errorf("content or media path is required")
+	}
+	if msg.ScheduledAt.IsZero() {
+		return fmt.Errorf("scheduled time is required")
+	}
+	if msg.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return fmt.Errorf("failed to generate scheduled message ID: %w", err)
+		}
+		msg.ID = id
+	}
+	msg.Status = ScheduledStatusPending
+	msg.SentAt = nil
+
+	_, err := s.db.Exec(`
+		INSERT INTO scheduled_messages (id, recipient, content, media_path, scheduled_at, status)
+		VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?)`,
+		msg.ID, msg.Recipient, msg.Content, msg.MediaPath, msg.ScheduledAt.UTC(), msg.Status,
+	)
+	return err
+}
+
+// GetDueMessages retrieves pending messages scheduled at or before now,
+// oldest first
+func (s *Store) GetDueMessages(now time.Time) ([]*ScheduledMessage, error) {
+	rows, err := s.db.Query(`
+		SELECT `+scheduledColumns+`
+		FROM scheduled_messages
+		WHERE status = ? AND scheduled_at <= ?
+		ORDER BY scheduled_at, id`,
+		ScheduledStatusPending, now.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanScheduledMessages(rows)
+}
+
+// GetScheduledMessages retrieves scheduled messages with pagination, soonest
+// first. When status is set only messages in that state are returned.
+func (s *Store) GetScheduledMessages(status ScheduledStatus, limit, offset int) ([]*ScheduledMessage, error) {
+	rows, err := s.db.Query(`
+		SELECT `+scheduledColumns+`
+		FROM scheduled_messages
+		WHERE ? = '' OR status = ?
+		ORDER BY scheduled_at, id
+		LIMIT ? OFFSET ?`,
+		status, status, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanScheduledMessages(rows)
+}
+
+// MarkScheduledSent records that a pending message was sent. It returns
+// sql.ErrNoRows if no pending message has that ID.
+func (s *Store) MarkScheduledSent(id string, sentAt time.Time) error {
+	sentAt = sentAt.UTC()
+	return s.finishScheduled(id, ScheduledStatusSent, &sentAt)
+}
+
+// MarkScheduledFailed records that sending a pending message failed, so it
+// is not retried. It returns sql.ErrNoRows if no pending message has that ID.
+func (s *Store) MarkScheduledFailed(id string) error {
+	return s.finishScheduled(id, ScheduledStatusFailed, nil)
+}
+
+// finishScheduled moves a pending message to a final state
+func (s *Store) finishScheduled(id string, status ScheduledStatus, sentAt *time.Time) error {
+	result, err := s.db.Exec(
+		"UPDATE scheduled_messages SET status = ?, sent_at = ? WHERE id = ? AND status = ?",
+		status, sentAt, id, ScheduledStatusPending,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// scanScheduledMessages reads rows selected with scheduledColumns
+func scanScheduledMessages(rows *sql.Rows) ([]*ScheduledMessage, error) {
+	messages := []*ScheduledMessage{}
+	for rows.Next() {
+		m := &ScheduledMessage{}
+		var sentAt sql.NullTime
+		err := rows.Scan(&m.ID, &m.Recipient, &m.Content, &m.MediaPath, &m.ScheduledAt, &sentAt, &m.Status)
+		if err != nil {
+			return nil, err
+		}
+		if sentAt.Valid {
+			m.SentAt = &sentAt.Time
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
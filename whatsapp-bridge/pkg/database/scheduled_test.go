@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduledMessages(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	now := time.Now()
+	recipient := "111111111@s.whatsapp.net"
+
+	msgs := []*ScheduledMessage{
+		{Recipient: recipient, Content: "overdue", ScheduledAt: now.Add(-time.Hour)},
+		{Recipient: recipient, MediaPath: "/tmp/photo.jpg", ScheduledAt: now.Add(-time.Minute)},
+		{Recipient: recipient, Content: "later", ScheduledAt: now.Add(time.Hour)},
+	}
+	for _, msg := range msgs {
+		if err := store.ScheduleMessage(msg); err != nil {
+			t.Fatalf("Failed to schedule message: %v", err)
+		}
+		if msg.ID == "" || msg.Status != ScheduledStatusPending {
+			t.Errorf("Expected generated ID and pending status, got %q %q", msg.ID, msg.Status)
+		}
+	}
+
+	if err := store.ScheduleMessage(&ScheduledMessage{Recipient: recipient, ScheduledAt: now}); err == nil {
+		t.Error("Expected error when scheduling a message without content or media")
+	}
+
+	due, err := store.GetDueMessages(now)
+	if err != nil {
+		t.Fatalf("Failed to get due messages: %v", err)
+	}
+	if len(due) != 2 || due[0].ID != msgs[0].ID || due[1].MediaPath != "/tmp/photo.jpg" {
+		t.Fatalf("Expected the two overdue messages oldest first, got %+v", due)
+	}
+
+	sentAt := now.Add(time.Second)
+	if err := store.MarkScheduledSent(msgs[0].ID, sentAt); err != nil {
+		t.Fatalf("Failed to mark message sent: %v", err)
+	}
+	if err := store.MarkScheduledFailed(msgs[1].ID); err != nil {
+		t.Fatalf("Failed to mark message failed: %v", err)
+	}
+
+	// Only pending messages can be finished
+	if err := store.MarkScheduledSent(msgs[0].ID, sentAt); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows marking a sent message again, got %v", err)
+	}
+
+	due, err = store.GetDueMessages(now)
+	if err != nil {
+		t.Fatalf("Failed to get due messages: %v", err)
+	}
+	if len(due) != 0 {
+		t.Errorf("Expected no due messages after dispatch, got %d", len(due))
+	}
+
+	sent, err := store.GetScheduledMessages(ScheduledStatusSent, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get sent messages: %v", err)
+	}
+	if len(sent) != 1 || sent[0].SentAt == nil || !sent[0].SentAt.Equal(sentAt) {
+		t.Errorf("Expected one sent message with sent_at recorded, got %+v", sent)
+	}
+
+	all, err := store.GetScheduledMessages("", 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get scheduled messages: %v", err)
+	}
+	if len(all) != 3 || all[2].Status != ScheduledStatusPending {
+		t.Errorf("Expected all three messages soonest first, got %+v", all)
+	}
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// mediaTypes are the media_type values stored for messages with an
+// attachment
+var mediaTypes = map[string]bool{
+	"image":    true,
+	"video":    true,
+	"audio":    true,
+	"document": true,
+	"vcard":    true,
+}
+
+// ValidMediaType reports whether mediaType is a media_type messages are
+// stored with
+func ValidMediaType(mediaType string) bool {
+	return mediaTypes[mediaType]
+}
+
+// SearchMessagesFilter narrows a filtered message search. Zero fields do
+// not filter; Query matches every word like SearchMessages, and the time
+// range is inclusive.
+type SearchMessagesFilter struct {
+	Query     string
+	ChatJID   string
+	Sender    string
+	FromTime  time.Time
+	ToTime    time.Time
+	MediaType string
+	IsFromMe  *bool
+}
+
+// Validate checks that the filter can be searched with
+func (f SearchMessagesFilter) Validate() error {
+	if !f.FromTime.IsZero() && !f.ToTime.IsZero() && f.FromTime.After(f.ToTime) {
+		return fmt.Errorf("from_time must not be after to_time")
+	}
+	if f.MediaType != "" && !ValidMediaType(f.MediaType) {
+		return fmt.Errorf("unsupported media_type: %s", f.MediaType)
+	}
+	return nil
+}
+
+// where builds the WHERE clause matching the filter and its arguments.
+// Soft-deleted messages never match.
+func (f SearchMessagesFilter) where(fts bool) (string, []interface{}) {
+	var b strings.Builder
+	var args []interface{}
+	b.WriteString(" WHERE is_deleted = 0")
+
+	if terms := strings.Fields(f.Query); len(terms) > 0 {
+		if fts {
+			b.WriteString(" AND rowid IN (SELECT rowid FROM messages_fts WHERE messages_fts MATCH ?)")
+			args = append(args, ftsQuery(terms))
+		} else {
+			for _, term := range terms {
+				b.WriteString(" AND content LIKE ? ESCAPE '\\'")
+				args = append(args, "%"+likeEscaper.Replace(term)+"%")
+			}
+		}
+	}
+	if f.ChatJID != "" {
+		b.WriteString(" AND chat_jid = ?")
+		args = append(args, f.ChatJID)
+	}
+	if f.Sender != "" {
+		b.WriteString(" AND sender = ?")
+		args = append(args, f.Sender)
+	}
+	if !f.FromTime.IsZero() {
+		b.WriteString(" AND timestamp >= ?")
+		args = append(args, f.FromTime.UTC())
+	}
+	if !f.ToTime.IsZero() {
+		b.WriteString(" AND timestamp <= ?")
+		args = append(args, f.ToTime.UTC())
+	}
+	if f.MediaType != "" {
+		b.WriteString(" AND media_type = ?")
+		args = append(args, f.MediaType)
+	}
+	if f.IsFromMe != nil {
+		b.WriteString(" AND is_from_me = ?")
+		args = append(args, *f.IsFromMe)
+	}
+
+	return b.String(), args
+}
+
+// SearchMessagesFiltered returns the newest messages matching the filter,
+// starting after cursor, and the cursor of the next page; the returned
+// cursor is zero on the last page.
+func (s *Store) SearchMessagesFiltered(f SearchMessagesFilter, limit int, cursor Cursor) ([]*Message, Cursor, error) {
+	if err := f.Validate(); err != nil {
+		return nil, Cursor{}, err
+	}
+
+	where, args := f.where(s.fts5)
+	var query strings.Builder
+	query.WriteString("SELECT " + messageColumns + " FROM messages")
+	query.WriteString(where)
+	if !cursor.IsZero() {
+		query.WriteString(" AND (timestamp, id) < (?, ?)")
+		args = append(args, cursor.AfterTimestamp.UTC(), cursor.AfterID)
+	}
+	query.WriteString(" ORDER BY timestamp DESC, id DESC LIMIT ?")
+	args = append(args, limit+1)
+
+	rows, err := s.db.Query(query.String(), args...)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+
+	var next Cursor
+	if len(messages) > limit {
+		messages = messages[:limit]
+		last := messages[limit-1]
+		next = Cursor{AfterTimestamp: last.Timestamp, AfterID: last.ID}
+	}
+	return messages, next, nil
+}
+
+// CountMessagesFiltered returns the number of messages matching the filter
+func (s *Store) CountMessagesFiltered(f SearchMessagesFilter) (int, error) {
+	if err := f.Validate(); err != nil {
+		return 0, err
+	}
+
+	where, args := f.where(s.fts5)
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM messages"+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetMediaMessages returns a chat's messages with attachments, newest
+// first, optionally only those of one media type
+func (s *Store) GetMediaMessages(chatJID string, mediaType string, limit, offset int) ([]*Message, error) {
+	if mediaType != "" && !ValidMediaType(mediaType) {
+		return nil, fmt.Errorf("unsupported media_type: %s", mediaType)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE chat_jid = ? AND media_type != '' AND (media_type = ? OR ? = '') AND is_deleted = 0
+		ORDER BY timestamp DESC, id DESC
+		LIMIT ? OFFSET ?`,
+		chatJID, mediaType, mediaType, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchMessagesFiltered(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	alice, bob := "111111111@s.whatsapp.net", "222222222@s.whatsapp.net"
+	for _, jid := range []string{alice, bob} {
+		store.StoreChat(&Chat{JID: jid, Name: jid, LastMessageTime: time.Now()})
+	}
+
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	messages := []*Message{
+		{ID: "m1", ChatJID: alice, Sender: "111111111", Content: "Lunch at noon?", Timestamp: base},
+		{ID: "m2", ChatJID: alice, Sender: "me", Content: "Lunch sounds good", Timestamp: base.Add(time.Hour), IsFromMe: true},
+		{ID: "m3", ChatJID: alice, Sender: "111111111", Content: "Menu", Timestamp: base.Add(2 * time.Hour), MediaType: "image"},
+		{ID: "m4", ChatJID: bob, Sender: "222222222", Content: "Lunch tomorrow", Timestamp: base.Add(3 * time.Hour)},
+		{ID: "m5", ChatJID: bob, Sender: "222222222", Content: "Deleted lunch", Timestamp: base.Add(4 * time.Hour)},
+	}
+	for _, msg := range messages {
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+	if err := store.SoftDeleteMessage("m5", bob, time.Now()); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+
+	fromMe, notFromMe := true, false
+	tests := []struct {
+		name   string
+		filter SearchMessagesFilter
+		want   []string
+	}{
+		{"everything", SearchMessagesFilter{}, []string{"m4", "m3", "m2", "m1"}},
+		{"text", SearchMessagesFilter{Query: "lunch"}, []string{"m4", "m2", "m1"}},
+		{"text in chat", SearchMessagesFilter{Query: "lunch", ChatJID: alice}, []string{"m2", "m1"}},
+		{"sender", SearchMessagesFilter{Sender: "111111111"}, []string{"m3", "m1"}},
+		{"time range", SearchMessagesFilter{FromTime: base.Add(time.Hour), ToTime: base.Add(2 * time.Hour)}, []string{"m3", "m2"}},
+		{"media type", SearchMessagesFilter{MediaType: "image"}, []string{"m3"}},
+		{"from me", SearchMessagesFilter{IsFromMe: &fromMe}, []string{"m2"}},
+		{"not from me with text", SearchMessagesFilter{Query: "lunch", IsFromMe: &notFromMe}, []string{"m4", "m1"}},
+		{"no match", SearchMessagesFilter{Query: "dinner"}, nil},
+	}
+
+	for _, test := range tests {
+		got, next, err := store.SearchMessagesFiltered(test.filter, 10, Cursor{})
+		if err != nil {
+			t.Fatalf("%s: SearchMessagesFiltered failed: %v", test.name, err)
+		}
+		if !next.IsZero() {
+			t.Errorf("%s: expected no next page", test.name)
+		}
+		if ids := messageIDs(got); !slices.Equal(ids, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, ids, test.want)
+		}
+		count, err := store.CountMessagesFiltered(test.filter)
+		if err != nil {
+			t.Fatalf("%s: CountMessagesFiltered failed: %v", test.name, err)
+		}
+		if count != len(test.want) {
+			t.Errorf("%s: counted %d, want %d", test.name, count, len(test.want))
+		}
+	}
+
+	// Page through the text matches two at a time
+	var pages [][]string
+	var cursor Cursor
+	for {
+		got, next, err := store.SearchMessagesFiltered(SearchMessagesFilter{Query: "lunch"}, 2, cursor)
+		if err != nil {
+			t.Fatalf("SearchMessagesFiltered failed: %v", err)
+		}
+		pages = append(pages, messageIDs(got))
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+	if len(pages) != 2 || !slices.Equal(pages[0], []string{"m4", "m2"}) || !slices.Equal(pages[1], []string{"m1"}) {
+		t.Errorf("Unexpected pages %v", pages)
+	}
+
+	invalid := []SearchMessagesFilter{
+		{FromTime: base.Add(time.Hour), ToTime: base},
+		{MediaType: "hologram"},
+	}
+	for _, f := range invalid {
+		if _, _, err := store.SearchMessagesFiltered(f, 10, Cursor{}); err == nil {
+			t.Errorf("Expected an error for filter %+v", f)
+		}
+	}
+}
+
+// messageIDs returns the IDs of messages in order
+func messageIDs(messages []*Message) []string {
+	var ids []string
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+func TestGetMediaMessages(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chatJID, otherJID := "111111111@s.whatsapp.net", "222222222@s.whatsapp.net"
+	for _, jid := range []string{chatJID, otherJID} {
+		store.StoreChat(&Chat{JID: jid, Name: jid, LastMessageTime: time.Now()})
+	}
+
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	messages := []*Message{
+		{ID: "text", ChatJID: chatJID, Sender: "111111111", Content: "Hi", Timestamp: base},
+		{ID: "photo1", ChatJID: chatJID, Sender: "111111111", MediaType: "image", Filename: "a.jpg", Timestamp: base.Add(time.Minute)},
+		{ID: "clip", ChatJID: chatJID, Sender: "111111111", MediaType: "video", Filename: "b.mp4", Timestamp: base.Add(2 * time.Minute)},
+		{ID: "photo2", ChatJID: chatJID, Sender: "111111111", MediaType: "image", Filename: "c.jpg", Timestamp: base.Add(3 * time.Minute)},
+		{ID: "deleted", ChatJID: chatJID, Sender: "111111111", MediaType: "image", Filename: "d.jpg", Timestamp: base.Add(4 * time.Minute)},
+		{ID: "elsewhere", ChatJID: otherJID, Sender: "222222222", MediaType: "image", Filename: "e.jpg", Timestamp: base},
+	}
+	for _, msg := range messages {
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+	if err := store.SoftDeleteMessage("deleted", chatJID, time.Now()); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+
+	tests := []struct {
+		mediaType     string
+		limit, offset int
+		want          []string
+	}{
+		{"", 10, 0, []string{"photo2", "clip", "photo1"}},
+		{"image", 10, 0, []string{"photo2", "photo1"}},
+		{"image", 1, 1, []string{"photo1"}},
+		{"audio", 10, 0, nil},
+	}
+	for _, test := range tests {
+		got, err := store.GetMediaMessages(chatJID, test.mediaType, test.limit, test.offset)
+		if err != nil {
+			t.Fatalf("GetMediaMessages(%q) failed: %v", test.mediaType, err)
+		}
+		if ids := messageIDs(got); !slices.Equal(ids, test.want) {
+			t.Errorf("GetMediaMessages(%q, %d, %d) = %v, want %v", test.mediaType, test.limit, test.offset, ids, test.want)
+		}
+	}
+
+	if _, err := store.GetMediaMessages(chatJID, "hologram", 10, 0); err == nil {
+		t.Error("Expected an error for an unknown media type")
+	}
+
+	rows, err := store.db.Query(`EXPLAIN QUERY PLAN SELECT `+messageColumns+` FROM messages
+		WHERE chat_jid = ? AND media_type != '' AND (media_type = ? OR ? = '') AND is_deleted = 0
+		ORDER BY timestamp DESC, id DESC LIMIT 20 OFFSET 0`, chatJID, "image", "image")
+	if err != nil {
+		t.Fatalf("Failed to explain query: %v", err)
+	}
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			t.Fatalf("Failed to scan plan: %v", err)
+		}
+		plan = append(plan, detail)
+	}
+	if joined := strings.Join(plan, "\n"); !strings.Contains(joined, "idx_messages_chat_media") || strings.Contains(joined, "TEMP B-TREE") {
+		t.Errorf("Expected idx_messages_chat_media without a sort step, got plan:\n%s", joined)
+	}
+}
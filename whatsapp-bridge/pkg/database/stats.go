@@ -0,0 +1,183 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// topSendersLimit caps the senders listed in ChatStatistics
+const topSendersLimit = 10
+
+// GetMessageStats aggregates the messages of a chat: the total, the count per
+// sender (most active first) and the count per UTC hour of day. Soft-deleted
+// messages are not counted.
+func (s *Store) GetMessageStats(chatJID string) (*MessageStats, error) {
+	stats := &MessageStats{ChatJID: chatJID, TopSenders: []SenderCount{}}
+
+	rows, err := s.db.Query(`
+		SELECT sender, COUNT(*) AS n
+		FROM messages
+		WHERE chat_jid = ? AND is_deleted = 0
+		GROUP BY sender
+		ORDER BY n DESC, sender`,
+		chatJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sc SenderCount
+		if err := rows.Scan(&sc.Sender, &sc.Count); err != nil {
+			return nil, err
+		}
+		stats.TopSenders = append(stats.TopSenders, sc)
+		stats.TotalMessages += sc.Count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`
+		SELECT CAST(strftime('%H', timestamp) AS INTEGER) AS hour, COUNT(*)
+		FROM messages
+		WHERE chat_jid = ? AND is_deleted = 0
+		GROUP BY hour`,
+		chatJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hour, count int
+		if err := rows.Scan(&hour, &count); err != nil {
+			return nil, err
+		}
+		if hour >= 0 && hour < len(stats.ByHour) {
+			stats.ByHour[hour] = count
+		}
+	}
+
+	return stats, rows.Err()
+}
+
+// GetChatStatistics summarizes a chat in a single query. Our own messages
+// count as one party whatever sender they were stored with, and a response
+// is any message following one from a different party. Soft-deleted
+// messages are not counted.
+func (s *Store) GetChatStatistics(chatJID string) (*ChatStatistics, error) {
+	rows, err := s.db.Query(`
+		WITH msgs AS (
+			SELECT id, sender, is_from_me, timestamp,
+				CASE WHEN is_from_me THEN '' ELSE sender END AS party
+			FROM messages
+			WHERE chat_jid = ? AND is_deleted = 0
+		),
+		totals AS (
+			SELECT COUNT(*) AS total,
+				COALESCE(SUM(is_from_me), 0) AS from_me,
+				CAST(strftime('%s', MIN(timestamp)) AS INTEGER) AS first_at,
+				CAST(strftime('%s', MAX(timestamp)) AS INTEGER) AS last_at
+			FROM msgs
+		),
+		sequence AS (
+			SELECT party, CAST(strftime('%s', timestamp) AS INTEGER) AS at,
+				LAG(party) OVER (ORDER BY timestamp, id) AS prev_party,
+				LAG(CAST(strftime('%s', timestamp) AS INTEGER)) OVER (ORDER BY timestamp, id) AS prev_at
+			FROM msgs
+		),
+		responses AS (
+			SELECT COALESCE(AVG(at - prev_at), 0) AS avg_seconds
+			FROM sequence
+			WHERE prev_party IS NOT NULL AND party != prev_party
+		),
+		top AS (
+			SELECT sender, COUNT(*) AS n
+			FROM msgs
+			GROUP BY sender
+			ORDER BY n DESC, sender
+			LIMIT ?
+		)
+		SELECT totals.total, totals.from_me, totals.first_at, totals.last_at,
+			responses.avg_seconds, top.sender, top.n
+		FROM totals
+		CROSS JOIN responses
+		LEFT JOIN top ON 1
+		ORDER BY top.n DESC, top.sender`,
+		chatJID, topSendersLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := &ChatStatistics{TopSenders: []SenderCount{}}
+	for rows.Next() {
+		var firstAt, lastAt, count sql.NullInt64
+		var sender sql.NullString
+		if err := rows.Scan(&stats.TotalMessages, &stats.FromMeCount, &firstAt, &lastAt,
+			&stats.AvgResponseTimeSeconds, &sender, &count); err != nil {
+			return nil, err
+		}
+		if firstAt.Valid {
+			stats.FirstMessageAt = time.Unix(firstAt.Int64, 0).UTC()
+			stats.LastMessageAt = time.Unix(lastAt.Int64, 0).UTC()
+		}
+		if sender.Valid {
+			stats.TopSenders = append(stats.TopSenders, SenderCount{Sender: sender.String, Count: int(count.Int64)})
+		}
+	}
+	stats.FromOthersCount = stats.TotalMessages - stats.FromMeCount
+
+	return stats, rows.Err()
+}
+
+// GetChatActivityByDay counts the messages of a chat on each of the last days
+// UTC days, oldest first and including today. Days without messages are
+// reported with a zero count.
+func (s *Store) GetChatActivityByDay(chatJID string, days int) ([]DayCount, error) {
+	if days < 1 {
+		return []DayCount{}, nil
+	}
+	now := time.Now().UTC()
+	first := now.AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	today := now.Format("2006-01-02")
+
+	// Timestamps are stored as UTC text, so the date prefix compares directly
+	rows, err := s.db.Query(`
+		WITH RECURSIVE day(d) AS (
+			SELECT ?
+			UNION ALL
+			SELECT date(d, '+1 day') FROM day WHERE d < ?
+		),
+		counts AS (
+			SELECT date(timestamp) AS d, COUNT(*) AS n
+			FROM messages
+			WHERE chat_jid = ? AND is_deleted = 0 AND timestamp >= ?
+			GROUP BY d
+		)
+		SELECT day.d, COALESCE(counts.n, 0)
+		FROM day
+		LEFT JOIN counts ON counts.d = day.d
+		ORDER BY day.d`,
+		first, today, chatJID, first,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []DayCount{}
+	for rows.Next() {
+		var dc DayCount
+		if err := rows.Scan(&dc.Date, &dc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, dc)
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMessageStats(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789-123456@g.us", Name: "Test Group", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	msgs := []*Message{
+		{ID: "m1", Sender: "alice", Timestamp: today.Add(9 * time.Hour)},
+		{ID: "m2", Sender: "alice", Timestamp: today.Add(9*time.Hour + 30*time.Minute)},
+		{ID: "m3", Sender: "bob", Timestamp: today.Add(-24*time.Hour + 21*time.Hour)},
+		{ID: "m4", Sender: "alice", Timestamp: today.Add(-48 * time.Hour)},
+		{ID: "m5", Sender: "bob", Timestamp: today.Add(10 * time.Hour)},
+	}
+	for _, msg := range msgs {
+		msg.ChatJID = chat.JID
+		msg.Content = "hello"
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+	// Deleted messages are not counted
+	store.SoftDeleteMessage("m5", chat.JID, time.Now())
+
+	stats, err := store.GetMessageStats(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get message stats: %v", err)
+	}
+	if stats.TotalMessages != 4 {
+		t.Errorf("Expected 4 messages, got %d", stats.TotalMessages)
+	}
+	if len(stats.TopSenders) != 2 || stats.TopSenders[0] != (SenderCount{"alice", 3}) {
+		t.Errorf("Expected alice to lead with 3 messages, got %+v", stats.TopSenders)
+	}
+	if stats.ByHour[9] != 2 || stats.ByHour[21] != 1 || stats.ByHour[0] != 1 || stats.ByHour[10] != 0 {
+		t.Errorf("Unexpected hourly buckets: %v", stats.ByHour)
+	}
+
+	activity, err := store.GetChatActivityByDay(chat.JID, 2)
+	if err != nil {
+		t.Fatalf("Failed to get activity: %v", err)
+	}
+	want := []DayCount{
+		{today.AddDate(0, 0, -1).Format("2006-01-02"), 1},
+		{today.Format("2006-01-02"), 2},
+	}
+	if len(activity) != len(want) || activity[0] != want[0] || activity[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, activity)
+	}
+
+	// Quiet days are reported with zero
+	activity, err = store.GetChatActivityByDay(chat.JID, 7)
+	if err != nil {
+		t.Fatalf("Failed to get activity: %v", err)
+	}
+	if len(activity) != 7 || activity[0].Count != 0 || activity[4].Count != 1 {
+		t.Errorf("Expected 7 days with m4 on the fifth, got %v", activity)
+	}
+}
+
+func TestChatStatistics(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789-123456@g.us", Name: "Test Group", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	msgs := []*Message{
+		{ID: "m1", Sender: "alice", Timestamp: base},
+		{ID: "m2", Sender: "alice", Timestamp: base.Add(30 * time.Second)},
+		{ID: "m3", Sender: "me", IsFromMe: true, Timestamp: base.Add(90 * time.Second)}, // replies after 60s
+		{ID: "m4", Sender: "bob", Timestamp: base.Add(210 * time.Second)},               // replies after 120s
+		{ID: "m5", Sender: "alice", Timestamp: base.Add(300 * time.Second)},             // replies after 90s
+		{ID: "m6", Sender: "bob", Timestamp: base.Add(time.Hour)},
+	}
+	for _, msg := range msgs {
+		msg.ChatJID = chat.JID
+		msg.Content = "hello"
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+	// Deleted messages are not counted
+	store.SoftDeleteMessage("m6", chat.JID, time.Now())
+
+	stats, err := store.GetChatStatistics(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get chat statistics: %v", err)
+	}
+	if stats.TotalMessages != 5 || stats.FromMeCount != 1 || stats.FromOthersCount != 4 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	wantSenders := []SenderCount{{"alice", 3}, {"bob", 1}, {"me", 1}}
+	if len(stats.TopSenders) != len(wantSenders) {
+		t.Fatalf("Expected senders %v, got %v", wantSenders, stats.TopSenders)
+	}
+	for i := range wantSenders {
+		if stats.TopSenders[i] != wantSenders[i] {
+			t.Errorf("Expected senders %v, got %v", wantSenders, stats.TopSenders)
+			break
+		}
+	}
+	if math.Abs(stats.AvgResponseTimeSeconds-90) > 0.01 {
+		t.Errorf("Expected an average response time of 90s, got %v", stats.AvgResponseTimeSeconds)
+	}
+	if !stats.FirstMessageAt.Equal(base) || !stats.LastMessageAt.Equal(base.Add(300*time.Second)) {
+		t.Errorf("Unexpected message range %v - %v", stats.FirstMessageAt, stats.LastMessageAt)
+	}
+
+	empty, err := store.GetChatStatistics("987654321@s.whatsapp.net")
+	if err != nil {
+		t.Fatalf("Failed to get chat statistics: %v", err)
+	}
+	if empty.TotalMessages != 0 || len(empty.TopSenders) != 0 || !empty.FirstMessageAt.IsZero() {
+		t.Errorf("Expected empty statistics, got %+v", empty)
+	}
+}
+
+func BenchmarkGetChatStatistics(b *testing.B) {
+	store, cleanup := setupTestStore(b)
+	defer cleanup()
+
+	const chatJID = "123456789-123456@g.us"
+	store.StoreChat(&Chat{JID: chatJID, Name: "Bench", LastMessageTime: time.Now()})
+	msgs := testMessages(chatJID, 10000)
+	for i, msg := range msgs {
+		msg.Sender = fmt.Sprintf("member%d", i%25)
+		msg.IsFromMe = i%7 == 0
+	}
+	if err := store.BulkStoreMessages(msgs); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.GetChatStatistics(chatJID); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if perOp := b.Elapsed() / time.Duration(b.N); perOp > 100*time.Millisecond {
+		b.Errorf("GetChatStatistics took %v on 10,000 messages, want under 100ms", perOp)
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// StoreStatus inserts or replaces a status update. A zero ExpiresAt is set
+// to StatusTTL after the timestamp.
+func (s *Store) StoreStatus(status *StatusMessage) error {
+	if status.ExpiresAt.IsZero() {
+		status.ExpiresAt = status.Timestamp.Add(StatusTTL)
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO status_messages (id, author_jid, content, media_type, url, media_key, timestamp, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			author_jid = excluded.author_jid, content = excluded.content, media_type = excluded.media_type,
+			url = excluded.url, media_key = excluded.media_key, timestamp = excluded.timestamp,
+			expires_at = excluded.expires_at`,
+		status.ID, status.AuthorJID, status.Content, status.MediaType, status.URL, status.MediaKey,
+		status.Timestamp.UTC(), status.ExpiresAt.UTC(),
+	)
+	return err
+}
+
+// GetStatuses retrieves a page of unexpired status updates, newest first
+func (s *Store) GetStatuses(limit, offset int) ([]*StatusMessage, error) {
+	rows, err := s.db.Query(`
+		SELECT id, author_jid, content, media_type, url, media_key, timestamp, expires_at
+		FROM status_messages
+		WHERE expires_at > ?
+		ORDER BY timestamp DESC
+		LIMIT ? OFFSET ?`,
+		time.Now().UTC(), limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStatuses(rows)
+}
+
+// GetStatusesByAuthor retrieves the unexpired status updates of one author,
+// newest first
+func (s *Store) GetStatusesByAuthor(authorJID string) ([]*StatusMessage, error) {
+	rows, err := s.db.Query(`
+		SELECT id, author_jid, content, media_type, url, media_key, timestamp, expires_at
+		FROM status_messages
+		WHERE author_jid = ? AND expires_at > ?
+		ORDER BY timestamp DESC`,
+		authorJID, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStatuses(rows)
+}
+
+// DeleteExpiredStatuses removes status updates past their expiry and returns
+// how many were removed
+func (s *Store) DeleteExpiredStatuses() (int64, error) {
+	result, err := s.db.Exec("DELETE FROM status_messages WHERE expires_at <= ?", time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteStatusesBefore removes status updates posted before cutoff, whatever
+// their expiry, and returns how many were removed
+func (s *Store) DeleteStatusesBefore(cutoff time.Time) (int64, error) {
+	result, err := s.db.Exec("DELETE FROM status_messages WHERE timestamp < ?", cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// scanStatuses reads status_messages rows
+func scanStatuses(rows *sql.Rows) ([]*StatusMessage, error) {
+	statuses := []*StatusMessage{}
+	for rows.Next() {
+		st := &StatusMessage{}
+		var content, mediaType, url sql.NullString
+		err := rows.Scan(&st.ID, &st.AuthorJID, &content, &mediaType, &url, &st.MediaKey, &st.Timestamp, &st.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		st.Content = content.String
+		st.MediaType = mediaType.String
+		st.URL = url.String
+		statuses = append(statuses, st)
+	}
+
+	return statuses, rows.Err()
+}
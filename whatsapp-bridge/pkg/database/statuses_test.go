@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatuses(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	alice, bob := "111111111@s.whatsapp.net", "222222222@s.whatsapp.net"
+	now := time.Now()
+	statuses := []*StatusMessage{
+		{ID: "s1", AuthorJID: alice, Content: "morning", Timestamp: now.Add(-2 * time.Hour)},
+		{ID: "s2", AuthorJID: bob, MediaType: "image", Timestamp: now.Add(-time.Hour)},
+		{ID: "s3", AuthorJID: alice, Content: "evening", Timestamp: now.Add(-time.Minute)},
+		{ID: "old", AuthorJID: alice, Content: "yesterday", Timestamp: now.Add(-25 * time.Hour)},
+	}
+	for _, st := range statuses {
+		if err := store.StoreStatus(st); err != nil {
+			t.Fatalf("Failed to store status %s: %v", st.ID, err)
+		}
+	}
+	if !statuses[0].ExpiresAt.Equal(statuses[0].Timestamp.Add(StatusTTL)) {
+		t.Errorf("Expected expiry a day after posting, got %v", statuses[0].ExpiresAt)
+	}
+
+	got, err := store.GetStatuses(10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get statuses: %v", err)
+	}
+	if len(got) != 3 || got[0].ID != "s3" || got[2].ID != "s1" {
+		t.Errorf("Expected the 3 unexpired statuses newest first, got %v", got)
+	}
+
+	page, _ := store.GetStatuses(1, 1)
+	if len(page) != 1 || page[0].ID != "s2" {
+		t.Errorf("Expected s2 on the second page, got %v", page)
+	}
+
+	byAlice, err := store.GetStatusesByAuthor(alice)
+	if err != nil {
+		t.Fatalf("Failed to get statuses by author: %v", err)
+	}
+	if len(byAlice) != 2 || byAlice[0].ID != "s3" || byAlice[1].ID != "s1" {
+		t.Errorf("Expected alice's unexpired statuses, got %v", byAlice)
+	}
+
+	deleted, err := store.DeleteExpiredStatuses()
+	if err != nil {
+		t.Fatalf("Failed to delete expired statuses: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 expired status deleted, got %d", deleted)
+	}
+	var count int
+	store.db.QueryRow("SELECT COUNT(*) FROM status_messages").Scan(&count)
+	if count != 3 {
+		t.Errorf("Expected 3 statuses left, got %d", count)
+	}
+}
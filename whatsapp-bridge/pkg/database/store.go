@@ -1,40 +1,82 @@
 package database
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
+
+	"whatsapp-client/pkg/metrics"
 )
 
+// DefaultBulkBatchSize is the number of messages BulkStoreMessages commits per transaction
+const DefaultBulkBatchSize = 500
+
+// driverName is the go-sqlite3 driver with the bridge's SQL functions
+// registered on every connection
+const driverName = "sqlite3_whatsapp"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("haversine_km", haversineKm, true)
+		},
+	})
+}
+
 // Store handles database operations
 type Store struct {
-	db *sql.DB
+	db            *queryDB
+	fts5          bool // messages_fts and contacts_fts are available for ranked full-text search
+	bulkBatchSize int
+	maintenanceMu sync.Mutex // held by Backup and Vacuum
+	metrics       *metrics.Metrics
+
+	walCheckpointInterval time.Duration
 }
 
-// NewStore creates a new database store
-func NewStore(dbPath, storeDir string) (*Store, error) {
+// NewStore creates a new database store, configured by opts
+func NewStore(dbPath, storeDir string, opts ...StoreOption) (*Store, error) {
+	cfg := defaultStoreConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(storeDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create store directory: %w", err)
 	}
 
 	// Open database with proper configuration
-	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL", dbPath))
+	db, err := sql.Open(driverName, fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000", dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
+	db.SetMaxOpenConns(cfg.maxOpenConns)
+	db.SetMaxIdleConns(cfg.maxIdleConns)
+	db.SetConnMaxLifetime(cfg.connMaxLifetime)
+
+	if err := Migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
 
-	store := &Store{db: db}
-	if err := store.initTables(); err != nil {
+	store := &Store{db: &queryDB{DB: db}, bulkBatchSize: DefaultBulkBatchSize, walCheckpointInterval: cfg.walCheckpointInterval}
+	if err := store.initSearchIndex(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+		return nil, fmt.Errorf("failed to initialize search index: %w", err)
 	}
+	// Setting up the schema may take longer than any one query
+	store.db.timeout = cfg.queryTimeout
 
 	return store, nil
 }
@@ -44,76 +86,385 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
-// initTables creates the required database tables and indexes
-func (s *Store) initTables() error {
-	schema := `
-		CREATE TABLE IF NOT EXISTS chats (
-			jid TEXT PRIMARY KEY,
-			name TEXT,
-			last_message_time TIMESTAMP
-		);
-		
-		CREATE TABLE IF NOT EXISTS messages (
-			id TEXT,
-			chat_jid TEXT,
-			sender TEXT,
-			content TEXT,
-			timestamp TIMESTAMP,
-			is_from_me BOOLEAN,
-			media_type TEXT,
-			filename TEXT,
-			url TEXT,
-			media_key BLOB,
-			file_sha256 BLOB,
-			file_enc_sha256 BLOB,
-			file_length INTEGER,
-			PRIMARY KEY (id, chat_jid),
-			FOREIGN KEY (chat_jid) REFERENCES chats(jid)
-		);
-
-		-- Performance indexes
-		CREATE INDEX IF NOT EXISTS idx_messages_chat_jid ON messages(chat_jid);
-		CREATE INDEX IF NOT EXISTS idx_messages_timestamp ON messages(timestamp);
-		CREATE INDEX IF NOT EXISTS idx_messages_sender ON messages(sender);
-		CREATE INDEX IF NOT EXISTS idx_chats_last_message_time ON chats(last_message_time);
-	`
-	
-	_, err := s.db.Exec(schema)
-	return err
+// SetMetrics makes the store count the messages and chats it writes
+func (s *Store) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// DBStats reports the state of the database connection pool
+func (s *Store) DBStats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// Ping checks that the database still answers queries
+func (s *Store) Ping() error {
+	var one int
+	return s.db.QueryRow("SELECT 1").Scan(&one)
+}
+
+// SetBulkBatchSize sets how many messages BulkStoreMessages commits per transaction
+func (s *Store) SetBulkBatchSize(n int) {
+	if n > 0 {
+		s.bulkBatchSize = n
+	}
+}
+
+// initSearchIndex creates the FTS5 indexes over message content and contact
+// names and the triggers keeping them in sync. FTS5 is only compiled into
+// go-sqlite3 with the sqlite_fts5 build tag; without it SearchMessages and
+// SearchContacts fall back to LIKE matching. It runs outside Migrate because
+// whether the indexes can exist depends on how the binary was built, not on
+// the schema version.
+func (s *Store) initSearchIndex() error {
+	if err := s.db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&s.fts5); err != nil {
+		return fmt.Errorf("failed to check FTS5 support: %w", err)
+	}
+	if !s.fts5 {
+		return nil
+	}
+
+	// The message index keeps its own copy of the content keyed by the
+	// messages rowid. INSERT OR REPLACE does not fire delete triggers, so the
+	// stale entry of a replaced row is removed before the insert instead.
+	if err := s.createSearchIndex("messages_fts", `
+		CREATE VIRTUAL TABLE messages_fts USING fts5(content);
+
+		INSERT INTO messages_fts (rowid, content) SELECT rowid, content FROM messages;
+
+		CREATE TRIGGER messages_fts_before_insert BEFORE INSERT ON messages BEGIN
+			DELETE FROM messages_fts
+			WHERE rowid = (SELECT rowid FROM messages WHERE id = new.id AND chat_jid = new.chat_jid);
+		END;
+
+		CREATE TRIGGER messages_fts_after_insert AFTER INSERT ON messages BEGIN
+			INSERT INTO messages_fts (rowid, content) VALUES (new.rowid, new.content);
+		END;
+
+		CREATE TRIGGER messages_fts_after_update AFTER UPDATE OF content ON messages BEGIN
+			DELETE FROM messages_fts WHERE rowid = old.rowid;
+			INSERT INTO messages_fts (rowid, content) VALUES (new.rowid, new.content);
+		END;
+
+		CREATE TRIGGER messages_fts_after_delete AFTER DELETE ON messages BEGIN
+			DELETE FROM messages_fts WHERE rowid = old.rowid;
+		END;
+	`); err != nil {
+		return err
+	}
+
+	// Contacts are upserted, so an update trigger covers re-synced contacts
+	return s.createSearchIndex("contacts_fts", `
+		CREATE VIRTUAL TABLE contacts_fts USING fts5(display_name, push_name, phone);
+
+		INSERT INTO contacts_fts (rowid, display_name, push_name, phone)
+		SELECT rowid, display_name, push_name, phone FROM contacts;
+
+		CREATE TRIGGER contacts_fts_after_insert AFTER INSERT ON contacts BEGIN
+			INSERT INTO contacts_fts (rowid, display_name, push_name, phone)
+			VALUES (new.rowid, new.display_name, new.push_name, new.phone);
+		END;
+
+		CREATE TRIGGER contacts_fts_after_update AFTER UPDATE ON contacts BEGIN
+			DELETE FROM contacts_fts WHERE rowid = old.rowid;
+			INSERT INTO contacts_fts (rowid, display_name, push_name, phone)
+			VALUES (new.rowid, new.display_name, new.push_name, new.phone);
+		END;
+
+		CREATE TRIGGER contacts_fts_after_delete AFTER DELETE ON contacts BEGIN
+			DELETE FROM contacts_fts WHERE rowid = old.rowid;
+		END;
+	`)
+}
+
+// createSearchIndex runs schema in a transaction unless the table name
+// already exists
+func (s *Store) createSearchIndex(name, schema string) error {
+	var exists int
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name,
+	).Scan(&exists)
+	if err != nil || exists > 0 {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(schema); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create search index %s: %w", name, err)
+	}
+	return tx.Commit()
 }
 
-// StoreChat inserts or updates a chat record
+// WithTransaction runs fn in a transaction, committing if it returns nil and
+// rolling back otherwise, so several writes either all land or none do
+func (s *Store) WithTransaction(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rolling back after a successful commit is a no-op
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// StoreChat inserts or updates a chat record. The unread counter and read
+// marker are maintained by StoreMessage and MarkChatRead and are left as is.
 func (s *Store) StoreChat(chat *Chat) error {
-	_, err := s.db.Exec(
-		"INSERT OR REPLACE INTO chats (jid, name, last_message_time) VALUES (?, ?, ?)",
-		chat.JID, chat.Name, chat.LastMessageTime,
+	err := s.WithTransaction(func(tx *sql.Tx) error {
+		return s.StoreChatTx(tx, chat)
+	})
+	if err == nil {
+		s.metrics.ChatStored()
+	}
+	return err
+}
+
+// StoreChatTx is StoreChat within the caller's transaction
+func (s *Store) StoreChatTx(tx *sql.Tx, chat *Chat) error {
+	_, err := tx.Exec(`
+		INSERT INTO chats (jid, name, last_message_time) VALUES (?, ?, ?)
+		ON CONFLICT (jid) DO UPDATE SET name = excluded.name, last_message_time = excluded.last_message_time`,
+		chat.JID, chat.Name, chat.LastMessageTime.UTC(),
 	)
 	return err
 }
 
-// StoreMessage inserts or updates a message record
+// insertMessageQuery inserts or updates a message; arguments come from
+// messageArgs. Deletion and edit state is left untouched, and edited content
+// kept, so re-syncing a retracted or edited message does not bring back what
+// it replaced. The stored status only moves forward, from pending or failed
+// through sent and delivered to read, so re-syncing a message that has since
+// been read does not mark it sent again.
+const insertMessageQuery = `
+	INSERT INTO messages 
+	(id, chat_jid, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, status,
+	quoted_message_id, quoted_message_content, media_mime_type, media_width, media_height, media_duration_seconds, thumbnail,
+	location_lat, location_lon, location_address, location_name, location_is_live, location_live_expiry,
+	vcard_name, vcard_phone) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(NULLIF(?, ''), 'sent'), NULLIF(?, ''), NULLIF(?, ''),
+		NULLIF(?, ''), NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, 0), ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?,
+		NULLIF(?, ''), NULLIF(?, ''))
+	ON CONFLICT (id, chat_jid) DO UPDATE SET
+		sender = excluded.sender, timestamp = excluded.timestamp,
+		content = CASE WHEN messages.is_edited THEN messages.content ELSE excluded.content END,
+		is_from_me = excluded.is_from_me, media_type = excluded.media_type, filename = excluded.filename,
+		url = excluded.url, media_key = excluded.media_key, file_sha256 = excluded.file_sha256,
+		file_enc_sha256 = excluded.file_enc_sha256, file_length = excluded.file_length,
+		status = CASE WHEN (CASE ? WHEN 'pending' THEN 0 WHEN 'failed' THEN 1 WHEN 'sent' THEN 2 WHEN 'delivered' THEN 3 WHEN 'read' THEN 4 ELSE -1 END)
+			> (CASE messages.status WHEN 'pending' THEN 0 WHEN 'failed' THEN 1 WHEN 'sent' THEN 2 WHEN 'delivered' THEN 3 WHEN 'read' THEN 4 ELSE -1 END)
+			THEN excluded.status ELSE messages.status END,
+		quoted_message_id = excluded.quoted_message_id, quoted_message_content = excluded.quoted_message_content,
+		media_mime_type = excluded.media_mime_type, media_width = excluded.media_width, media_height = excluded.media_height,
+		media_duration_seconds = excluded.media_duration_seconds, thumbnail = excluded.thumbnail,
+		location_lat = excluded.location_lat, location_lon = excluded.location_lon,
+		location_address = excluded.location_address, location_name = excluded.location_name,
+		location_is_live = excluded.location_is_live, location_live_expiry = excluded.location_live_expiry,
+		vcard_name = excluded.vcard_name, vcard_phone = excluded.vcard_phone`
+
+// messageArgs returns the insertMessageQuery arguments for a message. The
+// status is passed again for the update, where an unset status keeps the
+// stored one rather than defaulting to sent. Timestamps are stored in UTC
+// so that their text form sorts chronologically, which cursor pagination
+// relies on.
+func messageArgs(msg *Message) []interface{} {
+	return []interface{}{
+		msg.ID, msg.ChatJID, msg.Sender, msg.Content, msg.Timestamp.UTC(), msg.IsFromMe,
+		msg.MediaType, msg.Filename, msg.URL, msg.MediaKey, msg.FileSHA256, msg.FileEncSHA256, msg.FileLength,
+		msg.Status, msg.QuotedMessageID, msg.QuotedMessageContent,
+		msg.MediaMimeType, msg.MediaWidth, msg.MediaHeight, msg.MediaDurationSeconds, msg.Thumbnail,
+		msg.LocationLat, msg.LocationLon, msg.LocationAddress, msg.LocationName,
+		msg.LocationIsLive, utcTime(msg.LocationLiveExpiry),
+		msg.VCardName, msg.VCardPhone,
+		msg.Status,
+	}
+}
+
+// utcTime converts an optional time to UTC for storage
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// StoreMessage inserts or updates a message record, counting new incoming
+// messages as unread in their chat
 func (s *Store) StoreMessage(msg *Message) error {
-	// Only store if there's actual content or media
-	if msg.Content == "" && msg.MediaType == "" {
+	err := s.WithTransaction(func(tx *sql.Tx) error {
+		return s.StoreMessageTx(tx, msg)
+	})
+	// Same rule as StoreMessageTx for what is stored
+	if err == nil && (msg.Content != "" || msg.MediaType != "" || msg.IsLocation()) {
+		s.metrics.MessagesStored(1)
+	}
+	return err
+}
+
+// StoreMessageTx is StoreMessage within the caller's transaction
+func (s *Store) StoreMessageTx(tx *sql.Tx, msg *Message) error {
+	// Only store if there's actual content, media or a location
+	if msg.Content == "" && msg.MediaType == "" && !msg.IsLocation() {
 		return nil
 	}
+	applyVCard(msg)
 
-	_, err := s.db.Exec(`
-		INSERT OR REPLACE INTO messages 
-		(id, chat_jid, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		msg.ID, msg.ChatJID, msg.Sender, msg.Content, msg.Timestamp, msg.IsFromMe,
-		msg.MediaType, msg.Filename, msg.URL, msg.MediaKey, msg.FileSHA256, msg.FileEncSHA256, msg.FileLength,
-	)
+	var exists int
+	err := tx.QueryRow(
+		"SELECT COUNT(*) FROM messages WHERE id = ? AND chat_jid = ?",
+		msg.ID, msg.ChatJID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(insertMessageQuery, messageArgs(msg)...); err != nil {
+		return err
+	}
+	if err := storeMentions(tx, msg); err != nil {
+		return fmt.Errorf("failed to store mentions: %w", err)
+	}
+
+	// Only a newly received message adds to the unread count
+	if exists == 0 && !msg.IsFromMe {
+		_, err := tx.Exec("UPDATE chats SET unread_count = unread_count + 1 WHERE jid = ?", msg.ChatJID)
+		if err != nil {
+			return fmt.Errorf("failed to update unread count: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// BulkStoreMessages inserts or updates many messages using a prepared statement,
+// committing one transaction per batch. A failing batch is rolled back and
+// reported in the returned error; the remaining batches are still stored.
+func (s *Store) BulkStoreMessages(msgs []*Message) error {
+	var errs []error
+	for start := 0; start < len(msgs); start += s.bulkBatchSize {
+		end := min(start+s.bulkBatchSize, len(msgs))
+		if err := s.storeMessageBatch(msgs[start:end]); err != nil {
+			errs = append(errs, fmt.Errorf("batch %d (messages %d-%d): %w",
+				start/s.bulkBatchSize+1, start, end-1, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// storeMessageBatch stores a batch of messages in a single transaction
+func (s *Store) storeMessageBatch(msgs []*Message) error {
+	stored := 0
+	err := s.WithTransaction(func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(insertMessageQuery)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, msg := range msgs {
+			// Same rule as StoreMessage
+			if msg.Content == "" && msg.MediaType == "" && !msg.IsLocation() {
+				continue
+			}
+			applyVCard(msg)
+			if _, err := stmt.Exec(messageArgs(msg)...); err != nil {
+				return fmt.Errorf("message %s: %w", msg.ID, err)
+			}
+			if err := storeMentions(tx, msg); err != nil {
+				return fmt.Errorf("message %s mentions: %w", msg.ID, err)
+			}
+			stored++
+		}
+
+		return nil
+	})
+	if err == nil {
+		s.metrics.MessagesStored(stored)
+	}
 	return err
 }
 
-// GetMessages retrieves messages for a chat with pagination
+// messageColumnNames lists the messages columns in the order scanMessages expects
+var messageColumnNames = []string{
+	"id", "chat_jid", "sender", "content", "timestamp", "is_from_me", "media_type", "filename",
+	"url", "media_key", "file_sha256", "file_enc_sha256", "file_length", "status",
+	"is_deleted", "deleted_at", "quoted_message_id", "quoted_message_content",
+	"media_mime_type", "media_width", "media_height", "media_duration_seconds", "thumbnail",
+	"location_lat", "location_lon", "location_address", "location_name",
+	"location_is_live", "location_live_expiry",
+	"vcard_name", "vcard_phone", "is_edited", "edited_at",
+}
+
+var (
+	// messageColumns selects messageColumnNames from the messages table
+	messageColumns = strings.Join(messageColumnNames, ", ")
+	// qualifiedMessageColumns selects messageColumnNames from messages aliased as m
+	qualifiedMessageColumns = "m." + strings.Join(messageColumnNames, ", m.")
+)
+
+// GetMessagesPage retrieves a page of messages for a chat, newest first,
+// starting after the given cursor
+func (s *Store) GetMessagesPage(chatJID string, limit int, cursor Cursor, opts MessageOptions) (*PageResult[*Message], error) {
+	query := `SELECT ` + messageColumns + ` FROM messages WHERE chat_jid = ?`
+	args := []interface{}{chatJID}
+	if !opts.IncludeDeleted {
+		query += ` AND is_deleted = 0`
+	}
+	if !cursor.IsZero() {
+		query += ` AND (timestamp, id) < (?, ?)`
+		args = append(args, cursor.AfterTimestamp.UTC(), cursor.AfterID)
+	}
+	query += ` ORDER BY timestamp DESC, id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit+1, opts.Offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPageResult(messages, limit, func(msg *Message) Cursor {
+		return Cursor{AfterTimestamp: msg.Timestamp, AfterID: msg.ID}
+	}), nil
+}
+
+// CountMessages returns the number of messages in a chat that
+// GetMessagesPage would page through with the same options
+func (s *Store) CountMessages(chatJID string, opts MessageOptions) (int, error) {
+	query := `SELECT COUNT(*) FROM messages WHERE chat_jid = ?`
+	if !opts.IncludeDeleted {
+		query += ` AND is_deleted = 0`
+	}
+
+	var count int
+	err := s.db.QueryRow(query, chatJID).Scan(&count)
+	return count, err
+}
+
+// GetMessages retrieves messages for a chat with offset pagination.
+//
+// Soft-deleted messages are excluded.
+//
+// Deprecated: Use GetMessagesPage, which does not slow down on deep pages.
 func (s *Store) GetMessages(chatJID string, limit, offset int) ([]*Message, error) {
 	rows, err := s.db.Query(`
-		SELECT id, chat_jid, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length
+		SELECT `+messageColumns+`
 		FROM messages 
-		WHERE chat_jid = ? 
+		WHERE chat_jid = ? AND is_deleted = 0
 		ORDER BY timestamp DESC 
 		LIMIT ? OFFSET ?`,
 		chatJID, limit, offset,
@@ -123,28 +474,439 @@ func (s *Store) GetMessages(chatJID string, limit, offset int) ([]*Message, erro
 	}
 	defer rows.Close()
 
+	return scanMessages(rows)
+}
+
+// UpdateMessageStatus sets the delivery status of a message. It returns
+// sql.ErrNoRows if the message does not exist.
+func (s *Store) UpdateMessageStatus(id, chatJID string, status MessageStatus) error {
+	if _, err := ParseMessageStatus(string(status)); err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(
+		"UPDATE messages SET status = ? WHERE id = ? AND chat_jid = ?",
+		status, id, chatJID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// maxThreadDepth caps how many quoted messages GetMessageThread follows, so
+// cyclic quote data cannot recurse forever
+const maxThreadDepth = 5
+
+// GetMessageThread retrieves a message together with the chain of messages it
+// quotes, up to maxThreadDepth levels, oldest first. Quoted messages that are
+// not in the store end the chain. It returns sql.ErrNoRows if the message
+// does not exist.
+func (s *Store) GetMessageThread(id, chatJID string) ([]*Message, error) {
+	rows, err := s.db.Query(`
+		WITH RECURSIVE thread(rid, depth) AS (
+			SELECT rowid, 0 FROM messages WHERE id = ? AND chat_jid = ?
+			UNION
+			SELECT q.rowid, t.depth + 1
+			FROM thread t
+			JOIN messages m ON m.rowid = t.rid
+			JOIN messages q ON q.id = m.quoted_message_id AND q.chat_jid = m.chat_jid
+			WHERE t.depth < ?
+		)
+		SELECT `+qualifiedMessageColumns+`
+		FROM (SELECT rid, MIN(depth) AS depth FROM thread GROUP BY rid) t
+		JOIN messages m ON m.rowid = t.rid
+		ORDER BY t.depth DESC`,
+		id, chatJID, maxThreadDepth,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return messages, nil
+}
+
+// SoftDeleteMessage marks a message as deleted without removing it, keeping
+// the time of the first deletion. It returns sql.ErrNoRows if the message
+// does not exist.
+func (s *Store) SoftDeleteMessage(id, chatJID string, deletedAt time.Time) error {
+	result, err := s.db.Exec(
+		"UPDATE messages SET is_deleted = 1, deleted_at = COALESCE(deleted_at, ?) WHERE id = ? AND chat_jid = ?",
+		deletedAt.UTC(), id, chatJID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// PurgeDeletedMessages permanently removes messages soft-deleted before
+// cutoff, together with their reactions, pins, mentions and edit history,
+// and returns how many were removed
+func (s *Store) PurgeDeletedMessages(cutoff time.Time) (int64, error) {
+	result, err := s.db.Exec(
+		"DELETE FROM messages WHERE is_deleted = 1 AND deleted_at < ?",
+		cutoff.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// maxDeleteBatch caps the IDs bound per statement in BulkDeleteMessages,
+// keeping well below SQLite's limit on host parameters
+const maxDeleteBatch = 500
+
+// DeleteMessage permanently removes a message together with its reactions,
+// pins and mentions. It returns sql.ErrNoRows if the message does not exist.
+func (s *Store) DeleteMessage(id, chatJID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM messages WHERE id = ? AND chat_jid = ?", id, chatJID)
+	if err != nil {
+		return err
+	}
+	if err := requireRowsAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// BulkDeleteMessages permanently removes the given messages of a chat in a
+// single transaction. IDs that do not exist are ignored.
+func (s *Store) BulkDeleteMessages(ids []string, chatJID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Full batches share one prepared statement
+	var fullBatch *sql.Stmt
+	if len(ids) >= maxDeleteBatch {
+		fullBatch, err = tx.Prepare(deleteMessagesQuery(maxDeleteBatch))
+		if err != nil {
+			return err
+		}
+		defer fullBatch.Close()
+	}
+
+	for start := 0; start < len(ids); start += maxDeleteBatch {
+		batch := ids[start:min(start+maxDeleteBatch, len(ids))]
+		args := make([]interface{}, 0, len(batch)+1)
+		args = append(args, chatJID)
+		for _, id := range batch {
+			args = append(args, id)
+		}
+
+		if len(batch) == maxDeleteBatch {
+			_, err = fullBatch.Exec(args...)
+		} else {
+			_, err = tx.Exec(deleteMessagesQuery(len(batch)), args...)
+		}
+		if err != nil {
+			return fmt.Errorf("batch %d: %w", start/maxDeleteBatch+1, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// deleteMessagesQuery returns a statement deleting n messages of one chat by
+// ID; the chat JID is the first argument
+func deleteMessagesQuery(n int) string {
+	return "DELETE FROM messages WHERE chat_jid = ? AND id IN (?" + strings.Repeat(", ?", n-1) + ")"
+}
+
+// DeleteChatMessages permanently removes every message of a chat and resets
+// its unread state. The chat itself is kept.
+func (s *Store) DeleteChatMessages(chatJID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteChatMessages(tx, chatJID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteChat permanently removes a chat and all of its messages. It returns
+// sql.ErrNoRows if the chat does not exist.
+func (s *Store) DeleteChat(jid string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Messages reference the chat, so they go first
+	if err := deleteChatMessages(tx, jid); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("DELETE FROM chats WHERE jid = ?", jid)
+	if err != nil {
+		return err
+	}
+	if err := requireRowsAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deleteChatMessages removes every message of a chat within tx
+func deleteChatMessages(tx *sql.Tx, chatJID string) error {
+	if _, err := tx.Exec("DELETE FROM messages WHERE chat_jid = ?", chatJID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM poll_messages WHERE chat_jid = ?", chatJID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(
+		"UPDATE chats SET unread_count = 0, last_read_message_id = NULL WHERE jid = ?",
+		chatJID,
+	)
+	return err
+}
+
+// requireRowsAffected returns sql.ErrNoRows if a statement changed no rows
+func requireRowsAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// newID returns a random 16-character hex identifier
+func newID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// isForeignKeyViolation reports whether err is a failed foreign key constraint
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
+
+// isUniqueViolation reports whether err is a failed UNIQUE or PRIMARY KEY constraint
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
+
+// GetMessage retrieves a single message by ID
+func (s *Store) GetMessage(id, chatJID string) (*Message, error) {
+	rows, err := s.db.Query(`
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE id = ? AND chat_jid = ?`,
+		id, chatJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return messages[0], nil
+}
+
+// SearchMessages finds messages whose content matches every term of a
+// plain-text query, optionally scoped to a single chat when chatJID is set.
+// Results are ordered by bm25 relevance when FTS5 is available and by
+// recency otherwise. Soft-deleted messages are excluded.
+func (s *Store) SearchMessages(query, chatJID string, limit, offset int) ([]*Message, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	var rows *sql.Rows
+	var err error
+	if s.fts5 {
+		rows, err = s.db.Query(`
+			SELECT `+qualifiedMessageColumns+`
+			FROM messages_fts
+			JOIN messages m ON m.rowid = messages_fts.rowid
+			WHERE messages_fts MATCH ? AND (? = '' OR m.chat_jid = ?) AND m.is_deleted = 0
+			ORDER BY bm25(messages_fts)
+			LIMIT ? OFFSET ?`,
+			ftsQuery(terms), chatJID, chatJID, limit, offset,
+		)
+	} else {
+		where := strings.Repeat(" AND content LIKE ? ESCAPE '\\'", len(terms))
+		args := make([]interface{}, 0, len(terms)+4)
+		args = append(args, chatJID, chatJID)
+		for _, term := range terms {
+			args = append(args, "%"+likeEscaper.Replace(term)+"%")
+		}
+		args = append(args, limit, offset)
+
+		rows, err = s.db.Query(`
+			SELECT `+messageColumns+`
+			FROM messages
+			WHERE (? = '' OR chat_jid = ?) AND is_deleted = 0`+where+`
+			ORDER BY timestamp DESC
+			LIMIT ? OFFSET ?`,
+			args...,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+// likeEscaper escapes LIKE wildcards so search terms match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ftsQuery quotes each term as an FTS5 string so user input cannot inject
+// query syntax; adjacent strings are implicitly ANDed
+func ftsQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ftsPrefixQuery is like ftsQuery but each term also matches words it is a
+// prefix of
+func ftsPrefixQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// scanMessages reads rows selected with messageColumns
+func scanMessages(rows *sql.Rows) ([]*Message, error) {
 	var messages []*Message
 	for rows.Next() {
 		msg := &Message{}
+		var quotedID, quotedContent, mimeType, address, name, vcardName, vcardPhone sql.NullString
+		var width, height, duration sql.NullInt64
+		var lat, lon sql.NullFloat64
 		err := rows.Scan(
 			&msg.ID, &msg.ChatJID, &msg.Sender, &msg.Content, &msg.Timestamp,
 			&msg.IsFromMe, &msg.MediaType, &msg.Filename, &msg.URL,
-			&msg.MediaKey, &msg.FileSHA256, &msg.FileEncSHA256, &msg.FileLength,
+			&msg.MediaKey, &msg.FileSHA256, &msg.FileEncSHA256, &msg.FileLength, &msg.Status,
+			&msg.IsDeleted, &msg.DeletedAt, &quotedID, &quotedContent,
+			&mimeType, &width, &height, &duration, &msg.Thumbnail,
+			&lat, &lon, &address, &name, &msg.LocationIsLive, &msg.LocationLiveExpiry,
+			&vcardName, &vcardPhone,
+			&msg.IsEdited, &msg.EditedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		msg.QuotedMessageID = quotedID.String
+		msg.QuotedMessageContent = quotedContent.String
+		msg.MediaMimeType = mimeType.String
+		msg.MediaWidth = int(width.Int64)
+		msg.MediaHeight = int(height.Int64)
+		msg.MediaDurationSeconds = int(duration.Int64)
+		if lat.Valid && lon.Valid {
+			msg.LocationLat, msg.LocationLon = &lat.Float64, &lon.Float64
+		}
+		msg.LocationAddress = address.String
+		msg.LocationName = name.String
+		msg.VCardName = vcardName.String
+		msg.VCardPhone = vcardPhone.String
 		messages = append(messages, msg)
 	}
 
 	return messages, rows.Err()
 }
 
-// GetChats retrieves all chats with pagination
+// GetChatsPage retrieves a page of chats ordered by most recent activity,
+// starting after the given cursor
+func (s *Store) GetChatsPage(limit int, cursor Cursor, opts ChatOptions) (*PageResult[*Chat], error) {
+	conds, args := chatConditions(opts)
+	if !cursor.IsZero() {
+		conds = append(conds, `(c.last_message_time, c.jid) < (?, ?)`)
+		args = append(args, cursor.AfterTimestamp.UTC(), cursor.AfterID)
+	}
+	query := chatSelect(opts) + whereClause(conds) + ` ORDER BY c.last_message_time DESC, c.jid DESC LIMIT ? OFFSET ?`
+	args = append(args, limit+1, opts.Offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chats, err := scanChats(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPageResult(chats, limit, func(chat *Chat) Cursor {
+		return Cursor{AfterTimestamp: chat.LastMessageTime, AfterID: chat.JID}
+	}), nil
+}
+
+// CountChats returns the number of chats that GetChatsPage would page
+// through with the same options
+func (s *Store) CountChats(opts ChatOptions) (int, error) {
+	conds, args := chatConditions(opts)
+
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM chats c`+whereClause(conds), args...).Scan(&count)
+	return count, err
+}
+
+// GetChats retrieves unarchived chats with offset pagination.
+//
+// Deprecated: Use GetChatsPage, which does not slow down on deep pages.
 func (s *Store) GetChats(limit, offset int) ([]*Chat, error) {
 	rows, err := s.db.Query(`
-		SELECT jid, name, last_message_time 
-		FROM chats 
+		SELECT `+chatColumns+`
+		FROM chats c
+		WHERE archived_at IS NULL
 		ORDER BY last_message_time DESC 
 		LIMIT ? OFFSET ?`,
 		limit, offset,
@@ -154,10 +916,136 @@ func (s *Store) GetChats(limit, offset int) ([]*Chat, error) {
 	}
 	defer rows.Close()
 
+	return scanChats(rows)
+}
+
+// GetLastMessagePerChat retrieves the most recent message of each of the
+// given chats in a single query, keyed by chat JID. Chats without messages
+// are absent from the map. An empty jids covers all chats. Soft-deleted
+// messages are skipped.
+func (s *Store) GetLastMessagePerChat(jids []string) (map[string]*Message, error) {
+	filter := ""
+	args := []interface{}{}
+	if len(jids) > 0 {
+		filter = " AND chat_jid IN (?" + strings.Repeat(", ?", len(jids)-1) + ")"
+		for _, jid := range jids {
+			args = append(args, jid)
+		}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+messageColumns+`
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY chat_jid ORDER BY timestamp DESC, id DESC) AS rn
+			FROM messages
+			WHERE is_deleted = 0`+filter+`
+		)
+		WHERE rn = 1`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	last := make(map[string]*Message, len(messages))
+	for _, msg := range messages {
+		last[msg.ChatJID] = msg
+	}
+
+	return last, nil
+}
+
+// GetChatsWithLastMessage retrieves unarchived chats with offset pagination,
+// each with its most recent message. It runs two queries however many
+// chats are on the page.
+func (s *Store) GetChatsWithLastMessage(limit, offset int) ([]*ChatWithLastMessage, error) {
+	chats, err := s.GetChats(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.WithLastMessages(chats)
+}
+
+// WithLastMessages pairs already loaded chats with their most recent
+// message, looked up in a single query
+func (s *Store) WithLastMessages(chats []*Chat) ([]*ChatWithLastMessage, error) {
+	result := make([]*ChatWithLastMessage, len(chats))
+	if len(chats) == 0 {
+		return result, nil
+	}
+
+	jids := make([]string, len(chats))
+	for i, chat := range chats {
+		jids[i] = chat.JID
+	}
+	last, err := s.GetLastMessagePerChat(jids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, chat := range chats {
+		result[i] = &ChatWithLastMessage{Chat: chat, LastMessage: last[chat.JID]}
+	}
+
+	return result, nil
+}
+
+// chatConditions returns the WHERE conditions and arguments filtering chats
+// aliased as c by opts
+func chatConditions(opts ChatOptions) ([]string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if !opts.IncludeArchived {
+		conds = append(conds, `c.archived_at IS NULL`)
+	}
+	if opts.LabelID != "" {
+		conds = append(conds, `EXISTS (SELECT 1 FROM chat_labels cl WHERE cl.chat_jid = c.jid AND cl.label_id = ?)`)
+		args = append(args, opts.LabelID)
+	}
+	return conds, args
+}
+
+// whereClause joins conditions into a WHERE clause, or returns "" if there are none
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// chatColumns lists the columns of chats aliased as c in the order scanChats expects
+const chatColumns = `c.jid, c.name, c.last_message_time, c.unread_count, c.last_read_at,
+	c.archived_at, c.mute_until, COALESCE(c.last_read_message_id, '')`
+
+// chatSelect returns the SELECT ... FROM clause reading chatColumns from chats
+// aliased as c, taking the name from contacts when opts asks for it
+func chatSelect(opts ChatOptions) string {
+	if !opts.ResolveContactNames {
+		return "SELECT " + chatColumns + " FROM chats c"
+	}
+	return `SELECT c.jid, COALESCE(NULLIF(ct.display_name, ''), NULLIF(ct.push_name, ''), c.name),
+			c.last_message_time, c.unread_count, c.last_read_at,
+			c.archived_at, c.mute_until, COALESCE(c.last_read_message_id, '')
+		FROM chats c
+		LEFT JOIN contacts ct ON ct.jid = c.jid`
+}
+
+// scanChats reads rows selected with chatColumns
+func scanChats(rows *sql.Rows) ([]*Chat, error) {
 	var chats []*Chat
 	for rows.Next() {
 		chat := &Chat{}
-		err := rows.Scan(&chat.JID, &chat.Name, &chat.LastMessageTime)
+		err := rows.Scan(
+			&chat.JID, &chat.Name, &chat.LastMessageTime, &chat.UnreadCount, &chat.LastReadAt,
+			&chat.ArchivedAt, &chat.MuteUntil, &chat.LastReadMessageID,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -165,4 +1053,218 @@ func (s *Store) GetChats(limit, offset int) ([]*Chat, error) {
 	}
 
 	return chats, rows.Err()
-}
\ No newline at end of file
+}
+
+// GetChat retrieves a single chat by JID
+func (s *Store) GetChat(jid string) (*Chat, error) {
+	rows, err := s.db.Query("SELECT "+chatColumns+" FROM chats c WHERE c.jid = ?", jid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chats, err := scanChats(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(chats) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return chats[0], nil
+}
+
+// MarkChatRead resets the unread counter of a chat and records when it was
+// read. The newest message becomes the read position. It returns
+// sql.ErrNoRows if the chat does not exist.
+func (s *Store) MarkChatRead(jid string) error {
+	var latestID string
+	err := s.db.QueryRow(
+		"SELECT id FROM messages WHERE chat_jid = ? ORDER BY timestamp DESC, id DESC LIMIT 1",
+		jid,
+	).Scan(&latestID)
+	if err == nil {
+		return s.SetReadPosition(jid, latestID, time.Now())
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	// Nothing to point the read position at
+	result, err := s.db.Exec(
+		"UPDATE chats SET unread_count = 0, last_read_at = ? WHERE jid = ?",
+		time.Now().UTC(), jid,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// SetReadPosition records messageID as the last message read in a chat and
+// recounts the unread messages received after it. It returns sql.ErrNoRows
+// if the chat or message does not exist.
+func (s *Store) SetReadPosition(chatJID, messageID string, at time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var readTimestamp time.Time
+	err = tx.QueryRow(
+		"SELECT timestamp FROM messages WHERE id = ? AND chat_jid = ?",
+		messageID, chatJID,
+	).Scan(&readTimestamp)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE chats SET
+			last_read_message_id = ?,
+			last_read_at = ?,
+			unread_count = (
+				SELECT COUNT(*) FROM messages
+				WHERE chat_jid = ? AND is_from_me = 0 AND is_deleted = 0 AND (timestamp, id) > (?, ?)
+			)
+		WHERE jid = ?`,
+		messageID, at.UTC(), chatJID, readTimestamp.UTC(), messageID, chatJID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireRowsAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetReadPosition returns the last message read in a chat and when it was
+// read. messageID is empty if no position was recorded. It returns
+// sql.ErrNoRows if the chat does not exist.
+func (s *Store) GetReadPosition(chatJID string) (messageID string, at time.Time, err error) {
+	var readAt sql.NullTime
+	err = s.db.QueryRow(
+		"SELECT COALESCE(last_read_message_id, ''), last_read_at FROM chats WHERE jid = ?",
+		chatJID,
+	).Scan(&messageID, &readAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return messageID, readAt.Time, nil
+}
+
+// ArchiveChat hides a chat from the default chat list. Archiving an already
+// archived chat keeps the original time. It returns sql.ErrNoRows if the chat
+// does not exist.
+func (s *Store) ArchiveChat(jid string, at time.Time) error {
+	result, err := s.db.Exec(
+		"UPDATE chats SET archived_at = COALESCE(archived_at, ?) WHERE jid = ?",
+		at.UTC(), jid,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// UnarchiveChat returns a chat to the default chat list. It returns
+// sql.ErrNoRows if the chat does not exist.
+func (s *Store) UnarchiveChat(jid string) error {
+	result, err := s.db.Exec("UPDATE chats SET archived_at = NULL WHERE jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetArchivedChats retrieves archived chats with pagination, most recently
+// archived first
+func (s *Store) GetArchivedChats(limit, offset int) ([]*Chat, error) {
+	rows, err := s.db.Query(`
+		SELECT `+chatColumns+`
+		FROM chats c
+		WHERE archived_at IS NOT NULL
+		ORDER BY archived_at DESC, jid
+		LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChats(rows)
+}
+
+// UpdateChatName renames a chat. It returns sql.ErrNoRows if the chat does
+// not exist.
+func (s *Store) UpdateChatName(jid, name string) error {
+	result, err := s.db.Exec("UPDATE chats SET name = ? WHERE jid = ?", name, jid)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// MuteChat silences a chat's notifications until the given time. It returns
+// sql.ErrNoRows if the chat does not exist.
+func (s *Store) MuteChat(jid string, until time.Time) error {
+	result, err := s.db.Exec("UPDATE chats SET mute_until = ? WHERE jid = ?", until.UTC(), jid)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// UnmuteChat restores a chat's notifications. It returns sql.ErrNoRows if
+// the chat does not exist.
+func (s *Store) UnmuteChat(jid string) error {
+	result, err := s.db.Exec("UPDATE chats SET mute_until = NULL WHERE jid = ?", jid)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetMutedChats retrieves chats that are muted right now, most recent first
+func (s *Store) GetMutedChats() ([]*Chat, error) {
+	rows, err := s.db.Query(`
+		SELECT `+chatColumns+`
+		FROM chats c
+		WHERE mute_until IS NOT NULL AND mute_until > ?
+		ORDER BY last_message_time DESC`,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChats(rows)
+}
+
+// GetUnreadChats retrieves chats with unread messages, most recent first
+func (s *Store) GetUnreadChats() ([]*Chat, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + chatColumns + `
+		FROM chats c
+		WHERE unread_count > 0
+		ORDER BY last_message_time DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChats(rows)
+}
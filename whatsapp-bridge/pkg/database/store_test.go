@@ -1,9 +1,20 @@
 package database
 
 import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"whatsapp-client/pkg/metrics"
 )
 
 func TestNewStore(t *testing.T) {
@@ -23,6 +34,20 @@ func TestNewStore(t *testing.T) {
 	}
 }
 
+func TestPing(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	if err := store.Ping(); err != nil {
+		t.Fatalf("Expected an open store to answer, got %v", err)
+	}
+	
+	store.Close()
+	if err := store.Ping(); err == nil {
+		t.Error("Expected an error pinging a closed store")
+	}
+}
+
 func TestStoreChat(t *testing.T) {
 	store, cleanup := setupTestStore(t)
 	defer cleanup()
@@ -94,6 +119,913 @@ func TestStoreMessage(t *testing.T) {
 	}
 }
 
+func TestSearchMessages(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chats := []string{"123456789@s.whatsapp.net", "987654321@s.whatsapp.net"}
+	for _, jid := range chats {
+		store.StoreChat(&Chat{JID: jid, Name: jid, LastMessageTime: time.Now()})
+	}
+	
+	messages := []*Message{
+		{ID: "msg1", ChatJID: chats[0], Sender: chats[0], Content: "Lunch tomorrow at noon?", Timestamp: time.Now()},
+		{ID: "msg2", ChatJID: chats[0], Sender: chats[0], Content: "Running late for lunch", Timestamp: time.Now()},
+		{ID: "msg3", ChatJID: chats[1], Sender: chats[1], Content: "Lunch was great", Timestamp: time.Now()},
+		{ID: "msg4", ChatJID: chats[1], Sender: chats[1], Content: "See you at 100% capacity", Timestamp: time.Now()},
+	}
+	for _, msg := range messages {
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+	
+	// Replacing a message must update the search index
+	messages[1].Content = "Running late for dinner"
+	if err := store.StoreMessage(messages[1]); err != nil {
+		t.Fatalf("Failed to replace message: %v", err)
+	}
+	
+	tests := []struct {
+		query   string
+		chatJID string
+		want    int
+	}{
+		{"lunch", "", 2},
+		{"lunch", chats[0], 1},
+		{"lunch noon", "", 1},
+		{"dinner", "", 1},
+		{"100%", "", 1},
+		{"breakfast", "", 0},
+	}
+	
+	for _, test := range tests {
+		results, err := store.SearchMessages(test.query, test.chatJID, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchMessages(%q, %q) failed: %v", test.query, test.chatJID, err)
+		}
+		if len(results) != test.want {
+			t.Errorf("SearchMessages(%q, %q) returned %d messages, want %d", test.query, test.chatJID, len(results), test.want)
+		}
+	}
+	
+	if _, err := store.SearchMessages("   ", "", 10, 0); err == nil {
+		t.Errorf("Expected error for empty search query")
+	}
+}
+
+func TestBulkStoreMessages(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	store.SetBulkBatchSize(3)
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	msgs := testMessages(chat.JID, 10)
+	msgs[4].Content = "" // skipped like StoreMessage does
+	
+	if err := store.BulkStoreMessages(msgs); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+	
+	messages, err := store.GetMessages(chat.JID, 100, 0)
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(messages) != 9 {
+		t.Errorf("Expected 9 messages, got %d", len(messages))
+	}
+}
+
+func TestStoreMetrics(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	m := metrics.New(store.DBStats)
+	store.SetMetrics(m)
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	msgs := testMessages(chat.JID, 4)
+	msgs[3].Content = "" // not stored, so not counted
+	store.StoreMessage(msgs[0])
+	store.StoreMessage(msgs[3])
+	if err := store.BulkStoreMessages(msgs[1:]); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+	
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{"whatsapp_chats_stored_total 1", "whatsapp_messages_stored_total 3"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("Expected %q in the metrics output", want)
+		}
+	}
+}
+
+func TestBulkStoreMessagesPartialFailure(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	store.SetBulkBatchSize(3)
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	// The second batch references an unknown chat and violates the foreign key
+	msgs := testMessages(chat.JID, 9)
+	msgs[4].ChatJID = "unknown@s.whatsapp.net"
+	
+	err := store.BulkStoreMessages(msgs)
+	if err == nil {
+		t.Fatalf("Expected error for failing batch")
+	}
+	
+	messages, err := store.GetMessages(chat.JID, 100, 0)
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(messages) != 6 {
+		t.Errorf("Expected the 6 messages of the other batches, got %d", len(messages))
+	}
+}
+
+func TestBulkStoreMessagesRollsBackBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+	store := &Store{db: &queryDB{DB: db}, bulkBatchSize: DefaultBulkBatchSize}
+	
+	// The second insert fails, so the first must not be committed
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO messages")
+	mock.ExpectExec("INSERT INTO messages").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM mentions").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO messages").WillReturnError(errors.New("disk I/O error"))
+	mock.ExpectRollback()
+	
+	err = store.BulkStoreMessages(testMessages("123456789@s.whatsapp.net", 3))
+	if err == nil || !strings.Contains(err.Error(), "disk I/O error") {
+		t.Fatalf("Expected the injected write error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expected the batch to be rolled back: %v", err)
+	}
+}
+
+func TestWithTransaction(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	msgs := testMessages(chat.JID, 5)
+	msgs[3].ChatJID = "unknown@s.whatsapp.net"
+	
+	// A write failing midway rolls back the chat and the messages before it
+	err := store.WithTransaction(func(tx *sql.Tx) error {
+		if err := store.StoreChatTx(tx, chat); err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := store.StoreMessageTx(tx, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("Expected foreign key error")
+	}
+	
+	if _, err := store.GetChat(chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected chat to be rolled back, got %v", err)
+	}
+	var count int
+	store.db.QueryRow("SELECT COUNT(*) FROM messages").Scan(&count)
+	if count != 0 {
+		t.Errorf("Expected no messages after rollback, got %d", count)
+	}
+	
+	msgs[3].ChatJID = chat.JID
+	err = store.WithTransaction(func(tx *sql.Tx) error {
+		if err := store.StoreChatTx(tx, chat); err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := store.StoreMessageTx(tx, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+	
+	stored, err := store.GetChat(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get chat: %v", err)
+	}
+	if stored.UnreadCount != len(msgs) {
+		t.Errorf("Expected %d unread messages, got %d", len(msgs), stored.UnreadCount)
+	}
+}
+
+func BenchmarkStoreMessage(b *testing.B) {
+	store, cleanup := setupTestStore(b)
+	defer cleanup()
+	store.StoreChat(&Chat{JID: "123456789@s.whatsapp.net", Name: "Bench", LastMessageTime: time.Now()})
+	msgs := testMessages("123456789@s.whatsapp.net", b.N)
+	
+	b.ResetTimer()
+	for _, msg := range msgs {
+		if err := store.StoreMessage(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBulkStoreMessages(b *testing.B) {
+	store, cleanup := setupTestStore(b)
+	defer cleanup()
+	store.StoreChat(&Chat{JID: "123456789@s.whatsapp.net", Name: "Bench", LastMessageTime: time.Now()})
+	msgs := testMessages("123456789@s.whatsapp.net", b.N)
+	
+	b.ResetTimer()
+	if err := store.BulkStoreMessages(msgs); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func TestUpdateMessageStatus(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	msg := testMessages(chat.JID, 1)[0]
+	store.StoreMessage(msg)
+	
+	stored, err := store.GetMessage(msg.ID, chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+	if stored.Status != StatusSent {
+		t.Errorf("Expected default status %s, got %s", StatusSent, stored.Status)
+	}
+	
+	if err := store.UpdateMessageStatus(msg.ID, chat.JID, StatusRead); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	stored, _ = store.GetMessage(msg.ID, chat.JID)
+	if stored.Status != StatusRead {
+		t.Errorf("Expected status %s, got %s", StatusRead, stored.Status)
+	}
+	
+	if err := store.UpdateMessageStatus(msg.ID, chat.JID, MessageStatus("bogus")); err == nil {
+		t.Errorf("Expected error for invalid status")
+	}
+	
+	err = store.UpdateMessageStatus("missing", chat.JID, StatusRead)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing message, got %v", err)
+	}
+}
+
+func TestStoreMessageKeepsStatus(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	msg := testMessages(chat.JID, 1)[0]
+	if err := store.StoreMessage(msg); err != nil {
+		t.Fatalf("Failed to store message: %v", err)
+	}
+	if err := store.UpdateMessageStatus(msg.ID, chat.JID, StatusRead); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	
+	// Re-syncing the message without a status, or with an older one, does
+	// not take it back to sent
+	for _, status := range []MessageStatus{"", StatusSent, StatusDelivered} {
+		msg.Status = status
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message again: %v", err)
+		}
+		stored, _ := store.GetMessage(msg.ID, chat.JID)
+		if stored.Status != StatusRead {
+			t.Errorf("Storing with status %q: expected status %s, got %s", status, StatusRead, stored.Status)
+		}
+	}
+	
+	// A status further along replaces the stored one
+	other := testMessages(chat.JID, 2)[1]
+	other.Status = StatusPending
+	store.StoreMessage(other)
+	other.Status = StatusDelivered
+	if err := store.BulkStoreMessages([]*Message{other}); err != nil {
+		t.Fatalf("Failed to store message again: %v", err)
+	}
+	stored, _ := store.GetMessage(other.ID, chat.JID)
+	if stored.Status != StatusDelivered {
+		t.Errorf("Expected status %s, got %s", StatusDelivered, stored.Status)
+	}
+}
+
+func TestGetMessagesPage(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	msgs := testMessages(chat.JID, 7)
+	msgs[4].Timestamp = msgs[3].Timestamp // tie broken by id
+	if err := store.BulkStoreMessages(msgs); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+	
+	want := []string{"msg6", "msg5", "msg4", "msg3", "msg2", "msg1", "msg0"}
+	var got []string
+	token := ""
+	for pages := 0; pages < len(want); pages++ {
+		cursor, err := DecodeCursor(token)
+		if err != nil {
+			t.Fatalf("Failed to decode cursor %q: %v", token, err)
+		}
+		page, err := store.GetMessagesPage(chat.JID, 3, cursor, MessageOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get messages page: %v", err)
+		}
+		for _, msg := range page.Items {
+			got = append(got, msg.ID)
+		}
+		if token = page.NextCursor; token == "" {
+			break
+		}
+	}
+	
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected messages %v, got %v", want, got)
+	}
+}
+
+func TestGetChatsPage(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		store.StoreChat(&Chat{
+			JID:             fmt.Sprintf("12345678%d@s.whatsapp.net", i),
+			LastMessageTime: now.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	
+	first, err := store.GetChatsPage(2, Cursor{}, ChatOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get chats page: %v", err)
+	}
+	if len(first.Items) != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected 2 chats and a next cursor, got %d chats and cursor %q", len(first.Items), first.NextCursor)
+	}
+	
+	cursor, err := DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	second, err := store.GetChatsPage(2, cursor, ChatOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get chats page: %v", err)
+	}
+	if len(second.Items) != 1 || second.NextCursor != "" {
+		t.Errorf("Expected 1 chat and no next cursor, got %d chats and cursor %q", len(second.Items), second.NextCursor)
+	}
+	if len(second.Items) == 1 && second.Items[0].JID != "123456780@s.whatsapp.net" {
+		t.Errorf("Expected oldest chat on last page, got %s", second.Items[0].JID)
+	}
+}
+
+func TestPageOffsetAndCounts(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	store.StoreChat(&Chat{JID: "987654321@s.whatsapp.net", LastMessageTime: time.Now().Add(-time.Hour)})
+	if err := store.BulkStoreMessages(testMessages(chat.JID, 5)); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+	store.SoftDeleteMessage("msg0", chat.JID, time.Now())
+	
+	page, err := store.GetMessagesPage(chat.JID, 2, Cursor{}, MessageOptions{Offset: 1})
+	if err != nil {
+		t.Fatalf("Failed to get messages page: %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[0].ID != "msg3" || page.NextCursor == "" {
+		t.Errorf("Expected msg3 and msg2 with a next cursor, got %d items and cursor %q", len(page.Items), page.NextCursor)
+	}
+	
+	count, err := store.CountMessages(chat.JID, MessageOptions{})
+	if err != nil || count != 4 {
+		t.Errorf("Expected 4 visible messages, got %d (err %v)", count, err)
+	}
+	count, err = store.CountMessages(chat.JID, MessageOptions{IncludeDeleted: true})
+	if err != nil || count != 5 {
+		t.Errorf("Expected 5 messages including deleted, got %d (err %v)", count, err)
+	}
+	
+	chats, err := store.GetChatsPage(10, Cursor{}, ChatOptions{Offset: 1})
+	if err != nil {
+		t.Fatalf("Failed to get chats page: %v", err)
+	}
+	if len(chats.Items) != 1 || chats.Items[0].JID != "987654321@s.whatsapp.net" {
+		t.Errorf("Expected only the older chat after skipping one, got %d chats", len(chats.Items))
+	}
+	count, err = store.CountChats(ChatOptions{})
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 chats, got %d (err %v)", count, err)
+	}
+}
+
+func TestGetLastMessagePerChat(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	now := time.Now()
+	alice := &Chat{JID: "111111111@s.whatsapp.net", Name: "Alice", LastMessageTime: now}
+	bob := &Chat{JID: "222222222@s.whatsapp.net", Name: "Bob", LastMessageTime: now.Add(-time.Minute)}
+	empty := &Chat{JID: "333333333@s.whatsapp.net", Name: "Empty", LastMessageTime: now.Add(-time.Hour)}
+	for _, chat := range []*Chat{alice, bob, empty} {
+		store.StoreChat(chat)
+	}
+	store.BulkStoreMessages(testMessages(alice.JID, 3))
+	store.BulkStoreMessages(testMessages(bob.JID, 2))
+	
+	// The newest message is skipped once deleted
+	store.SoftDeleteMessage("msg1", bob.JID, now)
+	
+	last, err := store.GetLastMessagePerChat(nil)
+	if err != nil {
+		t.Fatalf("Failed to get last messages: %v", err)
+	}
+	if len(last) != 2 || last[alice.JID].ID != "msg2" || last[bob.JID].ID != "msg0" {
+		t.Errorf("Expected msg2 for alice and msg0 for bob, got %v", last)
+	}
+	
+	last, err = store.GetLastMessagePerChat([]string{bob.JID})
+	if err != nil {
+		t.Fatalf("Failed to get last messages: %v", err)
+	}
+	if len(last) != 1 || last[bob.JID] == nil {
+		t.Errorf("Expected only bob's last message, got %v", last)
+	}
+	
+	chats, err := store.GetChatsWithLastMessage(10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get chats with last message: %v", err)
+	}
+	if len(chats) != 3 || chats[0].JID != alice.JID || chats[0].LastMessage.ID != "msg2" || chats[2].LastMessage != nil {
+		t.Errorf("Expected chats in activity order with last messages, got %+v", chats)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, token := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := DecodeCursor(token); err == nil {
+			t.Errorf("Expected error decoding cursor %q", token)
+		}
+	}
+}
+
+func TestSoftDeleteMessage(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	msgs := testMessages(chat.JID, 3)
+	if err := store.BulkStoreMessages(msgs); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+	
+	deletedAt := time.Now()
+	if err := store.SoftDeleteMessage("msg1", chat.JID, deletedAt); err != nil {
+		t.Fatalf("Failed to soft delete message: %v", err)
+	}
+	
+	// Re-syncing the message must not bring it back
+	if err := store.StoreMessage(msgs[1]); err != nil {
+		t.Fatalf("Failed to re-store message: %v", err)
+	}
+	
+	page, err := store.GetMessagesPage(chat.JID, 10, Cursor{}, MessageOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(page.Items) != 2 {
+		t.Errorf("Expected 2 messages excluding deleted, got %d", len(page.Items))
+	}
+	
+	page, err = store.GetMessagesPage(chat.JID, 10, Cursor{}, MessageOptions{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(page.Items) != 3 {
+		t.Fatalf("Expected 3 messages including deleted, got %d", len(page.Items))
+	}
+	deleted := page.Items[1]
+	if !deleted.IsDeleted || deleted.DeletedAt == nil || !deleted.DeletedAt.Equal(deletedAt) {
+		t.Errorf("Expected msg1 deleted at %v, got is_deleted=%v deleted_at=%v", deletedAt, deleted.IsDeleted, deleted.DeletedAt)
+	}
+	
+	err = store.SoftDeleteMessage("missing", chat.JID, deletedAt)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing message, got %v", err)
+	}
+}
+
+func TestDeleteMessages(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	msgs := testMessages(chat.JID, maxDeleteBatch+10)
+	if err := store.BulkStoreMessages(msgs); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+	store.StoreReaction(&Reaction{MessageID: "msg0", ChatJID: chat.JID, Sender: "me", Emoji: "👍", Timestamp: time.Now()})
+	
+	if err := store.DeleteMessage("msg0", chat.JID); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+	if _, err := store.GetMessage("msg0", chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected deleted message to be gone, got %v", err)
+	}
+	if reactions, _ := store.GetReactions("msg0", chat.JID); len(reactions) != 0 {
+		t.Errorf("Expected reactions to be deleted with the message, got %d", len(reactions))
+	}
+	if err := store.DeleteMessage("msg0", chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows deleting a missing message, got %v", err)
+	}
+	
+	// Spans a full batch and a partial one, and skips unknown IDs
+	ids := []string{"missing"}
+	for _, msg := range msgs[1 : maxDeleteBatch+5] {
+		ids = append(ids, msg.ID)
+	}
+	if err := store.BulkDeleteMessages(ids, chat.JID); err != nil {
+		t.Fatalf("Failed to bulk delete messages: %v", err)
+	}
+	remaining, err := store.GetMessages(chat.JID, 100, 0)
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(remaining) != 5 {
+		t.Errorf("Expected 5 messages left after bulk delete, got %d", len(remaining))
+	}
+	
+	if err := store.DeleteChatMessages(chat.JID); err != nil {
+		t.Fatalf("Failed to delete chat messages: %v", err)
+	}
+	got, err := store.GetChat(chat.JID)
+	if err != nil {
+		t.Fatalf("Expected chat to be kept, got %v", err)
+	}
+	if got.UnreadCount != 0 {
+		t.Errorf("Expected unread count reset, got %d", got.UnreadCount)
+	}
+	
+	store.StoreMessage(testMessages(chat.JID, 1)[0])
+	if err := store.DeleteChat(chat.JID); err != nil {
+		t.Fatalf("Failed to delete chat: %v", err)
+	}
+	if _, err := store.GetChat(chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected deleted chat to be gone, got %v", err)
+	}
+	if err := store.DeleteChat(chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows deleting a missing chat, got %v", err)
+	}
+}
+
+func TestUnreadCount(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	msgs := testMessages(chat.JID, 3)
+	msgs[2].IsFromMe = true // own messages are never unread
+	for _, msg := range msgs {
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+	// Storing the same message again or refreshing the chat must not change the count
+	store.StoreMessage(msgs[0])
+	store.StoreChat(chat)
+	
+	unread, err := store.GetUnreadChats()
+	if err != nil {
+		t.Fatalf("Failed to get unread chats: %v", err)
+	}
+	if len(unread) != 1 || unread[0].UnreadCount != 2 {
+		t.Fatalf("Expected 1 chat with 2 unread messages, got %+v", unread)
+	}
+	
+	if err := store.MarkChatRead(chat.JID); err != nil {
+		t.Fatalf("Failed to mark chat read: %v", err)
+	}
+	
+	got, err := store.GetChat(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get chat: %v", err)
+	}
+	if got.UnreadCount != 0 || got.LastReadAt == nil {
+		t.Errorf("Expected read chat, got unread_count=%d last_read_at=%v", got.UnreadCount, got.LastReadAt)
+	}
+	
+	unread, err = store.GetUnreadChats()
+	if err != nil {
+		t.Fatalf("Failed to get unread chats: %v", err)
+	}
+	if len(unread) != 0 {
+		t.Errorf("Expected no unread chats, got %d", len(unread))
+	}
+	
+	if err := store.MarkChatRead("missing@s.whatsapp.net"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing chat, got %v", err)
+	}
+}
+
+func TestReadPosition(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	for _, msg := range testMessages(chat.JID, 5) {
+		store.StoreMessage(msg)
+	}
+	
+	if id, _, err := store.GetReadPosition(chat.JID); err != nil || id != "" {
+		t.Errorf("Expected no read position yet, got %q, %v", id, err)
+	}
+	
+	readAt := time.Now().Add(-time.Minute)
+	if err := store.SetReadPosition(chat.JID, "msg1", readAt); err != nil {
+		t.Fatalf("Failed to set read position: %v", err)
+	}
+	id, at, err := store.GetReadPosition(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get read position: %v", err)
+	}
+	if id != "msg1" || !at.Equal(readAt) {
+		t.Errorf("Expected msg1 read at %v, got %q at %v", readAt, id, at)
+	}
+	
+	// Messages after the read position stay unread
+	got, err := store.GetChat(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get chat: %v", err)
+	}
+	if got.UnreadCount != 3 || got.LastReadMessageID != "msg1" {
+		t.Errorf("Expected 3 unread after msg1, got %d (position %q)", got.UnreadCount, got.LastReadMessageID)
+	}
+	
+	// Marking the chat read moves the position to the newest message
+	if err := store.MarkChatRead(chat.JID); err != nil {
+		t.Fatalf("Failed to mark chat read: %v", err)
+	}
+	if id, _, _ := store.GetReadPosition(chat.JID); id != "msg4" {
+		t.Errorf("Expected read position msg4 after marking read, got %q", id)
+	}
+	
+	if err := store.SetReadPosition(chat.JID, "missing", readAt); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing message, got %v", err)
+	}
+	if _, _, err := store.GetReadPosition("missing@s.whatsapp.net"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing chat, got %v", err)
+	}
+}
+
+func TestArchiveChat(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	now := time.Now()
+	for i, jid := range []string{"111111111@s.whatsapp.net", "222222222@s.whatsapp.net", "333333333@s.whatsapp.net"} {
+		store.StoreChat(&Chat{JID: jid, Name: jid, LastMessageTime: now.Add(time.Duration(i) * time.Minute)})
+	}
+	
+	archivedAt := now.Add(-time.Hour)
+	if err := store.ArchiveChat("111111111@s.whatsapp.net", archivedAt); err != nil {
+		t.Fatalf("Failed to archive chat: %v", err)
+	}
+	// Archiving again keeps the original time
+	if err := store.ArchiveChat("111111111@s.whatsapp.net", now); err != nil {
+		t.Fatalf("Failed to re-archive chat: %v", err)
+	}
+	
+	page, err := store.GetChatsPage(10, Cursor{}, ChatOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get chats: %v", err)
+	}
+	if len(page.Items) != 2 {
+		t.Errorf("Expected archived chat to be hidden, got %d chats", len(page.Items))
+	}
+	
+	page, err = store.GetChatsPage(10, Cursor{}, ChatOptions{IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("Failed to get chats: %v", err)
+	}
+	if len(page.Items) != 3 {
+		t.Errorf("Expected 3 chats including archived, got %d", len(page.Items))
+	}
+	
+	archived, err := store.GetArchivedChats(10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get archived chats: %v", err)
+	}
+	if len(archived) != 1 || archived[0].ArchivedAt == nil || !archived[0].ArchivedAt.Equal(archivedAt) {
+		t.Errorf("Expected one chat archived at %v, got %+v", archivedAt, archived)
+	}
+	
+	if err := store.UnarchiveChat("111111111@s.whatsapp.net"); err != nil {
+		t.Fatalf("Failed to unarchive chat: %v", err)
+	}
+	chats, err := store.GetChats(10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get chats: %v", err)
+	}
+	if len(chats) != 3 {
+		t.Errorf("Expected 3 chats after unarchiving, got %d", len(chats))
+	}
+	
+	if err := store.ArchiveChat("missing@s.whatsapp.net", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing chat, got %v", err)
+	}
+}
+
+func TestMuteChat(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	now := time.Now()
+	muted := &Chat{JID: "123456789-123456@g.us", Name: "Muted Group", LastMessageTime: now}
+	expired := &Chat{JID: "987654321-654321@g.us", Name: "Expired Group", LastMessageTime: now}
+	store.StoreChat(muted)
+	store.StoreChat(expired)
+	
+	if err := store.MuteChat(muted.JID, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to mute chat: %v", err)
+	}
+	if err := store.MuteChat(expired.JID, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed to mute chat: %v", err)
+	}
+	
+	chats, err := store.GetMutedChats()
+	if err != nil {
+		t.Fatalf("Failed to get muted chats: %v", err)
+	}
+	if len(chats) != 1 || chats[0].JID != muted.JID || !chats[0].IsMuted() {
+		t.Errorf("Expected only %s to be muted, got %+v", muted.JID, chats)
+	}
+	
+	chat, err := store.GetChat(expired.JID)
+	if err != nil {
+		t.Fatalf("Failed to get chat: %v", err)
+	}
+	if chat.IsMuted() {
+		t.Error("Expected an expired mute not to count as muted")
+	}
+	
+	if err := store.UnmuteChat(muted.JID); err != nil {
+		t.Fatalf("Failed to unmute chat: %v", err)
+	}
+	chat, err = store.GetChat(muted.JID)
+	if err != nil {
+		t.Fatalf("Failed to get chat: %v", err)
+	}
+	if chat.MuteUntil != nil || chat.IsMuted() {
+		t.Errorf("Expected mute_until to be cleared, got %v", chat.MuteUntil)
+	}
+	
+	if err := store.MuteChat("missing@s.whatsapp.net", now.Add(time.Hour)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing chat, got %v", err)
+	}
+}
+
+func TestGetMessageThread(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	// msg7 quotes msg6, which quotes msg5, ... down to msg0
+	msgs := testMessages(chat.JID, 8)
+	for i := 1; i < len(msgs); i++ {
+		msgs[i].QuotedMessageID = msgs[i-1].ID
+		msgs[i].QuotedMessageContent = msgs[i-1].Content
+	}
+	if err := store.BulkStoreMessages(msgs); err != nil {
+		t.Fatalf("Failed to bulk store messages: %v", err)
+	}
+	
+	thread, err := store.GetMessageThread("msg2", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get thread: %v", err)
+	}
+	if len(thread) != 3 || thread[0].ID != "msg0" || thread[2].ID != "msg2" {
+		t.Errorf("Expected thread msg0..msg2, got %d messages", len(thread))
+	}
+	if thread[2].QuotedMessageContent != msgs[1].Content {
+		t.Errorf("Expected quoted content %q, got %q", msgs[1].Content, thread[2].QuotedMessageContent)
+	}
+	
+	// Depth is capped
+	thread, err = store.GetMessageThread("msg7", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get thread: %v", err)
+	}
+	if len(thread) != maxThreadDepth+1 {
+		t.Errorf("Expected %d messages, got %d", maxThreadDepth+1, len(thread))
+	}
+	
+	// Cyclic quotes terminate and do not repeat messages
+	msgs[0].QuotedMessageID = "msg1"
+	store.StoreMessage(msgs[0])
+	thread, err = store.GetMessageThread("msg1", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get cyclic thread: %v", err)
+	}
+	if len(thread) != 2 {
+		t.Errorf("Expected 2 messages in cyclic thread, got %d", len(thread))
+	}
+	
+	if _, err := store.GetMessageThread("missing", chat.JID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for missing message, got %v", err)
+	}
+}
+
+func TestMediaMetadata(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+	
+	video := &Message{
+		ID:                   "video1",
+		ChatJID:              chat.JID,
+		Sender:               "123456789",
+		Timestamp:            time.Now(),
+		MediaType:            "video",
+		Filename:             "clip.mp4",
+		MediaMimeType:        "video/mp4",
+		MediaWidth:           1280,
+		MediaHeight:          720,
+		MediaDurationSeconds: 42,
+		Thumbnail:            []byte{0xff, 0xd8, 0xff},
+	}
+	if err := store.StoreMessage(video); err != nil {
+		t.Fatalf("Failed to store message: %v", err)
+	}
+	
+	got, err := store.GetMessage("video1", chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+	if !got.IsMedia() || got.MediaMimeType != "video/mp4" || got.MediaWidth != 1280 || got.MediaHeight != 720 {
+		t.Errorf("Expected video metadata to round-trip, got %+v", got)
+	}
+	if got.MediaDuration() != 42*time.Second {
+		t.Errorf("Expected duration 42s, got %v", got.MediaDuration())
+	}
+	if !bytes.Equal(got.Thumbnail, video.Thumbnail) {
+		t.Errorf("Expected thumbnail %x, got %x", video.Thumbnail, got.Thumbnail)
+	}
+	
+	// Text messages have no metadata
+	text := testMessages(chat.JID, 1)[0]
+	store.StoreMessage(text)
+	got, err = store.GetMessage(text.ID, chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+	if got.IsMedia() || got.MediaMimeType != "" || got.MediaDuration() != 0 || got.Thumbnail != nil {
+		t.Errorf("Expected no media metadata on a text message, got %+v", got)
+	}
+}
+
 func TestChatIsGroup(t *testing.T) {
 	tests := []struct {
 		jid      string
@@ -101,6 +1033,9 @@ func TestChatIsGroup(t *testing.T) {
 	}{
 		{"123456789@s.whatsapp.net", false},
 		{"123456789-123456789@g.us", true},
+		{"123456789@broadcast", false},
+		{"123456789-123456789@g.us.evil", false},
+		{"g.us", false},
 		{"invalid", false},
 	}
 	
@@ -119,7 +1054,9 @@ func TestChatIsContact(t *testing.T) {
 		expected bool
 	}{
 		{"123456789@s.whatsapp.net", true},
+		{"123456789:12@s.whatsapp.net", true},
 		{"123456789-123456789@g.us", false},
+		{"123456789@s.whatsapp.net.evil", false},
 		{"invalid", false},
 	}
 	
@@ -132,7 +1069,97 @@ func TestChatIsContact(t *testing.T) {
 	}
 }
 
-func setupTestStore(t *testing.T) (*Store, func()) {
+func TestChatIsBroadcast(t *testing.T) {
+	tests := []struct {
+		jid      string
+		expected bool
+	}{
+		{"123456789@broadcast", true},
+		{"status@broadcast", false},
+		{"123456789@s.whatsapp.net", false},
+		{"123456789-123456789@g.us", false},
+	}
+	
+	for _, test := range tests {
+		chat := &Chat{JID: test.jid}
+		result := chat.IsBroadcast()
+		if result != test.expected {
+			t.Errorf("For JID %s, expected IsBroadcast() = %v, got %v", test.jid, test.expected, result)
+		}
+	}
+}
+
+func TestChatDisplayName(t *testing.T) {
+	tests := []struct {
+		name     string
+		chat     Chat
+		expected string
+	}{
+		{"populated name", Chat{JID: "123456789@s.whatsapp.net", Name: "Alice"}, "Alice"},
+		{"contact JID", Chat{JID: "123456789@s.whatsapp.net"}, "+123456789"},
+		{"contact device JID", Chat{JID: "123456789:12@s.whatsapp.net"}, "+123456789"},
+		{"group JID", Chat{JID: "123456789-987654@g.us"}, "Group (123456789-987654)"},
+		{"broadcast JID", Chat{JID: "123456789@broadcast"}, "123456789@broadcast"},
+		{"empty name and JID", Chat{}, ""},
+	}
+	
+	for _, test := range tests {
+		if result := test.chat.DisplayName(); result != test.expected {
+			t.Errorf("%s: expected DisplayName() = %q, got %q", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestChatShortName(t *testing.T) {
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Café Society"}
+	tests := []struct {
+		maxLen   int
+		expected string
+	}{
+		{20, "Café Society"},
+		{12, "Café Society"},
+		{5, "Café…"},
+		{1, "…"},
+		{0, ""},
+	}
+	
+	for _, test := range tests {
+		if result := chat.ShortName(test.maxLen); result != test.expected {
+			t.Errorf("Expected ShortName(%d) = %q, got %q", test.maxLen, test.expected, result)
+		}
+	}
+	
+	if result := (&Chat{JID: "123456789@s.whatsapp.net"}).ShortName(6); result != "+1234…" {
+		t.Errorf("Expected the phone number to be shortened, got %q", result)
+	}
+}
+
+func TestUpdateChatName(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	chat := &Chat{JID: "123456789@s.whatsapp.net", LastMessageTime: time.Now()}
+	if err := store.StoreChat(chat); err != nil {
+		t.Fatalf("Failed to store chat: %v", err)
+	}
+	if err := store.UpdateChatName(chat.JID, "Alice"); err != nil {
+		t.Fatalf("Failed to update chat name: %v", err)
+	}
+	
+	got, err := store.GetChat(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get chat: %v", err)
+	}
+	if got.Name != "Alice" || got.DisplayName() != "Alice" {
+		t.Errorf("Expected name Alice, got %q", got.Name)
+	}
+	
+	if err := store.UpdateChatName("missing@s.whatsapp.net", "Bob"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing chat, got %v", err)
+	}
+}
+
+func setupTestStore(t testing.TB) (*Store, func()) {
 	tempDir := t.TempDir()
 	dbPath := tempDir + "/test.db"
 	
@@ -146,4 +1173,19 @@ func setupTestStore(t *testing.T) (*Store, func()) {
 	}
 	
 	return store, cleanup
-}
\ No newline at end of file
+}
+
+func testMessages(chatJID string, n int) []*Message {
+	msgs := make([]*Message, n)
+	start := time.Now().Add(-time.Duration(n) * time.Second)
+	for i := range msgs {
+		msgs[i] = &Message{
+			ID:        fmt.Sprintf("msg%d", i),
+			ChatJID:   chatJID,
+			Sender:    chatJID,
+			Content:   fmt.Sprintf("Test message %d", i),
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return msgs
+}
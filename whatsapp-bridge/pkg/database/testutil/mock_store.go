@@ -0,0 +1,619 @@
+// Package testutil provides test doubles for the database package
+package testutil
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"whatsapp-client/pkg/database"
+	"whatsapp-client/pkg/metrics"
+)
+
+// MockStore is a database.DataStore whose methods return what the test set
+// up with On. A nil pointer, slice or map result may be given as nil.
+// WithTransaction records the call without running its function, since
+// there is no transaction to give it.
+type MockStore struct {
+	mock.Mock
+}
+
+var _ database.DataStore = (*MockStore)(nil)
+
+// result returns the i-th value set up with Return, or the zero value of T
+// if it is nil
+func result[T any](args mock.Arguments, i int) T {
+	v, _ := args.Get(i).(T)
+	return v
+}
+
+func (m *MockStore) StoreChat(chat *database.Chat) error {
+	return m.Called(chat).Error(0)
+}
+
+func (m *MockStore) StoreChatTx(tx *sql.Tx, chat *database.Chat) error {
+	return m.Called(tx, chat).Error(0)
+}
+
+func (m *MockStore) DeleteChat(jid string) error {
+	return m.Called(jid).Error(0)
+}
+
+func (m *MockStore) GetChatsPage(limit int, cursor database.Cursor, opts database.ChatOptions) (*database.PageResult[*database.Chat], error) {
+	args := m.Called(limit, cursor, opts)
+	return result[*database.PageResult[*database.Chat]](args, 0), args.Error(1)
+}
+
+func (m *MockStore) CountChats(opts database.ChatOptions) (int, error) {
+	args := m.Called(opts)
+	return result[int](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetChats(limit int, offset int) ([]*database.Chat, error) {
+	args := m.Called(limit, offset)
+	return result[[]*database.Chat](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetChatsWithLastMessage(limit int, offset int) ([]*database.ChatWithLastMessage, error) {
+	args := m.Called(limit, offset)
+	return result[[]*database.ChatWithLastMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) WithLastMessages(chats []*database.Chat) ([]*database.ChatWithLastMessage, error) {
+	args := m.Called(chats)
+	return result[[]*database.ChatWithLastMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetChat(jid string) (*database.Chat, error) {
+	args := m.Called(jid)
+	return result[*database.Chat](args, 0), args.Error(1)
+}
+
+func (m *MockStore) MarkChatRead(jid string) error {
+	return m.Called(jid).Error(0)
+}
+
+func (m *MockStore) SetReadPosition(chatJID string, messageID string, at time.Time) error {
+	return m.Called(chatJID, messageID, at).Error(0)
+}
+
+func (m *MockStore) GetReadPosition(chatJID string) (string, time.Time, error) {
+	args := m.Called(chatJID)
+	return result[string](args, 0), result[time.Time](args, 1), args.Error(2)
+}
+
+func (m *MockStore) ArchiveChat(jid string, at time.Time) error {
+	return m.Called(jid, at).Error(0)
+}
+
+func (m *MockStore) UnarchiveChat(jid string) error {
+	return m.Called(jid).Error(0)
+}
+
+func (m *MockStore) GetArchivedChats(limit int, offset int) ([]*database.Chat, error) {
+	args := m.Called(limit, offset)
+	return result[[]*database.Chat](args, 0), args.Error(1)
+}
+
+func (m *MockStore) UpdateChatName(jid string, name string) error {
+	return m.Called(jid, name).Error(0)
+}
+
+func (m *MockStore) MuteChat(jid string, until time.Time) error {
+	return m.Called(jid, until).Error(0)
+}
+
+func (m *MockStore) UnmuteChat(jid string) error {
+	return m.Called(jid).Error(0)
+}
+
+func (m *MockStore) GetMutedChats() ([]*database.Chat, error) {
+	args := m.Called()
+	return result[[]*database.Chat](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetUnreadChats() ([]*database.Chat, error) {
+	args := m.Called()
+	return result[[]*database.Chat](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreMessage(msg *database.Message) error {
+	return m.Called(msg).Error(0)
+}
+
+func (m *MockStore) StoreMessageTx(tx *sql.Tx, msg *database.Message) error {
+	return m.Called(tx, msg).Error(0)
+}
+
+func (m *MockStore) BulkStoreMessages(msgs []*database.Message) error {
+	return m.Called(msgs).Error(0)
+}
+
+func (m *MockStore) GetMessagesPage(chatJID string, limit int, cursor database.Cursor, opts database.MessageOptions) (*database.PageResult[*database.Message], error) {
+	args := m.Called(chatJID, limit, cursor, opts)
+	return result[*database.PageResult[*database.Message]](args, 0), args.Error(1)
+}
+
+func (m *MockStore) CountMessages(chatJID string, opts database.MessageOptions) (int, error) {
+	args := m.Called(chatJID, opts)
+	return result[int](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetMessages(chatJID string, limit int, offset int) ([]*database.Message, error) {
+	args := m.Called(chatJID, limit, offset)
+	return result[[]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) UpdateMessageStatus(id string, chatJID string, status database.MessageStatus) error {
+	return m.Called(id, chatJID, status).Error(0)
+}
+
+func (m *MockStore) GetMessageThread(id string, chatJID string) ([]*database.Message, error) {
+	args := m.Called(id, chatJID)
+	return result[[]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) SoftDeleteMessage(id string, chatJID string, deletedAt time.Time) error {
+	return m.Called(id, chatJID, deletedAt).Error(0)
+}
+
+func (m *MockStore) PurgeDeletedMessages(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return result[int64](args, 0), args.Error(1)
+}
+
+func (m *MockStore) DeleteMessage(id string, chatJID string) error {
+	return m.Called(id, chatJID).Error(0)
+}
+
+func (m *MockStore) BulkDeleteMessages(ids []string, chatJID string) error {
+	return m.Called(ids, chatJID).Error(0)
+}
+
+func (m *MockStore) DeleteChatMessages(chatJID string) error {
+	return m.Called(chatJID).Error(0)
+}
+
+func (m *MockStore) GetMessage(id string, chatJID string) (*database.Message, error) {
+	args := m.Called(id, chatJID)
+	return result[*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) SearchMessages(query string, chatJID string, limit int, offset int) ([]*database.Message, error) {
+	args := m.Called(query, chatJID, limit, offset)
+	return result[[]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetLastMessagePerChat(jids []string) (map[string]*database.Message, error) {
+	args := m.Called(jids)
+	return result[map[string]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) RecordMessageEdit(messageID string, chatJID string, newContent string, editedAt time.Time) error {
+	return m.Called(messageID, chatJID, newContent, editedAt).Error(0)
+}
+
+func (m *MockStore) GetEditHistory(messageID string, chatJID string) ([]*database.EditRecord, error) {
+	args := m.Called(messageID, chatJID)
+	return result[[]*database.EditRecord](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetNearbyMessages(lat float64, lon float64, radiusKm float64, limit int) ([]*database.Message, error) {
+	args := m.Called(lat, lon, radiusKm, limit)
+	return result[[]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetNearbyLocations(lat float64, lon float64, radiusKm float64, since time.Time, limit int) ([]*database.LocationMessage, error) {
+	args := m.Called(lat, lon, radiusKm, since, limit)
+	return result[[]*database.LocationMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreLocation(loc *database.LocationMessage) error {
+	return m.Called(loc).Error(0)
+}
+
+func (m *MockStore) GetLocation(messageID string, chatJID string) (*database.LocationMessage, error) {
+	args := m.Called(messageID, chatJID)
+	return result[*database.LocationMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreMediaMetadata(md *database.MediaMetadata) error {
+	return m.Called(md).Error(0)
+}
+
+func (m *MockStore) UpdateLocalPath(messageID string, chatJID string, path string) error {
+	return m.Called(messageID, chatJID, path).Error(0)
+}
+
+func (m *MockStore) GetMediaMetadata(messageID string, chatJID string) (*database.MediaMetadata, error) {
+	args := m.Called(messageID, chatJID)
+	return result[*database.MediaMetadata](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetMentionsForJID(jid string, limit int, offset int) ([]*database.Message, error) {
+	args := m.Called(jid, limit, offset)
+	return result[[]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) PinMessage(messageID string, chatJID string, pinnedBy string, pinnedAt time.Time) error {
+	return m.Called(messageID, chatJID, pinnedBy, pinnedAt).Error(0)
+}
+
+func (m *MockStore) UnpinMessage(messageID string, chatJID string) error {
+	return m.Called(messageID, chatJID).Error(0)
+}
+
+func (m *MockStore) GetPinnedMessages(chatJID string) ([]*database.Message, error) {
+	args := m.Called(chatJID)
+	return result[[]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StorePoll(poll *database.PollMessage) error {
+	return m.Called(poll).Error(0)
+}
+
+func (m *MockStore) StorePollVote(vote *database.PollVote) error {
+	return m.Called(vote).Error(0)
+}
+
+func (m *MockStore) UpdatePollResults(messageID string, chatJID string, voterJID string, selectedIDs []string) error {
+	return m.Called(messageID, chatJID, voterJID, selectedIDs).Error(0)
+}
+
+func (m *MockStore) GetPoll(messageID string, chatJID string) (*database.PollMessage, error) {
+	args := m.Called(messageID, chatJID)
+	return result[*database.PollMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetPollResults(messageID string, chatJID string) (*database.PollResults, error) {
+	args := m.Called(messageID, chatJID)
+	return result[*database.PollResults](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreReaction(r *database.Reaction) error {
+	return m.Called(r).Error(0)
+}
+
+func (m *MockStore) DeleteReaction(messageID string, chatJID string, sender string) error {
+	return m.Called(messageID, chatJID, sender).Error(0)
+}
+
+func (m *MockStore) GetReactions(messageID string, chatJID string) ([]*database.Reaction, error) {
+	args := m.Called(messageID, chatJID)
+	return result[[]*database.Reaction](args, 0), args.Error(1)
+}
+
+func (m *MockStore) SearchMessagesFiltered(f database.SearchMessagesFilter, limit int, cursor database.Cursor) ([]*database.Message, database.Cursor, error) {
+	args := m.Called(f, limit, cursor)
+	return result[[]*database.Message](args, 0), result[database.Cursor](args, 1), args.Error(2)
+}
+
+func (m *MockStore) CountMessagesFiltered(f database.SearchMessagesFilter) (int, error) {
+	args := m.Called(f)
+	return result[int](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetMediaMessages(chatJID string, mediaType string, limit int, offset int) ([]*database.Message, error) {
+	args := m.Called(chatJID, mediaType, limit, offset)
+	return result[[]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetMessageStats(chatJID string) (*database.MessageStats, error) {
+	args := m.Called(chatJID)
+	return result[*database.MessageStats](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetChatStatistics(chatJID string) (*database.ChatStatistics, error) {
+	args := m.Called(chatJID)
+	return result[*database.ChatStatistics](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetChatActivityByDay(chatJID string, days int) ([]database.DayCount, error) {
+	args := m.Called(chatJID, days)
+	return result[[]database.DayCount](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetContactShareMessages(chatJID string) ([]*database.Message, error) {
+	args := m.Called(chatJID)
+	return result[[]*database.Message](args, 0), args.Error(1)
+}
+
+func (m *MockStore) Close() error {
+	return m.Called().Error(0)
+}
+
+func (m *MockStore) SetMetrics(appMetrics *metrics.Metrics) {
+	m.Called(appMetrics)
+}
+
+func (m *MockStore) DBStats() sql.DBStats {
+	args := m.Called()
+	return result[sql.DBStats](args, 0)
+}
+
+func (m *MockStore) Ping() error {
+	return m.Called().Error(0)
+}
+
+func (m *MockStore) SetBulkBatchSize(n int) {
+	m.Called(n)
+}
+
+func (m *MockStore) WithTransaction(fn func(tx *sql.Tx) error) error {
+	return m.Called(fn).Error(0)
+}
+
+func (m *MockStore) Backup(destPath string) error {
+	return m.Called(destPath).Error(0)
+}
+
+func (m *MockStore) BlockContact(jid string) error {
+	return m.Called(jid).Error(0)
+}
+
+func (m *MockStore) UnblockContact(jid string) error {
+	return m.Called(jid).Error(0)
+}
+
+func (m *MockStore) IsBlocked(jid string) (bool, error) {
+	args := m.Called(jid)
+	return result[bool](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetBlockedContacts() ([]*database.Contact, error) {
+	args := m.Called()
+	return result[[]*database.Contact](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreBroadcastList(list *database.BroadcastList) error {
+	return m.Called(list).Error(0)
+}
+
+func (m *MockStore) GetBroadcastList(jid string) (*database.BroadcastList, error) {
+	args := m.Called(jid)
+	return result[*database.BroadcastList](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetBroadcastLists() ([]*database.BroadcastList, error) {
+	args := m.Called()
+	return result[[]*database.BroadcastList](args, 0), args.Error(1)
+}
+
+func (m *MockStore) AddBroadcastRecipient(listJID string, recipientJID string) error {
+	return m.Called(listJID, recipientJID).Error(0)
+}
+
+func (m *MockStore) RemoveBroadcastRecipient(listJID string, recipientJID string) error {
+	return m.Called(listJID, recipientJID).Error(0)
+}
+
+func (m *MockStore) GetBroadcastRecipients(listJID string) ([]string, error) {
+	args := m.Called(listJID)
+	return result[[]string](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreCallLog(call *database.CallLog) error {
+	return m.Called(call).Error(0)
+}
+
+func (m *MockStore) GetCallLogs(jid string, limit int, offset int) ([]*database.CallLog, error) {
+	args := m.Called(jid, limit, offset)
+	return result[[]*database.CallLog](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetMissedCalls(limit int) ([]*database.CallLog, error) {
+	args := m.Called(limit)
+	return result[[]*database.CallLog](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetCallLogByID(id string) (*database.CallLog, error) {
+	args := m.Called(id)
+	return result[*database.CallLog](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreContact(c *database.Contact) error {
+	return m.Called(c).Error(0)
+}
+
+func (m *MockStore) GetContact(jid string) (*database.Contact, error) {
+	args := m.Called(jid)
+	return result[*database.Contact](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetContactByPhone(phone string) (*database.Contact, error) {
+	args := m.Called(phone)
+	return result[*database.Contact](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetContacts() ([]*database.Contact, error) {
+	args := m.Called()
+	return result[[]*database.Contact](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetContactsByCountry(code string) ([]*database.Contact, error) {
+	args := m.Called(code)
+	return result[[]*database.Contact](args, 0), args.Error(1)
+}
+
+func (m *MockStore) SearchContacts(query string, limit int) ([]*database.Contact, error) {
+	args := m.Called(query, limit)
+	return result[[]*database.Contact](args, 0), args.Error(1)
+}
+
+func (m *MockStore) DeleteContact(jid string) error {
+	return m.Called(jid).Error(0)
+}
+
+func (m *MockStore) UpsertParticipant(p *database.GroupParticipant) error {
+	return m.Called(p).Error(0)
+}
+
+func (m *MockStore) RemoveParticipant(groupJID string, participantJID string, leftAt time.Time) error {
+	return m.Called(groupJID, participantJID, leftAt).Error(0)
+}
+
+func (m *MockStore) GetParticipants(groupJID string) ([]*database.GroupParticipant, error) {
+	args := m.Called(groupJID)
+	return result[[]*database.GroupParticipant](args, 0), args.Error(1)
+}
+
+func (m *MockStore) IsAdmin(groupJID string, participantJID string) (bool, error) {
+	args := m.Called(groupJID, participantJID)
+	return result[bool](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreGroupInfo(info *database.GroupInfo) error {
+	return m.Called(info).Error(0)
+}
+
+func (m *MockStore) GetGroupInfo(jid string) (*database.GroupInfo, error) {
+	args := m.Called(jid)
+	return result[*database.GroupInfo](args, 0), args.Error(1)
+}
+
+func (m *MockStore) ListGroups(limit int, offset int) ([]*database.GroupInfo, error) {
+	args := m.Called(limit, offset)
+	return result[[]*database.GroupInfo](args, 0), args.Error(1)
+}
+
+func (m *MockStore) CreateJob(job *database.Job) error {
+	return m.Called(job).Error(0)
+}
+
+func (m *MockStore) UpdateJobStatus(id string, status database.JobStatus, jobErr string, at time.Time) error {
+	return m.Called(id, status, jobErr, at).Error(0)
+}
+
+func (m *MockStore) GetJob(id string) (*database.Job, error) {
+	args := m.Called(id)
+	return result[*database.Job](args, 0), args.Error(1)
+}
+
+func (m *MockStore) CreateLabel(l *database.Label) error {
+	return m.Called(l).Error(0)
+}
+
+func (m *MockStore) UpdateLabel(l *database.Label) error {
+	return m.Called(l).Error(0)
+}
+
+func (m *MockStore) DeleteLabel(id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockStore) GetLabel(id string) (*database.Label, error) {
+	args := m.Called(id)
+	return result[*database.Label](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetLabels() ([]*database.Label, error) {
+	args := m.Called()
+	return result[[]*database.Label](args, 0), args.Error(1)
+}
+
+func (m *MockStore) AddChatLabel(chatJID string, labelID string) error {
+	return m.Called(chatJID, labelID).Error(0)
+}
+
+func (m *MockStore) RemoveChatLabel(chatJID string, labelID string) error {
+	return m.Called(chatJID, labelID).Error(0)
+}
+
+func (m *MockStore) GetChatsByLabel(labelID string, limit int, offset int) ([]*database.Chat, error) {
+	args := m.Called(labelID, limit, offset)
+	return result[[]*database.Chat](args, 0), args.Error(1)
+}
+
+func (m *MockStore) Vacuum() error {
+	return m.Called().Error(0)
+}
+
+func (m *MockStore) CheckpointWAL() error {
+	return m.Called().Error(0)
+}
+
+func (m *MockStore) WALCheckpointInterval() time.Duration {
+	return result[time.Duration](m.Called(), 0)
+}
+
+func (m *MockStore) DatabaseStats() (*database.DBStats, error) {
+	args := m.Called()
+	return result[*database.DBStats](args, 0), args.Error(1)
+}
+
+func (m *MockStore) StoreProfilePicture(pp *database.ProfilePicture) error {
+	return m.Called(pp).Error(0)
+}
+
+func (m *MockStore) GetProfilePicture(jid string) (*database.ProfilePicture, error) {
+	args := m.Called(jid)
+	return result[*database.ProfilePicture](args, 0), args.Error(1)
+}
+
+func (m *MockStore) InvalidateProfilePicture(jid string) error {
+	return m.Called(jid).Error(0)
+}
+
+func (m *MockStore) ScheduleMessage(msg *database.ScheduledMessage) error {
+	return m.Called(msg).Error(0)
+}
+
+func (m *MockStore) GetDueMessages(now time.Time) ([]*database.ScheduledMessage, error) {
+	args := m.Called(now)
+	return result[[]*database.ScheduledMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetScheduledMessages(status database.ScheduledStatus, limit int, offset int) ([]*database.ScheduledMessage, error) {
+	args := m.Called(status, limit, offset)
+	return result[[]*database.ScheduledMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) MarkScheduledSent(id string, sentAt time.Time) error {
+	return m.Called(id, sentAt).Error(0)
+}
+
+func (m *MockStore) MarkScheduledFailed(id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockStore) StoreStatus(status *database.StatusMessage) error {
+	return m.Called(status).Error(0)
+}
+
+func (m *MockStore) GetStatuses(limit int, offset int) ([]*database.StatusMessage, error) {
+	args := m.Called(limit, offset)
+	return result[[]*database.StatusMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) GetStatusesByAuthor(authorJID string) ([]*database.StatusMessage, error) {
+	args := m.Called(authorJID)
+	return result[[]*database.StatusMessage](args, 0), args.Error(1)
+}
+
+func (m *MockStore) DeleteExpiredStatuses() (int64, error) {
+	args := m.Called()
+	return result[int64](args, 0), args.Error(1)
+}
+
+func (m *MockStore) DeleteStatusesBefore(cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return result[int64](args, 0), args.Error(1)
+}
+
+func (m *MockStore) RegisterWebhook(w *database.Webhook) error {
+	return m.Called(w).Error(0)
+}
+
+func (m *MockStore) DeleteWebhook(id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockStore) GetWebhooks() ([]*database.Webhook, error) {
+	args := m.Called()
+	return result[[]*database.Webhook](args, 0), args.Error(1)
+}
+
+func (m *MockStore) RecordWebhookDelivery(id string, success bool) error {
+	return m.Called(id, success).Error(0)
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"whatsapp-client/pkg/vcard"
+)
+
+// applyVCard fills the name and phone of a shared contact from its raw card.
+// A card that cannot be parsed is stored without them rather than dropping
+// the message.
+func applyVCard(msg *Message) {
+	if msg.MediaType != "vcard" || msg.VCard == "" {
+		return
+	}
+
+	name, phone, err := vcard.ParseVCard(msg.VCard)
+	if err != nil {
+		return
+	}
+	msg.VCardName, msg.VCardPhone = name, phone
+}
+
+// GetContactShareMessages retrieves the shared contacts of a chat, newest
+// first. Soft-deleted messages are excluded.
+func (s *Store) GetContactShareMessages(chatJID string) ([]*Message, error) {
+	rows, err := s.db.Query(`
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE chat_jid = ? AND media_type = 'vcard' AND is_deleted = 0
+		ORDER BY timestamp DESC`,
+		chatJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
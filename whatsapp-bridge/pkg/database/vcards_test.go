@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetContactShareMessages(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	chat := &Chat{JID: "123456789@s.whatsapp.net", Name: "Test Contact", LastMessageTime: time.Now()}
+	store.StoreChat(chat)
+
+	now := time.Now()
+	msgs := []*Message{
+		{ID: "card1", ChatJID: chat.JID, Sender: "123456789", Content: "Alice Smith", Timestamp: now.Add(-time.Minute), MediaType: "vcard",
+			VCard: "BEGIN:VCARD\nVERSION:3.0\nFN:Alice Smith\nitem1.TEL;waid=15551234567:+1 555-123-4567\nEND:VCARD"},
+		{ID: "card2", ChatJID: chat.JID, Sender: "123456789", Content: "Broken", Timestamp: now, MediaType: "vcard",
+			VCard: "not a card"},
+		{ID: "text", ChatJID: chat.JID, Sender: "123456789", Content: "hello", Timestamp: now},
+	}
+	for _, msg := range msgs {
+		if err := store.StoreMessage(msg); err != nil {
+			t.Fatalf("Failed to store message %s: %v", msg.ID, err)
+		}
+	}
+
+	shares, err := store.GetContactShareMessages(chat.JID)
+	if err != nil {
+		t.Fatalf("Failed to get contact shares: %v", err)
+	}
+	if len(shares) != 2 || shares[0].ID != "card2" {
+		t.Fatalf("Expected both cards newest first, got %v", shares)
+	}
+
+	// An unparseable card is kept without parsed fields
+	if shares[0].VCardName != "" || shares[0].VCardPhone != "" {
+		t.Errorf("Expected no parsed fields for a broken card, got %q %q", shares[0].VCardName, shares[0].VCardPhone)
+	}
+	if shares[1].VCardName != "Alice Smith" || shares[1].VCardPhone != "+1 555-123-4567" {
+		t.Errorf("Unexpected parsed card: %q %q", shares[1].VCardName, shares[1].VCardPhone)
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RegisterWebhook stores a new webhook, generating its ID if empty and
+// setting its creation time
+func (s *Store) RegisterWebhook(w *Webhook) error {
+	if w.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return fmt.Errorf("failed to generate webhook ID: %w", err)
+		}
+		w.ID = id
+	}
+	if w.Events == nil {
+		w.Events = []string{}
+	}
+	w.CreatedAt = time.Now().UTC()
+	w.LastDeliveryAt = nil
+	w.FailureCount = 0
+
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		"INSERT INTO webhooks (id, url, secret, events, created_at) VALUES (?, ?, ?, ?, ?)",
+		w.ID, w.URL, w.Secret, string(events), w.CreatedAt,
+	)
+	return err
+}
+
+// DeleteWebhook removes a webhook. It returns sql.ErrNoRows if the webhook
+// does not exist.
+func (s *Store) DeleteWebhook(id string) error {
+	result, err := s.db.Exec("DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
+
+// GetWebhooks retrieves all webhooks, oldest first
+func (s *Store) GetWebhooks() ([]*Webhook, error) {
+	rows, err := s.db.Query(`
+		SELECT id, url, secret, COALESCE(events, '[]'), created_at, last_delivery_at, failure_count
+		FROM webhooks
+		ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*Webhook{}
+	for rows.Next() {
+		w := &Webhook{}
+		var events string
+		var lastDeliveryAt sql.NullTime
+		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, &events, &w.CreatedAt, &lastDeliveryAt, &w.FailureCount); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(events), &w.Events); err != nil {
+			return nil, fmt.Errorf("failed to decode events of webhook %s: %w", w.ID, err)
+		}
+		if lastDeliveryAt.Valid {
+			w.LastDeliveryAt = &lastDeliveryAt.Time
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// RecordWebhookDelivery records the outcome of delivering an event to a
+// webhook. A success sets its last delivery time and resets its failure
+// count; a failure increments the count. It returns sql.ErrNoRows if the
+// webhook does not exist.
+func (s *Store) RecordWebhookDelivery(id string, success bool) error {
+	var result sql.Result
+	var err error
+	if success {
+		result, err = s.db.Exec(
+			"UPDATE webhooks SET last_delivery_at = ?, failure_count = 0 WHERE id = ?",
+			time.Now().UTC(), id,
+		)
+	} else {
+		result, err = s.db.Exec("UPDATE webhooks SET failure_count = failure_count + 1 WHERE id = ?", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	return requireRowsAffected(result)
+}
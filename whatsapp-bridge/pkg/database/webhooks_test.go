@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWebhooks(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	all := &Webhook{URL: "https://example.com/all", Secret: "s1"}
+	messages := &Webhook{URL: "https://example.com/messages", Secret: "s2", Events: []string{"new_message"}}
+	for _, w := range []*Webhook{all, messages} {
+		if err := store.RegisterWebhook(w); err != nil {
+			t.Fatalf("Failed to register webhook: %v", err)
+		}
+		if w.ID == "" {
+			t.Fatal("Expected a generated ID")
+		}
+	}
+
+	webhooks, err := store.GetWebhooks()
+	if err != nil {
+		t.Fatalf("Failed to get webhooks: %v", err)
+	}
+	if len(webhooks) != 2 {
+		t.Fatalf("Expected 2 webhooks, got %d", len(webhooks))
+	}
+	byID := map[string]*Webhook{}
+	for _, w := range webhooks {
+		byID[w.ID] = w
+	}
+	got := byID[messages.ID]
+	if got == nil || got.URL != messages.URL || got.Secret != "s2" || !slices.Equal(got.Events, []string{"new_message"}) {
+		t.Errorf("Expected the stored webhook, got %+v", got)
+	}
+	if !byID[all.ID].Subscribes("new_message") || got.Subscribes("other") {
+		t.Error("Expected only matching or unfiltered webhooks to subscribe")
+	}
+
+	if err := store.RecordWebhookDelivery(messages.ID, false); err != nil {
+		t.Fatalf("Failed to record delivery: %v", err)
+	}
+	if err := store.RecordWebhookDelivery(messages.ID, false); err != nil {
+		t.Fatalf("Failed to record delivery: %v", err)
+	}
+	webhooks, _ = store.GetWebhooks()
+	for _, w := range webhooks {
+		if w.ID == messages.ID && (w.FailureCount != 2 || w.LastDeliveryAt != nil) {
+			t.Errorf("Expected 2 failures and no delivery, got %d and %v", w.FailureCount, w.LastDeliveryAt)
+		}
+	}
+
+	if err := store.RecordWebhookDelivery(messages.ID, true); err != nil {
+		t.Fatalf("Failed to record delivery: %v", err)
+	}
+	webhooks, _ = store.GetWebhooks()
+	for _, w := range webhooks {
+		if w.ID == messages.ID && (w.FailureCount != 0 || w.LastDeliveryAt == nil) {
+			t.Errorf("Expected a delivery to reset failures, got %d and %v", w.FailureCount, w.LastDeliveryAt)
+		}
+	}
+
+	if err := store.DeleteWebhook(all.ID); err != nil {
+		t.Fatalf("Failed to delete webhook: %v", err)
+	}
+	if err := store.DeleteWebhook(all.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows deleting twice, got %v", err)
+	}
+	if err := store.RecordWebhookDelivery(all.ID, true); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a deleted webhook, got %v", err)
+	}
+	if webhooks, _ := store.GetWebhooks(); len(webhooks) != 1 {
+		t.Errorf("Expected 1 webhook left, got %d", len(webhooks))
+	}
+}
@@ -0,0 +1,189 @@
+// Package janitor periodically removes stale data from the message store.
+package janitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsapp-client/pkg/database"
+)
+
+// DefaultCleanupInterval is how often expired statuses and old soft-deleted
+// messages are removed
+const DefaultCleanupInterval = time.Hour
+
+// Names of the janitor's operations as reported by Stats
+const (
+	OpStatuses      = "statuses"
+	OpSoftDeleted   = "soft_deleted_messages"
+	OpWALCheckpoint = "wal_checkpoint"
+	OpVacuum        = "vacuum"
+)
+
+// JanitorConfig controls what the janitor removes and how often. A zero
+// value disables the corresponding operation, except that expired statuses
+// are always removed.
+type JanitorConfig struct {
+	// StatusTTL also removes statuses posted longer ago than this, even if
+	// they have not reached their expiry
+	StatusTTL time.Duration
+	// SoftDeleteRetentionDays is how long a soft-deleted message is kept
+	// before it is removed for good
+	SoftDeleteRetentionDays int
+	VacuumInterval          time.Duration
+	// WALCheckpointInterval defaults to the store's, set with
+	// database.WithWALCheckpointInterval
+	WALCheckpointInterval time.Duration
+	// Logger receives a line for every run of an operation; nil discards them
+	Logger waLog.Logger
+}
+
+// OperationStats describes the runs of one janitor operation
+type OperationStats struct {
+	Runs         int           `json:"runs"`
+	LastRun      time.Time     `json:"last_run"`
+	LastDuration time.Duration `json:"last_duration_ns"`
+	LastRemoved  int64         `json:"last_removed"`
+	TotalRemoved int64         `json:"total_removed"`
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+// Janitor removes expired statuses and old soft-deleted messages, and
+// checkpoints and vacuums the database on schedule
+type Janitor struct {
+	store    *database.Store
+	cfg      JanitorConfig
+	logger   waLog.Logger
+	interval time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*OperationStats
+}
+
+// NewJanitor creates a janitor that cleans up every DefaultCleanupInterval
+func NewJanitor(store *database.Store, cfg JanitorConfig) *Janitor {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = waLog.Noop
+	}
+
+	if cfg.WALCheckpointInterval == 0 {
+		cfg.WALCheckpointInterval = store.WALCheckpointInterval()
+	}
+
+	return &Janitor{
+		store:    store,
+		cfg:      cfg,
+		logger:   logger,
+		interval: DefaultCleanupInterval,
+		stats:    make(map[string]*OperationStats),
+	}
+}
+
+// Start cleans up immediately and then runs each operation on its schedule
+// until ctx is cancelled. It blocks, so callers usually run it in a goroutine.
+func (j *Janitor) Start(ctx context.Context) {
+	cleanup := time.NewTicker(j.interval)
+	defer cleanup.Stop()
+	checkpoint, stopCheckpoint := tick(j.cfg.WALCheckpointInterval)
+	defer stopCheckpoint()
+	vacuum, stopVacuum := tick(j.cfg.VacuumInterval)
+	defer stopVacuum()
+
+	j.cleanup(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-cleanup.C:
+			j.cleanup(time.Now())
+		case <-checkpoint:
+			j.run(OpWALCheckpoint, func() (int64, error) {
+				return 0, j.store.CheckpointWAL()
+			})
+		case <-vacuum:
+			j.run(OpVacuum, func() (int64, error) {
+				return 0, j.store.Vacuum()
+			})
+		}
+	}
+}
+
+// Stats returns a snapshot of the runs of each operation so far, keyed by
+// operation name
+func (j *Janitor) Stats() map[string]OperationStats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stats := make(map[string]OperationStats, len(j.stats))
+	for op, s := range j.stats {
+		stats[op] = *s
+	}
+	return stats
+}
+
+// cleanup removes expired statuses and soft-deleted messages past retention
+func (j *Janitor) cleanup(now time.Time) {
+	j.run(OpStatuses, func() (int64, error) {
+		removed, err := j.store.DeleteExpiredStatuses()
+		if err != nil || j.cfg.StatusTTL <= 0 {
+			return removed, err
+		}
+		old, err := j.store.DeleteStatusesBefore(now.Add(-j.cfg.StatusTTL))
+		return removed + old, err
+	})
+
+	if j.cfg.SoftDeleteRetentionDays > 0 {
+		j.run(OpSoftDeleted, func() (int64, error) {
+			return j.store.PurgeDeletedMessages(now.AddDate(0, 0, -j.cfg.SoftDeleteRetentionDays))
+		})
+	}
+}
+
+// run executes one operation, logs its outcome and records it in the stats
+func (j *Janitor) run(op string, fn func() (int64, error)) {
+	start := time.Now()
+	removed, err := fn()
+	duration := time.Since(start)
+
+	switch {
+	case errors.Is(err, database.ErrMaintenanceInProgress):
+		j.logger.Infof("Janitor skipped %s: %v", op, err)
+	case err != nil:
+		j.logger.Warnf("Janitor %s failed after %v: %v", op, duration, err)
+	default:
+		j.logger.Infof("Janitor %s removed %d rows in %v", op, removed, duration)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	s, ok := j.stats[op]
+	if !ok {
+		s = &OperationStats{}
+		j.stats[op] = s
+	}
+	s.Runs++
+	s.LastRun = start
+	s.LastDuration = duration
+	s.LastRemoved = removed
+	s.TotalRemoved += removed
+	s.LastError = ""
+	if err != nil {
+		s.LastError = err.Error()
+	}
+}
+
+// tick returns a channel that fires every d, or a nil channel that never
+// fires when d is not positive, and a function releasing the ticker
+func tick(d time.Duration) (<-chan time.Time, func()) {
+	if d <= 0 {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(d)
+	return ticker.C, ticker.Stop
+}
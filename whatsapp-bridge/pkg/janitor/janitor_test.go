@@ -0,0 +1,115 @@
+package janitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"whatsapp-client/pkg/database"
+)
+
+func TestCleanup(t *testing.T) {
+	dir := t.TempDir()
+	store, err := database.NewStore(dir+"/test.db", dir)
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	author := "111111111@s.whatsapp.net"
+	statuses := []*database.StatusMessage{
+		{ID: "expired", AuthorJID: author, Content: "a", Timestamp: now.Add(-25 * time.Hour)},
+		{ID: "past-ttl", AuthorJID: author, Content: "b", Timestamp: now.Add(-13 * time.Hour)},
+		{ID: "fresh", AuthorJID: author, Content: "c", Timestamp: now.Add(-time.Hour)},
+	}
+	for _, st := range statuses {
+		if err := store.StoreStatus(st); err != nil {
+			t.Fatalf("Failed to store status: %v", err)
+		}
+	}
+
+	chat := &database.Chat{JID: "111111111@s.whatsapp.net", Name: "Alice", LastMessageTime: now}
+	store.StoreChat(chat)
+	for _, id := range []string{"old", "recent", "kept"} {
+		err := store.StoreMessage(&database.Message{ID: id, ChatJID: chat.JID, Sender: "111111111", Content: id, Timestamp: now})
+		if err != nil {
+			t.Fatalf("Failed to store message: %v", err)
+		}
+	}
+	store.SoftDeleteMessage("old", chat.JID, now.AddDate(0, 0, -31))
+	store.SoftDeleteMessage("recent", chat.JID, now.AddDate(0, 0, -1))
+
+	j := NewJanitor(store, JanitorConfig{StatusTTL: 12 * time.Hour, SoftDeleteRetentionDays: 30})
+	j.cleanup(now)
+
+	remaining, err := store.GetStatuses(10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get statuses: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != "fresh" {
+		t.Errorf("Expected only the fresh status to remain, got %v", remaining)
+	}
+
+	page, err := store.GetMessagesPage(chat.JID, 10, database.Cursor{}, database.MessageOptions{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(page.Items) != 2 {
+		t.Errorf("Expected the old soft-deleted message to be purged, got %d messages", len(page.Items))
+	}
+
+	stats := j.Stats()
+	if s := stats[OpStatuses]; s.Runs != 1 || s.LastRemoved != 2 || s.LastError != "" {
+		t.Errorf("Unexpected status stats: %+v", s)
+	}
+	if s := stats[OpSoftDeleted]; s.Runs != 1 || s.TotalRemoved != 1 {
+		t.Errorf("Unexpected soft delete stats: %+v", s)
+	}
+}
+
+func TestStartRunsScheduledOperations(t *testing.T) {
+	dir := t.TempDir()
+	store, err := database.NewStore(dir+"/test.db", dir)
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer store.Close()
+
+	j := NewJanitor(store, JanitorConfig{WALCheckpointInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	j.Start(ctx)
+
+	stats := j.Stats()
+	if stats[OpStatuses].Runs != 1 {
+		t.Errorf("Expected one cleanup on start, got %d", stats[OpStatuses].Runs)
+	}
+	if stats[OpWALCheckpoint].Runs == 0 {
+		t.Errorf("Expected WAL checkpoints to run")
+	}
+	if _, ok := stats[OpVacuum]; ok {
+		t.Errorf("Expected vacuum to be disabled")
+	}
+	if _, ok := stats[OpSoftDeleted]; ok {
+		t.Errorf("Expected soft delete purge to be disabled")
+	}
+}
+
+func TestStartUsesStoreCheckpointInterval(t *testing.T) {
+	dir := t.TempDir()
+	store, err := database.NewStore(dir+"/test.db", dir, database.WithWALCheckpointInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer store.Close()
+
+	j := NewJanitor(store, JanitorConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	j.Start(ctx)
+
+	if runs := j.Stats()[OpWALCheckpoint].Runs; runs == 0 {
+		t.Errorf("Expected WAL checkpoints to run on the store's schedule")
+	}
+}
@@ -0,0 +1,120 @@
+// Package jobs sends messages accepted by the API in the background, so
+// slow uploads do not hold an HTTP request open.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsapp-client/pkg/database"
+)
+
+// DefaultQueueSize is how many jobs may wait for a free worker
+const DefaultQueueSize = 100
+
+// ErrQueueFull is returned by Enqueue when no more jobs can wait
+var ErrQueueFull = errors.New("job queue is full")
+
+// SendFunc delivers a message with an optional media file attached. A
+// non-nil error marks the job as failed.
+type SendFunc func(recipient, message, mediaPath string) error
+
+// Request is a message to send in the background
+type Request struct {
+	Recipient string
+	Message   string
+	MediaPath string
+}
+
+// queued is a request waiting for a worker along with its job ID
+type queued struct {
+	jobID string
+	req   Request
+}
+
+// Queue hands requests to a fixed pool of workers and records each job's
+// progress in the store
+type Queue struct {
+	store   *database.Store
+	send    SendFunc
+	logger  waLog.Logger
+	workers int
+	pending chan queued
+}
+
+// NewQueue creates a queue served by the given number of workers, at least
+// one, with room for DefaultQueueSize waiting jobs
+func NewQueue(store *database.Store, send SendFunc, workers int, logger waLog.Logger) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Queue{
+		store:   store,
+		send:    send,
+		logger:  logger,
+		workers: workers,
+		pending: make(chan queued, DefaultQueueSize),
+	}
+}
+
+// Enqueue records a queued job for req and hands it to the workers. If the
+// queue is full the job is marked failed and ErrQueueFull is returned.
+func (q *Queue) Enqueue(req Request) (*database.Job, error) {
+	job := &database.Job{}
+	if err := q.store.CreateJob(job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	select {
+	case q.pending <- queued{jobID: job.ID, req: req}:
+		return job, nil
+	default:
+		if err := q.store.UpdateJobStatus(job.ID, database.JobStatusFailed, ErrQueueFull.Error(), time.Now()); err != nil {
+			q.logger.Errorf("Failed to mark job %s as failed: %v", job.ID, err)
+		}
+		return nil, ErrQueueFull
+	}
+}
+
+// Start runs the workers until ctx is cancelled. Jobs still waiting then
+// stay queued. It blocks, so callers usually run it in a goroutine.
+func (q *Queue) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item := <-q.pending:
+					q.run(item)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// run sends one queued request and records the outcome
+func (q *Queue) run(item queued) {
+	if err := q.store.UpdateJobStatus(item.jobID, database.JobStatusRunning, "", time.Now()); err != nil {
+		q.logger.Errorf("Failed to mark job %s as running: %v", item.jobID, err)
+	}
+
+	status, jobErr := database.JobStatusCompleted, ""
+	if err := q.send(item.req.Recipient, item.req.Message, item.req.MediaPath); err != nil {
+		q.logger.Warnf("Failed to send job %s to %s: %v", item.jobID, item.req.Recipient, err)
+		status, jobErr = database.JobStatusFailed, err.Error()
+	}
+
+	if err := q.store.UpdateJobStatus(item.jobID, status, jobErr, time.Now()); err != nil {
+		q.logger.Errorf("Failed to mark job %s as %s: %v", item.jobID, status, err)
+	}
+}
@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsapp-client/pkg/database"
+)
+
+func newTestStore(t *testing.T) *database.Store {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := database.NewStore(dir+"/test.db", dir)
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestQueue(t *testing.T) {
+	store := newTestStore(t)
+	send := func(recipient, message, mediaPath string) error {
+		if message == "fail" {
+			return errors.New("send failed")
+		}
+		return nil
+	}
+	q := NewQueue(store, send, 2, waLog.Noop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Start(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	ok, err := q.Enqueue(Request{Recipient: "15551234567", Message: "ok"})
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+	failed, err := q.Enqueue(Request{Recipient: "15551234567", Message: "fail"})
+	if err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	for id, want := range map[string]database.JobStatus{ok.ID: database.JobStatusCompleted, failed.ID: database.JobStatusFailed} {
+		job := waitForJob(t, store, id)
+		if job.Status != want {
+			t.Errorf("Expected job %s to be %s, got %+v", id, want, job)
+		}
+	}
+	if job := waitForJob(t, store, failed.ID); job.Error != "send failed" {
+		t.Errorf("Expected the send error to be recorded, got %q", job.Error)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	store := newTestStore(t)
+	q := NewQueue(store, func(recipient, message, mediaPath string) error { return nil }, 1, waLog.Noop)
+
+	// Without running workers nothing leaves the queue
+	for i := 0; i < DefaultQueueSize; i++ {
+		if _, err := q.Enqueue(Request{Recipient: "15551234567", Message: "hi"}); err != nil {
+			t.Fatalf("Failed to enqueue job %d: %v", i, err)
+		}
+	}
+	if _, err := q.Enqueue(Request{Recipient: "15551234567", Message: "hi"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+}
+
+// waitForJob polls the store until the job has finished
+func waitForJob(t *testing.T, store *database.Store, id string) *database.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := store.GetJob(id)
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if job.Status.IsFinal() || time.Now().After(deadline) {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,25 @@
+// Package logger builds the structured logger used for HTTP request logs.
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// NewLogger returns a logger writing to stdout at the given level (debug,
+// info, warn or error). The format is "json" for one JSON object per line;
+// anything else, including empty, selects the human-readable text format.
+func NewLogger(level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	if strings.EqualFold(format, "json") {
+		return slog.New(slog.NewJSONHandler(os.Stdout, opts)), nil
+	}
+	return slog.New(slog.NewTextHandler(os.Stdout, opts)), nil
+}
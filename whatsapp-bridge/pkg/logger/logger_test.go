@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		level    string
+		format   string
+		want     slog.Level
+		wantJSON bool
+		wantErr  bool
+	}{
+		{level: "debug", want: slog.LevelDebug},
+		{level: "info", format: "json", want: slog.LevelInfo, wantJSON: true},
+		{level: "WARN", format: "JSON", want: slog.LevelWarn, wantJSON: true},
+		{level: "error", format: "text", want: slog.LevelError},
+		{level: "verbose", wantErr: true},
+		{level: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level+"/"+tt.format, func(t *testing.T) {
+			logger, err := NewLogger(tt.level, tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			ctx := context.Background()
+			if !logger.Enabled(ctx, tt.want) || logger.Enabled(ctx, tt.want-1) {
+				t.Errorf("Expected minimum level %v", tt.want)
+			}
+			if _, isJSON := logger.Handler().(*slog.JSONHandler); isJSON != tt.wantJSON {
+				t.Errorf("Expected JSON handler %v, got %T", tt.wantJSON, logger.Handler())
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package media encrypts and decrypts WhatsApp media attachments.
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HKDF info strings WhatsApp derives media keys with; each kind of
+// attachment uses its own
+const (
+	ImageKeys    = "WhatsApp Image Keys"
+	VideoKeys    = "WhatsApp Video Keys"
+	AudioKeys    = "WhatsApp Audio Keys"
+	DocumentKeys = "WhatsApp Document Keys"
+)
+
+// mediaKeyLength is the size of the key sent along with each attachment
+const mediaKeyLength = 32
+
+// macLength is the size of the truncated HMAC appended to encrypted media
+const macLength = 10
+
+// fetchTimeout bounds a download of encrypted media
+const fetchTimeout = 2 * time.Minute
+
+// mediaDomain is the domain of WhatsApp's media servers, the only hosts
+// Fetch downloads from
+const mediaDomain = "whatsapp.net"
+
+// ErrMediaTooLarge is returned by Fetch when the server sends more than the
+// attachment can be
+var ErrMediaTooLarge = errors.New("media is larger than expected")
+
+// Errors returned when encrypted media fails an integrity check
+var (
+	ErrInvalidEncSHA256 = errors.New("encrypted media does not match its SHA-256")
+	ErrInvalidMAC       = errors.New("media HMAC does not match")
+	ErrInvalidSHA256    = errors.New("decrypted media does not match its SHA-256")
+)
+
+// infos lists the key infos DecryptMedia tries, most common first
+var infos = []string{ImageKeys, VideoKeys, AudioKeys, DocumentKeys}
+
+var httpClient = &http.Client{Timeout: fetchTimeout}
+
+// DecryptMedia decrypts an attachment as downloaded from WhatsApp, checking
+// it against the hashes of the encrypted and decrypted file. The kind of
+// attachment is not needed: the key derived for each kind is tried until
+// one matches the attachment's HMAC.
+func DecryptMedia(encData, mediaKey, fileSHA256, fileEncSHA256 []byte) ([]byte, error) {
+	if len(mediaKey) != mediaKeyLength {
+		return nil, fmt.Errorf("media key must be %d bytes, got %d", mediaKeyLength, len(mediaKey))
+	}
+	if len(encData) <= macLength {
+		return nil, fmt.Errorf("encrypted media is too short: %d bytes", len(encData))
+	}
+	if sum := sha256.Sum256(encData); !bytes.Equal(sum[:], fileEncSHA256) {
+		return nil, ErrInvalidEncSHA256
+	}
+
+	ciphertext, mac := encData[:len(encData)-macLength], encData[len(encData)-macLength:]
+	for _, info := range infos {
+		iv, cipherKey, macKey, err := deriveKeys(mediaKey, info)
+		if err != nil {
+			return nil, err
+		}
+		if !hmac.Equal(mediaMAC(macKey, iv, ciphertext), mac) {
+			continue
+		}
+
+		plaintext, err := decryptCBC(cipherKey, iv, ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		if sum := sha256.Sum256(plaintext); !bytes.Equal(sum[:], fileSHA256) {
+			return nil, ErrInvalidSHA256
+		}
+		return plaintext, nil
+	}
+
+	return nil, ErrInvalidMAC
+}
+
+// EncryptMedia encrypts an attachment the way WhatsApp expects it, using
+// info to derive the keys. It returns the encrypted file together with the
+// hashes DecryptMedia checks.
+func EncryptMedia(plaintext, mediaKey []byte, info string) (encData, fileSHA256, fileEncSHA256 []byte, err error) {
+	if len(mediaKey) != mediaKeyLength {
+		return nil, nil, nil, fmt.Errorf("media key must be %d bytes, got %d", mediaKeyLength, len(mediaKey))
+	}
+	iv, cipherKey, macKey, err := deriveKeys(mediaKey, info)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	ciphertext := append(bytes.Clone(plaintext), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+
+	encData = append(ciphertext, mediaMAC(macKey, iv, ciphertext)...)
+	plainSum, encSum := sha256.Sum256(plaintext), sha256.Sum256(encData)
+	return encData, plainSum[:], encSum[:], nil
+}
+
+// NewMediaKey returns a random key for EncryptMedia
+func NewMediaKey() ([]byte, error) {
+	key := make([]byte, mediaKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// MaxEncryptedSize is the largest an encrypted attachment of fileLength
+// plaintext bytes can be: padding adds up to one block, then the HMAC follows
+func MaxEncryptedSize(fileLength uint64) int64 {
+	return int64(fileLength) + aes.BlockSize + macLength
+}
+
+// Fetch downloads encrypted media of at most maxBytes from url. The URL
+// comes from the sender's message, so only https URLs on WhatsApp's media
+// servers are fetched; anything else could make the bridge request
+// addresses on its own network.
+func Fetch(ctx context.Context, rawURL string, maxBytes int64) ([]byte, error) {
+	if err := checkMediaURL(rawURL); err != nil {
+		return nil, err
+	}
+	return fetch(ctx, rawURL, maxBytes)
+}
+
+// checkMediaURL reports an error unless rawURL is an https URL on a
+// WhatsApp media server
+func checkMediaURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid media URL: %w", err)
+	}
+	host := u.Hostname()
+	if u.Scheme != "https" || (host != mediaDomain && !strings.HasSuffix(host, "."+mediaDomain)) {
+		return fmt.Errorf("media URL %q is not an https URL on %s", u.Redacted(), mediaDomain)
+	}
+	return nil
+}
+
+// fetch downloads at most maxBytes from url without checking where it
+// points
+func fetch(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	// Read one byte past the limit to tell a full file from an oversized one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, ErrMediaTooLarge
+	}
+	return data, nil
+}
+
+// deriveKeys expands a media key into the IV, AES key and HMAC key of an
+// attachment
+func deriveKeys(mediaKey []byte, info string) (iv, cipherKey, macKey []byte, err error) {
+	expanded, err := hkdf.Key(sha256.New, mediaKey, nil, info, 112)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return expanded[:16], expanded[16:48], expanded[48:80], nil
+}
+
+// mediaMAC computes the truncated HMAC WhatsApp appends to encrypted media
+func mediaMAC(macKey, iv, ciphertext []byte) []byte {
+	h := hmac.New(sha256.New, macKey)
+	h.Write(iv)
+	h.Write(ciphertext)
+	return h.Sum(nil)[:macLength]
+}
+
+// decryptCBC decrypts AES-CBC ciphertext and strips its PKCS#7 padding
+func decryptCBC(cipherKey, iv, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext is not a whole number of blocks")
+	}
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
@@ -0,0 +1,137 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDecryptMedia(t *testing.T) {
+	key, err := NewMediaKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	for _, info := range []string{ImageKeys, VideoKeys, AudioKeys, DocumentKeys} {
+		for _, size := range []int{0, 15, 16, 1000} {
+			plaintext := bytes.Repeat([]byte{0xab}, size)
+			enc, sum, encSum, err := EncryptMedia(plaintext, key, info)
+			if err != nil {
+				t.Fatalf("%s/%d: failed to encrypt: %v", info, size, err)
+			}
+			got, err := DecryptMedia(enc, key, sum, encSum)
+			if err != nil {
+				t.Fatalf("%s/%d: failed to decrypt: %v", info, size, err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("%s/%d: decrypted media does not match", info, size)
+			}
+		}
+	}
+}
+
+func TestDecryptMediaIntegrity(t *testing.T) {
+	key, _ := NewMediaKey()
+	otherKey, _ := NewMediaKey()
+	enc, sum, encSum, err := EncryptMedia([]byte("a photo"), key, ImageKeys)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	tampered := bytes.Clone(enc)
+	tampered[0] ^= 1
+
+	tests := []struct {
+		name    string
+		enc     []byte
+		key     []byte
+		sum     []byte
+		encSum  []byte
+		wantErr error
+	}{
+		{"tampered file", tampered, key, sum, encSum, ErrInvalidEncSHA256},
+		{"wrong key", enc, otherKey, sum, encSum, ErrInvalidMAC},
+		{"wrong plaintext hash", enc, key, encSum, encSum, ErrInvalidSHA256},
+	}
+	for _, tt := range tests {
+		if _, err := DecryptMedia(tt.enc, tt.key, tt.sum, tt.encSum); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	if _, err := DecryptMedia(enc, key[:16], sum, encSum); err == nil {
+		t.Error("Expected an error for a short media key")
+	}
+	if _, err := DecryptMedia(enc[:macLength], key, sum, encSum); err == nil {
+		t.Error("Expected an error for truncated media")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/media.enc" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("encrypted"))
+	}))
+	defer server.Close()
+
+	data, err := fetch(context.Background(), server.URL+"/media.enc", 9)
+	if err != nil || string(data) != "encrypted" {
+		t.Fatalf("Expected the file contents, got %q (%v)", data, err)
+	}
+	if _, err := fetch(context.Background(), server.URL+"/expired.enc", 9); err == nil {
+		t.Error("Expected an error for a 404")
+	}
+	if _, err := fetch(context.Background(), server.URL+"/media.enc", 8); !errors.Is(err, ErrMediaTooLarge) {
+		t.Errorf("Expected ErrMediaTooLarge for an oversized body, got %v", err)
+	}
+}
+
+func TestFetchRejectsOtherHosts(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	for _, url := range []string{
+		server.URL + "/media.enc",
+		"http://mmg.whatsapp.net/d/f/media.enc",
+		"https://127.0.0.1/media.enc",
+		"https://169.254.169.254/latest/meta-data",
+		"https://evilwhatsapp.net/media.enc",
+		"https://mmg.whatsapp.net.evil.com/media.enc",
+		"file:///etc/passwd",
+	} {
+		if _, err := Fetch(context.Background(), url, 1<<20); err == nil {
+			t.Errorf("Expected %s to be rejected", url)
+		}
+	}
+	if requested {
+		t.Error("Expected no request to be made")
+	}
+
+	for _, url := range []string{"https://mmg.whatsapp.net/d/f/media.enc", "https://media-ams4-1.cdn.whatsapp.net/v/t62/media.enc"} {
+		if err := checkMediaURL(url); err != nil {
+			t.Errorf("Expected %s to be allowed: %v", url, err)
+		}
+	}
+}
+
+func TestMaxEncryptedSize(t *testing.T) {
+	key, _ := NewMediaKey()
+	for _, n := range []int{0, 1, 15, 16, 17, 1000} {
+		enc, _, _, err := EncryptMedia(make([]byte, n), key, ImageKeys)
+		if err != nil {
+			t.Fatalf("Failed to encrypt media: %v", err)
+		}
+		if max := MaxEncryptedSize(uint64(n)); int64(len(enc)) > max {
+			t.Errorf("Encrypted %d bytes to %d, more than MaxEncryptedSize %d", n, len(enc), max)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package metrics collects Prometheus metrics for the bridge.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the bridge's metrics in a registry of their own. A nil
+// *Metrics records nothing, so callers need not check whether metrics are
+// enabled.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	messagesStored prometheus.Counter
+	chatsStored    prometheus.Counter
+}
+
+// New creates and registers the bridge's metrics along with the Go runtime
+// and process collectors. dbStats reports the database connection pool; it
+// may be nil when there is no database to report on.
+func New(dbStats func() sql.DBStats) *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "whatsapp_http_requests_total",
+			Help: "HTTP requests served, by method, route and status code.",
+		}, []string{"method", "path", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "whatsapp_http_request_duration_seconds",
+			Help:    "HTTP request latency, by method and route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "path"}),
+		messagesStored: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "whatsapp_messages_stored_total",
+			Help: "Messages written to the message store.",
+		}),
+		chatsStored: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "whatsapp_chats_stored_total",
+			Help: "Chats written to the message store.",
+		}),
+	}
+
+	m.Registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.messagesStored,
+		m.chatsStored,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if dbStats != nil {
+		m.Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "whatsapp_db_open_connections",
+			Help: "Open connections to the message database.",
+		}, func() float64 {
+			return float64(dbStats().OpenConnections)
+		}))
+	}
+
+	return m
+}
+
+// ObserveRequest records a served HTTP request. The path should be the
+// route pattern rather than the raw URL path to keep label cardinality
+// bounded.
+func (m *Metrics) ObserveRequest(method, path string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(method, path, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(method, path).Observe(elapsed.Seconds())
+}
+
+// MessagesStored counts n messages written to the store
+func (m *Metrics) MessagesStored(n int) {
+	if m == nil {
+		return
+	}
+	m.messagesStored.Add(float64(n))
+}
+
+// ChatStored counts a chat written to the store
+func (m *Metrics) ChatStored() {
+	if m == nil {
+		return
+	}
+	m.chatsStored.Inc()
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
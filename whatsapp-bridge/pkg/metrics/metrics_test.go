@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New(func() sql.DBStats { return sql.DBStats{OpenConnections: 3} })
+	m.ObserveRequest(http.MethodGet, "/chats/{jid}", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/chats/{jid}", http.StatusOK, 30*time.Millisecond)
+	m.MessagesStored(5)
+	m.ChatStored()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`whatsapp_http_requests_total{method="GET",path="/chats/{jid}",status="200"} 2`,
+		`whatsapp_http_request_duration_seconds_count{method="GET",path="/chats/{jid}"} 2`,
+		`whatsapp_db_open_connections 3`,
+		`whatsapp_messages_stored_total 5`,
+		`whatsapp_chats_stored_total 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in the metrics output", want)
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveRequest(http.MethodGet, "/chats", http.StatusOK, time.Millisecond)
+	m.MessagesStored(1)
+	m.ChatStored()
+}
@@ -0,0 +1,261 @@
+// Package openapi describes the REST API as an OpenAPI 3.0 document.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+
+	"whatsapp-client/pkg/api"
+)
+
+// operation is a route of the API, with the body it reads and the type of
+// the data field of its success response, if either is a named type
+type operation struct {
+	method   string
+	path     string
+	summary  string
+	request  any
+	response any
+}
+
+// operations mirrors api.Handler.Routes
+var operations = []operation{
+	{http.MethodPost, "/admin/backup", "Back up the message database", api.BackupRequest{}, nil},
+	{http.MethodGet, "/admin/db_stats", "Get database statistics", nil, nil},
+	{http.MethodGet, "/admin/janitor_stats", "Get background cleanup statistics", nil, nil},
+	{http.MethodPost, "/admin/vacuum", "Vacuum the message database", nil, nil},
+
+	{http.MethodGet, "/broadcasts", "List broadcast lists", nil, nil},
+	{http.MethodGet, "/broadcasts/{jid}/recipients", "List the recipients of a broadcast list", nil, nil},
+
+	{http.MethodGet, "/calls", "List calls", nil, nil},
+	{http.MethodGet, "/calls/missed", "List missed calls", nil, nil},
+	{http.MethodGet, "/calls/{id}", "Get a call", nil, nil},
+
+	{http.MethodGet, "/chats", "List chats", nil, nil},
+	{http.MethodGet, "/chats/archived", "List archived chats", nil, nil},
+	{http.MethodGet, "/chats/muted", "List muted chats", nil, nil},
+	{http.MethodGet, "/chats/unread", "List chats with unread messages", nil, nil},
+	{http.MethodDelete, "/chats/{jid}", "Delete a chat", nil, nil},
+	{http.MethodDelete, "/chats/{jid}/messages", "Clear the messages of a chat", nil, nil},
+	{http.MethodPost, "/chats/{jid}/read", "Mark a chat read", nil, nil},
+	{http.MethodPut, "/chats/{jid}/read_position", "Set the read position of a chat", api.ReadPositionRequest{}, nil},
+	{http.MethodGet, "/chats/{jid}/media", "List the media messages of a chat", nil, api.MessagesResponse{}},
+	{http.MethodGet, "/chats/{jid}/stats", "Get chat statistics", nil, api.ChatStatsResponse{}},
+	{http.MethodPost, "/chats/{jid}/archive", "Archive a chat", nil, nil},
+	{http.MethodDelete, "/chats/{jid}/archive", "Unarchive a chat", nil, nil},
+	{http.MethodPost, "/chats/{jid}/mute", "Mute a chat", api.MuteChatRequest{}, nil},
+	{http.MethodDelete, "/chats/{jid}/mute", "Unmute a chat", nil, nil},
+	{http.MethodGet, "/chats/{jid}/pinned", "List the pinned messages of a chat", nil, nil},
+	{http.MethodPost, "/chats/{jid}/pinned", "Pin a message", api.PinMessageRequest{}, nil},
+	{http.MethodDelete, "/chats/{jid}/pinned/{msg_id}", "Unpin a message", nil, nil},
+	{http.MethodPost, "/chats/{jid}/labels/{label_id}", "Label a chat", nil, nil},
+	{http.MethodDelete, "/chats/{jid}/labels/{label_id}", "Remove a label from a chat", nil, nil},
+
+	{http.MethodGet, "/contacts", "List contacts", nil, nil},
+	{http.MethodGet, "/contacts/blocked", "List blocked contacts", nil, nil},
+	{http.MethodGet, "/contacts/search", "Search contacts", nil, nil},
+	{http.MethodGet, "/contacts/by_phone", "Look up a contact by phone number", nil, nil},
+	{http.MethodGet, "/contacts/{jid}", "Get a contact", nil, nil},
+	{http.MethodPost, "/contacts/{jid}/block", "Block a contact", nil, nil},
+	{http.MethodDelete, "/contacts/{jid}/block", "Unblock a contact", nil, nil},
+	{http.MethodGet, "/contacts/{jid}/mentions", "List messages mentioning a contact", nil, nil},
+	{http.MethodGet, "/contacts/{jid}/profile_picture", "Get the profile picture of a contact", nil, nil},
+	{http.MethodGet, "/contacts/{jid}/statuses", "List the status updates of a contact", nil, nil},
+
+	{http.MethodGet, "/groups", "List groups", nil, nil},
+	{http.MethodPost, "/groups", "Create a group", api.CreateGroupRequest{}, api.GroupInfoResponse{}},
+	{http.MethodGet, "/groups/{jid}", "Get a group", nil, api.GroupInfoResponse{}},
+	{http.MethodGet, "/groups/{jid}/participants", "List the participants of a group", nil, nil},
+	{http.MethodPost, "/groups/{jid}/participants", "Add a participant to a group", api.AddParticipantRequest{}, nil},
+	{http.MethodDelete, "/groups/{jid}/participants/{participant_jid}", "Remove a participant from a group", nil, nil},
+
+	{http.MethodGet, "/jobs/{id}", "Get a background send job", nil, nil},
+
+	{http.MethodGet, "/labels", "List labels", nil, nil},
+	{http.MethodPost, "/labels", "Create a label", api.LabelRequest{}, nil},
+	{http.MethodGet, "/labels/{id}", "Get a label", nil, nil},
+	{http.MethodPut, "/labels/{id}", "Update a label", api.LabelRequest{}, nil},
+	{http.MethodDelete, "/labels/{id}", "Delete a label", nil, nil},
+
+	{http.MethodPost, "/media/download", "Download and decrypt the media of a message", api.DownloadMediaRequest{}, api.DownloadMediaResponse{}},
+
+	{http.MethodGet, "/messages", "List messages", nil, nil},
+	{http.MethodPost, "/messages/async", "Queue a message to send in the background", api.SendMessageRequest{}, api.AsyncSendResponse{}},
+	{http.MethodPost, "/messages/bulk", "Send a message to several recipients", api.BulkSendRequest{}, api.BulkSendResponse{}},
+	{http.MethodPost, "/messages/image", "Send an image", api.SendImageRequest{}, nil},
+	{http.MethodPost, "/messages/audio", "Send audio", api.SendAudioRequest{}, nil},
+	{http.MethodPost, "/messages/document", "Send a document", api.SendDocumentRequest{}, nil},
+	{http.MethodPost, "/messages/contact", "Send a contact card", api.SendContactRequest{}, nil},
+	{http.MethodPost, "/messages/location", "Send a location", api.SendLocationRequest{}, nil},
+	{http.MethodPost, "/messages/reaction", "React to a message", api.SendReactionRequest{}, nil},
+	{http.MethodDelete, "/messages/reaction", "Remove a reaction", api.DeleteReactionRequest{}, nil},
+	{http.MethodGet, "/messages/nearby", "List messages with a location near a point", nil, api.MessagesResponse{}},
+	{http.MethodGet, "/messages/locations/nearby", "List locations shared near a point", nil, api.LocationsResponse{}},
+	{http.MethodGet, "/messages/search", "Search messages", nil, nil},
+	{http.MethodPost, "/messages/schedule", "Schedule a message", api.ScheduleMessageRequest{}, nil},
+	{http.MethodGet, "/messages/scheduled", "List scheduled messages", nil, nil},
+	{http.MethodGet, "/messages/{id}/thread", "Get the reply thread of a message", nil, nil},
+	{http.MethodGet, "/messages/{id}/history", "Get the edit history of a message", nil, nil},
+	{http.MethodGet, "/messages/{id}/media_metadata", "Get the metadata of a message's attachment", nil, nil},
+	{http.MethodPut, "/messages/{id}/status", "Update the delivery status of a message", api.UpdateMessageStatusRequest{}, nil},
+	{http.MethodPut, "/messages/{id}", "Edit a message", api.EditMessageRequest{}, nil},
+	{http.MethodDelete, "/messages/{id}", "Delete a message for everyone", nil, nil},
+	{http.MethodGet, "/messages/{id}/poll", "Get a poll and its votes", nil, nil},
+	{http.MethodGet, "/messages/{id}/reactions", "List the reactions to a message", nil, nil},
+	{http.MethodPost, "/messages/{id}/reactions", "Record a reaction to a message", api.ReactionRequest{}, nil},
+
+	{http.MethodGet, "/statuses", "List status updates", nil, nil},
+
+	{http.MethodGet, "/webhooks", "List webhooks", nil, nil},
+	{http.MethodPost, "/webhooks", "Register a webhook", api.RegisterWebhookRequest{}, api.RegisterWebhookResponse{}},
+	{http.MethodDelete, "/webhooks/{id}", "Delete a webhook", nil, nil},
+}
+
+// extraSchemas are request types no route in operations reads as a body
+// but that clients may still want to generate code for
+var extraSchemas = []any{api.SendFileRequest{}}
+
+// required lists the fields each request's validation rejects when they
+// are missing. Fields of which one of several is required, such as the
+// image_base64 and image_path of an image, are not listed.
+var required = map[string][]string{
+	"AddParticipantRequest":      {"participant_jid"},
+	"BackupRequest":              {"dest_path"},
+	"BulkSendRequest":            {"recipients", "message"},
+	"CreateGroupRequest":         {"subject"},
+	"DeleteReactionRequest":      {"recipient", "message_id"},
+	"DownloadMediaRequest":       {"message_id", "chat_jid"},
+	"EditMessageRequest":         {"chat_jid", "new_content"},
+	"LabelRequest":               {"name"},
+	"MuteChatRequest":            {"until"},
+	"PinMessageRequest":          {"message_id"},
+	"ReactionRequest":            {"chat_jid", "sender"},
+	"ReadPositionRequest":        {"message_id"},
+	"RegisterWebhookRequest":     {"url"},
+	"ScheduleMessageRequest":     {"recipient", "scheduled_at"},
+	"SendAudioRequest":           {"recipient"},
+	"SendContactRequest":         {"recipient", "contact_jid"},
+	"SendDocumentRequest":        {"recipient", "file_path"},
+	"SendFileRequest":            {"recipient", "file_path"},
+	"SendImageRequest":           {"recipient"},
+	"SendLocationRequest":        {"recipient", "latitude", "longitude"},
+	"SendMessageRequest":         {"recipient"},
+	"SendReactionRequest":        {"recipient", "message_id", "emoji"},
+	"UpdateMessageStatusRequest": {"status"},
+}
+
+// pathParam matches a {name} wildcard of a route
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// GenerateSpec builds the OpenAPI document of the API from its request and
+// response types, as JSON. Paths are relative to the /api/v1 server.
+func GenerateSpec() ([]byte, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:   "WhatsApp Bridge API",
+			Version: "1",
+		},
+		Servers: openapi3.Servers{{URL: "/api/v1"}},
+		Paths:   openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{},
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"bearerAuth": &openapi3.SecuritySchemeRef{
+					Value: openapi3.NewJWTSecurityScheme().WithBearerFormat(""),
+				},
+			},
+		},
+		Security: openapi3.SecurityRequirements{{"bearerAuth": []string{}}},
+	}
+
+	if _, err := addSchema(doc, api.Response{}); err != nil {
+		return nil, err
+	}
+	for _, v := range extraSchemas {
+		if _, err := addSchema(doc, v); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, op := range operations {
+		o := &openapi3.Operation{
+			Summary:   op.summary,
+			Responses: openapi3.NewResponses(),
+		}
+
+		for _, match := range pathParam.FindAllStringSubmatch(op.path, -1) {
+			o.AddParameter(openapi3.NewPathParameter(match[1]).WithSchema(openapi3.NewStringSchema()))
+		}
+
+		if op.request != nil {
+			ref, err := addSchema(doc, op.request)
+			if err != nil {
+				return nil, err
+			}
+			o.RequestBody = &openapi3.RequestBodyRef{
+				Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(ref),
+			}
+		}
+
+		// Every response is a Response; a success response may narrow the
+		// type of its data
+		success := responseRef()
+		if op.response != nil {
+			ref, err := addSchema(doc, op.response)
+			if err != nil {
+				return nil, err
+			}
+			data := openapi3.NewObjectSchema().WithPropertyRef("data", ref)
+			success = &openapi3.SchemaRef{Value: &openapi3.Schema{AllOf: openapi3.SchemaRefs{responseRef(), data.NewRef()}}}
+		}
+		o.Responses.Set("200", &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().WithDescription("Success").WithJSONSchemaRef(success),
+		})
+		o.Responses.Set("default", &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().WithDescription("Error").WithJSONSchemaRef(responseRef()),
+		})
+
+		doc.AddOperation(op.path, op.method, o)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// addSchema adds the schema of v's type to the document's components and
+// returns a reference to it
+func addSchema(doc *openapi3.T, v any) (*openapi3.SchemaRef, error) {
+	name := reflect.TypeOf(v).Name()
+	ref := openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+	if _, ok := doc.Components.Schemas[name]; ok {
+		return ref, nil
+	}
+
+	schema, err := openapi3gen.NewSchemaRefForValue(v, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the %s schema: %w", name, err)
+	}
+	if fields, ok := required[name]; ok {
+		for _, field := range fields {
+			if _, ok := schema.Value.Properties[field]; !ok {
+				return nil, fmt.Errorf("%s has no required field %s", name, field)
+			}
+		}
+		schema.Value.Required = fields
+	}
+
+	doc.Components.Schemas[name] = schema
+	return ref, nil
+}
+
+// responseRef refers to the envelope every API response is wrapped in
+func responseRef() *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/Response", nil)
+}
@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestGenerateSpec(t *testing.T) {
+	data, err := GenerateSpec()
+	if err != nil {
+		t.Fatalf("Failed to generate spec: %v", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Spec is not valid JSON: %v", err)
+	}
+	if raw["openapi"] != "3.0.3" {
+		t.Errorf("Expected OpenAPI 3.0.3, got %v", raw["openapi"])
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("Spec is invalid: %v", err)
+	}
+
+	for _, op := range operations {
+		item := doc.Paths.Find(op.path)
+		if item == nil || item.GetOperation(op.method) == nil {
+			t.Errorf("Expected %s %s in the spec", op.method, op.path)
+		}
+	}
+
+	location := doc.Paths.Find("/messages/location").GetOperation(http.MethodPost)
+	schema := location.RequestBody.Value.Content.Get("application/json").Schema
+	if schema.Ref != "#/components/schemas/SendLocationRequest" {
+		t.Errorf("Expected the location request schema, got %q", schema.Ref)
+	}
+	request := doc.Components.Schemas["SendLocationRequest"].Value
+	if !slices.Equal(request.Required, []string{"recipient", "latitude", "longitude"}) {
+		t.Errorf("Expected recipient, latitude and longitude to be required, got %v", request.Required)
+	}
+	if _, ok := request.Properties["name"]; !ok {
+		t.Error("Expected the optional name property")
+	}
+
+	params := doc.Paths.Find("/chats/{jid}/pinned/{msg_id}").GetOperation(http.MethodDelete).Parameters
+	if len(params) != 2 || params[0].Value.Name != "jid" || params[1].Value.Name != "msg_id" {
+		t.Errorf("Expected the jid and msg_id path parameters, got %d", len(params))
+	}
+
+	for name := range required {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("Expected a %s schema", name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package scheduler dispatches scheduled messages once they fall due.
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsapp-client/pkg/database"
+)
+
+// DefaultInterval is how often the scheduler polls for due messages
+const DefaultInterval = 30 * time.Second
+
+// SendFunc delivers a scheduled message. A non-nil error marks the message
+// as failed.
+type SendFunc func(msg *database.ScheduledMessage) error
+
+// Scheduler polls the store for due messages and hands them to a SendFunc
+type Scheduler struct {
+	store    *database.Store
+	send     SendFunc
+	logger   waLog.Logger
+	interval time.Duration
+}
+
+// NewScheduler creates a scheduler that polls every DefaultInterval
+func NewScheduler(store *database.Store, send SendFunc, logger waLog.Logger) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		send:     send,
+		logger:   logger,
+		interval: DefaultInterval,
+	}
+}
+
+// Start dispatches due messages immediately and then on every tick until ctx
+// is cancelled. It blocks, so callers usually run it in a goroutine.
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDue(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchDue sends every message due at now and records the outcome
+func (s *Scheduler) dispatchDue(now time.Time) {
+	due, err := s.store.GetDueMessages(now)
+	if err != nil {
+		s.logger.Errorf("Failed to get due scheduled messages: %v", err)
+		return
+	}
+
+	for _, msg := range due {
+		if err := s.send(msg); err != nil {
+			s.logger.Warnf("Failed to send scheduled message %s to %s: %v", msg.ID, msg.Recipient, err)
+			if err := s.store.MarkScheduledFailed(msg.ID); err != nil {
+				s.logger.Errorf("Failed to mark scheduled message %s as failed: %v", msg.ID, err)
+			}
+			continue
+		}
+
+		if err := s.store.MarkScheduledSent(msg.ID, time.Now()); err != nil {
+			s.logger.Errorf("Failed to mark scheduled message %s as sent: %v", msg.ID, err)
+		}
+	}
+}
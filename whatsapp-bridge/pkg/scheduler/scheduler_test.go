@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsapp-client/pkg/database"
+)
+
+func TestDispatchDue(t *testing.T) {
+	dir := t.TempDir()
+	store, err := database.NewStore(dir+"/test.db", dir)
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	recipient := "111111111@s.whatsapp.net"
+	for _, content := range []string{"ok", "fail"} {
+		err := store.ScheduleMessage(&database.ScheduledMessage{
+			ID:          content,
+			Recipient:   recipient,
+			Content:     content,
+			ScheduledAt: now.Add(-time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("Failed to schedule message: %v", err)
+		}
+	}
+	err = store.ScheduleMessage(&database.ScheduledMessage{
+		ID:          "future",
+		Recipient:   recipient,
+		Content:     "future",
+		ScheduledAt: now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Failed to schedule message: %v", err)
+	}
+
+	var sent []string
+	send := func(msg *database.ScheduledMessage) error {
+		sent = append(sent, msg.ID)
+		if msg.Content == "fail" {
+			return errors.New("send failed")
+		}
+		return nil
+	}
+
+	s := NewScheduler(store, send, waLog.Noop)
+	s.dispatchDue(now)
+
+	if len(sent) != 2 {
+		t.Fatalf("Expected the two due messages to be sent, got %v", sent)
+	}
+
+	statuses := map[string]database.ScheduledStatus{}
+	all, err := store.GetScheduledMessages("", 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get scheduled messages: %v", err)
+	}
+	for _, msg := range all {
+		statuses[msg.ID] = msg.Status
+	}
+	want := map[string]database.ScheduledStatus{
+		"ok":     database.ScheduledStatusSent,
+		"fail":   database.ScheduledStatusFailed,
+		"future": database.ScheduledStatusPending,
+	}
+	for id, status := range want {
+		if statuses[id] != status {
+			t.Errorf("Expected %s to be %s, got %s", id, status, statuses[id])
+		}
+	}
+
+	// A second pass finds nothing left to send
+	s.dispatchDue(now)
+	if len(sent) != 2 {
+		t.Errorf("Expected no further sends, got %v", sent)
+	}
+}
+
+func TestStartStopsOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	store, err := database.NewStore(dir+"/test.db", dir)
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer store.Close()
+
+	s := NewScheduler(store, func(*database.ScheduledMessage) error { return nil }, waLog.Noop)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after the context was cancelled")
+	}
+}
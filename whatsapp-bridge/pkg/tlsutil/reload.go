@@ -0,0 +1,86 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// TLSConfigManager serves a certificate loaded from disk and loads it again
+// whenever the process receives SIGHUP, so a renewed certificate is picked
+// up without restarting the server. Use GetCertificate as
+// tls.Config.GetCertificate.
+type TLSConfigManager struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	signals chan os.Signal
+	done    chan struct{}
+}
+
+// NewTLSConfigManager loads the certificate and key and starts reloading
+// them on SIGHUP. It fails if they cannot be loaded now; a failed reload
+// later is logged and the previous certificate kept.
+func NewTLSConfigManager(certFile, keyFile string) (*TLSConfigManager, error) {
+	m := &TLSConfigManager{
+		certFile: certFile,
+		keyFile:  keyFile,
+		signals:  make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+
+	signal.Notify(m.signals, syscall.SIGHUP)
+	go m.watch()
+	return m, nil
+}
+
+// watch reloads the certificate on each SIGHUP until Close is called
+func (m *TLSConfigManager) watch() {
+	for {
+		select {
+		case <-m.signals:
+			if err := m.Reload(); err != nil {
+				log.Printf("Failed to reload TLS certificate, keeping the previous one: %v", err)
+			}
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// Reload loads the certificate and key from disk, replacing the one served
+// only if both load successfully
+func (m *TLSConfigManager) Reload() error {
+	cert, err := tls.LoadX509KeyPair(m.certFile, m.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	m.mu.Lock()
+	m.cert = &cert
+	m.mu.Unlock()
+	return nil
+}
+
+// GetCertificate returns the current certificate for every handshake
+func (m *TLSConfigManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert, nil
+}
+
+// Close stops reloading on SIGHUP. The last certificate is still served.
+func (m *TLSConfigManager) Close() {
+	signal.Stop(m.signals)
+	close(m.done)
+}
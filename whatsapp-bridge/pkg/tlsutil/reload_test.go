@@ -0,0 +1,100 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTLSConfigManagerReloadsOnSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	if err := GenerateDevCert(certPath, keyPath); err != nil {
+		t.Fatalf("Failed to generate certificate: %v", err)
+	}
+
+	m, err := NewTLSConfigManager(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer m.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{GetCertificate: m.GetCertificate})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &http.Server{Handler: http.NotFoundHandler()}
+	go server.Serve(listener)
+	defer server.Close()
+
+	servedSerial := func() *big.Int {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("TLS handshake failed: %v", err)
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber
+	}
+	original := servedSerial()
+
+	if err := GenerateDevCert(certPath, keyPath); err != nil {
+		t.Fatalf("Failed to replace certificate: %v", err)
+	}
+	if got := servedSerial(); got.Cmp(original) != 0 {
+		t.Fatal("Expected the old certificate to be served until SIGHUP")
+	}
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find own process: %v", err)
+	}
+	if err := self.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for servedSerial().Cmp(original) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the new certificate to be served after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTLSConfigManagerKeepsCertificateOnFailedReload(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	if err := GenerateDevCert(certPath, keyPath); err != nil {
+		t.Fatalf("Failed to generate certificate: %v", err)
+	}
+
+	m, err := NewTLSConfigManager(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer m.Close()
+	before, _ := m.GetCertificate(nil)
+
+	if err := os.WriteFile(certPath, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt certificate: %v", err)
+	}
+	if err := m.Reload(); err == nil {
+		t.Error("Expected reloading a corrupt certificate to fail")
+	}
+	if after, _ := m.GetCertificate(nil); after != before {
+		t.Error("Expected the previous certificate to be kept")
+	}
+
+	if _, err := NewTLSConfigManager(certPath, keyPath); err == nil {
+		t.Error("Expected a corrupt certificate to be rejected at startup")
+	}
+	if _, err := NewTLSConfigManager(filepath.Join(dir, "missing.pem"), keyPath); err == nil {
+		t.Error("Expected a missing certificate to be rejected at startup")
+	}
+}
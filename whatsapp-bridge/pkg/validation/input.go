@@ -1,21 +1,66 @@
 package validation
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ttacon/libphonenumber"
+	"golang.org/x/text/unicode/norm"
 )
 
 var (
 	// JID patterns for validation
-	phoneJIDPattern = regexp.MustCompile(`^\d{10,15}@s\.whatsapp\.net$`)
-	groupJIDPattern = regexp.MustCompile(`^\d+-\d+@g\.us$`)
-	phonePattern    = regexp.MustCompile(`^\d{10,15}$`)
+	phoneJIDPattern     = regexp.MustCompile(`^\d+@s\.whatsapp\.net$`)
+	groupJIDPattern     = regexp.MustCompile(`^\d+-\d+@g\.us$`)
+	broadcastJIDPattern = regexp.MustCompile(`^(status|\d+)@broadcast$`)
+	phonePattern        = regexp.MustCompile(`^\d{10,15}$`)
+	
+	// formattedPhonePattern matches a phone number that may carry a
+	// leading + and formatting characters
+	formattedPhonePattern = regexp.MustCompile(`^\+?[\d\s\-.()]+$`)
+	
+	// mentionPattern matches an @<phone> mention that is not part of a
+	// longer word or email address
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@(\d{10,15})\b`)
 )
 
+// errNullByte is returned by every validator given input containing a null
+// byte, which C code such as SQLite would read as the end of the string
+var errNullByte = errors.New("input contains null byte")
+
+// containsNullByte reports whether s contains a null byte
+func containsNullByte(s string) bool {
+	return strings.IndexByte(s, 0) >= 0
+}
+
+// NormalizeInput converts s to Unicode normalization form NFC, so that
+// text spelled with combining characters compares equal to its composed
+// form. Compatibility characters such as fullwidth digits are deliberately
+// kept, so they still fail the ASCII-only JID and phone number patterns
+// instead of being accepted in place of the digits they resemble.
+func NormalizeInput(s string) string {
+	return norm.NFC.String(s)
+}
+
 // ValidateJID validates WhatsApp JID format
 func ValidateJID(jid string) error {
+	if containsNullByte(jid) {
+		return errNullByte
+	}
+	jid = NormalizeInput(jid)
+	
 	if jid == "" {
 		return fmt.Errorf("JID cannot be empty")
 	}
@@ -23,12 +68,80 @@ func ValidateJID(jid string) error {
 	if phoneJIDPattern.MatchString(jid) || groupJIDPattern.MatchString(jid) {
 		return nil
 	}
+	if ValidateBroadcastJID(jid) == nil {
+		return nil
+	}
 	
 	return fmt.Errorf("invalid JID format: %s", jid)
 }
 
+// ValidateContactJID validates the JID of a user, as opposed to a group or
+// broadcast list
+func ValidateContactJID(jid string) error {
+	if containsNullByte(jid) {
+		return errNullByte
+	}
+	if !strings.HasSuffix(jid, "@s.whatsapp.net") {
+		return fmt.Errorf("contact JID must end with @s.whatsapp.net: %s", jid)
+	}
+	if !phoneJIDPattern.MatchString(jid) {
+		return fmt.Errorf("invalid JID format: %s", jid)
+	}
+	return nil
+}
+
+// ValidateGroupJID validates the JID of a group
+func ValidateGroupJID(jid string) error {
+	if containsNullByte(jid) {
+		return errNullByte
+	}
+	if !strings.HasSuffix(jid, "@g.us") {
+		return fmt.Errorf("group JID must end with @g.us: %s", jid)
+	}
+	if !groupJIDPattern.MatchString(jid) {
+		return fmt.Errorf("invalid JID format: %s", jid)
+	}
+	return nil
+}
+
+// ValidateBroadcastJID validates the JID of a broadcast list or of the
+// status broadcast, status@broadcast
+func ValidateBroadcastJID(jid string) error {
+	if containsNullByte(jid) {
+		return errNullByte
+	}
+	if !strings.HasSuffix(jid, "@broadcast") {
+		return fmt.Errorf("broadcast JID must end with @broadcast: %s", jid)
+	}
+	if !broadcastJIDPattern.MatchString(jid) {
+		return fmt.Errorf("invalid JID format: %s", jid)
+	}
+	return nil
+}
+
+// ExtractMentions returns the JIDs of users mentioned as @<phone> in message
+// content, in order of first appearance and without duplicates
+func ExtractMentions(content string) []string {
+	var jids []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		jid := match[1] + "@s.whatsapp.net"
+		if !seen[jid] {
+			seen[jid] = true
+			jids = append(jids, jid)
+		}
+	}
+	
+	return jids
+}
+
 // ValidatePhoneNumber validates phone number format
 func ValidatePhoneNumber(phone string) error {
+	if containsNullByte(phone) {
+		return errNullByte
+	}
+	phone = NormalizeInput(phone)
+	
 	if phone == "" {
 		return fmt.Errorf("phone number cannot be empty")
 	}
@@ -40,27 +153,136 @@ func ValidatePhoneNumber(phone string) error {
 	return nil
 }
 
-// ValidateRecipient validates recipient (can be phone number or JID)
-func ValidateRecipient(recipient string) error {
+// defaultCountryCode is the region of phone numbers given without a
+// country code; see SetDefaultCountryCode
+var defaultCountryCode string
+
+// SetDefaultCountryCode sets the ISO 3166-1 region, such as "US", that
+// recipients given without a country code are numbers of. It should be
+// called once at startup, before any recipient is validated.
+func SetDefaultCountryCode(code string) {
+	defaultCountryCode = strings.ToUpper(strings.TrimSpace(code))
+}
+
+// NormalizePhoneNumber parses a phone number, which may include a country
+// code and formatting such as spaces and dashes, and returns it in E.164
+// form without the leading "+", as used in JIDs. A number without a "+" is
+// first read as a number of defaultCountryCode, if set, and then as one
+// that starts with its country code.
+func NormalizePhoneNumber(phone, defaultCountryCode string) (string, error) {
+	phone = strings.TrimSpace(phone)
+	if phone == "" {
+		return "", fmt.Errorf("phone number cannot be empty")
+	}
+	if !formattedPhonePattern.MatchString(phone) {
+		return "", fmt.Errorf("invalid phone number format: %s", phone)
+	}
+	
+	type candidate struct{ number, region string }
+	var candidates []candidate
+	if strings.HasPrefix(phone, "+") {
+		candidates = []candidate{{phone, ""}}
+	} else {
+		if defaultCountryCode != "" {
+			candidates = append(candidates, candidate{phone, strings.ToUpper(defaultCountryCode)})
+		}
+		candidates = append(candidates, candidate{"+" + phone, ""})
+	}
+	
+	for _, c := range candidates {
+		num, err := libphonenumber.Parse(c.number, c.region)
+		if err == nil && libphonenumber.IsPossibleNumber(num) {
+			return strings.TrimPrefix(libphonenumber.Format(num, libphonenumber.E164), "+"), nil
+		}
+	}
+	
+	return "", fmt.Errorf("not a possible phone number: %s", phone)
+}
+
+// ExtractCountryCode returns the ISO 3166-1 alpha-2 code, such as "US", of
+// the country an international phone number belongs to. The leading "+"
+// is optional.
+func ExtractCountryCode(phone string) (string, error) {
+	phone = strings.TrimSpace(phone)
+	if phone == "" {
+		return "", fmt.Errorf("phone number cannot be empty")
+	}
+	if !strings.HasPrefix(phone, "+") {
+		phone = "+" + phone
+	}
+	
+	num, err := libphonenumber.Parse(phone, "")
+	if err != nil {
+		return "", fmt.Errorf("invalid phone number %s: %w", phone, err)
+	}
+	region := libphonenumber.GetRegionCodeForNumber(num)
+	if region == "" || region == libphonenumber.UNKNOWN_REGION {
+		return "", fmt.Errorf("no country for phone number %s", phone)
+	}
+	
+	return region, nil
+}
+
+// NormalizeRecipient validates a recipient and returns it in the form used
+// in JIDs: a JID unchanged, or a phone number normalized with the default
+// country code. Plain numbers of 10-15 digits that cannot be normalized are
+// returned as they are.
+func NormalizeRecipient(recipient string) (string, error) {
 	if recipient == "" {
-		return fmt.Errorf("recipient cannot be empty")
+		return "", fmt.Errorf("recipient cannot be empty")
 	}
 	
-	// Try phone number first
+	if strings.Contains(recipient, "@") {
+		if err := ValidateJID(recipient); err != nil {
+			return "", fmt.Errorf("invalid recipient format: %s", recipient)
+		}
+		return recipient, nil
+	}
+	
+	if phone, err := NormalizePhoneNumber(recipient, defaultCountryCode); err == nil {
+		return phone, nil
+	}
 	if err := ValidatePhoneNumber(recipient); err == nil {
-		return nil
+		return recipient, nil
 	}
 	
-	// Try JID format
-	if err := ValidateJID(recipient); err == nil {
-		return nil
+	return "", fmt.Errorf("invalid recipient format: %s", recipient)
+}
+
+// ValidateRecipient validates recipient (can be phone number or JID)
+func ValidateRecipient(recipient string) error {
+	if containsNullByte(recipient) {
+		return errNullByte
+	}
+	_, err := NormalizeRecipient(recipient)
+	return err
+}
+
+// ValidateBulkRecipients validates every recipient of a bulk send and
+// reports all invalid entries at once, not just the first
+func ValidateBulkRecipients(recipients []string) error {
+	if len(recipients) == 0 {
+		return fmt.Errorf("recipients cannot be empty")
 	}
 	
-	return fmt.Errorf("invalid recipient format: %s", recipient)
+	var errs []error
+	for i, recipient := range recipients {
+		if err := ValidateRecipient(recipient); err != nil {
+			errs = append(errs, fmt.Errorf("recipient %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// ValidateFilePath validates and sanitizes file paths to prevent path traversal
-func ValidateFilePath(path string) error {
+// ValidateFilePath validates and sanitizes file paths to prevent path
+// traversal. The file must exist and, unless allowedDirs is nil, lie
+// within one of allowedDirs once symlinks are resolved, so requests cannot
+// read files such as /etc/passwd.
+func ValidateFilePath(path string, allowedDirs []string) error {
+	if containsNullByte(path) {
+		return errNullByte
+	}
+	
 	if path == "" {
 		return fmt.Errorf("file path cannot be empty")
 	}
@@ -79,16 +301,52 @@ func ValidateFilePath(path string) error {
 		return fmt.Errorf("invalid file path: %s", path)
 	}
 	
-	// Additional security: ensure path doesn't contain null bytes
-	if strings.Contains(absPath, "\x00") {
-		return fmt.Errorf("invalid file path contains null byte: %s", path)
+	if _, err := os.Stat(absPath); err != nil {
+		return fmt.Errorf("file not accessible: %s", path)
 	}
 	
-	return nil
+	if allowedDirs == nil {
+		return nil
+	}
+	for _, dir := range allowedDirs {
+		if withinDir(absPath, dir) {
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("file is outside the allowed directories: %s", path)
+}
+
+// withinDir reports whether the absolute path absPath lies within dir
+func withinDir(absPath, dir string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(absDir); err == nil {
+		absDir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+	
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 // ValidateMessageContent validates message content
 func ValidateMessageContent(content string) error {
+	if containsNullByte(content) {
+		return errNullByte
+	}
+	
+	if content == "" {
+		return fmt.Errorf("message content cannot be empty")
+	}
+	
 	if len(content) > 4096 { // WhatsApp message limit
 		return fmt.Errorf("message content too long: %d characters (max 4096)", len(content))
 	}
@@ -96,35 +354,372 @@ func ValidateMessageContent(content string) error {
 	return nil
 }
 
-// ValidateMediaType validates media file types
-func ValidateMediaType(filename string) error {
+// ValidateSearchQuery validates a search query typed by a user
+func ValidateSearchQuery(q string) error {
+	if containsNullByte(q) {
+		return errNullByte
+	}
+	
+	if strings.TrimSpace(q) == "" {
+		return fmt.Errorf("search query cannot be empty")
+	}
+	
+	if n := utf8.RuneCountInString(q); n > 100 {
+		return fmt.Errorf("search query too long: %d characters (max 100)", n)
+	}
+	
+	return nil
+}
+
+// allowedMediaTypes maps each allowed file extension to the MIME types a
+// file with that extension may declare
+var allowedMediaTypes = map[string][]string{
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".png":  {"image/png"},
+	".gif":  {"image/gif"},
+	".webp": {"image/webp"},
+	".mp4":  {"video/mp4"},
+	".mov":  {"video/quicktime"},
+	".avi":  {"video/x-msvideo", "video/avi"},
+	".mp3":  {"audio/mpeg"},
+	".wav":  {"audio/wav", "audio/x-wav"},
+	".ogg":  {"audio/ogg"},
+	".m4a":  {"audio/mp4", "audio/x-m4a"},
+	".pdf":  {"application/pdf"},
+	".doc":  {"application/msword"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	".txt":  {"text/plain"},
+}
+
+// ValidateMediaType validates media file types. When mimeType is set it
+// must be well formed and match the file extension; parameters such as
+// "codecs=opus" are ignored.
+func ValidateMediaType(filename, mimeType string) error {
+	if containsNullByte(filename) || containsNullByte(mimeType) {
+		return errNullByte
+	}
+	
 	if filename == "" {
 		return fmt.Errorf("filename cannot be empty")
 	}
 	
 	ext := strings.ToLower(filepath.Ext(filename))
-	allowedTypes := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".gif":  true,
-		".webp": true,
-		".mp4":  true,
-		".mov":  true,
-		".avi":  true,
-		".mp3":  true,
-		".wav":  true,
-		".ogg":  true,
-		".m4a":  true,
-		".pdf":  true,
-		".doc":  true,
-		".docx": true,
-		".txt":  true,
-	}
-	
-	if !allowedTypes[ext] {
+	mimeTypes, ok := allowedMediaTypes[ext]
+	if !ok {
 		return fmt.Errorf("unsupported media type: %s", ext)
 	}
 	
+	if mimeType == "" {
+		return nil
+	}
+	
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return fmt.Errorf("invalid MIME type: %s", mimeType)
+	}
+	
+	for _, allowed := range mimeTypes {
+		if mediaType == allowed {
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("MIME type %s does not match file extension %s", mediaType, ext)
+}
+
+// sniffedMediaTypes lists the MIME types DetectMIMEType reports for files
+// of an allowed extension, where they differ from those in
+// allowedMediaTypes. M4A audio shares the MP4 container, Office Open XML
+// files are zip archives and legacy Office files are OLE containers.
+var sniffedMediaTypes = map[string][]string{
+	".wav":  {"audio/wave"},
+	".ogg":  {"application/ogg"},
+	".m4a":  {"video/mp4"},
+	".doc":  {"application/x-ole-storage"},
+	".docx": {"application/zip"},
+}
+
+// executableMagic lists the prefixes of ELF, PE and Mach-O binaries, in
+// either byte order for Mach-O, and of scripts run through a shebang line
+var executableMagic = [][]byte{
+	[]byte("\x7fELF"),
+	[]byte("MZ"),
+	{0xFE, 0xED, 0xFA},
+	{0xCE, 0xFA, 0xED, 0xFE},
+	{0xCF, 0xFA, 0xED, 0xFE},
+	[]byte("#!"),
+}
+
+// IsExecutable reports whether the file at path starts like a program or
+// script, whatever its extension
+func IsExecutable(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	
+	header := make([]byte, 4)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	header = header[:n]
+	
+	for _, magic := range executableMagic {
+		if bytes.HasPrefix(header, magic) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ValidateMIMEContent checks that the contents of the file at path match
+// its extension, so a file cannot pass ValidateMediaType merely by being
+// renamed. Executables are rejected whatever their extension.
+func ValidateMIMEContent(path string) error {
+	if containsNullByte(path) {
+		return errNullByte
+	}
+	
+	ext := strings.ToLower(filepath.Ext(path))
+	mimeTypes, ok := allowedMediaTypes[ext]
+	if !ok {
+		return fmt.Errorf("unsupported media type: %s", ext)
+	}
+	
+	executable, err := IsExecutable(path)
+	if err != nil {
+		return err
+	}
+	if executable {
+		return fmt.Errorf("file is an executable")
+	}
+	
+	detected, err := DetectMIMEType(path)
+	if err != nil {
+		return err
+	}
+	
+	if slices.Contains(mimeTypes, detected) || slices.Contains(sniffedMediaTypes[ext], detected) {
+		return nil
+	}
+	
+	return fmt.Errorf("file content is %s but extension %s expects %s", detected, ext, strings.Join(mimeTypes, " or "))
+}
+
+// ValidateFileSize checks that the file at path is at most maxBytes long.
+// A non-positive maxBytes allows any size.
+func ValidateFileSize(path string, maxBytes int64) error {
+	if containsNullByte(path) {
+		return errNullByte
+	}
+	
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("path is a directory: %s", path)
+	}
+	
+	if maxBytes > 0 && info.Size() > maxBytes {
+		return fmt.Errorf("file is %d bytes, over the %d byte limit", info.Size(), maxBytes)
+	}
+	
 	return nil
-}
\ No newline at end of file
+}
+
+// FileConstraints are the limits a media file must meet to be sent
+type FileConstraints struct {
+	// MaxBytes caps the file size; zero allows any size
+	MaxBytes int64
+}
+
+// ValidateMediaFileConstraints checks that the file at path has a supported
+// extension, is no larger than cfg allows and has contents matching its
+// extension. A nil cfg applies no size limit.
+func ValidateMediaFileConstraints(path string, cfg *FileConstraints) error {
+	if containsNullByte(path) {
+		return errNullByte
+	}
+	
+	if err := ValidateMediaType(path, ""); err != nil {
+		return err
+	}
+	
+	var maxBytes int64
+	if cfg != nil {
+		maxBytes = cfg.MaxBytes
+	}
+	if err := ValidateFileSize(path, maxBytes); err != nil {
+		return err
+	}
+	
+	return ValidateMIMEContent(path)
+}
+
+// oleMagic starts Compound File Binary files such as legacy .doc files,
+// which http.DetectContentType does not recognize
+var oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// quickTimeMagic is the file type box of a QuickTime movie, which
+// http.DetectContentType does not recognize
+var quickTimeMagic = []byte("ftypqt  ")
+
+// DetectMIMEType sniffs the MIME type of the file at path from its first
+// 512 bytes rather than trusting its extension. Parameters such as the
+// charset are stripped.
+func DetectMIMEType(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	
+	header := make([]byte, 512)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	header = header[:n]
+	
+	if bytes.HasPrefix(header, oleMagic) {
+		return "application/x-ole-storage", nil
+	}
+	if len(header) >= 12 && bytes.Equal(header[4:12], quickTimeMagic) {
+		return "video/quicktime", nil
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(header))
+	if err != nil {
+		return "", err
+	}
+	return mediaType, nil
+}
+
+// ValidateEmoji checks that s is a single emoji, counting a sequence such
+// as a skin-toned thumbs up, a ZWJ family or a flag as one
+func ValidateEmoji(s string) error {
+	if containsNullByte(s) {
+		return errNullByte
+	}
+	if s == "" {
+		return fmt.Errorf("emoji cannot be empty")
+	}
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("emoji must be valid UTF-8")
+	}
+	
+	s = norm.NFC.String(s)
+	if n := graphemeClusters(s); n != 1 {
+		return fmt.Errorf("emoji must be a single character, got %d", n)
+	}
+	
+	first, _ := utf8.DecodeRuneInString(s)
+	keycap := strings.HasSuffix(s, "\u20E3") && strings.ContainsRune("0123456789#*", first)
+	if !unicode.Is(unicode.So, first) && !keycap {
+		return fmt.Errorf("not an emoji: %q", s)
+	}
+	
+	return nil
+}
+
+// graphemeClusters counts the user-perceived characters in s. It implements
+// the parts of Unicode's extended grapheme cluster rules that matter for
+// emoji: combining marks, variation selectors, skin tone modifiers, tag
+// sequences, zero width joiner sequences and regional indicator pairs.
+func graphemeClusters(s string) int {
+	clusters := 0
+	prev := rune(-1)
+	regionalRun := 0
+	for _, r := range s {
+		switch {
+		case prev < 0:
+			clusters++
+		case prev == '\u200D':
+			// Joined to the previous character
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc),
+			r >= 0xFE00 && r <= 0xFE0F,
+			r >= 0x1F3FB && r <= 0x1F3FF,
+			r >= 0xE0020 && r <= 0xE007F,
+			r == '\u200D':
+			// Extends the current cluster
+		case isRegionalIndicator(r) && regionalRun%2 == 1:
+			// Second half of a flag
+		default:
+			clusters++
+		}
+		
+		if isRegionalIndicator(r) {
+			regionalRun++
+		} else {
+			regionalRun = 0
+		}
+		prev = r
+	}
+	return clusters
+}
+
+// isRegionalIndicator reports whether r is one of the letters that pair up
+// into flag emoji
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// ValidateCoordinates validates a latitude and longitude in decimal degrees
+func ValidateCoordinates(lat, lon float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90: %v", lat)
+	}
+	if math.IsNaN(lon) || lon < -180 || lon > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180: %v", lon)
+	}
+	
+	return nil
+}
+
+// ValidateVCard checks that a contact card is a vCard 3.0 or 4.0 with the
+// required BEGIN, VERSION, FN and END properties
+func ValidateVCard(raw string) error {
+	if containsNullByte(raw) {
+		return errNullByte
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < 2 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCARD") {
+		return fmt.Errorf("vCard must start with BEGIN:VCARD")
+	}
+	if !strings.EqualFold(strings.TrimSpace(lines[len(lines)-1]), "END:VCARD") {
+		return fmt.Errorf("vCard must end with END:VCARD")
+	}
+	
+	var version string
+	hasName := false
+	for _, line := range lines[1 : len(lines)-1] {
+		prop, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		prop, _, _ = strings.Cut(strings.ToUpper(prop), ";")
+		switch prop {
+		case "VERSION":
+			version = strings.TrimSpace(value)
+		case "FN":
+			hasName = strings.TrimSpace(value) != ""
+		}
+	}
+	
+	if version != "3.0" && version != "4.0" {
+		return fmt.Errorf("unsupported vCard version: %q (should be 3.0 or 4.0)", version)
+	}
+	if !hasName {
+		return fmt.Errorf("vCard is missing the FN property")
+	}
+	
+	return nil
+}
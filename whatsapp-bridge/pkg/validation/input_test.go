@@ -1,8 +1,11 @@
 package validation
 
 import (
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -13,9 +16,12 @@ func TestValidateJID(t *testing.T) {
 	}{
 		{"123456789@s.whatsapp.net", false},
 		{"123456789-123456789@g.us", false},
+		{"status@broadcast", false},
+		{"123456789@broadcast", false},
 		{"", true},
 		{"invalid", true},
 		{"123@invalid.domain", true},
+		{"abc@broadcast", true},
 	}
 	
 	for _, test := range tests {
@@ -26,6 +32,136 @@ func TestValidateJID(t *testing.T) {
 	}
 }
 
+func TestNormalizeInput(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1234567890@s.whatsapp.net", "1234567890@s.whatsapp.net"},
+		{"Jose\u0301", "Jos\u00e9"},
+		{"Jos\u00e9", "Jos\u00e9"},
+		{"", ""},
+	}
+	
+	for _, test := range tests {
+		if got := NormalizeInput(test.input); got != test.want {
+			t.Errorf("NormalizeInput(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestValidateFullwidthDigits(t *testing.T) {
+	// U+FF10-U+FF19 look like 0-9 but are not ASCII digits
+	var fullwidth strings.Builder
+	for _, r := range "1234567890" {
+		fullwidth.WriteRune(r - '0' + '\uFF10')
+	}
+	phone := fullwidth.String()
+	jid := phone + "@s.whatsapp.net"
+	
+	// NFC keeps compatibility characters, so the lookalikes must be
+	// rejected rather than read as the digits they resemble
+	if got := NormalizeInput(jid); got != jid {
+		t.Errorf("NormalizeInput(%q) = %q, want it unchanged", jid, got)
+	}
+	if err := ValidateJID(jid); err == nil {
+		t.Errorf("ValidateJID(%q) accepted fullwidth digits", jid)
+	}
+	if err := ValidatePhoneNumber(phone); err == nil {
+		t.Errorf("ValidatePhoneNumber(%q) accepted fullwidth digits", phone)
+	}
+	for _, recipient := range []string{jid, phone} {
+		if err := ValidateRecipient(recipient); err == nil {
+			t.Errorf("ValidateRecipient(%q) accepted fullwidth digits", recipient)
+		}
+	}
+}
+
+func TestValidateBroadcastJID(t *testing.T) {
+	tests := []struct {
+		jid     string
+		wantErr bool
+	}{
+		{"status@broadcast", false},
+		{"1234567890@broadcast", false},
+		{"1234567890@s.whatsapp.net", true},
+		{"123456789-123456@g.us", true},
+		{"abc@broadcast", true},
+		{"@broadcast", true},
+		{"1234567890@broadcast.net", true},
+		{"", true},
+	}
+	
+	for _, test := range tests {
+		err := ValidateBroadcastJID(test.jid)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateBroadcastJID(%s) error = %v, wantErr %v", test.jid, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateContactJID(t *testing.T) {
+	tests := []struct {
+		jid     string
+		wantErr bool
+	}{
+		{"1234567890@s.whatsapp.net", false},
+		{"123456789-123456@g.us", true},
+		{"status@broadcast", true},
+		{"1234567890", true},
+		{"abc@s.whatsapp.net", true},
+		{"", true},
+	}
+	
+	for _, test := range tests {
+		err := ValidateContactJID(test.jid)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateContactJID(%s) error = %v, wantErr %v", test.jid, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateGroupJID(t *testing.T) {
+	tests := []struct {
+		jid     string
+		wantErr bool
+	}{
+		{"123456789-123456@g.us", false},
+		{"1234567890@s.whatsapp.net", true},
+		{"status@broadcast", true},
+		{"abc-def@g.us", true},
+		{"", true},
+	}
+	
+	for _, test := range tests {
+		err := ValidateGroupJID(test.jid)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateGroupJID(%s) error = %v, wantErr %v", test.jid, err, test.wantErr)
+		}
+	}
+}
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"no mentions here", nil},
+		{"@1234567890 hi", []string{"1234567890@s.whatsapp.net"}},
+		{"hey @1234567890 and @15551234567!", []string{"1234567890@s.whatsapp.net", "15551234567@s.whatsapp.net"}},
+		{"@1234567890 @1234567890", []string{"1234567890@s.whatsapp.net"}},
+		{"mail me at user@1234567890.com", nil},
+		{"too short @123", nil},
+	}
+	
+	for _, test := range tests {
+		got := ExtractMentions(test.content)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ExtractMentions(%q) = %v, want %v", test.content, got, test.want)
+		}
+	}
+}
+
 func TestValidatePhoneNumber(t *testing.T) {
 	tests := []struct {
 		phone   string
@@ -67,6 +203,146 @@ func TestValidateRecipient(t *testing.T) {
 	}
 }
 
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		phone   string
+		country string
+		want    string
+		wantErr bool
+	}{
+		// With a country code
+		{"+12025550123", "", "12025550123", false},
+		{"+12025550123", "GB", "12025550123", false},
+		{"+447911123456", "US", "447911123456", false},
+		{"447911123456", "US", "447911123456", false},
+		{"15551234567", "", "15551234567", false},
+		// Without a country code
+		{"2025550123", "US", "12025550123", false},
+		{"5551234567", "us", "15551234567", false},
+		{"07911 123456", "GB", "447911123456", false},
+		// With formatting characters
+		{"+1 555-123-4567", "", "15551234567", false},
+		{"(555) 123-4567", "US", "15551234567", false},
+		{"+44 (0)7911 123 456", "", "447911123456", false},
+		{"555.123.4567", "US", "15551234567", false},
+		// Invalid
+		{"", "US", "", true},
+		{"123", "US", "", true},
+		{"abc1234567890", "US", "", true},
+		{"1-800-FLOWERS", "US", "", true},
+		{"5551234567", "", "", true},
+		// No US area code starts with 1
+		{"1234567890", "US", "", true},
+	}
+	
+	for _, test := range tests {
+		got, err := NormalizePhoneNumber(test.phone, test.country)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("NormalizePhoneNumber(%q, %q) = %q, %v; want %q, wantErr %v", test.phone, test.country, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestExtractCountryCode(t *testing.T) {
+	tests := []struct {
+		phone   string
+		want    string
+		wantErr bool
+	}{
+		{"12025550123", "US", false},
+		{"+14165550123", "CA", false},
+		{"447400123456", "GB", false},
+		{"4915123456789", "DE", false},
+		{"33612345678", "FR", false},
+		{"919876543210", "IN", false},
+		{"5511987654321", "BR", false},
+		{"61412345678", "AU", false},
+		{"819012345678", "JP", false},
+		{"5215512345678", "MX", false},
+		{"2348031234567", "NG", false},
+		{"27821234567", "ZA", false},
+		{"", "", true},
+		{"999123456", "", true},
+	}
+	
+	for _, test := range tests {
+		got, err := ExtractCountryCode(test.phone)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ExtractCountryCode(%q) = %q, %v; want %q, wantErr %v", test.phone, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestNormalizeRecipient(t *testing.T) {
+	SetDefaultCountryCode("US")
+	defer SetDefaultCountryCode("")
+	
+	tests := []struct {
+		recipient string
+		want      string
+		wantErr   bool
+	}{
+		{"2025550123", "12025550123", false},
+		{"+1 (202) 555-0123", "12025550123", false},
+		{"12025550123", "12025550123", false},
+		// Plain numbers that cannot be normalized are kept
+		{"1234567890", "1234567890", false},
+		{"123456789@s.whatsapp.net", "123456789@s.whatsapp.net", false},
+		{"", "", true},
+		{"invalid", "", true},
+	}
+	
+	for _, test := range tests {
+		got, err := NormalizeRecipient(test.recipient)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("NormalizeRecipient(%q) = %q, %v; want %q, wantErr %v", test.recipient, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestValidateBulkRecipients(t *testing.T) {
+	if err := ValidateBulkRecipients([]string{"1234567890", "123456789-123456789@g.us"}); err != nil {
+		t.Errorf("Expected valid recipients, got %v", err)
+	}
+	if err := ValidateBulkRecipients(nil); err == nil {
+		t.Error("Expected an error for no recipients")
+	}
+	
+	err := ValidateBulkRecipients([]string{"invalid", "1234567890", ""})
+	if err == nil {
+		t.Fatal("Expected an error for invalid recipients")
+	}
+	for _, want := range []string{"recipient 0:", "recipient 2:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in error %q", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "recipient 1:") {
+		t.Errorf("Valid recipient reported in error %q", err)
+	}
+}
+
+func TestValidateSearchQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"ali", false},
+		{"José Müller", false},
+		{strings.Repeat("é", 100), false},
+		{"", true},
+		{"   ", true},
+		{strings.Repeat("a", 101), true},
+	}
+	
+	for _, test := range tests {
+		err := ValidateSearchQuery(test.query)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateSearchQuery(%q) error = %v, wantErr %v", test.query, err, test.wantErr)
+		}
+	}
+}
+
 func TestValidateFilePath(t *testing.T) {
 	// Create temporary test file
 	tempDir := t.TempDir()
@@ -88,19 +364,72 @@ func TestValidateFilePath(t *testing.T) {
 	}
 	
 	for _, test := range tests {
-		err := ValidateFilePath(test.path)
+		err := ValidateFilePath(test.path, nil)
 		if (err != nil) != test.wantErr {
 			t.Errorf("ValidateFilePath(%s) error = %v, wantErr %v", test.path, err, test.wantErr)
 		}
 	}
 }
 
+func TestValidateFilePathAllowedDirs(t *testing.T) {
+	uploads := t.TempDir()
+	other := t.TempDir()
+	write := func(path string) string {
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		return path
+	}
+	if err := os.Mkdir(filepath.Join(uploads, "nested"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	inside := write(filepath.Join(uploads, "photo.jpg"))
+	nested := write(filepath.Join(uploads, "nested", "photo.jpg"))
+	outside := write(filepath.Join(other, "photo.jpg"))
+	sibling := uploads + "-sibling"
+	if err := os.Mkdir(sibling, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(sibling) })
+	siblingFile := write(filepath.Join(sibling, "photo.jpg"))
+	link := filepath.Join(uploads, "link.jpg")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	
+	allowed := []string{uploads}
+	tests := []struct {
+		name        string
+		path        string
+		allowedDirs []string
+		wantErr     bool
+	}{
+		{"inside", inside, allowed, false},
+		{"nested", nested, allowed, false},
+		{"second allowed dir", outside, []string{uploads, other}, false},
+		{"outside", outside, allowed, true},
+		{"system file", "/etc/passwd", allowed, true},
+		{"directory name prefix", siblingFile, allowed, true},
+		{"symlink out of the allowed dir", link, allowed, true},
+		{"empty allowed list", inside, []string{}, true},
+		{"nil allowed list", outside, nil, false},
+	}
+	
+	for _, test := range tests {
+		err := ValidateFilePath(test.path, test.allowedDirs)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateFilePath(%s) error = %v, wantErr %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
 func TestValidateMessageContent(t *testing.T) {
 	tests := []struct {
 		content string
 		wantErr bool
 	}{
 		{"Hello", false},
+		{" ", false},
 		{"", true},
 		{string(make([]byte, 5000)), true}, // Too long
 	}
@@ -116,20 +445,371 @@ func TestValidateMessageContent(t *testing.T) {
 func TestValidateMediaType(t *testing.T) {
 	tests := []struct {
 		filename string
+		mimeType string
+		wantErr  bool
+	}{
+		{"image.jpg", "", false},
+		{"video.mp4", "", false},
+		{"document.pdf", "", false},
+		{"", "", true},
+		{"malicious.exe", "", true},
+		{"script.js", "", true},
+		{"image.jpg", "image/jpeg", false},
+		{"voice.ogg", "audio/ogg; codecs=opus", false},
+		{"image.jpg", "video/mp4", true},
+		{"image.jpg", "not a mime type", true},
+		{"malicious.exe", "application/x-msdownload", true},
+	}
+	
+	for _, test := range tests {
+		err := ValidateMediaType(test.filename, test.mimeType)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateMediaType(%s, %s) error = %v, wantErr %v", test.filename, test.mimeType, err, test.wantErr)
+		}
+	}
+}
+
+func TestDetectMIMEType(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"doc.pdf", []byte("%PDF-1.7\n"), "application/pdf"},
+		{"notes.txt", []byte("plain notes\n"), "text/plain"},
+		{"report.docx", []byte("PK\x03\x04rest of archive"), "application/zip"},
+		{"legacy.doc", append([]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}, 0, 0), "application/x-ole-storage"},
+		{"renamed.pdf", []byte("MZ\x90\x00\x03\x00\x00\x00"), "application/octet-stream"},
+		{"empty.txt", nil, "text/plain"},
+	}
+	
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		if err := os.WriteFile(path, test.content, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", test.name, err)
+		}
+		got, err := DetectMIMEType(path)
+		if err != nil {
+			t.Errorf("DetectMIMEType(%s) error = %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("DetectMIMEType(%s) = %s, want %s", test.name, got, test.want)
+		}
+	}
+	
+	if _, err := DetectMIMEType(filepath.Join(dir, "missing.pdf")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestIsExecutable(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{"elf.jpg", []byte("\x7fELF\x02\x01\x01\x00"), true},
+		{"pe.png", []byte("MZ\x90\x00\x03\x00"), true},
+		{"macho.mp4", []byte{0xFE, 0xED, 0xFA, 0xCF, 0x00}, true},
+		{"macho-le.mp4", []byte{0xCF, 0xFA, 0xED, 0xFE, 0x07}, true},
+		{"script.txt", []byte("#!/bin/sh\nrm -rf /\n"), true},
+		{"photo.jpg", []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00"), false},
+		{"doc.pdf", []byte("%PDF-1.7\n"), false},
+		{"notes.txt", []byte("# Notes\n"), false},
+		{"short.txt", []byte("M"), false},
+		{"empty.txt", nil, false},
+	}
+	
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		if err := os.WriteFile(path, test.content, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", test.name, err)
+		}
+		got, err := IsExecutable(path)
+		if err != nil {
+			t.Errorf("IsExecutable(%s) error = %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("IsExecutable(%s) = %v, want %v", test.name, got, test.want)
+		}
+		if test.want {
+			if err := ValidateMIMEContent(path); err == nil {
+				t.Errorf("ValidateMIMEContent(%s) accepted an executable", test.name)
+			}
+		}
+	}
+	
+	if _, err := IsExecutable(filepath.Join(dir, "missing.jpg")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestValidateMIMEContent(t *testing.T) {
+	dir := t.TempDir()
+	jpeg := []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00")
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdf := []byte("%PDF-1.7\n")
+	exe := []byte("MZ\x90\x00\x03\x00\x00\x00")
+	mp4 := []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom")
+	mov := []byte("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00qt  ")
+	m4a := []byte("\x00\x00\x00\x1cftypM4A \x00\x00\x00\x00M4A mp42isom")
+	wav := []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+	ogg := []byte("OggS\x00\x02\x00\x00")
+	mp3 := []byte("ID3\x03\x00\x00\x00\x00\x00\x00")
+	tests := []struct {
+		name    string
+		content []byte
+		wantErr bool
+	}{
+		{"photo.jpg", jpeg, false},
+		{"photo.JPEG", jpeg, false},
+		{"image.png", png, false},
+		{"clip.mp4", mp4, false},
+		{"clip.mov", mov, false},
+		{"voice.m4a", m4a, false},
+		{"sound.wav", wav, false},
+		{"voice.ogg", ogg, false},
+		{"song.mp3", mp3, false},
+		{"doc.pdf", pdf, false},
+		{"report.docx", []byte("PK\x03\x04rest of archive"), false},
+		{"notes.txt", []byte("plain notes\n"), false},
+		{"png renamed to jpg", png, true},
+		{"executable renamed to jpg", exe, true},
+		{"executable renamed to mp4", exe, true},
+		{"pdf renamed to png", pdf, true},
+		{"text renamed to mp3", []byte("plain notes\n"), true},
+		{"jpeg renamed to pdf", jpeg, true},
+		{"program.exe", exe, true},
+	}
+	
+	for _, test := range tests {
+		name := test.name
+		if strings.Contains(name, " renamed to ") {
+			name = "file." + name[strings.LastIndex(name, " ")+1:]
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, test.content, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		err := ValidateMIMEContent(path)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateMIMEContent(%s) error = %v, wantErr %v", test.name, err, test.wantErr)
+		}
+	}
+	
+	mismatch := filepath.Join(dir, "mismatch.jpg")
+	if err := os.WriteFile(mismatch, png, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", mismatch, err)
+	}
+	err := ValidateMIMEContent(mismatch)
+	if err == nil || !strings.Contains(err.Error(), "image/png") || !strings.Contains(err.Error(), "image/jpeg") {
+		t.Errorf("Expected the error to name the detected and expected types, got %v", err)
+	}
+	if err := ValidateMIMEContent(filepath.Join(dir, "missing.jpg")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestValidateFileSize(t *testing.T) {
+	dir := t.TempDir()
+	const limit = 1024
+	tests := []struct {
+		name     string
+		size     int
+		maxBytes int64
 		wantErr  bool
 	}{
-		{"image.jpg", false},
-		{"video.mp4", false},
-		{"document.pdf", false},
+		{"below.txt", limit - 1, limit, false},
+		{"exact.txt", limit, limit, false},
+		{"above.txt", limit + 1, limit, true},
+		{"empty.txt", 0, limit, false},
+		{"unlimited.txt", limit + 1, 0, false},
+	}
+	
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		if err := os.WriteFile(path, make([]byte, test.size), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", test.name, err)
+		}
+		err := ValidateFileSize(path, test.maxBytes)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateFileSize(%s, %d) error = %v, wantErr %v", test.name, test.maxBytes, err, test.wantErr)
+		}
+	}
+	
+	if err := ValidateFileSize(filepath.Join(dir, "missing.txt"), limit); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if err := ValidateFileSize(dir, limit); err == nil {
+		t.Error("Expected an error for a directory")
+	}
+}
+
+func TestValidateMediaFileConstraints(t *testing.T) {
+	dir := t.TempDir()
+	pdf := append([]byte("%PDF-1.7\n"), make([]byte, 1015)...)
+	write := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	doc := write("doc.pdf", pdf)
+	
+	tests := []struct {
+		name    string
+		path    string
+		cfg     *FileConstraints
+		wantErr bool
+	}{
+		{"below the limit", doc, &FileConstraints{MaxBytes: int64(len(pdf)) + 1}, false},
+		{"at the limit", doc, &FileConstraints{MaxBytes: int64(len(pdf))}, false},
+		{"above the limit", doc, &FileConstraints{MaxBytes: int64(len(pdf)) - 1}, true},
+		{"no constraints", doc, nil, false},
+		{"unsupported extension", write("doc.exe", pdf), nil, true},
+		{"mismatched content", write("doc.png", pdf), nil, true},
+		{"missing file", filepath.Join(dir, "missing.pdf"), nil, true},
+	}
+	
+	for _, test := range tests {
+		err := ValidateMediaFileConstraints(test.path, test.cfg)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateMediaFileConstraints(%s) error = %v, wantErr %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateEmoji(t *testing.T) {
+	tests := []struct {
+		name    string
+		emoji   string
+		wantErr bool
+	}{
+		{"simple", "👍", false},
+		{"skin tone modifier", "👍🏽", false},
+		{"variation selector", "❤️", false},
+		{"zwj family", "👨\u200D👩\u200D👧\u200D👦", false},
+		{"zwj with skin tones", "🧑🏽\u200D🤝\u200D🧑🏻", false},
+		{"flag", "🇩🇪", false},
+		{"tag sequence flag", "🏴\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007F", false},
+		{"keycap", "1\uFE0F\u20E3", false},
+		{"two emoji", "👍👍", true},
+		{"two flags", "🇩🇪🇫🇷", true},
+		{"emoji and text", "👍ok", true},
+		{"letter", "a", true},
+		{"decomposed letter", "e\u0301", true},
+		{"digit", "1", true},
+		{"empty", "", true},
+		{"invalid utf-8", "\xff", true},
+	}
+	
+	for _, test := range tests {
+		err := ValidateEmoji(test.emoji)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: ValidateEmoji(%q) error = %v, wantErr %v", test.name, test.emoji, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateCoordinates(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		wantErr  bool
+	}{
+		{52.52, 13.405, false},
+		{0, 0, false},
+		{-90, -180, false},
+		{90, 180, false},
+		{90.1, 0, true},
+		{0, -180.5, true},
+		{math.NaN(), 0, true},
+		{0, math.Inf(1), true},
+	}
+	
+	for _, test := range tests {
+		err := ValidateCoordinates(test.lat, test.lon)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateCoordinates(%v, %v) error = %v, wantErr %v", test.lat, test.lon, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateVCard(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantErr bool
+	}{
+		{"BEGIN:VCARD\nVERSION:3.0\nFN:Alice Smith\nTEL:+15551234567\nEND:VCARD", false},
+		{"BEGIN:VCARD\r\nVERSION:4.0\r\nFN;CHARSET=UTF-8:Bob\r\nEND:VCARD\r\n", false},
 		{"", true},
-		{"malicious.exe", true},
-		{"script.js", true},
+		{"VERSION:3.0\nFN:Alice\nEND:VCARD", true},
+		{"BEGIN:VCARD\nVERSION:3.0\nFN:Alice", true},
+		{"BEGIN:VCARD\nVERSION:2.1\nFN:Alice\nEND:VCARD", true},
+		{"BEGIN:VCARD\nVERSION:3.0\nN:Smith;Alice\nEND:VCARD", true},
+		{"BEGIN:VCARD\nVERSION:3.0\nFN: \nEND:VCARD", true},
 	}
 	
 	for _, test := range tests {
-		err := ValidateMediaType(test.filename)
+		err := ValidateVCard(test.raw)
 		if (err != nil) != test.wantErr {
-			t.Errorf("ValidateMediaType(%s) error = %v, wantErr %v", test.filename, err, test.wantErr)
+			t.Errorf("ValidateVCard(%q) error = %v, wantErr %v", test.raw, err, test.wantErr)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func FuzzValidateAll(f *testing.F) {
+	for _, seed := range []string{
+		"1234567890@s.whatsapp.net",
+		"123456789-123456789@g.us",
+		"status@broadcast",
+		"+1 (202) 555-0123",
+		"photo.jpg",
+		"hello @12025550123",
+		"👍🏽",
+		"BEGIN:VCARD\nVERSION:3.0\nFN:Jo\nEND:VCARD",
+		"bad\x00input",
+		"\x01\x1b[31m\r\n",
+	} {
+		f.Add(seed)
+	}
+	dirs := []string{f.TempDir()}
+	
+	f.Fuzz(func(t *testing.T, s string) {
+		errs := map[string]error{
+			"ValidateJID":                  ValidateJID(s),
+			"ValidateContactJID":           ValidateContactJID(s),
+			"ValidateGroupJID":             ValidateGroupJID(s),
+			"ValidateBroadcastJID":         ValidateBroadcastJID(s),
+			"ValidatePhoneNumber":          ValidatePhoneNumber(s),
+			"ValidateRecipient":            ValidateRecipient(s),
+			"ValidateBulkRecipients":       ValidateBulkRecipients([]string{s}),
+			"ValidateFilePath":             ValidateFilePath(s, dirs),
+			"ValidateMessageContent":       ValidateMessageContent(s),
+			"ValidateSearchQuery":          ValidateSearchQuery(s),
+			"ValidateMediaType":            ValidateMediaType(s, ""),
+			"ValidateMediaType mime":       ValidateMediaType("photo.jpg", s),
+			"ValidateMIMEContent":          ValidateMIMEContent(s),
+			"ValidateFileSize":             ValidateFileSize(s, 1),
+			"ValidateMediaFileConstraints": ValidateMediaFileConstraints(s, nil),
+			"ValidateEmoji":                ValidateEmoji(s),
+			"ValidateVCard":                ValidateVCard(s),
+		}
+		NormalizeRecipient(s)
+		ExtractMentions(s)
+		ExtractCountryCode(s)
+		
+		if !strings.ContainsRune(s, 0) {
+			return
+		}
+		for name, err := range errs {
+			if err == nil {
+				t.Errorf("%s(%q) accepted a null byte", name, s)
+			}
+		}
+	})
+}
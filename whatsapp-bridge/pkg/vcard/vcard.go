@@ -0,0 +1,108 @@
+// Package vcard reads and writes the contact cards WhatsApp shares.
+package vcard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// valueUnescaper undoes vCard text escaping
+var valueUnescaper = strings.NewReplacer(`\\`, `\`, `\,`, `,`, `\;`, `;`, `\n`, "\n", `\N`, "\n")
+
+// ParseVCard returns the formatted name (FN) and first phone number (TEL) of
+// a vCard 3.0 or 4.0 string. Property parameters and group prefixes such as
+// "item1.TEL;type=CELL" are accepted. It fails if raw is not a vCard or has
+// no name.
+func ParseVCard(raw string) (name, phone string, err error) {
+	lines := unfold(raw)
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCARD") {
+		return "", "", fmt.Errorf("not a vCard: missing BEGIN:VCARD")
+	}
+
+	for _, line := range lines[1:] {
+		prop, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		switch propertyName(prop) {
+		case "FN":
+			if name == "" {
+				name = strings.TrimSpace(valueUnescaper.Replace(value))
+			}
+		case "TEL":
+			if phone == "" {
+				phone = strings.TrimSpace(strings.TrimPrefix(value, "tel:"))
+			}
+		}
+	}
+
+	if name == "" {
+		return "", "", fmt.Errorf("vCard has no FN property")
+	}
+	return name, phone, nil
+}
+
+// propertyName returns the upper-cased name of a content line's property,
+// without its group prefix and parameters
+func propertyName(prop string) string {
+	prop, _, _ = strings.Cut(prop, ";")
+	if i := strings.LastIndexByte(prop, '.'); i >= 0 {
+		prop = prop[i+1:]
+	}
+	return strings.ToUpper(strings.TrimSpace(prop))
+}
+
+// unfold splits a vCard into content lines, joining continuation lines that
+// start with a space or tab and dropping empty lines
+func unfold(raw string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Contact holds the fields written to a generated vCard. Phone is an
+// international number; any formatting is stripped for the WhatsApp ID.
+type Contact struct {
+	Name  string
+	Phone string
+}
+
+// valueEscaper applies vCard text escaping
+var valueEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `;`, `\;`, "\r\n", `\n`, "\n", `\n`)
+
+// FormatVCard formats c as a vCard 3.0 string in the layout WhatsApp uses
+// for shared contacts, with the phone number tagged with its WhatsApp ID
+func FormatVCard(c *Contact) string {
+	name := valueEscaper.Replace(c.Name)
+
+	var b strings.Builder
+	b.WriteString("BEGIN:VCARD\nVERSION:3.0\n")
+	fmt.Fprintf(&b, "N:;%s;;;\n", name)
+	fmt.Fprintf(&b, "FN:%s\n", name)
+	if waid := digits(c.Phone); waid != "" {
+		fmt.Fprintf(&b, "item1.TEL;waid=%s:+%s\n", waid, waid)
+		b.WriteString("item1.X-ABLabel:Mobile\n")
+	}
+	b.WriteString("END:VCARD")
+	return b.String()
+}
+
+// digits returns the ASCII digits of s
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package vcard
+
+import (
+	"testing"
+)
+
+func TestParseVCard(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		wantName  string
+		wantPhone string
+		wantErr   bool
+	}{
+		{
+			name:      "whatsapp 3.0",
+			raw:       "BEGIN:VCARD\nVERSION:3.0\nN:Smith;Alice;;;\nFN:Alice Smith\nitem1.TEL;waid=15551234567:+1 555-123-4567\nitem1.X-ABLabel:Mobile\nEND:VCARD",
+			wantName:  "Alice Smith",
+			wantPhone: "+1 555-123-4567",
+		},
+		{
+			name:      "4.0 with uri phone and CRLF",
+			raw:       "BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Bob Jones\r\nTEL;VALUE=uri;TYPE=cell:tel:+44-20-7946-0958\r\nTEL:+44-20-0000-0000\r\nEND:VCARD\r\n",
+			wantName:  "Bob Jones",
+			wantPhone: "+44-20-7946-0958",
+		},
+		{
+			name:     "folded and escaped name without phone",
+			raw:      "BEGIN:VCARD\nVERSION:3.0\nFN:Carol\n  Doe\\, PhD\nEND:VCARD",
+			wantName: "Carol Doe, PhD",
+		},
+		{name: "not a vcard", raw: "FN:Alice", wantErr: true},
+		{name: "no name", raw: "BEGIN:VCARD\nVERSION:3.0\nTEL:+15551234567\nEND:VCARD", wantErr: true},
+		{name: "empty", raw: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		name, phone, err := ParseVCard(test.raw)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: ParseVCard() error = %v, wantErr %v", test.name, err, test.wantErr)
+			continue
+		}
+		if name != test.wantName || phone != test.wantPhone {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", test.name, name, phone, test.wantName, test.wantPhone)
+		}
+	}
+}
+
+func TestFormatVCard(t *testing.T) {
+	tests := []struct {
+		name    string
+		contact Contact
+		want    string
+	}{
+		{
+			name:    "international number",
+			contact: Contact{Name: "Alice Smith", Phone: "+44 20 7946 0958"},
+			want:    "BEGIN:VCARD\nVERSION:3.0\nN:;Alice Smith;;;\nFN:Alice Smith\nitem1.TEL;waid=442079460958:+442079460958\nitem1.X-ABLabel:Mobile\nEND:VCARD",
+		},
+		{
+			name:    "unicode name",
+			contact: Contact{Name: "José Müller", Phone: "4915112345678"},
+			want:    "BEGIN:VCARD\nVERSION:3.0\nN:;José Müller;;;\nFN:José Müller\nitem1.TEL;waid=4915112345678:+4915112345678\nitem1.X-ABLabel:Mobile\nEND:VCARD",
+		},
+		{
+			name:    "non-latin script",
+			contact: Contact{Name: "山田太郎", Phone: "+81-90-1234-5678"},
+			want:    "BEGIN:VCARD\nVERSION:3.0\nN:;山田太郎;;;\nFN:山田太郎\nitem1.TEL;waid=819012345678:+819012345678\nitem1.X-ABLabel:Mobile\nEND:VCARD",
+		},
+		{
+			name:    "escaped name",
+			contact: Contact{Name: "Doe, Jane; PhD", Phone: "(555) 123-4567"},
+			want:    "BEGIN:VCARD\nVERSION:3.0\nN:;Doe\\, Jane\\; PhD;;;\nFN:Doe\\, Jane\\; PhD\nitem1.TEL;waid=5551234567:+5551234567\nitem1.X-ABLabel:Mobile\nEND:VCARD",
+		},
+		{
+			name:    "no phone",
+			contact: Contact{Name: "Карина"},
+			want:    "BEGIN:VCARD\nVERSION:3.0\nN:;Карина;;;\nFN:Карина\nEND:VCARD",
+		},
+	}
+
+	for _, test := range tests {
+		got := FormatVCard(&test.contact)
+		if got != test.want {
+			t.Errorf("%s: FormatVCard() =\n%s\nwant\n%s", test.name, got, test.want)
+			continue
+		}
+
+		name, phone, err := ParseVCard(got)
+		if err != nil {
+			t.Errorf("%s: ParseVCard() error = %v", test.name, err)
+			continue
+		}
+		if name != test.contact.Name {
+			t.Errorf("%s: round-tripped name %q, want %q", test.name, name, test.contact.Name)
+		}
+		if test.contact.Phone != "" && phone != "+"+digits(test.contact.Phone) {
+			t.Errorf("%s: round-tripped phone %q", test.name, phone)
+		}
+	}
+}
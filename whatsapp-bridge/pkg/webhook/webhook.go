@@ -0,0 +1,158 @@
+// Package webhook POSTs events to the webhooks registered through the API,
+// so integrations learn of new messages without polling.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsapp-client/pkg/database"
+)
+
+// EventNewMessage is the event type dispatched for each newly stored message
+const EventNewMessage = "new_message"
+
+// Events lists the event types a webhook can subscribe to
+var Events = []string{EventNewMessage}
+
+// Headers set on every delivery. The signature is "sha256=" followed by the
+// hex HMAC-SHA256 of the body keyed with the webhook's secret.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+)
+
+const (
+	// maxRetries is how many times a failed delivery is retried
+	maxRetries = 3
+	// initialBackoff is the wait before the first retry; it doubles after
+	// each one
+	initialBackoff = time.Second
+	// deliveryTimeout bounds a single delivery attempt
+	deliveryTimeout = 10 * time.Second
+)
+
+// WebhookEvent is the body POSTed to a webhook
+type WebhookEvent struct {
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDispatcher delivers events to the webhooks in the store and
+// records the outcome of each delivery
+type WebhookDispatcher struct {
+	store   *database.Store
+	client  *http.Client
+	logger  waLog.Logger
+	backoff time.Duration
+}
+
+// NewWebhookDispatcher creates a dispatcher for the webhooks in store
+func NewWebhookDispatcher(store *database.Store, logger waLog.Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		store:   store,
+		client:  &http.Client{Timeout: deliveryTimeout},
+		logger:  logger,
+		backoff: initialBackoff,
+	}
+}
+
+// Dispatch delivers event to every webhook subscribed to its type at once,
+// retrying each failed delivery up to three times with exponential backoff.
+// It blocks until every delivery has succeeded or run out of retries, and
+// returns the errors of those that did not succeed.
+func (d *WebhookDispatcher) Dispatch(event WebhookEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+
+	webhooks, err := d.store.GetWebhooks()
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(webhooks))
+	for i, w := range webhooks {
+		if !w.Subscribes(event.Type) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = d.deliverWithRetries(w, event.Type, body)
+			if err := d.store.RecordWebhookDelivery(w.ID, errs[i] == nil); err != nil {
+				d.logger.Errorf("Failed to record delivery to webhook %s: %v", w.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// deliverWithRetries POSTs body to a webhook until it succeeds or has been
+// retried maxRetries times
+func (d *WebhookDispatcher) deliverWithRetries(w *database.Webhook, eventType string, body []byte) error {
+	backoff := d.backoff
+	for attempt := 0; ; attempt++ {
+		err := d.deliver(w, eventType, body)
+		if err == nil {
+			return nil
+		}
+		if attempt == maxRetries {
+			return fmt.Errorf("webhook %s: %w", w.ID, err)
+		}
+		d.logger.Warnf("Delivery to webhook %s failed, retrying in %v: %v", w.ID, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// deliver makes a single signed POST of body to a webhook. Any status
+// other than 2xx is a failure.
+func (d *WebhookDispatcher) deliver(w *database.Webhook, eventType string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value of a body delivered to a webhook
+// with the given secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
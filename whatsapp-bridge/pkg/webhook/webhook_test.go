@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"whatsapp-client/pkg/database"
+)
+
+func newTestStore(t *testing.T) *database.Store {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := database.NewStore(dir+"/test.db", dir)
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestDispatch(t *testing.T) {
+	store := newTestStore(t)
+
+	var received atomic.Int32
+	var badSignatures atomic.Int32
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign("secret", body) || r.Header.Get(EventHeader) != EventNewMessage {
+			badSignatures.Add(1)
+		}
+		received.Add(1)
+	}))
+	defer ok.Close()
+
+	var flakyAttempts atomic.Int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if flakyAttempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer flaky.Close()
+
+	var brokenAttempts atomic.Int32
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		brokenAttempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	webhooks := []*database.Webhook{
+		{URL: ok.URL, Secret: "secret", Events: []string{EventNewMessage}},
+		{URL: flaky.URL, Secret: "secret"},
+		{URL: broken.URL, Secret: "secret"},
+		{URL: ok.URL, Secret: "secret", Events: []string{"other"}},
+	}
+	for _, w := range webhooks {
+		if err := store.RegisterWebhook(w); err != nil {
+			t.Fatalf("Failed to register webhook: %v", err)
+		}
+	}
+
+	d := NewWebhookDispatcher(store, waLog.Noop)
+	d.backoff = time.Millisecond
+	err := d.Dispatch(WebhookEvent{Type: EventNewMessage, Data: map[string]string{"id": "msg1"}})
+	if err == nil {
+		t.Fatal("Expected an error for the broken webhook")
+	}
+
+	if received.Load() != 1 || badSignatures.Load() != 0 {
+		t.Errorf("Expected one signed delivery to the subscribed webhook, got %d (%d badly signed)", received.Load(), badSignatures.Load())
+	}
+	if flakyAttempts.Load() != 3 {
+		t.Errorf("Expected the flaky webhook to succeed on its third attempt, got %d attempts", flakyAttempts.Load())
+	}
+	if brokenAttempts.Load() != 1+maxRetries {
+		t.Errorf("Expected %d attempts at the broken webhook, got %d", 1+maxRetries, brokenAttempts.Load())
+	}
+
+	stored, err := store.GetWebhooks()
+	if err != nil {
+		t.Fatalf("Failed to get webhooks: %v", err)
+	}
+	for _, w := range stored {
+		switch w.ID {
+		case webhooks[0].ID, webhooks[1].ID:
+			if w.LastDeliveryAt == nil || w.FailureCount != 0 {
+				t.Errorf("Expected %s to record a delivery, got %v and %d failures", w.URL, w.LastDeliveryAt, w.FailureCount)
+			}
+		case webhooks[2].ID:
+			if w.LastDeliveryAt != nil || w.FailureCount != 1 {
+				t.Errorf("Expected the broken webhook to record a failure, got %v and %d failures", w.LastDeliveryAt, w.FailureCount)
+			}
+		case webhooks[3].ID:
+			if w.LastDeliveryAt != nil || w.FailureCount != 0 {
+				t.Error("Expected no delivery to an unsubscribed webhook")
+			}
+		}
+	}
+}